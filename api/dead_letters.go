@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+const (
+	defaultDeadLetterLimit = 100
+	maxDeadLetterLimit     = 1000
+)
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("GET /dead-letters", routeHandler(slurpee, listDeadLettersHandler))
+		router.Handle("POST /dead-letters/redrive", routeHandler(slurpee, redriveDeadLettersHandler))
+	})
+}
+
+type DeadLetterResponse struct {
+	ID             string    `json:"id"`
+	EventID        string    `json:"event_id"`
+	Subject        string    `json:"subject"`
+	SubscriberID   string    `json:"subscriber_id"`
+	SubscriberName string    `json:"subscriber_name"`
+	EndpointURL    string    `json:"endpoint_url"`
+	SubscriptionID string    `json:"subscription_id"`
+	Attempts       int32     `json:"attempts"`
+	LastError      string    `json:"last_error"`
+	ExhaustedAt    time.Time `json:"exhausted_at"`
+}
+
+type RedriveDeadLettersRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type RedriveDeadLettersResponse struct {
+	Redriven int `json:"redriven"`
+	Skipped  int `json:"skipped"`
+}
+
+func listDeadLettersHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	query := r.URL.Query()
+	params := db.ListDeadLettersFilteredParams{
+		Limit: defaultDeadLetterLimit,
+	}
+
+	if s := query.Get("subscriber_id"); s != "" {
+		parsed, err := uuid.Parse(s)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid subscriber_id"})
+			return
+		}
+		params.SubscriberFilter = pgtype.UUID{Bytes: parsed, Valid: true}
+	}
+
+	// Subject filter uses the same * wildcard as subscription patterns
+	if s := query.Get("subject"); s != "" {
+		params.SubjectFilter = strings.ReplaceAll(s, "*", "%")
+	}
+
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxDeadLetterLimit {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		params.Limit = int32(limit)
+	}
+	if s := query.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "offset must be a non-negative integer"})
+			return
+		}
+		params.Offset = int32(offset)
+	}
+
+	rows, err := slurpee.DB.ListDeadLettersFiltered(r.Context(), params)
+	if err != nil {
+		log(r.Context()).Error("Failed to list dead letters", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list dead letters"})
+		return
+	}
+
+	response := make([]DeadLetterResponse, len(rows))
+	for i, row := range rows {
+		response[i] = DeadLetterResponse{
+			ID:             app.UuidToString(row.ID),
+			EventID:        app.UuidToString(row.EventID),
+			Subject:        row.Subject,
+			SubscriberID:   app.UuidToString(row.SubscriberID),
+			SubscriberName: row.SubscriberName,
+			EndpointURL:    row.EndpointUrl,
+			SubscriptionID: app.UuidToString(row.SubscriptionID),
+			Attempts:       row.Attempts,
+			LastError:      row.LastError,
+			ExhaustedAt:    row.ExhaustedAt.Time,
+		}
+	}
+
+	writeJsonResponse(w, http.StatusOK, response)
+}
+
+func redriveDeadLettersHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	var req RedriveDeadLettersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "ids is required"})
+		return
+	}
+
+	ids := make([]pgtype.UUID, len(req.IDs))
+	for i, s := range req.IDs {
+		parsed, err := uuid.Parse(s)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid dead letter ID: " + s})
+			return
+		}
+		ids[i] = pgtype.UUID{Bytes: parsed, Valid: true}
+	}
+
+	result, err := app.RedriveDeadLetters(r.Context(), slurpee, ids)
+	if err != nil {
+		if errors.Is(err, app.ErrDispatcherNotRunning) {
+			writeJsonResponse(w, http.StatusServiceUnavailable, map[string]string{"error": "Delivery dispatcher is not running"})
+			return
+		}
+		log(r.Context()).Error("Failed to redrive dead letters", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to redrive dead letters"})
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, RedriveDeadLettersResponse{
+		Redriven: result.Redriven,
+		Skipped:  result.Skipped,
+	})
+}
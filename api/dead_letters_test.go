@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/testutil"
+)
+
+// --- GET /api/dead-letters tests ---
+
+func TestListDeadLetters_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodGet, "/dead-letters", nil)
+
+	rec := callHandler(t, slurpee, listDeadLettersHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestListDeadLetters_InvalidSubscriberID(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodGet, "/dead-letters?subscriber_id=not-a-uuid", nil)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, listDeadLettersHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "Invalid subscriber_id")
+}
+
+func TestListDeadLetters_AppliesFilters(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	row := db.ListDeadLettersFilteredRow{
+		ID:             testutil.NewUUID(),
+		EventID:        testutil.NewUUID(),
+		SubscriberID:   subscriber.ID,
+		SubscriptionID: testutil.NewUUID(),
+		Attempts:       6,
+		LastError:      "received HTTP 500",
+		ExhaustedAt:    testutil.NewTimestamp(),
+		Subject:        "order.created",
+		SubscriberName: subscriber.Name,
+		EndpointUrl:    subscriber.EndpointUrl,
+	}
+
+	mockDB.On("ListDeadLettersFiltered", mock.Anything, db.ListDeadLettersFilteredParams{
+		Limit:            10,
+		Offset:           20,
+		SubscriberFilter: subscriber.ID,
+		SubjectFilter:    "order.%",
+	}).Return([]db.ListDeadLettersFilteredRow{row}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodGet,
+		"/dead-letters?subscriber_id="+app.UuidToString(subscriber.ID)+"&subject=order.*&limit=10&offset=20", nil)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, listDeadLettersHandler, req)
+
+	var resp []DeadLetterResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	if assert.Len(t, resp, 1) {
+		assert.Equal(t, app.UuidToString(row.ID), resp[0].ID)
+		assert.Equal(t, "order.created", resp[0].Subject)
+		assert.Equal(t, int32(6), resp[0].Attempts)
+		assert.Equal(t, "received HTTP 500", resp[0].LastError)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+// --- POST /api/dead-letters/redrive tests ---
+
+func TestRedriveDeadLetters_MissingIDs(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/dead-letters/redrive", map[string]any{"ids": []string{}})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, redriveDeadLettersHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "ids is required")
+}
+
+func TestRedriveDeadLetters_InvalidID(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/dead-letters/redrive", map[string]any{"ids": []string{"bogus"}})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, redriveDeadLettersHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "Invalid dead letter ID")
+}
+
+func TestRedriveDeadLetters_DispatcherNotRunning(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/dead-letters/redrive", map[string]any{
+		"ids": []string{app.UuidToString(testutil.NewUUID())},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, redriveDeadLettersHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusServiceUnavailable, "Delivery dispatcher is not running")
+}
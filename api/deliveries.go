@@ -0,0 +1,302 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 1000
+)
+
+var deliveryStatuses = map[string]bool{
+	app.DeliveryPending:   true,
+	app.DeliveryRetrying:  true,
+	app.DeliveryHeld:      true,
+	app.DeliverySucceeded: true,
+	app.DeliveryFailed:    true,
+}
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("GET /subscribers/{id}/deliveries", routeHandler(slurpee, listSubscriberDeliveriesHandler))
+	})
+}
+
+type SubscriberDeliveryResponse struct {
+	EventID        string     `json:"event_id"`
+	Subject        string     `json:"subject"`
+	SubscriptionID *string    `json:"subscription_id"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastError      string     `json:"last_error"`
+	LagMs          *int64     `json:"lag_ms"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type DeliverySummaryResponse struct {
+	Succeeded    int64   `json:"succeeded"`
+	Failed       int64   `json:"failed"`
+	Pending      int64   `json:"pending"`
+	LatencyP50Ms float64 `json:"latency_p50_ms"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+}
+
+type SubscriberDeliveriesResponse struct {
+	Deliveries []SubscriberDeliveryResponse `json:"deliveries"`
+	Summary    DeliverySummaryResponse      `json:"summary"`
+	NextCursor *string                      `json:"next_cursor"`
+}
+
+func listSubscriberDeliveriesHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+	subscriberID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	if !authorizeSubscriberRead(slurpee, w, r, subscriberID) {
+		return
+	}
+
+	query := r.URL.Query()
+	params := db.ListDeliveriesForSubscriberParams{SubscriberID: subscriberID}
+
+	if s := query.Get("status"); s != "" {
+		if !deliveryStatuses[s] {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "status must be one of pending, retrying, held, succeeded, failed"})
+			return
+		}
+		params.StatusFilter = s
+	}
+
+	// Subject filter uses the same * wildcard as subscription patterns
+	if s := query.Get("subject"); s != "" {
+		params.SubjectFilter = subjectLikePattern(s)
+	}
+
+	if s := query.Get("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		params.Since = pgtype.Timestamptz{Time: since, Valid: true}
+	}
+	if s := query.Get("until"); s != "" {
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "until must be an RFC 3339 timestamp"})
+			return
+		}
+		params.Until = pgtype.Timestamptz{Time: until, Valid: true}
+	}
+
+	limit := defaultDeliveryLimit
+	if s := query.Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxDeliveryLimit {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+	}
+	// Fetch one extra row to tell whether there is another page
+	params.RowLimit = int32(limit + 1)
+
+	if s := query.Get("cursor"); s != "" {
+		createdAt, eventID, err := decodeDeliveryCursor(s)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+			return
+		}
+		params.AfterCreatedAt = createdAt
+		params.AfterEventID = eventID
+	}
+
+	rows, err := slurpee.DB.ListDeliveriesForSubscriber(r.Context(), params)
+	if err != nil {
+		log(r.Context()).Error("Failed to list deliveries", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list deliveries"})
+		return
+	}
+
+	// The summary covers every delivery in range, whatever the status filter
+	// or page
+	summary, err := slurpee.DB.GetDeliverySummaryForSubscriber(r.Context(), db.GetDeliverySummaryForSubscriberParams{
+		SubscriberID:  subscriberID,
+		SubjectFilter: params.SubjectFilter,
+		Since:         params.Since,
+		Until:         params.Until,
+	})
+	if err != nil {
+		log(r.Context()).Error("Failed to summarize deliveries", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list deliveries"})
+		return
+	}
+
+	response := SubscriberDeliveriesResponse{
+		Deliveries: make([]SubscriberDeliveryResponse, 0, len(rows)),
+		Summary: DeliverySummaryResponse{
+			Succeeded:    summary.Succeeded,
+			Failed:       summary.Failed,
+			Pending:      summary.Pending,
+			LatencyP50Ms: summary.LatencyP50Ms,
+			LatencyP95Ms: summary.LatencyP95Ms,
+		},
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		cursor := encodeDeliveryCursor(last.CreatedAt, last.EventID)
+		response.NextCursor = &cursor
+	}
+	for _, row := range rows {
+		response.Deliveries = append(response.Deliveries, subscriberDeliveryToResponse(row))
+	}
+
+	writeJsonResponse(w, http.StatusOK, response)
+}
+
+// authorizeSubscriberRead lets the request through with the admin secret, or
+// with an API secret associated with the subscriber so that subscriber owners
+// can read their own deliveries. It writes the error response and returns
+// false otherwise.
+func authorizeSubscriberRead(slurpee *app.Application, w http.ResponseWriter, r *http.Request, subscriberID pgtype.UUID) bool {
+	if adminSecret := r.Header.Get("X-Slurpee-Admin-Secret"); adminSecret != "" {
+		if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+			writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+			return false
+		}
+		_, err := slurpee.DB.GetSubscriberByID(r.Context(), subscriberID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscriber not found"})
+				return false
+			}
+			log(r.Context()).Error("Failed to get subscriber", "error", err)
+			writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list deliveries"})
+			return false
+		}
+		return true
+	}
+
+	secretIDHeader := r.Header.Get("X-Slurpee-Secret-ID")
+	if secretIDHeader == "" {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Missing admin secret or X-Slurpee-Secret-ID header"})
+		return false
+	}
+	secretID, err := uuid.Parse(secretIDHeader)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "X-Slurpee-Secret-ID must be a valid UUID"})
+		return false
+	}
+	secretHeader := r.Header.Get("X-Slurpee-Secret")
+	if secretHeader == "" {
+		slog.Warn("Missing API secret on GET /api/subscribers/{id}/deliveries", "remote_addr", r.RemoteAddr)
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Missing or invalid API secret"})
+		return false
+	}
+	matchedSecret, err := app.ValidateSecretByID(r.Context(), slurpee, secretID, secretHeader)
+	if err != nil {
+		slog.Warn("Invalid API secret on GET /api/subscribers/{id}/deliveries", "remote_addr", r.RemoteAddr)
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Missing or invalid API secret"})
+		return false
+	}
+
+	inScope, err := app.CheckSubscriberScope(r.Context(), slurpee.DB, matchedSecret.ID, subscriberID)
+	if err != nil {
+		log(r.Context()).Error("Failed to check subscriber scope", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list deliveries"})
+		return false
+	}
+	if !inScope {
+		slog.Warn("Subscriber not in scope for API secret", "remote_addr", r.RemoteAddr, "subscriber_id", app.UuidToString(subscriberID))
+		writeJsonResponse(w, http.StatusForbidden, map[string]string{"error": "Subscriber not permitted by API secret scope"})
+		return false
+	}
+	return true
+}
+
+// encodeDeliveryCursor returns an opaque cursor for the page after the
+// delivery with the given creation time and event ID.
+func encodeDeliveryCursor(createdAt pgtype.Timestamptz, eventID pgtype.UUID) string {
+	raw := createdAt.Time.UTC().Format(time.RFC3339Nano) + "," + app.UuidToString(eventID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeDeliveryCursor(cursor string) (pgtype.Timestamptz, pgtype.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	ts, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, fmt.Errorf("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	eventID, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}, err
+	}
+	return pgtype.Timestamptz{Time: createdAt, Valid: true}, pgtype.UUID{Bytes: eventID, Valid: true}, nil
+}
+
+func subscriberDeliveryToResponse(row db.ListDeliveriesForSubscriberRow) SubscriberDeliveryResponse {
+	resp := SubscriberDeliveryResponse{
+		EventID:   app.UuidToString(row.EventID),
+		Subject:   row.Subject,
+		Status:    row.Status,
+		Attempts:  row.Attempts,
+		LastError: row.LastError,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	if row.SubscriptionID.Valid {
+		s := app.UuidToString(row.SubscriptionID)
+		resp.SubscriptionID = &s
+	}
+	if row.LastAttemptAt.Valid {
+		t := row.LastAttemptAt.Time
+		resp.LastAttemptAt = &t
+	}
+	if row.NextAttemptAt.Valid {
+		t := row.NextAttemptAt.Time
+		resp.NextAttemptAt = &t
+	}
+	if row.LagMs.Valid {
+		lag := row.LagMs.Int64
+		resp.LagMs = &lag
+	}
+	return resp
+}
+
+// likeEscaper escapes the characters special to a LIKE pattern with the
+// backslash named by the queries' ESCAPE clause.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// subjectLikePattern converts a subject filter to a LIKE pattern. * matches
+// any sequence of characters; every other character matches itself.
+func subjectLikePattern(subject string) string {
+	return strings.ReplaceAll(likeEscaper.Replace(subject), "*", "%")
+}
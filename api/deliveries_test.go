@@ -0,0 +1,240 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/testutil"
+)
+
+// --- GET /api/subscribers/{id}/deliveries tests ---
+
+func newSubscriberDeliveriesRequest(subscriberID pgtype.UUID, query string) *http.Request {
+	id := app.UuidToString(subscriberID)
+	req := httptest.NewRequest(http.MethodGet, "/subscribers/"+id+"/deliveries"+query, nil)
+	req.SetPathValue("id", id)
+	return req
+}
+
+func TestListSubscriberDeliveries_MissingCredentials(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := newSubscriberDeliveriesRequest(testutil.NewUUID(), "")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Missing admin secret or X-Slurpee-Secret-ID header")
+}
+
+func TestListSubscriberDeliveries_WrongAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := newSubscriberDeliveriesRequest(testutil.NewUUID(), "")
+	testutil.WithAdminSecret(req, "wrong-secret")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestListSubscriberDeliveries_SecretNotAssociated(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	secret := testutil.NewApiSecretWithHash("test-secret")
+	mockDB.On("GetApiSecretByID", mock.Anything, secret.ID).
+		Return(secret, nil)
+	mockDB.On("GetApiSecretSubscriberExists", mock.Anything, db.GetApiSecretSubscriberExistsParams{
+		ApiSecretID:  secret.ID,
+		SubscriberID: subscriber.ID,
+	}).Return(false, nil)
+
+	req := newSubscriberDeliveriesRequest(subscriber.ID, "")
+	testutil.WithSecretHeaders(req, app.UuidToString(secret.ID), "test-secret")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusForbidden, "Subscriber not permitted by API secret scope")
+	mockDB.AssertNotCalled(t, "ListDeliveriesForSubscriber", mock.Anything, mock.Anything)
+}
+
+func TestListSubscriberDeliveries_InvalidStatus(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+
+	req := newSubscriberDeliveriesRequest(subscriber.ID, "?status=lost")
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "status must be one of")
+}
+
+func TestListSubscriberDeliveries_InvalidCursor(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+
+	req := newSubscriberDeliveriesRequest(subscriber.ID, "?cursor=not-a-cursor")
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "Invalid cursor")
+}
+
+func TestListSubscriberDeliveries_AssociatedSecretAppliesFilters(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	secret := testutil.NewApiSecretWithHash("test-secret")
+	mockDB.On("GetApiSecretByID", mock.Anything, secret.ID).
+		Return(secret, nil)
+	mockDB.On("GetApiSecretSubscriberExists", mock.Anything, db.GetApiSecretSubscriberExistsParams{
+		ApiSecretID:  secret.ID,
+		SubscriberID: subscriber.ID,
+	}).Return(true, nil)
+
+	since := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	row := db.ListDeliveriesForSubscriberRow{
+		EventID:        testutil.NewUUID(),
+		SubscriberID:   subscriber.ID,
+		SubscriptionID: testutil.NewUUID(),
+		Status:         app.DeliveryFailed,
+		Attempts:       4,
+		LastAttemptAt:  testutil.NewTimestamp(),
+		LastError:      "received HTTP 500",
+		CreatedAt:      testutil.NewTimestamp(),
+		UpdatedAt:      testutil.NewTimestamp(),
+		Subject:        "order.created",
+	}
+	mockDB.On("ListDeliveriesForSubscriber", mock.Anything, db.ListDeliveriesForSubscriberParams{
+		SubscriberID:  subscriber.ID,
+		StatusFilter:  app.DeliveryFailed,
+		SubjectFilter: "order.%",
+		Since:         pgtype.Timestamptz{Time: since, Valid: true},
+		Until:         pgtype.Timestamptz{Time: until, Valid: true},
+		RowLimit:      11,
+	}).Return([]db.ListDeliveriesForSubscriberRow{row}, nil)
+	mockDB.On("GetDeliverySummaryForSubscriber", mock.Anything, db.GetDeliverySummaryForSubscriberParams{
+		SubscriberID:  subscriber.ID,
+		SubjectFilter: "order.%",
+		Since:         pgtype.Timestamptz{Time: since, Valid: true},
+		Until:         pgtype.Timestamptz{Time: until, Valid: true},
+	}).Return(db.GetDeliverySummaryForSubscriberRow{
+		Succeeded:    40,
+		Failed:       1,
+		Pending:      3,
+		LatencyP50Ms: 120,
+		LatencyP95Ms: 850.5,
+	}, nil)
+
+	req := newSubscriberDeliveriesRequest(subscriber.ID,
+		"?status=failed&subject=order.*&since=2026-02-01T00:00:00Z&until=2026-02-02T00:00:00Z&limit=10")
+	testutil.WithSecretHeaders(req, app.UuidToString(secret.ID), "test-secret")
+
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+
+	var resp SubscriberDeliveriesResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	if assert.Len(t, resp.Deliveries, 1) {
+		assert.Equal(t, app.UuidToString(row.EventID), resp.Deliveries[0].EventID)
+		assert.Equal(t, "order.created", resp.Deliveries[0].Subject)
+		assert.Equal(t, app.DeliveryFailed, resp.Deliveries[0].Status)
+		assert.Equal(t, int32(4), resp.Deliveries[0].Attempts)
+		assert.Nil(t, resp.Deliveries[0].NextAttemptAt)
+		assert.Nil(t, resp.Deliveries[0].LagMs, "Only successful deliveries have a lag")
+	}
+	assert.Equal(t, DeliverySummaryResponse{Succeeded: 40, Failed: 1, Pending: 3, LatencyP50Ms: 120, LatencyP95Ms: 850.5}, resp.Summary)
+	assert.Nil(t, resp.NextCursor, "No cursor on the last page")
+	mockDB.AssertExpectations(t)
+}
+
+func TestSubjectLikePattern(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"order.*", "order.%"},
+		{"order_created", `order\_created`},
+		{"100%", `100\%`},
+		{`a\b*`, `a\\b%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			assert.Equal(t, tt.want, subjectLikePattern(tt.subject))
+		})
+	}
+}
+
+func TestListSubscriberDeliveries_KeysetPagination(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+	mockDB.On("GetDeliverySummaryForSubscriber", mock.Anything, mock.AnythingOfType("db.GetDeliverySummaryForSubscriberParams")).
+		Return(db.GetDeliverySummaryForSubscriberRow{}, nil)
+
+	newRow := func(createdAt time.Time) db.ListDeliveriesForSubscriberRow {
+		return db.ListDeliveriesForSubscriberRow{
+			EventID:      testutil.NewUUID(),
+			SubscriberID: subscriber.ID,
+			Status:       app.DeliverySucceeded,
+			CreatedAt:    pgtype.Timestamptz{Time: createdAt, Valid: true},
+		}
+	}
+	base := time.Date(2026, 2, 1, 12, 0, 0, 123456000, time.UTC)
+	first := newRow(base)
+	second := newRow(base.Add(-time.Second))
+	third := newRow(base.Add(-2 * time.Second))
+
+	mockDB.On("ListDeliveriesForSubscriber", mock.Anything, db.ListDeliveriesForSubscriberParams{
+		SubscriberID: subscriber.ID,
+		RowLimit:     3,
+	}).Return([]db.ListDeliveriesForSubscriberRow{first, second, third}, nil)
+
+	req := newSubscriberDeliveriesRequest(subscriber.ID, "?limit=2")
+	testutil.WithAdminSecret(req, "test-admin-secret")
+	rec := callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+
+	var page SubscriberDeliveriesResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &page)
+	assert.Len(t, page.Deliveries, 2)
+	require.NotNil(t, page.NextCursor)
+
+	// The next page starts after the last delivery returned
+	mockDB.On("ListDeliveriesForSubscriber", mock.Anything, db.ListDeliveriesForSubscriberParams{
+		SubscriberID:   subscriber.ID,
+		AfterCreatedAt: second.CreatedAt,
+		AfterEventID:   second.EventID,
+		RowLimit:       3,
+	}).Return([]db.ListDeliveriesForSubscriberRow{third}, nil)
+
+	req = newSubscriberDeliveriesRequest(subscriber.ID, "?limit=2&cursor="+*page.NextCursor)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+	rec = callHandler(t, slurpee, listSubscriberDeliveriesHandler, req)
+
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &page)
+	if assert.Len(t, page.Deliveries, 1) {
+		assert.Equal(t, app.UuidToString(third.EventID), page.Deliveries[0].EventID)
+	}
+	assert.Nil(t, page.NextCursor)
+	mockDB.AssertExpectations(t)
+}
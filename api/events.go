@@ -13,6 +13,8 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sweater-ventures/slurpee/app"
 	"github.com/sweater-ventures/slurpee/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 func init() {
@@ -84,14 +86,23 @@ func createEventHandler(slurpee *app.Application, w http.ResponseWriter, r *http
 		return
 	}
 
+	// Continue the caller's trace; an invalid traceparent is ignored as the spec requires
+	traceparent := app.ParseTraceparent(r.Header.Get(app.TraceparentHeader))
+	reqTraceID := ""
+	if req.TraceID != nil {
+		reqTraceID = *req.TraceID
+	}
+	ctx, span := app.StartCreateEventSpan(r.Context(), slurpee, app.IngestSpanParent(traceparent, reqTraceID), req.Subject)
+	defer span.End()
+
 	if req.Subject == "" {
 		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "subject is required"})
 		return
 	}
 
 	// Check subject against secret's subject_pattern
-	if !app.CheckSendScope(matchedSecret.SubjectPattern, req.Subject) {
-		slog.Warn("Subject not in scope for API secret", "remote_addr", r.RemoteAddr, "subject", req.Subject, "pattern", matchedSecret.SubjectPattern)
+	if !app.CheckSendScope(matchedSecret.PatternSyntax, matchedSecret.SubjectPattern, req.Subject) {
+		slog.Warn("Subject not in scope for API secret", "remote_addr", r.RemoteAddr, "subject", req.Subject, "pattern", matchedSecret.SubjectPattern, "pattern_syntax", matchedSecret.PatternSyntax)
 		writeJsonResponse(w, http.StatusForbidden, map[string]string{"error": "Subject not permitted by API secret scope"})
 		return
 	}
@@ -137,9 +148,12 @@ func createEventHandler(slurpee *app.Application, w http.ResponseWriter, r *http
 			return
 		}
 		traceID = pgtype.UUID{Bytes: parsed, Valid: true}
+	} else if traceparent.IsValid() {
+		// Link the event to the caller's trace so it can be found by trace_id
+		traceID = pgtype.UUID{Bytes: traceparent.TraceID(), Valid: true}
 	}
 
-	event, err := slurpee.DB.InsertEvent(r.Context(), db.InsertEventParams{
+	event, err := app.InsertEvent(ctx, slurpee, db.InsertEventParams{
 		ID:              eventID,
 		Subject:         req.Subject,
 		Timestamp:       pgtype.Timestamptz{Time: ts, Valid: true},
@@ -148,14 +162,18 @@ func createEventHandler(slurpee *app.Application, w http.ResponseWriter, r *http
 		RetryCount:      0,
 		DeliveryStatus:  "pending",
 		StatusUpdatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		ApiSecretID:     matchedSecret.ID,
 	})
 	if err != nil {
 		log(r.Context()).Error("Failed to insert event", "error", err)
+		span.SetStatus(codes.Error, "failed to insert event")
 		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to create event"})
 		return
 	}
+	span.SetAttributes(attribute.String("slurpee.event.id", app.UuidToString(event.ID)))
 
 	LogEvent(r.Context(), slurpee, event)
+	slurpee.Metrics.EventIngested(event.Subject)
 	// Publish 'created' message to the event bus for SSE clients
 	props := app.ExtractLogProperties(r.Context(), slurpee, event.Subject, event.Data)
 	app.PublishCreatedEvent(slurpee, event, props)
@@ -15,6 +15,7 @@ import (
 	"github.com/sweater-ventures/slurpee/app"
 	"github.com/sweater-ventures/slurpee/db"
 	"github.com/sweater-ventures/slurpee/testutil"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // callHandler invokes an appHandler via routeHandler with the given app and request.
@@ -113,6 +114,30 @@ func TestCreateEvent_SubjectOutOfScope(t *testing.T) {
 	testutil.AssertJSONError(t, rec, http.StatusForbidden, "Subject not permitted by API secret scope")
 }
 
+func TestCreateEvent_TokenScopeDoesNotCrossDots(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	secretID := uuid.Must(uuid.NewV7())
+	secret := testutil.NewApiSecretWithHash("test-secret", func(s *db.ApiSecret) {
+		s.ID = pgtype.UUID{Bytes: secretID, Valid: true}
+		s.SubjectPattern = "orders.*"
+		s.PatternSyntax = "token"
+	})
+
+	mockDB.On("GetApiSecretByID", mock.Anything, pgtype.UUID{Bytes: secretID, Valid: true}).
+		Return(secret, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/events", map[string]any{
+		"subject": "orders.line.added",
+		"data":    map[string]any{"key": "value"},
+	})
+	testutil.WithSecretHeaders(req, secretID.String(), "test-secret")
+
+	rec := callHandler(t, slurpee, createEventHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusForbidden, "Subject not permitted by API secret scope")
+}
+
 func TestCreateEvent_MissingSubject(t *testing.T) {
 	mockDB := new(testutil.MockQuerier)
 	slurpee := testutil.NewTestApp(mockDB)
@@ -228,6 +253,43 @@ func TestCreateEvent_Success(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestCreateEvent_RecordsPublishingSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	secretID := uuid.Must(uuid.NewV7())
+	secret := testutil.NewApiSecretWithHash("test-secret", func(s *db.ApiSecret) {
+		s.ID = pgtype.UUID{Bytes: secretID, Valid: true}
+		s.SubjectPattern = "*"
+	})
+
+	mockDB.On("GetApiSecretByID", mock.Anything, pgtype.UUID{Bytes: secretID, Valid: true}).
+		Return(secret, nil)
+
+	insertedEvent := testutil.NewEvent(func(e *db.Event) {
+		e.Subject = "order.created"
+		e.ApiSecretID = secret.ID
+	})
+	mockDB.On("InsertEvent", mock.Anything, mock.MatchedBy(func(p db.InsertEventParams) bool {
+		return p.ApiSecretID == secret.ID
+	})).Return(insertedEvent, nil)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, "order.created").
+		Return(db.LogConfig{}, assert.AnError)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/events", map[string]any{
+		"subject": "order.created",
+		"data":    map[string]any{"order_id": "123"},
+	})
+	testutil.WithSecretHeaders(req, secretID.String(), "test-secret")
+
+	rec := callHandler(t, slurpee, createEventHandler, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	event := <-slurpee.DeliveryChan
+	assert.Equal(t, secret.ID, event.ApiSecretID)
+	mockDB.AssertExpectations(t)
+}
+
 // --- GET /api/events/{id} tests ---
 
 func TestGetEvent_MissingSecretHeaders(t *testing.T) {
@@ -448,3 +510,80 @@ func TestCreateEvent_SuccessResponseFormat(t *testing.T) {
 
 	mockDB.AssertExpectations(t)
 }
+
+// createTracedEvent posts an event with the given traceparent header and
+// trace_id, returning the parameters the event was inserted with.
+func createTracedEvent(t *testing.T, traceparent string, traceID string) db.InsertEventParams {
+	t.Helper()
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	secretID := uuid.Must(uuid.NewV7())
+	secret := testutil.NewApiSecretWithHash("test-secret", func(s *db.ApiSecret) {
+		s.ID = pgtype.UUID{Bytes: secretID, Valid: true}
+		s.SubjectPattern = "*"
+	})
+	mockDB.On("GetApiSecretByID", mock.Anything, pgtype.UUID{Bytes: secretID, Valid: true}).
+		Return(secret, nil)
+	var params db.InsertEventParams
+	mockDB.On("InsertEvent", mock.Anything, mock.AnythingOfType("db.InsertEventParams")).
+		Run(func(args mock.Arguments) { params = args.Get(1).(db.InsertEventParams) }).
+		Return(testutil.NewEvent(), nil)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, mock.Anything).
+		Return(db.LogConfig{}, assert.AnError)
+
+	body := map[string]any{
+		"subject": "user.updated",
+		"data":    map[string]any{"user_id": "456"},
+	}
+	if traceID != "" {
+		body["trace_id"] = traceID
+	}
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/events", body)
+	testutil.WithSecretHeaders(req, secretID.String(), "test-secret")
+	if traceparent != "" {
+		req.Header.Set("traceparent", traceparent)
+	}
+
+	rec := callHandler(t, slurpee, createEventHandler, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	return params
+}
+
+func TestCreateEvent_TraceparentHeaderSetsTraceID(t *testing.T) {
+	params := createTracedEvent(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+
+	assert.Equal(t, pgtype.UUID{Bytes: uuid.MustParse("4bf92f35-77b3-4da6-a3ce-929d0e0e4736"), Valid: true}, params.TraceID)
+	require.True(t, params.Traceparent.Valid)
+	sc := app.ParseTraceparent(params.Traceparent.String)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.NotEqual(t, "00f067aa0ba902b7", sc.SpanID().String())
+}
+
+func TestCreateEvent_TraceIDTakesPrecedenceOverTraceparent(t *testing.T) {
+	traceID := uuid.Must(uuid.NewV7())
+	params := createTracedEvent(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceID.String())
+
+	assert.Equal(t, pgtype.UUID{Bytes: traceID, Valid: true}, params.TraceID)
+	sc := app.ParseTraceparent(params.Traceparent.String)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+}
+
+func TestCreateEvent_TraceIDNamesTraceWithoutTraceparent(t *testing.T) {
+	traceID := uuid.Must(uuid.NewV7())
+	params := createTracedEvent(t, "", traceID.String())
+
+	sc := app.ParseTraceparent(params.Traceparent.String)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, trace.TraceID(traceID), sc.TraceID())
+}
+
+func TestCreateEvent_InvalidTraceparentIsIgnored(t *testing.T) {
+	params := createTracedEvent(t, "not-a-traceparent", "")
+
+	assert.False(t, params.TraceID.Valid)
+	sc := app.ParseTraceparent(params.Traceparent.String)
+	assert.True(t, sc.IsValid())
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sweater-ventures/slurpee/app"
+)
+
+// AddMetrics registers the Prometheus scrape endpoint at /metrics. It sits
+// outside /api, where scrapers expect it, and is only served when a metrics
+// token is configured.
+func AddMetrics(slurpee *app.Application, router *http.ServeMux) {
+	router.Handle("GET /metrics", routeHandler(slurpee, metricsHandler))
+}
+
+func metricsHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	if slurpee.Config.MetricsToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	// Verify bearer token
+	if r.Header.Get("Authorization") != "Bearer "+slurpee.Config.MetricsToken {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing metrics token"})
+		return
+	}
+
+	promhttp.HandlerFor(app.MetricsGatherer(slurpee), promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(log(r.Context()).Handler(), slog.LevelError),
+	}).ServeHTTP(w, r)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/testutil"
+)
+
+// --- GET /metrics tests ---
+
+func withMetricsToken(token string) testutil.AppOpt {
+	return func(a *app.Application) {
+		a.Config.MetricsToken = token
+	}
+}
+
+func TestMetrics_DisabledWithoutToken(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer ")
+
+	rec := callHandler(t, slurpee, metricsHandler, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestMetrics_InvalidToken(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB, withMetricsToken("scrape-token"))
+
+	for _, header := range []string{"", "Bearer wrong", "scrape-token"} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+
+		rec := callHandler(t, slurpee, metricsHandler, req)
+		testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing metrics token")
+	}
+}
+
+func TestMetrics_Success(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB, withMetricsToken("scrape-token"))
+	slurpee.Metrics.EventIngested("order.created")
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-token")
+
+	rec := callHandler(t, slurpee, metricsHandler, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), `slurpee_events_ingested_total{subject="order.created"} 1`)
+	assert.Contains(t, rec.Body.String(), "slurpee_delivery_chan_capacity 100\n")
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+const (
+	defaultReplayJobLimit = 50
+	maxReplayJobLimit     = 500
+)
+
+var eventDeliveryStatuses = map[string]bool{
+	"pending":   true,
+	"partial":   true,
+	"delivered": true,
+	"failed":    true,
+	"recorded":  true,
+}
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("POST /replay-jobs", routeHandler(slurpee, createReplayJobHandler))
+		router.Handle("GET /replay-jobs", routeHandler(slurpee, listReplayJobsHandler))
+		router.Handle("GET /replay-jobs/{id}", routeHandler(slurpee, getReplayJobHandler))
+		router.Handle("POST /replay-jobs/{id}/cancel", routeHandler(slurpee, cancelReplayJobHandler))
+	})
+}
+
+type CreateReplayJobRequest struct {
+	Subject        string          `json:"subject"`
+	DeliveryStatus string          `json:"delivery_status"`
+	StartTime      *time.Time      `json:"start_time"`
+	EndTime        *time.Time      `json:"end_time"`
+	Data           json.RawMessage `json:"data"`
+	TraceID        string          `json:"trace_id"`
+	SubscriberID   string          `json:"subscriber_id"`
+	RatePerSecond  int32           `json:"rate_per_second"`
+}
+
+type ReplayJobResponse struct {
+	ID             string          `json:"id"`
+	Status         string          `json:"status"`
+	Subject        string          `json:"subject"`
+	DeliveryStatus string          `json:"delivery_status"`
+	StartTime      *time.Time      `json:"start_time"`
+	EndTime        time.Time       `json:"end_time"`
+	Data           json.RawMessage `json:"data"`
+	TraceID        string          `json:"trace_id"`
+	SubscriberID   *string         `json:"subscriber_id"`
+	RatePerSecond  int32           `json:"rate_per_second"`
+	Total          int32           `json:"total"`
+	Processed      int32           `json:"processed"`
+	Replayed       int32           `json:"replayed"`
+	Skipped        int32           `json:"skipped"`
+	Failed         int32           `json:"failed"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	FinishedAt     *time.Time      `json:"finished_at"`
+}
+
+func createReplayJobHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	var req CreateReplayJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	spec := app.ReplayJobSpec{
+		// Subject filter uses the same * wildcard as subscription patterns
+		SubjectFilter: strings.ReplaceAll(req.Subject, "*", "%"),
+		RatePerSecond: req.RatePerSecond,
+	}
+	if req.DeliveryStatus != "" {
+		if !eventDeliveryStatuses[req.DeliveryStatus] {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "delivery_status must be one of pending, partial, delivered, failed, recorded"})
+			return
+		}
+		spec.StatusFilter = req.DeliveryStatus
+	}
+	if req.StartTime != nil {
+		spec.StartTimeFilter = pgtype.Timestamptz{Time: *req.StartTime, Valid: true}
+	}
+	if req.EndTime != nil {
+		spec.EndTimeFilter = pgtype.Timestamptz{Time: *req.EndTime, Valid: true}
+	}
+	if len(req.Data) > 0 && string(req.Data) != "null" {
+		var data map[string]any
+		if err := json.Unmarshal(req.Data, &data); err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "data must be a JSON object"})
+			return
+		}
+		spec.DataFilter = req.Data
+	}
+	if req.TraceID != "" {
+		parsed, err := uuid.Parse(req.TraceID)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "trace_id must be a valid UUID"})
+			return
+		}
+		spec.TraceIDFilter = parsed.String()
+	}
+	if req.SubscriberID != "" {
+		parsed, err := uuid.Parse(req.SubscriberID)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "subscriber_id must be a valid UUID"})
+			return
+		}
+		spec.SubscriberID = pgtype.UUID{Bytes: parsed, Valid: true}
+	}
+	if err := app.ValidateReplayJobSpec(spec); err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	job, err := app.StartReplayJob(r.Context(), slurpee, spec)
+	if err != nil {
+		if errors.Is(err, app.ErrDispatcherNotRunning) {
+			writeJsonResponse(w, http.StatusServiceUnavailable, map[string]string{"error": "Delivery dispatcher is not running"})
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscriber not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to start replay job", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to start replay job"})
+		return
+	}
+
+	log(r.Context()).Info("Replay job started", "replay_job_id", app.UuidToString(job.ID), "total", job.Total)
+	writeJsonResponse(w, http.StatusAccepted, replayJobToResponse(job))
+}
+
+func listReplayJobsHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	limit := defaultReplayJobLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		var err error
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxReplayJobLimit {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 500"})
+			return
+		}
+	}
+
+	jobs, err := slurpee.DB.ListReplayJobs(r.Context(), int32(limit))
+	if err != nil {
+		log(r.Context()).Error("Failed to list replay jobs", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list replay jobs"})
+		return
+	}
+
+	response := make([]ReplayJobResponse, len(jobs))
+	for i, job := range jobs {
+		response[i] = replayJobToResponse(job)
+	}
+	writeJsonResponse(w, http.StatusOK, response)
+}
+
+func getReplayJobHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	parsed, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+
+	job, err := slurpee.DB.GetReplayJob(r.Context(), pgtype.UUID{Bytes: parsed, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "replay job not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get replay job", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get replay job"})
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, replayJobToResponse(job))
+}
+
+func cancelReplayJobHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	parsed, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+	jobID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	job, err := slurpee.DB.GetReplayJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "replay job not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get replay job", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to cancel replay job"})
+		return
+	}
+
+	if err := app.CancelReplayJob(slurpee, jobID); err != nil {
+		writeJsonResponse(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The job stops once the event it is replaying is done, so the returned
+	// status may still be running
+	log(r.Context()).Info("Replay job cancelled", "replay_job_id", app.UuidToString(jobID))
+	writeJsonResponse(w, http.StatusAccepted, replayJobToResponse(job))
+}
+
+func replayJobToResponse(job db.ReplayJob) ReplayJobResponse {
+	resp := ReplayJobResponse{
+		ID:             app.UuidToString(job.ID),
+		Status:         job.Status,
+		Subject:        strings.ReplaceAll(job.SubjectFilter, "%", "*"),
+		DeliveryStatus: job.StatusFilter,
+		EndTime:        job.EndTimeFilter.Time,
+		Data:           json.RawMessage(job.DataFilter),
+		TraceID:        job.TraceIDFilter,
+		RatePerSecond:  job.RatePerSecond,
+		Total:          job.Total,
+		Processed:      job.Processed,
+		Replayed:       job.Replayed,
+		Skipped:        job.Skipped,
+		Failed:         job.Failed,
+		LastError:      job.LastError,
+		CreatedAt:      job.CreatedAt.Time,
+		UpdatedAt:      job.UpdatedAt.Time,
+	}
+	if job.StartTimeFilter.Valid {
+		t := job.StartTimeFilter.Time
+		resp.StartTime = &t
+	}
+	if job.SubscriberID.Valid {
+		s := app.UuidToString(job.SubscriberID)
+		resp.SubscriberID = &s
+	}
+	if job.FinishedAt.Valid {
+		t := job.FinishedAt.Time
+		resp.FinishedAt = &t
+	}
+	return resp
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/testutil"
+)
+
+func newTestReplayJob() db.ReplayJob {
+	return db.ReplayJob{
+		ID:            testutil.NewUUID(),
+		SubjectFilter: "order.%",
+		EndTimeFilter: testutil.NewTimestamp(),
+		DataFilter:    []byte(`{"region":"eu"}`),
+		RatePerSecond: 10,
+		Status:        app.ReplayJobRunning,
+		Total:         40,
+		Processed:     12,
+		Replayed:      10,
+		Skipped:       2,
+		CreatedAt:     testutil.NewTimestamp(),
+		UpdatedAt:     testutil.NewTimestamp(),
+	}
+}
+
+// --- POST /api/replay-jobs tests ---
+
+func TestCreateReplayJob_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/replay-jobs", map[string]any{
+		"subject": "order.*",
+	})
+
+	rec := callHandler(t, slurpee, createReplayJobHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestCreateReplayJob_RequiresFilter(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/replay-jobs", map[string]any{
+		"rate_per_second": 5,
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createReplayJobHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "at least one event filter is required")
+}
+
+func TestCreateReplayJob_InvalidFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+		want string
+	}{
+		{"status", map[string]any{"delivery_status": "lost"}, "delivery_status must be one of"},
+		{"data", map[string]any{"data": []int{1}}, "data must be a JSON object"},
+		{"trace id", map[string]any{"trace_id": "not-a-uuid"}, "trace_id must be a valid UUID"},
+		{"subscriber id", map[string]any{"subject": "a", "subscriber_id": "nope"}, "subscriber_id must be a valid UUID"},
+		{"time range", map[string]any{"start_time": "2026-02-02T00:00:00Z", "end_time": "2026-02-01T00:00:00Z"}, "start_time must be before end_time"},
+		{"rate", map[string]any{"subject": "a", "rate_per_second": 5000}, "rate_per_second must be between 1 and 1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/replay-jobs", tt.body)
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createReplayJobHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.want)
+		})
+	}
+}
+
+func TestCreateReplayJob_DispatcherNotRunning(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/replay-jobs", map[string]any{
+		"subject": "order.*",
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createReplayJobHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusServiceUnavailable, "Delivery dispatcher is not running")
+	mockDB.AssertNotCalled(t, "InsertReplayJob", mock.Anything, mock.Anything)
+}
+
+// --- GET /api/replay-jobs tests ---
+
+func TestListReplayJobs_ReturnsJobs(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	job := newTestReplayJob()
+	mockDB.On("ListReplayJobs", mock.Anything, int32(5)).
+		Return([]db.ReplayJob{job}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/replay-jobs?limit=5", nil)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, listReplayJobsHandler, req)
+
+	var resp []ReplayJobResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	if assert.Len(t, resp, 1) {
+		assert.Equal(t, app.UuidToString(job.ID), resp[0].ID)
+		assert.Equal(t, "order.*", resp[0].Subject, "Subject is returned with the * wildcard")
+		assert.JSONEq(t, `{"region":"eu"}`, string(resp[0].Data))
+		assert.Equal(t, int32(12), resp[0].Processed)
+		assert.Nil(t, resp[0].SubscriberID)
+		assert.Nil(t, resp[0].FinishedAt)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+// --- GET /api/replay-jobs/{id} tests ---
+
+func TestGetReplayJob_NotFound(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	id := testutil.NewUUID()
+	mockDB.On("GetReplayJob", mock.Anything, id).
+		Return(db.ReplayJob{}, pgx.ErrNoRows)
+
+	req := httptest.NewRequest(http.MethodGet, "/replay-jobs/"+app.UuidToString(id), nil)
+	req.SetPathValue("id", app.UuidToString(id))
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, getReplayJobHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "replay job not found")
+}
+
+// --- POST /api/replay-jobs/{id}/cancel tests ---
+
+func TestCancelReplayJob_NotRunning(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	job := newTestReplayJob()
+	job.Status = app.ReplayJobCompleted
+	mockDB.On("GetReplayJob", mock.Anything, job.ID).
+		Return(job, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/replay-jobs/"+app.UuidToString(job.ID)+"/cancel", nil)
+	req.SetPathValue("id", app.UuidToString(job.ID))
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, cancelReplayJobHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusConflict, "replay job is not running")
+}
+
+func TestReplayJobResponse_UnsetFiltersAreNull(t *testing.T) {
+	job := newTestReplayJob()
+	job.DataFilter = nil
+
+	body, err := json.Marshal(replayJobToResponse(job))
+	assert.NoError(t, err)
+
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(body, &fields))
+	assert.Nil(t, fields["start_time"])
+	assert.Nil(t, fields["data"])
+	assert.Nil(t, fields["subscriber_id"])
+}
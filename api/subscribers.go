@@ -3,7 +3,10 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,40 +21,102 @@ func init() {
 		router.Handle("POST /subscribers", routeHandler(slurpee, createSubscriberHandler))
 		router.Handle("GET /subscribers", routeHandler(slurpee, listSubscribersHandler))
 		router.Handle("DELETE /subscribers/{id}", routeHandler(slurpee, deleteSubscriberHandler))
+		router.Handle("POST /subscribers/{id}/enable", routeHandler(slurpee, enableSubscriberHandler))
+		router.Handle("POST /subscribers/{id}/pause", routeHandler(slurpee, pauseSubscriberHandler))
+		router.Handle("POST /subscribers/{id}/resume", routeHandler(slurpee, resumeSubscriberHandler))
 	})
 }
 
 type SubscriptionRequest struct {
-	SubjectPattern string          `json:"subject_pattern"`
-	Filter         json.RawMessage `json:"filter"`
-	MaxRetries     *int32          `json:"max_retries"`
+	SubjectPattern       string          `json:"subject_pattern"`
+	PatternSyntax        string          `json:"pattern_syntax"`
+	Filter               json.RawMessage `json:"filter"`
+	Transform            json.RawMessage `json:"transform"`
+	MaxRetries           *int32          `json:"max_retries"`
+	Ordered              bool            `json:"ordered"`
+	OrderingKey          string          `json:"ordering_key"`
+	RetryPolicy          string          `json:"retry_policy"`
+	RetryIntervalSeconds *int32          `json:"retry_interval_seconds"`
+	RetrySchedule        []int32         `json:"retry_schedule"`
+	RetryMaxAgeSeconds   *int32          `json:"retry_max_age_seconds"`
 }
 
 type CreateSubscriberRequest struct {
-	Name          string                `json:"name"`
-	EndpointURL   string                `json:"endpoint_url"`
-	AuthSecret    string                `json:"auth_secret"`
-	MaxParallel   *int32                `json:"max_parallel"`
-	Subscriptions []SubscriptionRequest `json:"subscriptions"`
+	Name                 string                `json:"name"`
+	EndpointURL          string                `json:"endpoint_url"`
+	AuthSecret           string                `json:"auth_secret"`
+	MaxParallel          *int32                `json:"max_parallel"`
+	SigningMode          string                `json:"signing_mode"`
+	PayloadFormat        string                `json:"payload_format"`
+	BatchSize            *int32                `json:"batch_size"`
+	BatchLingerMs        *int32                `json:"batch_linger_ms"`
+	NonRetryableStatuses []int32               `json:"non_retryable_statuses"`
+	DisableOnGone        bool                  `json:"disable_on_gone"`
+	HTTPMethod           string                `json:"http_method"`
+	TimeoutSeconds       *int32                `json:"timeout_seconds"`
+	ExtraHeaders         map[string]string     `json:"extra_headers"`
+	CABundle             string                `json:"ca_bundle"`
+	ClientCert           string                `json:"client_cert"`
+	ClientKey            string                `json:"client_key"`
+	AuthMode             string                `json:"auth_mode"`
+	OAuthTokenURL        string                `json:"oauth_token_url"`
+	OAuthClientID        string                `json:"oauth_client_id"`
+	OAuthClientSecret    string                `json:"oauth_client_secret"`
+	OAuthScopes          []string              `json:"oauth_scopes"`
+	RateLimitPerSecond   float64               `json:"rate_limit_per_second"`
+	RateLimitBurst       int32                 `json:"rate_limit_burst"`
+	Subscriptions        []SubscriptionRequest `json:"subscriptions"`
 }
 
 type SubscriptionResponse struct {
-	ID             string          `json:"id"`
-	SubjectPattern string          `json:"subject_pattern"`
-	Filter         json.RawMessage `json:"filter"`
-	MaxRetries     *int32          `json:"max_retries"`
-	CreatedAt      time.Time       `json:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at"`
+	ID                   string          `json:"id"`
+	SubjectPattern       string          `json:"subject_pattern"`
+	PatternSyntax        string          `json:"pattern_syntax"`
+	Filter               json.RawMessage `json:"filter"`
+	Transform            json.RawMessage `json:"transform,omitempty"`
+	MaxRetries           *int32          `json:"max_retries"`
+	Ordered              bool            `json:"ordered"`
+	OrderingKey          string          `json:"ordering_key,omitempty"`
+	RetryPolicy          string          `json:"retry_policy"`
+	RetryIntervalSeconds int32           `json:"retry_interval_seconds"`
+	RetrySchedule        []int32         `json:"retry_schedule,omitempty"`
+	RetryMaxAgeSeconds   *int32          `json:"retry_max_age_seconds"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
 }
 
 type SubscriberResponse struct {
-	ID            string                 `json:"id"`
-	Name          string                 `json:"name"`
-	EndpointURL   string                 `json:"endpoint_url"`
-	MaxParallel   int32                  `json:"max_parallel"`
-	CreatedAt     time.Time              `json:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at"`
-	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	EndpointURL          string                 `json:"endpoint_url"`
+	MaxParallel          int32                  `json:"max_parallel"`
+	SigningMode          string                 `json:"signing_mode"`
+	PayloadFormat        string                 `json:"payload_format"`
+	BatchSize            int32                  `json:"batch_size"`
+	BatchLingerMs        int32                  `json:"batch_linger_ms"`
+	NonRetryableStatuses []int32                `json:"non_retryable_statuses"`
+	DisableOnGone        bool                   `json:"disable_on_gone"`
+	HTTPMethod           string                 `json:"http_method"`
+	TimeoutSeconds       int32                  `json:"timeout_seconds"`
+	ExtraHeaderNames     []string               `json:"extra_header_names"`
+	CABundle             string                 `json:"ca_bundle,omitempty"`
+	ClientCert           string                 `json:"client_cert,omitempty"`
+	HasClientKey         bool                   `json:"has_client_key"`
+	AuthMode             string                 `json:"auth_mode"`
+	OAuthTokenURL        string                 `json:"oauth_token_url,omitempty"`
+	OAuthClientID        string                 `json:"oauth_client_id,omitempty"`
+	OAuthScopes          []string               `json:"oauth_scopes,omitempty"`
+	RateLimitPerSecond   float64                `json:"rate_limit_per_second"`
+	RateLimitBurst       int32                  `json:"rate_limit_burst"`
+	QueuedTasks          int                    `json:"queued_tasks"`
+	Disabled             bool                   `json:"disabled"`
+	DisabledAt           *time.Time             `json:"disabled_at,omitempty"`
+	DisabledReason       string                 `json:"disabled_reason,omitempty"`
+	Paused               bool                   `json:"paused"`
+	PausedAt             *time.Time             `json:"paused_at,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+	Subscriptions        []SubscriptionResponse `json:"subscriptions"`
 }
 
 func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
@@ -84,13 +149,103 @@ func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "subscriptions is required"})
 		return
 	}
+	if req.SigningMode == "" {
+		req.SigningMode = app.SigningModeSecret
+	}
+	if !app.ValidSigningMode(req.SigningMode) {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "signing_mode must be 'secret' or 'hmac'"})
+		return
+	}
+	if req.PayloadFormat == "" {
+		req.PayloadFormat = app.PayloadFormatData
+	}
+	if !app.ValidPayloadFormat(req.PayloadFormat) {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "payload_format must be one of 'data', 'envelope', 'cloudevents-binary', 'cloudevents-structured'"})
+		return
+	}
 
-	// Validate subscriptions
-	for _, sub := range req.Subscriptions {
+	batchSize := int32(1)
+	if req.BatchSize != nil {
+		batchSize = *req.BatchSize
+	}
+	if batchSize < 1 || batchSize > app.MaxBatchSize {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "batch_size must be between 1 and 1000"})
+		return
+	}
+	var batchLingerMs int32
+	if req.BatchLingerMs != nil {
+		batchLingerMs = *req.BatchLingerMs
+	}
+	if batchLingerMs < 0 || batchLingerMs > app.MaxBatchLingerMs {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "batch_linger_ms must be between 0 and 60000"})
+		return
+	}
+
+	nonRetryable := req.NonRetryableStatuses
+	if nonRetryable == nil {
+		nonRetryable = app.DefaultNonRetryableStatuses
+	}
+	for _, code := range nonRetryable {
+		if !app.ValidNonRetryableStatus(code) {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "non_retryable_statuses may only contain 4xx codes other than 408 and 429"})
+			return
+		}
+	}
+
+	if !app.ValidRateLimit(req.RateLimitPerSecond, req.RateLimitBurst) {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "rate_limit_per_second and rate_limit_burst must be between 0 and 10000"})
+		return
+	}
+	if req.RateLimitBurst > 0 && req.RateLimitPerSecond == 0 {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "rate_limit_burst requires rate_limit_per_second"})
+		return
+	}
+
+	extraHeaders, clientKeyEncrypted, errMsg := validateHTTPSettings(slurpee, &req)
+	if errMsg != "" {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+	oauthSecretEncrypted, errMsg := validateOAuthSettings(slurpee, &req)
+	if errMsg != "" {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+
+	// Validate subscriptions and apply retry policy defaults
+	for i := range req.Subscriptions {
+		sub := &req.Subscriptions[i]
 		if sub.SubjectPattern == "" {
 			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "subject_pattern is required for each subscription"})
 			return
 		}
+		if sub.PatternSyntax == "" {
+			sub.PatternSyntax = app.PatternSyntaxLike
+		}
+		if !app.ValidPatternSyntax(sub.PatternSyntax) {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "pattern_syntax must be 'like' or 'token'"})
+			return
+		}
+		if err := app.ValidateSubjectPattern(sub.PatternSyntax, sub.SubjectPattern); err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid subject_pattern %q: %v", sub.SubjectPattern, err)})
+			return
+		}
+		if sub.OrderingKey != "" && !sub.Ordered {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "ordering_key requires ordered to be true"})
+			return
+		}
+		if errMsg := validateRetryPolicy(sub); errMsg != "" {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+			return
+		}
+		if _, err := app.ParseFilter(sub.Filter); err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid filter for subject_pattern %q: %v", sub.SubjectPattern, err)})
+			return
+		}
+		if _, err := app.ParseTransform(sub.Transform); err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid transform for subject_pattern %q: %v", sub.SubjectPattern, err)})
+			return
+		}
 	}
 
 	maxParallel := int32(slurpee.Config.MaxParallel)
@@ -101,11 +256,30 @@ func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 	// Upsert subscriber
 	subscriberID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 	subscriber, err := slurpee.DB.UpsertSubscriber(r.Context(), db.UpsertSubscriberParams{
-		ID:          subscriberID,
-		Name:        req.Name,
-		EndpointUrl: req.EndpointURL,
-		AuthSecret:  req.AuthSecret,
-		MaxParallel: maxParallel,
+		ID:                         subscriberID,
+		Name:                       req.Name,
+		EndpointUrl:                req.EndpointURL,
+		AuthSecret:                 req.AuthSecret,
+		MaxParallel:                maxParallel,
+		SigningMode:                req.SigningMode,
+		PayloadFormat:              req.PayloadFormat,
+		BatchSize:                  batchSize,
+		BatchLingerMs:              batchLingerMs,
+		NonRetryableStatuses:       nonRetryable,
+		DisableOnGone:              req.DisableOnGone,
+		HttpMethod:                 req.HTTPMethod,
+		TimeoutSeconds:             *req.TimeoutSeconds,
+		ExtraHeaders:               extraHeaders,
+		CaBundle:                   req.CABundle,
+		ClientCert:                 req.ClientCert,
+		ClientKeyEncrypted:         clientKeyEncrypted,
+		AuthMode:                   req.AuthMode,
+		OauthTokenUrl:              req.OAuthTokenURL,
+		OauthClientID:              req.OAuthClientID,
+		OauthClientSecretEncrypted: oauthSecretEncrypted,
+		OauthScopes:                req.OAuthScopes,
+		RateLimitPerSecond:         req.RateLimitPerSecond,
+		RateLimitBurst:             req.RateLimitBurst,
 	})
 	if err != nil {
 		log(r.Context()).Error("Failed to upsert subscriber", "error", err)
@@ -136,18 +310,34 @@ func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 		if len(sub.Filter) > 0 && string(sub.Filter) != "null" {
 			filter = sub.Filter
 		}
+		var transform []byte
+		if len(sub.Transform) > 0 && string(sub.Transform) != "null" {
+			transform = sub.Transform
+		}
 
 		var maxRetries pgtype.Int4
 		if sub.MaxRetries != nil {
 			maxRetries = pgtype.Int4{Int32: *sub.MaxRetries, Valid: true}
 		}
+		var retryMaxAge pgtype.Int4
+		if sub.RetryMaxAgeSeconds != nil {
+			retryMaxAge = pgtype.Int4{Int32: *sub.RetryMaxAgeSeconds, Valid: true}
+		}
 
 		if existingSub, ok := existingByPattern[sub.SubjectPattern]; ok {
 			// Update existing subscription in place
 			updated, err := slurpee.DB.UpdateSubscription(r.Context(), db.UpdateSubscriptionParams{
-				ID:         existingSub.ID,
-				Filter:     filter,
-				MaxRetries: maxRetries,
+				ID:                   existingSub.ID,
+				Filter:               filter,
+				MaxRetries:           maxRetries,
+				Ordered:              sub.Ordered,
+				OrderingKey:          sub.OrderingKey,
+				RetryPolicy:          sub.RetryPolicy,
+				RetryIntervalSeconds: *sub.RetryIntervalSeconds,
+				RetrySchedule:        sub.RetrySchedule,
+				RetryMaxAgeSeconds:   retryMaxAge,
+				PatternSyntax:        sub.PatternSyntax,
+				Transform:            transform,
 			})
 			if err != nil {
 				log(r.Context()).Error("Failed to update subscription", "error", err, "subject_pattern", sub.SubjectPattern)
@@ -159,11 +349,19 @@ func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 			// Create new subscription
 			subID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 			created, err := slurpee.DB.CreateSubscription(r.Context(), db.CreateSubscriptionParams{
-				ID:             subID,
-				SubscriberID:   subscriber.ID,
-				SubjectPattern: sub.SubjectPattern,
-				Filter:         filter,
-				MaxRetries:     maxRetries,
+				ID:                   subID,
+				SubscriberID:         subscriber.ID,
+				SubjectPattern:       sub.SubjectPattern,
+				Filter:               filter,
+				MaxRetries:           maxRetries,
+				Ordered:              sub.Ordered,
+				OrderingKey:          sub.OrderingKey,
+				RetryPolicy:          sub.RetryPolicy,
+				RetryIntervalSeconds: *sub.RetryIntervalSeconds,
+				RetrySchedule:        sub.RetrySchedule,
+				RetryMaxAgeSeconds:   retryMaxAge,
+				PatternSyntax:        sub.PatternSyntax,
+				Transform:            transform,
 			})
 			if err != nil {
 				log(r.Context()).Error("Failed to create subscription", "error", err, "subject_pattern", sub.SubjectPattern)
@@ -194,23 +392,54 @@ func createSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 		"subscription_count", len(subscriptions),
 	)
 
-	resp := subscriberToResponse(subscriber, subscriptions)
+	resp := subscriberToResponse(slurpee, subscriber, subscriptions)
 	writeJsonResponse(w, http.StatusOK, resp)
 }
 
-func subscriberToResponse(s db.Subscriber, subs []SubscriptionResponse) SubscriberResponse {
+func subscriberToResponse(slurpee *app.Application, s db.Subscriber, subs []SubscriptionResponse) SubscriberResponse {
 	if subs == nil {
 		subs = []SubscriptionResponse{}
 	}
-	return SubscriberResponse{
-		ID:            app.UuidToString(s.ID),
-		Name:          s.Name,
-		EndpointURL:   s.EndpointUrl,
-		MaxParallel:   s.MaxParallel,
-		CreatedAt:     s.CreatedAt.Time,
-		UpdatedAt:     s.UpdatedAt.Time,
-		Subscriptions: subs,
+	resp := SubscriberResponse{
+		ID:                   app.UuidToString(s.ID),
+		Name:                 s.Name,
+		EndpointURL:          s.EndpointUrl,
+		MaxParallel:          s.MaxParallel,
+		SigningMode:          s.SigningMode,
+		PayloadFormat:        s.PayloadFormat,
+		BatchSize:            s.BatchSize,
+		BatchLingerMs:        s.BatchLingerMs,
+		NonRetryableStatuses: s.NonRetryableStatuses,
+		DisableOnGone:        s.DisableOnGone,
+		HTTPMethod:           s.HttpMethod,
+		TimeoutSeconds:       s.TimeoutSeconds,
+		ExtraHeaderNames:     extraHeaderNames(s),
+		CABundle:             s.CaBundle,
+		ClientCert:           s.ClientCert,
+		HasClientKey:         s.ClientKeyEncrypted != "",
+		AuthMode:             s.AuthMode,
+		OAuthTokenURL:        s.OauthTokenUrl,
+		OAuthClientID:        s.OauthClientID,
+		OAuthScopes:          s.OauthScopes,
+		RateLimitPerSecond:   s.RateLimitPerSecond,
+		RateLimitBurst:       s.RateLimitBurst,
+		QueuedTasks:          app.SubscriberQueuedTasks(slurpee, s.ID),
+		Disabled:             s.DisabledAt.Valid,
+		Paused:               s.PausedAt.Valid,
+		DisabledReason:       s.DisabledReason,
+		CreatedAt:            s.CreatedAt.Time,
+		UpdatedAt:            s.UpdatedAt.Time,
+		Subscriptions:        subs,
+	}
+	if s.DisabledAt.Valid {
+		disabledAt := s.DisabledAt.Time
+		resp.DisabledAt = &disabledAt
+	}
+	if s.PausedAt.Valid {
+		pausedAt := s.PausedAt.Time
+		resp.PausedAt = &pausedAt
 	}
+	return resp
 }
 
 func listSubscribersHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
@@ -242,7 +471,7 @@ func listSubscribersHandler(slurpee *app.Application, w http.ResponseWriter, r *
 			subResponses = append(subResponses, subscriptionToResponse(s))
 		}
 
-		response = append(response, subscriberToResponse(sub, subResponses))
+		response = append(response, subscriberToResponse(slurpee, sub, subResponses))
 	}
 
 	if response == nil {
@@ -299,19 +528,288 @@ func deleteSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func enableSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+
+	subscriberID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	// Verify subscriber exists
+	_, err = slurpee.DB.GetSubscriberByID(r.Context(), subscriberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscriber not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to enable subscriber"})
+		return
+	}
+
+	if err := slurpee.DB.EnableSubscriber(r.Context(), subscriberID); err != nil {
+		log(r.Context()).Error("Failed to enable subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to enable subscriber"})
+		return
+	}
+
+	slurpee.SubscriptionCache.Flush()
+
+	log(r.Context()).Info("Subscriber enabled", "subscriber_id", app.UuidToString(subscriberID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pauseSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+
+	subscriberID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	// Verify subscriber exists
+	_, err = slurpee.DB.GetSubscriberByID(r.Context(), subscriberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscriber not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to pause subscriber"})
+		return
+	}
+
+	if err := app.PauseSubscriber(r.Context(), slurpee, subscriberID); err != nil {
+		log(r.Context()).Error("Failed to pause subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to pause subscriber"})
+		return
+	}
+
+	log(r.Context()).Info("Subscriber paused", "subscriber_id", app.UuidToString(subscriberID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func resumeSubscriberHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "id must be a valid UUID"})
+		return
+	}
+
+	subscriberID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	// Verify subscriber exists
+	_, err = slurpee.DB.GetSubscriberByID(r.Context(), subscriberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscriber not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to resume subscriber"})
+		return
+	}
+
+	released, err := app.ResumeSubscriber(r.Context(), slurpee, subscriberID)
+	if err != nil {
+		log(r.Context()).Error("Failed to resume subscriber", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to resume subscriber"})
+		return
+	}
+
+	log(r.Context()).Info("Subscriber resumed", "subscriber_id", app.UuidToString(subscriberID), "released_deliveries", released)
+	writeJsonResponse(w, http.StatusOK, map[string]int64{"released_deliveries": released})
+}
+
+// validateHTTPSettings applies defaults to the subscriber's HTTP client
+// settings and validates them. It returns the extra headers as JSON and the
+// encrypted client key, or an error message for the client.
+func validateHTTPSettings(slurpee *app.Application, req *CreateSubscriberRequest) ([]byte, string, string) {
+	if req.HTTPMethod == "" {
+		req.HTTPMethod = http.MethodPost
+	}
+	if !app.ValidDeliveryMethod(req.HTTPMethod) {
+		return nil, "", "http_method must be one of 'POST', 'PUT', 'PATCH'"
+	}
+	if req.TimeoutSeconds == nil {
+		timeout := int32(app.DefaultDeliveryTimeoutSeconds)
+		req.TimeoutSeconds = &timeout
+	}
+	if !app.ValidDeliveryTimeout(*req.TimeoutSeconds) {
+		return nil, "", "timeout_seconds must be between 1 and 300"
+	}
+	if req.ExtraHeaders == nil {
+		req.ExtraHeaders = map[string]string{}
+	}
+	extraHeaders, _ := json.Marshal(req.ExtraHeaders)
+	if _, err := app.ParseExtraHeaders(extraHeaders); err != nil {
+		return nil, "", err.Error()
+	}
+	if err := app.ValidateTLSSettings(req.CABundle, req.ClientCert, req.ClientKey); err != nil {
+		return nil, "", err.Error()
+	}
+	if req.ClientKey == "" {
+		return extraHeaders, "", ""
+	}
+	clientKeyEncrypted, err := app.EncryptSecret(slurpee.Config.EncryptionKey, req.ClientKey)
+	if errors.Is(err, app.ErrEncryptionKeyMissing) {
+		return nil, "", "client_key requires ENCRYPTION_KEY to be configured"
+	}
+	if err != nil {
+		return nil, "", "client_key could not be encrypted"
+	}
+	return extraHeaders, clientKeyEncrypted, ""
+}
+
+// validateOAuthSettings applies defaults to the subscriber's auth mode and
+// validates its OAuth2 client credentials settings. It returns the encrypted
+// client secret, or an error message for the client.
+func validateOAuthSettings(slurpee *app.Application, req *CreateSubscriberRequest) (string, string) {
+	if req.AuthMode == "" {
+		req.AuthMode = app.AuthModeNone
+	}
+	if !app.ValidAuthMode(req.AuthMode) {
+		return "", "auth_mode must be 'none' or 'oauth2'"
+	}
+	if req.OAuthScopes == nil {
+		req.OAuthScopes = []string{}
+	}
+	if req.AuthMode != app.AuthModeOAuth2 {
+		if req.OAuthTokenURL != "" || req.OAuthClientID != "" || req.OAuthClientSecret != "" || len(req.OAuthScopes) > 0 {
+			return "", "oauth_* fields require auth_mode to be 'oauth2'"
+		}
+		return "", ""
+	}
+	if !app.ValidTokenURL(req.OAuthTokenURL) {
+		return "", "oauth_token_url must be an http or https URL"
+	}
+	if req.OAuthClientID == "" || req.OAuthClientSecret == "" {
+		return "", "oauth_client_id and oauth_client_secret are required when auth_mode is 'oauth2'"
+	}
+	for _, scope := range req.OAuthScopes {
+		if scope == "" || strings.ContainsAny(scope, " \"\\") {
+			return "", "oauth_scopes entries must be non-empty and contain no spaces"
+		}
+	}
+	for name := range req.ExtraHeaders {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			return "", "extra_headers cannot set Authorization when auth_mode is 'oauth2'"
+		}
+	}
+	encrypted, err := app.EncryptSecret(slurpee.Config.EncryptionKey, req.OAuthClientSecret)
+	if errors.Is(err, app.ErrEncryptionKeyMissing) {
+		return "", "oauth_client_secret requires ENCRYPTION_KEY to be configured"
+	}
+	if err != nil {
+		return "", "oauth_client_secret could not be encrypted"
+	}
+	return encrypted, ""
+}
+
+// extraHeaderNames lists the names of a subscriber's extra headers. Their
+// values may be credentials and are never returned.
+func extraHeaderNames(s db.Subscriber) []string {
+	headers, _ := app.ParseExtraHeaders(s.ExtraHeaders)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// validateRetryPolicy checks a subscription's retry policy settings and fills
+// in the defaults. Returns an error message, or "" if the settings are valid.
+func validateRetryPolicy(sub *SubscriptionRequest) string {
+	if sub.RetryPolicy == "" {
+		sub.RetryPolicy = app.RetryPolicyExponential
+	}
+	if !app.ValidRetryPolicy(sub.RetryPolicy) {
+		return "retry_policy must be one of 'exponential', 'exponential-full-jitter', 'exponential-equal-jitter', 'linear', 'fixed', 'schedule'"
+	}
+	if sub.RetryIntervalSeconds == nil {
+		interval := int32(1)
+		sub.RetryIntervalSeconds = &interval
+	}
+	if !app.ValidRetryInterval(*sub.RetryIntervalSeconds) {
+		return "retry_interval_seconds must be between 1 and 86400"
+	}
+	if sub.RetrySchedule == nil {
+		sub.RetrySchedule = []int32{}
+	}
+	if len(sub.RetrySchedule) > 0 && sub.RetryPolicy != app.RetryPolicySchedule {
+		return "retry_schedule requires retry_policy to be 'schedule'"
+	}
+	if sub.RetryPolicy == app.RetryPolicySchedule && len(sub.RetrySchedule) == 0 {
+		return "retry_schedule is required when retry_policy is 'schedule'"
+	}
+	if len(sub.RetrySchedule) > app.MaxRetryScheduleLength {
+		return "retry_schedule may have at most 100 entries"
+	}
+	for _, seconds := range sub.RetrySchedule {
+		if !app.ValidRetryInterval(seconds) {
+			return "retry_schedule entries must be between 1 and 86400"
+		}
+	}
+	if sub.RetryMaxAgeSeconds != nil && *sub.RetryMaxAgeSeconds < 1 {
+		return "retry_max_age_seconds must be positive"
+	}
+	return ""
+}
+
 func subscriptionToResponse(s db.Subscription) SubscriptionResponse {
 	resp := SubscriptionResponse{
-		ID:             app.UuidToString(s.ID),
-		SubjectPattern: s.SubjectPattern,
-		CreatedAt:      s.CreatedAt.Time,
-		UpdatedAt:      s.UpdatedAt.Time,
+		ID:                   app.UuidToString(s.ID),
+		SubjectPattern:       s.SubjectPattern,
+		PatternSyntax:        s.PatternSyntax,
+		Ordered:              s.Ordered,
+		OrderingKey:          s.OrderingKey,
+		RetryPolicy:          s.RetryPolicy,
+		RetryIntervalSeconds: s.RetryIntervalSeconds,
+		RetrySchedule:        s.RetrySchedule,
+		CreatedAt:            s.CreatedAt.Time,
+		UpdatedAt:            s.UpdatedAt.Time,
 	}
 	if len(s.Filter) > 0 {
 		resp.Filter = s.Filter
 	}
+	if len(s.Transform) > 0 {
+		resp.Transform = s.Transform
+	}
 	if s.MaxRetries.Valid {
 		v := s.MaxRetries.Int32
 		resp.MaxRetries = &v
 	}
+	if s.RetryMaxAgeSeconds.Valid {
+		v := s.RetryMaxAgeSeconds.Int32
+		resp.RetryMaxAgeSeconds = &v
+	}
 	return resp
 }
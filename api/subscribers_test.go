@@ -138,6 +138,858 @@ func TestCreateSubscriber_SubscriptionMissingSubjectPattern(t *testing.T) {
 	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "subject_pattern is required for each subscription")
 }
 
+func TestCreateSubscriber_OrderingKeyWithoutOrdered(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.*", "ordering_key": "customer_id"},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "ordering_key requires ordered to be true")
+}
+
+func TestCreateSubscriber_OrderedSubscription(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	subscription := testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+		s.Ordered = true
+		s.OrderingKey = "customer_id"
+	})
+
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.AnythingOfType("db.UpsertSubscriberParams")).
+		Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return p.Ordered && p.OrderingKey == "customer_id"
+	})).Return(subscription, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         subscriber.Name,
+		"endpoint_url": subscriber.EndpointUrl,
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.*", "ordered": true, "ordering_key": "customer_id"},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	if assert.Len(t, resp.Subscriptions, 1) {
+		assert.True(t, resp.Subscriptions[0].Ordered)
+		assert.Equal(t, "customer_id", resp.Subscriptions[0].OrderingKey)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidSigningMode(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"signing_mode": "rsa",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "events.*"},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "signing_mode must be 'secret' or 'hmac'")
+}
+
+func TestCreateSubscriber_InvalidPayloadFormat(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":           "test-sub",
+		"endpoint_url":   "https://example.com/webhook",
+		"auth_secret":    "secret",
+		"payload_format": "xml",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "events.*"},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "payload_format must be one of 'data', 'envelope', 'cloudevents-binary', 'cloudevents-structured'")
+}
+
+func TestCreateSubscriber_PayloadFormat(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.PayloadFormat = app.PayloadFormatCloudEventsStructured
+	})
+
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return p.PayloadFormat == app.PayloadFormatCloudEventsStructured
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":           "test-sub",
+		"endpoint_url":   "https://example.com/webhook",
+		"auth_secret":    "secret",
+		"payload_format": "cloudevents-structured",
+		"subscriptions":  []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, "cloudevents-structured", resp.PayloadFormat)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidBatchSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  string
+	}{
+		{"zero batch size", map[string]any{"batch_size": 0}, "batch_size must be between 1 and 1000"},
+		{"batch size too large", map[string]any{"batch_size": 1001}, "batch_size must be between 1 and 1000"},
+		{"negative linger", map[string]any{"batch_linger_ms": -1}, "batch_linger_ms must be between 0 and 60000"},
+		{"linger too long", map[string]any{"batch_linger_ms": 60001}, "batch_linger_ms must be between 0 and 60000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			body := map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{},
+			}
+			for k, v := range tt.settings {
+				body[k] = v
+			}
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", body)
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscriber_BatchSettings(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.BatchSize = 50
+		s.BatchLingerMs = 200
+	})
+
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return p.BatchSize == 50 && p.BatchLingerMs == 200
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":            "test-sub",
+		"endpoint_url":    "https://example.com/webhook",
+		"auth_secret":     "secret",
+		"batch_size":      50,
+		"batch_linger_ms": 200,
+		"subscriptions":   []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, int32(50), resp.BatchSize)
+	assert.Equal(t, int32(200), resp.BatchLingerMs)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidNonRetryableStatus(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":                   "test-sub",
+		"endpoint_url":           "https://example.com/webhook",
+		"auth_secret":            "secret",
+		"non_retryable_statuses": []int{400, 429},
+		"subscriptions":          []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "non_retryable_statuses may only contain 4xx codes other than 408 and 429")
+}
+
+func TestCreateSubscriber_NonRetryableStatusesDefault(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return assert.ObjectsAreEqual(app.DefaultNonRetryableStatuses, p.NonRetryableStatuses) && !p.DisableOnGone
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":          "test-sub",
+		"endpoint_url":  "https://example.com/webhook",
+		"auth_secret":   "secret",
+		"subscriptions": []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, []int32{400, 404, 410}, resp.NonRetryableStatuses)
+	assert.False(t, resp.Disabled)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_EmptyNonRetryableStatusesRetriesAll(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.NonRetryableStatuses = []int32{}
+		s.DisableOnGone = true
+	})
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return p.NonRetryableStatuses != nil && len(p.NonRetryableStatuses) == 0 && p.DisableOnGone
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":                   "test-sub",
+		"endpoint_url":           "https://example.com/webhook",
+		"auth_secret":            "secret",
+		"non_retryable_statuses": []int{},
+		"disable_on_gone":        true,
+		"subscriptions":          []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.True(t, resp.DisableOnGone)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription map[string]any
+		wantErr      string
+	}{
+		{"unknown policy", map[string]any{"retry_policy": "random"}, "retry_policy must be one of 'exponential', 'exponential-full-jitter', 'exponential-equal-jitter', 'linear', 'fixed', 'schedule'"},
+		{"zero interval", map[string]any{"retry_policy": "fixed", "retry_interval_seconds": 0}, "retry_interval_seconds must be between 1 and 86400"},
+		{"schedule without policy", map[string]any{"retry_schedule": []int{5, 30}}, "retry_schedule requires retry_policy to be 'schedule'"},
+		{"policy without schedule", map[string]any{"retry_policy": "schedule"}, "retry_schedule is required when retry_policy is 'schedule'"},
+		{"schedule entry too long", map[string]any{"retry_policy": "schedule", "retry_schedule": []int{5, 86401}}, "retry_schedule entries must be between 1 and 86400"},
+		{"zero max age", map[string]any{"retry_max_age_seconds": 0}, "retry_max_age_seconds must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			subscription := map[string]any{"subject_pattern": "orders.*"}
+			for k, v := range tt.subscription {
+				subscription[k] = v
+			}
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{subscription},
+			})
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscriber_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  any
+		wantErr string
+	}{
+		{"not an object", []string{"type"}, `invalid filter for subject_pattern "orders.*": filter must be a JSON object`},
+		{"combinator on field", map[string]any{"status": map[string]any{"$any": []string{"a"}}}, `invalid filter for subject_pattern "orders.*": status: $any combines filters and cannot be used on a field`},
+		{"bad comparison", map[string]any{"amount": map[string]any{"$gt": "100"}}, `invalid filter for subject_pattern "orders.*": amount: $gt must be a number`},
+		{"nested error", map[string]any{"$any": []any{map[string]any{"email": map[string]any{"$prefix": 1}}}}, `invalid filter for subject_pattern "orders.*": $any[0].email: $prefix must be a string`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+				"name":         "test-sub",
+				"endpoint_url": "https://example.com/webhook",
+				"auth_secret":  "secret",
+				"subscriptions": []map[string]any{
+					{"subject_pattern": "orders.*", "filter": tt.filter},
+				},
+			})
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+			mockDB.AssertNotCalled(t, "UpsertSubscriber", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateSubscriber_AcceptsFilterOperators(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	filter := `{"$any":[{"customer.tier":{"$in":["gold","platinum"]}},{"amount":{"$gte":100}}]}`
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.AnythingOfType("db.UpsertSubscriberParams")).
+		Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return assert.ObjectsAreEqual(filter, string(p.Filter))
+	})).Return(testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.Filter = []byte(filter)
+	}), nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.*", "filter": json.RawMessage(filter)},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	require.Len(t, resp.Subscriptions, 1)
+	assert.JSONEq(t, filter, string(resp.Subscriptions[0].Filter))
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidSubjectPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     map[string]any
+		wantErr string
+	}{
+		{"unknown syntax", map[string]any{"subject_pattern": "orders.*", "pattern_syntax": "regex"}, "pattern_syntax must be 'like' or 'token'"},
+		{"partial token wildcard", map[string]any{"subject_pattern": "orders.creat*", "pattern_syntax": "token"}, `invalid subject_pattern "orders.creat*": * and > must be whole tokens, as in orders.* or orders.>`},
+		{"tail not last", map[string]any{"subject_pattern": "orders.>.eu", "pattern_syntax": "token"}, `invalid subject_pattern "orders.>.eu": > may only be the last token`},
+		{"empty token", map[string]any{"subject_pattern": "orders..created", "pattern_syntax": "token"}, `invalid subject_pattern "orders..created": tokens must not be empty; check for leading, trailing or repeated dots`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{tt.sub},
+			})
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+			mockDB.AssertNotCalled(t, "UpsertSubscriber", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateSubscriber_TokenPatternSyntax(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.AnythingOfType("db.UpsertSubscriberParams")).
+		Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return p.SubjectPattern == "orders.>" && p.PatternSyntax == "token"
+	})).Return(testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.>"
+		s.PatternSyntax = "token"
+	}), nil)
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return p.SubjectPattern == "users.*" && p.PatternSyntax == "like"
+	})).Return(testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "users.*"
+		s.PatternSyntax = "like"
+	}), nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.>", "pattern_syntax": "token"},
+			{"subject_pattern": "users.*"},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	require.Len(t, resp.Subscriptions, 2)
+	assert.Equal(t, "token", resp.Subscriptions[0].PatternSyntax)
+	assert.Equal(t, "like", resp.Subscriptions[1].PatternSyntax)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_Transform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	transform := `{"order":"$.data.id","kind":"$.subject"}`
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.AnythingOfType("db.UpsertSubscriberParams")).
+		Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return string(p.Transform) == transform
+	})).Return(testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.Transform = []byte(transform)
+	}), nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.*", "transform": json.RawMessage(transform)},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	require.Len(t, resp.Subscriptions, 1)
+	assert.JSONEq(t, transform, string(resp.Subscriptions[0].Transform))
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidTransform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{"subject_pattern": "orders.*", "transform": map[string]any{"items": []string{"$.data.items.0", "$.data..sku"}}},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, `invalid transform for subject_pattern "orders.*": items[1]: reference "$.data..sku" must not contain empty path segments`)
+	mockDB.AssertNotCalled(t, "UpsertSubscriber", mock.Anything, mock.Anything)
+}
+
+func TestCreateSubscriber_RetryPolicy(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.AnythingOfType("db.UpsertSubscriberParams")).
+		Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	created := testutil.NewSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+		s.RetryPolicy = app.RetryPolicySchedule
+		s.RetrySchedule = []int32{5, 30, 300}
+		s.RetryMaxAgeSeconds = pgtype.Int4{Int32: 3600, Valid: true}
+	})
+	mockDB.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(p db.CreateSubscriptionParams) bool {
+		return p.RetryPolicy == app.RetryPolicySchedule &&
+			p.RetryIntervalSeconds == 1 &&
+			assert.ObjectsAreEqual([]int32{5, 30, 300}, p.RetrySchedule) &&
+			p.RetryMaxAgeSeconds == pgtype.Int4{Int32: 3600, Valid: true}
+	})).Return(created, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":         "test-sub",
+		"endpoint_url": "https://example.com/webhook",
+		"auth_secret":  "secret",
+		"subscriptions": []map[string]any{
+			{
+				"subject_pattern":       "orders.*",
+				"retry_policy":          "schedule",
+				"retry_schedule":        []int{5, 30, 300},
+				"retry_max_age_seconds": 3600,
+			},
+		},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	require.Len(t, resp.Subscriptions, 1)
+	assert.Equal(t, "schedule", resp.Subscriptions[0].RetryPolicy)
+	assert.Equal(t, []int32{5, 30, 300}, resp.Subscriptions[0].RetrySchedule)
+	require.NotNil(t, resp.Subscriptions[0].RetryMaxAgeSeconds)
+	assert.Equal(t, int32(3600), *resp.Subscriptions[0].RetryMaxAgeSeconds)
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidHTTPSettings(t *testing.T) {
+	cert, key := testutil.NewCertificatePEM(t)
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  string
+	}{
+		{"unsupported method", map[string]any{"http_method": "GET"}, "http_method must be one of 'POST', 'PUT', 'PATCH'"},
+		{"zero timeout", map[string]any{"timeout_seconds": 0}, "timeout_seconds must be between 1 and 300"},
+		{"timeout too long", map[string]any{"timeout_seconds": 301}, "timeout_seconds must be between 1 and 300"},
+		{"reserved header", map[string]any{"extra_headers": map[string]string{"X-Slurpee-Secret": "override"}}, "cannot be set as an extra header"},
+		{"invalid ca bundle", map[string]any{"ca_bundle": "not a certificate"}, "ca_bundle contains no PEM certificates"},
+		{"cert without key", map[string]any{"client_cert": cert}, "client_cert and client_key must be set together"},
+		{"key without encryption key", map[string]any{"client_cert": cert, "client_key": key}, "client_key requires ENCRYPTION_KEY to be configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			body := map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{},
+			}
+			for k, v := range tt.settings {
+				body[k] = v
+			}
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", body)
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscriber_HTTPSettings(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB, func(a *app.Application) {
+		a.Config.EncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	})
+	cert, key := testutil.NewCertificatePEM(t)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.HttpMethod = http.MethodPatch
+		s.TimeoutSeconds = 10
+		s.ExtraHeaders = []byte(`{"X-Tenant":"acme","Authorization":"Bearer token"}`)
+		s.ClientCert = cert
+		s.ClientKeyEncrypted = "encrypted"
+	})
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		headers, err := app.ParseExtraHeaders(p.ExtraHeaders)
+		if err != nil {
+			return false
+		}
+		// The client key is stored encrypted
+		decrypted, err := app.DecryptSecret(slurpee.Config.EncryptionKey, p.ClientKeyEncrypted)
+		return p.HttpMethod == http.MethodPatch &&
+			p.TimeoutSeconds == 10 &&
+			assert.ObjectsAreEqual(map[string]string{"X-Tenant": "acme", "Authorization": "Bearer token"}, headers) &&
+			p.ClientCert == cert &&
+			err == nil && decrypted == key
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":            "test-sub",
+		"endpoint_url":    "https://example.com/webhook",
+		"auth_secret":     "secret",
+		"http_method":     "PATCH",
+		"timeout_seconds": 10,
+		"extra_headers":   map[string]string{"X-Tenant": "acme", "Authorization": "Bearer token"},
+		"client_cert":     cert,
+		"client_key":      key,
+		"subscriptions":   []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	body := testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, "PATCH", resp.HTTPMethod)
+	assert.Equal(t, int32(10), resp.TimeoutSeconds)
+	assert.Equal(t, []string{"Authorization", "X-Tenant"}, resp.ExtraHeaderNames)
+	assert.True(t, resp.HasClientKey)
+	assert.NotContains(t, string(body), "Bearer token", "Extra header values are never returned")
+	assert.NotContains(t, string(body), "PRIVATE KEY", "Client key is never returned")
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidOAuthSettings(t *testing.T) {
+	oauth := func(extra map[string]any) map[string]any {
+		settings := map[string]any{
+			"auth_mode":           "oauth2",
+			"oauth_token_url":     "https://auth.example.com/oauth/token",
+			"oauth_client_id":     "slurpee",
+			"oauth_client_secret": "client-secret",
+		}
+		for k, v := range extra {
+			settings[k] = v
+		}
+		return settings
+	}
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  string
+	}{
+		{"unknown auth mode", map[string]any{"auth_mode": "basic"}, "auth_mode must be 'none' or 'oauth2'"},
+		{"oauth fields without oauth2", map[string]any{"oauth_client_id": "slurpee"}, "oauth_* fields require auth_mode to be 'oauth2'"},
+		{"relative token url", oauth(map[string]any{"oauth_token_url": "/oauth/token"}), "oauth_token_url must be an http or https URL"},
+		{"missing client secret", oauth(map[string]any{"oauth_client_secret": ""}), "oauth_client_id and oauth_client_secret are required when auth_mode is 'oauth2'"},
+		{"scope with space", oauth(map[string]any{"oauth_scopes": []string{"events write"}}), "oauth_scopes entries must be non-empty and contain no spaces"},
+		{"authorization extra header", oauth(map[string]any{"extra_headers": map[string]string{"authorization": "Basic abc"}}), "extra_headers cannot set Authorization when auth_mode is 'oauth2'"},
+		{"no encryption key", oauth(nil), "oauth_client_secret requires ENCRYPTION_KEY to be configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			body := map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{},
+			}
+			for k, v := range tt.settings {
+				body[k] = v
+			}
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", body)
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscriber_OAuthSettings(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB, func(a *app.Application) {
+		a.Config.EncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	})
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.AuthMode = app.AuthModeOAuth2
+		s.OauthTokenUrl = "https://auth.example.com/oauth/token"
+		s.OauthClientID = "slurpee"
+		s.OauthClientSecretEncrypted = "encrypted"
+		s.OauthScopes = []string{"events:write"}
+	})
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		// The client secret is stored encrypted
+		secret, err := app.DecryptSecret(slurpee.Config.EncryptionKey, p.OauthClientSecretEncrypted)
+		return p.AuthMode == app.AuthModeOAuth2 &&
+			p.OauthTokenUrl == "https://auth.example.com/oauth/token" &&
+			p.OauthClientID == "slurpee" &&
+			assert.ObjectsAreEqual([]string{"events:write"}, p.OauthScopes) &&
+			err == nil && secret == "client-secret"
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":                "test-sub",
+		"endpoint_url":        "https://example.com/webhook",
+		"auth_secret":         "secret",
+		"auth_mode":           "oauth2",
+		"oauth_token_url":     "https://auth.example.com/oauth/token",
+		"oauth_client_id":     "slurpee",
+		"oauth_client_secret": "client-secret",
+		"oauth_scopes":        []string{"events:write"},
+		"subscriptions":       []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	body := testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, "oauth2", resp.AuthMode)
+	assert.Equal(t, "https://auth.example.com/oauth/token", resp.OAuthTokenURL)
+	assert.Equal(t, "slurpee", resp.OAuthClientID)
+	assert.Equal(t, []string{"events:write"}, resp.OAuthScopes)
+	assert.NotContains(t, string(body), "client-secret", "The client secret is never returned")
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_InvalidRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  string
+	}{
+		{"negative rate", map[string]any{"rate_limit_per_second": -1}, "rate_limit_per_second and rate_limit_burst must be between 0 and 10000"},
+		{"rate too high", map[string]any{"rate_limit_per_second": 10001}, "rate_limit_per_second and rate_limit_burst must be between 0 and 10000"},
+		{"burst too high", map[string]any{"rate_limit_per_second": 5, "rate_limit_burst": 10001}, "rate_limit_per_second and rate_limit_burst must be between 0 and 10000"},
+		{"burst without rate", map[string]any{"rate_limit_burst": 10}, "rate_limit_burst requires rate_limit_per_second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(testutil.MockQuerier)
+			slurpee := testutil.NewTestApp(mockDB)
+
+			body := map[string]any{
+				"name":          "test-sub",
+				"endpoint_url":  "https://example.com/webhook",
+				"auth_secret":   "secret",
+				"subscriptions": []map[string]any{},
+			}
+			for k, v := range tt.settings {
+				body[k] = v
+			}
+			req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", body)
+			testutil.WithAdminSecret(req, "test-admin-secret")
+
+			rec := callHandler(t, slurpee, createSubscriberHandler, req)
+			testutil.AssertJSONError(t, rec, http.StatusBadRequest, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscriber_RateLimit(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.RateLimitPerSecond = 2.5
+		s.RateLimitBurst = 5
+	})
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return p.RateLimitPerSecond == 2.5 && p.RateLimitBurst == 5
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":                  "test-sub",
+		"endpoint_url":          "https://example.com/webhook",
+		"auth_secret":           "secret",
+		"rate_limit_per_second": 2.5,
+		"rate_limit_burst":      5,
+		"subscriptions":         []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, 2.5, resp.RateLimitPerSecond)
+	assert.Equal(t, int32(5), resp.RateLimitBurst)
+	assert.Equal(t, 0, resp.QueuedTasks, "Nothing is queued while the dispatcher is not running")
+	mockDB.AssertExpectations(t)
+}
+
+func TestCreateSubscriber_HMACSigningMode(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.SigningMode = app.SigningModeHMAC
+	})
+
+	mockDB.On("UpsertSubscriber", mock.Anything, mock.MatchedBy(func(p db.UpsertSubscriberParams) bool {
+		return p.SigningMode == app.SigningModeHMAC
+	})).Return(subscriber, nil)
+	mockDB.On("ListSubscriptionsForSubscriber", mock.Anything, subscriber.ID).
+		Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/subscribers", map[string]any{
+		"name":          "test-sub",
+		"endpoint_url":  "https://example.com/webhook",
+		"auth_secret":   "secret",
+		"signing_mode":  "hmac",
+		"subscriptions": []map[string]any{},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, createSubscriberHandler, req)
+
+	var resp SubscriberResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, "hmac", resp.SigningMode)
+	mockDB.AssertExpectations(t)
+}
+
 func TestCreateSubscriber_InvalidJSONBody(t *testing.T) {
 	mockDB := new(testutil.MockQuerier)
 	slurpee := testutil.NewTestApp(mockDB)
@@ -275,9 +1127,13 @@ func TestCreateSubscriber_UpdateExistingSubscriptions(t *testing.T) {
 	updatedSub.MaxRetries = pgtype.Int4{Int32: 5, Valid: true}
 	updatedSub.Filter = []byte(`{"type":"urgent"}`)
 	mockDB.On("UpdateSubscription", mock.Anything, db.UpdateSubscriptionParams{
-		ID:         existingSub.ID,
-		Filter:     []byte(`{"type":"urgent"}`),
-		MaxRetries: pgtype.Int4{Int32: 5, Valid: true},
+		ID:                   existingSub.ID,
+		Filter:               []byte(`{"type":"urgent"}`),
+		MaxRetries:           pgtype.Int4{Int32: 5, Valid: true},
+		RetryPolicy:          "exponential",
+		RetryIntervalSeconds: 1,
+		RetrySchedule:        []int32{},
+		PatternSyntax:        "like",
 	}).Return(updatedSub, nil)
 
 	maxRetries := int32(5)
@@ -597,3 +1453,162 @@ func TestDeleteSubscriber_Success(t *testing.T) {
 	assert.Empty(t, rec.Body.String())
 	mockDB.AssertExpectations(t)
 }
+
+func TestEnableSubscriber_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7()).String()
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID+"/enable", nil)
+	req.SetPathValue("id", subscriberID)
+
+	rec := callHandler(t, slurpee, enableSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestEnableSubscriber_NotFound(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7())
+	mockDB.On("GetSubscriberByID", mock.Anything, pgtype.UUID{Bytes: subscriberID, Valid: true}).
+		Return(db.Subscriber{}, pgx.ErrNoRows)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID.String()+"/enable", nil)
+	req.SetPathValue("id", subscriberID.String())
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, enableSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "subscriber not found")
+	mockDB.AssertExpectations(t)
+}
+
+func TestEnableSubscriber_Success(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.DisabledAt = testutil.NewTimestamp()
+		s.DisabledReason = "Endpoint responded 410 Gone"
+	})
+
+	subscriberIDStr := app.UuidToString(subscriber.ID)
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+	mockDB.On("EnableSubscriber", mock.Anything, subscriber.ID).
+		Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberIDStr+"/enable", nil)
+	req.SetPathValue("id", subscriberIDStr)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, enableSubscriberHandler, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestPauseSubscriber_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7()).String()
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID+"/pause", nil)
+	req.SetPathValue("id", subscriberID)
+
+	rec := callHandler(t, slurpee, pauseSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestPauseSubscriber_NotFound(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7())
+	mockDB.On("GetSubscriberByID", mock.Anything, pgtype.UUID{Bytes: subscriberID, Valid: true}).
+		Return(db.Subscriber{}, pgx.ErrNoRows)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID.String()+"/pause", nil)
+	req.SetPathValue("id", subscriberID.String())
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, pauseSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "subscriber not found")
+	mockDB.AssertExpectations(t)
+}
+
+func TestPauseSubscriber_Success(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber()
+	subscriberIDStr := app.UuidToString(subscriber.ID)
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+	mockDB.On("PauseSubscriber", mock.Anything, subscriber.ID).
+		Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberIDStr+"/pause", nil)
+	req.SetPathValue("id", subscriberIDStr)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, pauseSubscriberHandler, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestResumeSubscriber_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7()).String()
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID+"/resume", nil)
+	req.SetPathValue("id", subscriberID)
+
+	rec := callHandler(t, slurpee, resumeSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestResumeSubscriber_NotFound(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriberID := uuid.Must(uuid.NewV7())
+	mockDB.On("GetSubscriberByID", mock.Anything, pgtype.UUID{Bytes: subscriberID, Valid: true}).
+		Return(db.Subscriber{}, pgx.ErrNoRows)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberID.String()+"/resume", nil)
+	req.SetPathValue("id", subscriberID.String())
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, resumeSubscriberHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "subscriber not found")
+	mockDB.AssertExpectations(t)
+}
+
+func TestResumeSubscriber_Success(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	subscriber := testutil.NewSubscriber(func(s *db.Subscriber) {
+		s.PausedAt = testutil.NewTimestamp()
+	})
+	subscriberIDStr := app.UuidToString(subscriber.ID)
+	mockDB.On("GetSubscriberByID", mock.Anything, subscriber.ID).
+		Return(subscriber, nil)
+	mockDB.On("ResumeSubscriber", mock.Anything, subscriber.ID).
+		Return(nil)
+	mockDB.On("ReleaseHeldDeliveryRetries", mock.Anything, subscriber.ID).
+		Return(int64(3), nil)
+	mockDB.On("ReleaseHeldDeliveries", mock.Anything, subscriber.ID).
+		Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscribers/"+subscriberIDStr+"/resume", nil)
+	req.SetPathValue("id", subscriberIDStr)
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, resumeSubscriberHandler, req)
+	var resp map[string]int64
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, int64(3), resp["released_deliveries"])
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+)
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("POST /transforms/preview", routeHandler(slurpee, previewTransformHandler))
+	})
+}
+
+// TransformPreviewRequest names a stored event and the transform to apply to
+// it: either a transform to try out or a subscription whose transform is used.
+type TransformPreviewRequest struct {
+	EventID        string          `json:"event_id"`
+	Transform      json.RawMessage `json:"transform"`
+	SubscriptionID string          `json:"subscription_id"`
+}
+
+type TransformPreviewResponse struct {
+	EventID string          `json:"event_id"`
+	Subject string          `json:"subject"`
+	Data    json.RawMessage `json:"data"`
+	Output  json.RawMessage `json:"output"`
+}
+
+func previewTransformHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	// Verify admin secret
+	adminSecret := r.Header.Get("X-Slurpee-Admin-Secret")
+	if slurpee.Config.AdminSecret == "" || adminSecret != slurpee.Config.AdminSecret {
+		writeJsonResponse(w, http.StatusUnauthorized, map[string]string{"error": "Invalid or missing admin secret"})
+		return
+	}
+
+	var req TransformPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	eventID, err := uuid.Parse(req.EventID)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "event_id must be a valid UUID"})
+		return
+	}
+
+	hasTransform := len(req.Transform) > 0 && string(req.Transform) != "null"
+	if hasTransform == (req.SubscriptionID != "") {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "provide either transform or subscription_id"})
+		return
+	}
+
+	rawTransform := []byte(req.Transform)
+	if !hasTransform {
+		subscriptionID, err := uuid.Parse(req.SubscriptionID)
+		if err != nil {
+			writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": "subscription_id must be a valid UUID"})
+			return
+		}
+		sub, err := slurpee.SubscriptionCache.GetSubscriptionByID(r.Context(), pgtype.UUID{Bytes: subscriptionID, Valid: true})
+		if err != nil {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "subscription not found"})
+			return
+		}
+		rawTransform = sub.Transform
+	}
+
+	transform, err := app.ParseTransform(rawTransform)
+	if err != nil {
+		writeJsonResponse(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid transform: %v", err)})
+		return
+	}
+
+	event, err := slurpee.DB.GetEventByID(r.Context(), pgtype.UUID{Bytes: eventID, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeJsonResponse(w, http.StatusNotFound, map[string]string{"error": "event not found"})
+			return
+		}
+		log(r.Context()).Error("Failed to get event", "error", err)
+		writeJsonResponse(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve event"})
+		return
+	}
+
+	output, err := transform.Apply(event)
+	if err != nil {
+		writeJsonResponse(w, http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("transform failed: %v", err)})
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TransformPreviewResponse{
+		EventID: app.UuidToString(event.ID),
+		Subject: event.Subject,
+		Data:    event.Data,
+		Output:  output,
+	})
+}
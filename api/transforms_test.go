@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/testutil"
+)
+
+func TestPreviewTransform_MissingAdminSecret(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", TransformPreviewRequest{})
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusUnauthorized, "Invalid or missing admin secret")
+}
+
+func TestPreviewTransform_InlineTransform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	event := testutil.NewEvent(func(e *db.Event) {
+		e.Subject = "order.created"
+		e.Data = json.RawMessage(`{"id":"o-1","customer":{"email":"ada@example.com"}}`)
+	})
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", map[string]any{
+		"event_id":  app.UuidToString(event.ID),
+		"transform": map[string]any{"order": "$.data.id", "email": "$.data.customer.email", "kind": "$.subject", "source": "shop"},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+
+	var resp TransformPreviewResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.Equal(t, app.UuidToString(event.ID), resp.EventID)
+	assert.Equal(t, "order.created", resp.Subject)
+	assert.JSONEq(t, string(event.Data), string(resp.Data))
+	assert.JSONEq(t, `{"order":"o-1","email":"ada@example.com","kind":"order.created","source":"shop"}`, string(resp.Output))
+}
+
+func TestPreviewTransform_SubscriptionTransform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	event := testutil.NewEvent()
+	sub := testutil.NewSubscription(func(s *db.Subscription) {
+		s.Transform = []byte(`{"renamed":"$.data.key"}`)
+	})
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{sub}, nil)
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", TransformPreviewRequest{
+		EventID:        app.UuidToString(event.ID),
+		SubscriptionID: app.UuidToString(sub.ID),
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+
+	var resp TransformPreviewResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.JSONEq(t, `{"renamed":"value"}`, string(resp.Output))
+}
+
+func TestPreviewTransform_SubscriptionWithoutTransform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	event := testutil.NewEvent()
+	sub := testutil.NewSubscription()
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{sub}, nil)
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", TransformPreviewRequest{
+		EventID:        app.UuidToString(event.ID),
+		SubscriptionID: app.UuidToString(sub.ID),
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+
+	var resp TransformPreviewResponse
+	testutil.AssertJSONResponse(t, rec, http.StatusOK, &resp)
+	assert.JSONEq(t, string(event.Data), string(resp.Output))
+}
+
+func TestPreviewTransform_UnknownSubscription(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{}, nil)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", TransformPreviewRequest{
+		EventID:        app.UuidToString(testutil.NewUUID()),
+		SubscriptionID: app.UuidToString(testutil.NewUUID()),
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "subscription not found")
+}
+
+func TestPreviewTransform_InvalidTransform(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", map[string]any{
+		"event_id":  app.UuidToString(testutil.NewUUID()),
+		"transform": map[string]any{"kind": "$.type"},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, `invalid transform: kind: unknown reference "$.type"`)
+	mockDB.AssertNotCalled(t, "GetEventByID", mock.Anything, mock.Anything)
+}
+
+func TestPreviewTransform_RequiresExactlyOneSource(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	for _, body := range []map[string]any{
+		{"event_id": app.UuidToString(testutil.NewUUID())},
+		{
+			"event_id":        app.UuidToString(testutil.NewUUID()),
+			"transform":       map[string]any{"a": "$.id"},
+			"subscription_id": app.UuidToString(testutil.NewUUID()),
+		},
+	} {
+		req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", body)
+		testutil.WithAdminSecret(req, "test-admin-secret")
+
+		rec := callHandler(t, slurpee, previewTransformHandler, req)
+		testutil.AssertJSONError(t, rec, http.StatusBadRequest, "provide either transform or subscription_id")
+	}
+}
+
+func TestPreviewTransform_InvalidEventID(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", map[string]any{
+		"event_id":  "not-a-uuid",
+		"transform": map[string]any{"a": "$.id"},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusBadRequest, "event_id must be a valid UUID")
+}
+
+func TestPreviewTransform_EventNotFound(t *testing.T) {
+	mockDB := new(testutil.MockQuerier)
+	slurpee := testutil.NewTestApp(mockDB)
+
+	eventID := testutil.NewUUID()
+	mockDB.On("GetEventByID", mock.Anything, eventID).Return(db.Event{}, pgx.ErrNoRows)
+
+	req := testutil.NewJSONRequest(t, http.MethodPost, "/transforms/preview", map[string]any{
+		"event_id":  app.UuidToString(eventID),
+		"transform": map[string]any{"a": "$.id"},
+	})
+	testutil.WithAdminSecret(req, "test-admin-secret")
+
+	rec := callHandler(t, slurpee, previewTransformHandler, req)
+	testutil.AssertJSONError(t, rec, http.StatusNotFound, "event not found")
+}
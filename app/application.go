@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sweater-ventures/slurpee/config"
 	"github.com/sweater-ventures/slurpee/db"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Application struct {
@@ -18,11 +19,23 @@ type Application struct {
 	SecretCache       *Cache[pgtype.UUID, db.ApiSecret]
 	LogConfigCache    *Cache[string, db.LogConfig]
 	SubscriptionCache *SubscriptionCache
+	HTTPClients       *HTTPClientPool
+	OAuthTokens       *OAuthTokenCache
+	Metrics           *Metrics
+	TracerProvider    *sdktrace.TracerProvider
 	dbconn            *pgxpool.Pool
 	stopDelivery      func()
+	dispatcher        *DispatcherState
 }
 
 func NewApp(config *config.AppConfig) (*Application, error) {
+	if config.EncryptionKey != "" {
+		if err := ValidateEncryptionKey(config.EncryptionKey); err != nil {
+			slog.Error("Invalid encryption key", "error", err)
+			return nil, err
+		}
+	}
+
 	conn, err := connectToDB(config)
 	queries := db.New(conn)
 	if err != nil {
@@ -39,6 +52,10 @@ func NewApp(config *config.AppConfig) (*Application, error) {
 		SecretCache:       NewCache[pgtype.UUID, db.ApiSecret](),
 		LogConfigCache:    NewCache[string, db.LogConfig](),
 		SubscriptionCache: NewSubscriptionCache(queries),
+		HTTPClients:       NewHTTPClientPool(),
+		OAuthTokens:       NewOAuthTokenCache(),
+		Metrics:           NewMetrics(),
+		TracerProvider:    newTracerProvider(config),
 		dbconn:            conn,
 		stopDelivery:      func() {},
 	}, nil
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/webhook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Limits on subscriber batching settings. A batch size of 1 disables batching.
+const (
+	MaxBatchSize     = 1000
+	MaxBatchLingerMs = 60000
+)
+
+// pendingBatch collects tasks for one subscriber until it is flushed.
+type pendingBatch struct {
+	tasks []deliveryTask
+	timer *time.Timer
+}
+
+// deliveryBatcher accumulates delivery tasks for subscribers with batching
+// enabled. A subscriber's batch is flushed once it holds batch_size tasks or
+// batch_linger_ms after its first task arrived, whichever comes first.
+// A full batch is sent by flush in the worker that filled it; a lingering
+// batch is passed to handoff, which queues it for a worker, so every request
+// is sent by the worker pool.
+type deliveryBatcher struct {
+	mu      sync.Mutex
+	pending map[[16]byte]*pendingBatch
+	flush   func([]deliveryTask)
+	handoff func([]deliveryTask)
+}
+
+func newDeliveryBatcher(flush, handoff func([]deliveryTask)) *deliveryBatcher {
+	return &deliveryBatcher{
+		pending: make(map[[16]byte]*pendingBatch),
+		flush:   flush,
+		handoff: handoff,
+	}
+}
+
+// add appends a task to its subscriber's pending batch. A batch that reaches
+// the subscriber's batch size is flushed in the calling goroutine; otherwise
+// the linger timer hands it off.
+func (b *deliveryBatcher) add(task deliveryTask) {
+	id := task.subscriber.ID.Bytes
+
+	b.mu.Lock()
+	batch, ok := b.pending[id]
+	if !ok {
+		batch = &pendingBatch{}
+		b.pending[id] = batch
+		linger := time.Duration(task.subscriber.BatchLingerMs) * time.Millisecond
+		batch.timer = time.AfterFunc(linger, func() {
+			b.flushPending(id, batch)
+		})
+	}
+	batch.tasks = append(batch.tasks, task)
+	if len(batch.tasks) < int(task.subscriber.BatchSize) {
+		b.mu.Unlock()
+		return
+	}
+	batch.timer.Stop()
+	delete(b.pending, id)
+	b.mu.Unlock()
+
+	b.flush(batch.tasks)
+}
+
+// flushPending hands off batch if it is still the subscriber's pending batch.
+// A batch that already filled up has been flushed by add. Called by the linger
+// timer, which must not send the request itself.
+func (b *deliveryBatcher) flushPending(id [16]byte, batch *pendingBatch) {
+	b.mu.Lock()
+	if b.pending[id] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, id)
+	b.mu.Unlock()
+
+	b.handoff(batch.tasks)
+}
+
+// processBatch delivers a batch of tasks for one subscriber in a single
+// request and completes each task with its own outcome, so events rejected by
+// the subscriber are retried individually. The request counts as one success
+// or failure towards the subscriber's circuit breaker.
+// Each event is reshaped by the transform of the subscription it matched. An
+// event whose transform fails is left out of the request and fails without
+// retrying, as a single delivery would.
+func processBatch(
+	slurpee *Application,
+	tasks []deliveryTask,
+	getSemaphore func([16]byte, int32) chan struct{},
+	ds *DispatcherState,
+) {
+	ctx := context.Background()
+	subscriber := tasks[0].subscriber
+
+	sending := make([]deliveryTask, 0, len(tasks))
+	events := make([]db.Event, 0, len(tasks))
+	probe := false
+	for _, task := range tasks {
+		probe = probe || task.probe
+		event, err := slurpee.SubscriptionCache.transformEventData(task.event, task.subscription)
+		if err != nil {
+			failTransformedTask(ctx, slurpee, task, err, ds)
+			ds.inflightWg.Done()
+			continue
+		}
+		sending = append(sending, task)
+		events = append(events, event)
+	}
+	if len(sending) == 0 {
+		// No request was sent, so the breaker learns nothing from this batch
+		if probe {
+			ds.breakers.releaseProbe(subscriber.ID.Bytes)
+		}
+		return
+	}
+	tasks = sending
+
+	batchID := uuid.Must(uuid.NewV7()).String()
+	logger := slog.Default().With("batch_id", batchID, "batch_size", len(tasks))
+
+	sem := getSemaphore(subscriber.ID.Bytes, subscriber.MaxParallel)
+	sem <- struct{}{}
+	outcomes, accepted := deliverBatch(ctx, slurpee, subscriber, batchID, tasks, events, logger)
+	<-sem
+
+	recordBreakerOutcome(slurpee, ds, subscriber, accepted, logger)
+	if outcomes[0].statusCode == http.StatusGone && subscriber.DisableOnGone {
+		disableGoneSubscriber(ctx, slurpee, subscriber, logger)
+	}
+	for i, task := range tasks {
+		completeDeliveryTask(ctx, slurpee, task, outcomes[i], ds)
+		ds.inflightWg.Done()
+	}
+}
+
+// deliverBatch sends events to the subscriber as a JSON array, identified by
+// batchID, and records one delivery attempt per event; events[i] is the
+// payload of tasks[i]. It returns an outcome per task and whether the
+// subscriber accepted the request with a 2xx response. A 2xx response may
+// carry a webhook.BatchResponse listing events that failed; all others
+// succeeded. Any other response fails every event in the batch.
+func deliverBatch(ctx context.Context, slurpee *Application, subscriber db.Subscriber, batchID string, tasks []deliveryTask, events []db.Event, logger *slog.Logger) ([]deliveryOutcome, bool) {
+	logger = logger.With("subscriber_id", UuidToString(subscriber.ID), "endpoint_url", subscriber.EndpointUrl)
+	now := time.Now().UTC()
+	outcomes := make([]deliveryOutcome, len(tasks))
+
+	// A batch spans several traces, so its span links to each event's instead
+	// of having a parent
+	var links []trace.Link
+	for _, task := range tasks {
+		if sc := eventSpanContext(task.event); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	ctx, span := slurpee.tracer().Start(ctx, spanDeliverBatch,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.String("slurpee.subscriber.id", UuidToString(subscriber.ID)),
+			attribute.String("url.full", subscriber.EndpointUrl),
+			attribute.String("slurpee.batch.id", batchID),
+			attribute.Int("slurpee.batch.size", len(tasks)),
+		),
+	)
+	defer span.End()
+
+	failAll := func(reqHeadersJSON []byte, duration time.Duration, errMsg string) ([]deliveryOutcome, bool) {
+		span.SetStatus(codes.Error, errMsg)
+		for i, task := range tasks {
+			attemptID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
+			recordFailedAttempt(ctx, slurpee, attemptID, task.event, subscriber, reqHeadersJSON, now, duration, errMsg)
+			outcomes[i] = deliveryOutcome{err: errMsg, duration: duration}
+		}
+		return outcomes, false
+	}
+
+	body, payloadHeaders, err := buildBatchPayload(cloudEventsSource(slurpee), events, subscriber.PayloadFormat)
+	if err != nil {
+		logger.Error("Failed to build batch payload", "error", err, "payload_format", subscriber.PayloadFormat)
+		return failAll(nil, 0, fmt.Sprintf("payload build failed: %v", err))
+	}
+
+	// Build request headers; the recorded copy never contains the auth secret
+	reqHeaders, recordedHeaders := buildBatchHeaders(batchID, len(tasks), subscriber, body, payloadHeaders, now)
+	injectTraceparent(span, reqHeaders, recordedHeaders)
+	reqHeadersJSON, _ := json.Marshal(recordedHeaders)
+
+	start := time.Now()
+	resp, err := sendDelivery(ctx, slurpee, subscriber, body, reqHeaders)
+	if err != nil {
+		logger.Error("Batch request failed", "error", err)
+		return failAll(reqHeadersJSON, time.Since(start), err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // Limit to 1MB
+	respHeadersJSON, _ := json.Marshal(resp.Header)
+	duration := time.Since(start)
+
+	accepted := resp.StatusCode >= 200 && resp.StatusCode < 300
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if !accepted {
+		span.SetStatus(codes.Error, fmt.Sprintf("received HTTP %d", resp.StatusCode))
+	}
+	rejected := make(map[string]bool)
+	if accepted {
+		var batchResp webhook.BatchResponse
+		if json.Unmarshal(respBody, &batchResp) == nil {
+			for _, id := range batchResp.Failed {
+				rejected[id] = true
+			}
+		}
+	}
+
+	failed := 0
+	for i, task := range tasks {
+		eventID := UuidToString(task.event.ID)
+		outcome := deliveryOutcome{succeeded: true, statusCode: resp.StatusCode}
+		if !accepted {
+			outcome = deliveryOutcome{
+				statusCode: resp.StatusCode,
+				err:        fmt.Sprintf("received HTTP %d", resp.StatusCode),
+				permanent:  isNonRetryableStatus(subscriber, resp.StatusCode),
+				retryAfter: retryAfterDelay(resp, now),
+			}
+		} else if rejected[eventID] {
+			outcome = deliveryOutcome{statusCode: resp.StatusCode, err: "rejected in batch response"}
+		}
+		outcome.duration = duration
+		outcomes[i] = outcome
+
+		status := "succeeded"
+		if !outcome.succeeded {
+			status = "failed"
+			failed++
+		}
+
+		_, err := slurpee.DB.InsertDeliveryAttempt(ctx, db.InsertDeliveryAttemptParams{
+			ID:                 pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true},
+			EventID:            task.event.ID,
+			SubscriberID:       subscriber.ID,
+			EndpointUrl:        subscriber.EndpointUrl,
+			AttemptedAt:        pgtype.Timestamptz{Time: now, Valid: true},
+			RequestHeaders:     reqHeadersJSON,
+			ResponseStatusCode: pgtype.Int4{Int32: int32(resp.StatusCode), Valid: true},
+			ResponseHeaders:    respHeadersJSON,
+			ResponseBody:       string(respBody),
+			Status:             status,
+			DurationMs:         attemptDurationMs(duration),
+		})
+		if err != nil {
+			logger.Error("Failed to record delivery attempt", "error", err, "event_id", eventID)
+		}
+
+		// Publish 'delivery_attempt' message to the event bus for SSE clients
+		slurpee.EventBus.Publish(BusMessage{
+			Type:               BusMessageDeliveryAttempt,
+			EventID:            eventID,
+			Subject:            task.event.Subject,
+			DeliveryStatus:     task.event.DeliveryStatus,
+			Timestamp:          now,
+			SubscriberEndpoint: subscriber.EndpointUrl,
+			AttemptStatus:      status,
+			ResponseStatusCode: resp.StatusCode,
+		})
+	}
+
+	if failed == 0 {
+		logger.Info("Batch delivery succeeded", "status_code", resp.StatusCode, "duration_ms", duration.Milliseconds())
+	} else {
+		logger.Warn("Batch delivery failed", "status_code", resp.StatusCode, "failed_events", failed, "duration_ms", duration.Milliseconds())
+	}
+	return outcomes, accepted
+}
@@ -0,0 +1,366 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/webhook"
+)
+
+func newBatchedTask(subscriber db.Subscriber) deliveryTask {
+	event := newTestEvent()
+	return deliveryTask{
+		event:        event,
+		subscription: newTestSubscription(func(s *db.Subscription) { s.SubscriberID = subscriber.ID }),
+		subscriber:   subscriber,
+		maxRetries:   3,
+		tracker: &eventTracker{
+			event:    event,
+			expected: 1,
+			results:  make(map[[16]byte]deliveryResult),
+			logger:   slog.Default(),
+		},
+	}
+}
+
+// taskEvents returns the untransformed events of tasks, as deliverBatch takes
+// them.
+func taskEvents(tasks []deliveryTask) []db.Event {
+	events := make([]db.Event, len(tasks))
+	for i, task := range tasks {
+		events[i] = task.event
+	}
+	return events
+}
+
+func TestDeliveryBatcher_FlushesAtBatchSize(t *testing.T) {
+	flushed := make(chan []deliveryTask, 2)
+	batcher := newDeliveryBatcher(func(tasks []deliveryTask) { flushed <- tasks }, func(tasks []deliveryTask) {
+		t.Error("A full batch should be flushed by the worker that filled it")
+	})
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.BatchSize = 3
+		s.BatchLingerMs = 60000
+	})
+
+	batcher.add(newBatchedTask(subscriber))
+	batcher.add(newBatchedTask(subscriber))
+	assert.Equal(t, 0, len(flushed), "Batch should wait until it is full")
+
+	batcher.add(newBatchedTask(subscriber))
+	require.Equal(t, 1, len(flushed))
+	assert.Len(t, <-flushed, 3)
+	assert.Empty(t, batcher.pending)
+}
+
+func TestDeliveryBatcher_HandsOffAfterLinger(t *testing.T) {
+	flushed := make(chan []deliveryTask, 2)
+	batcher := newDeliveryBatcher(func(tasks []deliveryTask) {
+		t.Error("A lingering batch should be handed off, not sent by the timer")
+	}, func(tasks []deliveryTask) { flushed <- tasks })
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.BatchSize = 10
+		s.BatchLingerMs = 20
+	})
+	other := newTestSubscriber(func(s *db.Subscriber) {
+		s.BatchSize = 10
+		s.BatchLingerMs = 20
+	})
+
+	batcher.add(newBatchedTask(subscriber))
+	batcher.add(newBatchedTask(subscriber))
+	batcher.add(newBatchedTask(other))
+
+	sizes := map[[16]byte]int{}
+	for i := 0; i < 2; i++ {
+		select {
+		case tasks := <-flushed:
+			sizes[tasks[0].subscriber.ID.Bytes] = len(tasks)
+		case <-time.After(time.Second):
+			t.Fatal("Batch was not handed off after the linger time")
+		}
+	}
+	assert.Equal(t, 2, sizes[subscriber.ID.Bytes], "Each subscriber should get its own batch")
+	assert.Equal(t, 1, sizes[other.ID.Bytes])
+}
+
+func TestProcessBatch_PartialSuccessRetriesRejectedEvents(t *testing.T) {
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.BatchSize = 2
+		s.PayloadFormat = PayloadFormatData
+	})
+	first := newBatchedTask(subscriber)
+	second := newBatchedTask(subscriber)
+
+	var receivedHeaders http.Header
+	var received []EventEnvelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		json.NewEncoder(w).Encode(webhook.BatchResponse{Failed: []string{UuidToString(second.event.ID)}})
+	}))
+	defer server.Close()
+	subscriber.EndpointUrl = server.URL
+	first.subscriber = subscriber
+	second.subscriber = subscriber
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	registry.register(first.event.ID.Bytes, first.tracker)
+	registry.register(second.event.ID.Bytes, second.tracker)
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	send := func(tasks []deliveryTask) {
+		processBatch(app, tasks, getSemaphore, ds)
+	}
+	ds.batcher = newDeliveryBatcher(send, send)
+
+	inflightWg.Add(2)
+	processDeliveryTask(app, first, getSemaphore, ds)
+	processDeliveryTask(app, second, getSemaphore, ds)
+	inflightWg.Wait()
+
+	// One request carrying both events as envelopes
+	require.Len(t, received, 2)
+	assert.Equal(t, UuidToString(first.event.ID), received[0].ID)
+	assert.Equal(t, UuidToString(second.event.ID), received[1].ID)
+	assert.NotEmpty(t, receivedHeaders.Get(webhook.HeaderBatchID))
+	assert.Equal(t, "2", receivedHeaders.Get(webhook.HeaderBatchSize))
+	assert.Equal(t, "application/json", receivedHeaders.Get("Content-Type"))
+
+	// One delivery attempt per event, failed only for the rejected event
+	mockDB.AssertNumberOfCalls(t, "InsertDeliveryAttempt", 2)
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return p.EventID == first.event.ID && p.Status == "succeeded"
+	}))
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return p.EventID == second.event.ID && p.Status == "failed"
+	}))
+
+	// The accepted event is delivered; the rejected one is scheduled for retry
+	mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.ID == first.event.ID && p.DeliveryStatus == "delivered"
+	}))
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == second.event.ID && p.AttemptNum == 1
+	}))
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == first.event.ID
+	}))
+}
+
+func TestProcessDeliveryTask_SendsHandedOffBatch(t *testing.T) {
+	var received []EventEnvelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.BatchSize = 10
+	})
+	tasks := []deliveryTask{newBatchedTask(subscriber), newBatchedTask(subscriber)}
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	for _, task := range tasks {
+		registry.register(task.event.ID.Bytes, task.tracker)
+	}
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+
+	inflightWg.Add(len(tasks))
+	processDeliveryTask(app, deliveryTask{batch: tasks}, getSemaphore, ds)
+	inflightWg.Wait()
+
+	require.Len(t, received, 2)
+	mockDB.AssertNumberOfCalls(t, "InsertDeliveryAttempt", 2)
+}
+
+func TestDeliverBatch_ErrorResponseFailsAllEvents(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.BatchSize = 2
+		s.PayloadFormat = PayloadFormatCloudEventsBinary
+	})
+	tasks := []deliveryTask{newBatchedTask(subscriber), newBatchedTask(subscriber)}
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return p.Status == "failed" && p.ResponseStatusCode.Int32 == http.StatusServiceUnavailable
+	})).Return(db.DeliveryAttempt{}, nil)
+
+	outcomes, accepted := deliverBatch(t.Context(), app, subscriber, "test-batch", tasks, taskEvents(tasks), slog.Default())
+
+	assert.False(t, accepted)
+	require.Len(t, outcomes, 2)
+	for _, outcome := range outcomes {
+		assert.False(t, outcome.succeeded)
+		assert.Equal(t, "received HTTP 503", outcome.err)
+	}
+	assert.Equal(t, "application/cloudevents-batch+json", contentType)
+	mockDB.AssertNumberOfCalls(t, "InsertDeliveryAttempt", 2)
+}
+
+func TestProcessBatch_AppliesEachSubscriptionTransform(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.BatchSize = 3
+	})
+	transformed := newBatchedTask(subscriber)
+	transformed.subscription.Transform = []byte(`{"value": "$.data.key"}`)
+	broken := newBatchedTask(subscriber)
+	broken.subscription.Transform = []byte(`{"value": "$.nope"}`)
+	plain := newBatchedTask(subscriber)
+	tasks := []deliveryTask{transformed, broken, plain}
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	for _, task := range tasks {
+		registry.register(task.event.ID.Bytes, task.tracker)
+	}
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	ds.breakers = newBreakerRegistry(1, time.Minute)
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+
+	inflightWg.Add(len(tasks))
+	processBatch(app, tasks, getSemaphore, ds)
+	inflightWg.Wait()
+
+	// The event whose transform fails is left out of the request
+	var batch []EventEnvelope
+	require.NoError(t, json.Unmarshal(body, &batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, UuidToString(transformed.event.ID), batch[0].ID)
+	assert.JSONEq(t, `{"value":"value"}`, string(batch[0].Data))
+	assert.Equal(t, UuidToString(plain.event.ID), batch[1].ID)
+	assert.JSONEq(t, `{"key":"value"}`, string(batch[1].Data))
+
+	// and fails without retrying, while the others are delivered
+	mockDB.AssertCalled(t, "UpsertDeadLetter", mock.Anything, mock.MatchedBy(func(p db.UpsertDeadLetterParams) bool {
+		return p.EventID == broken.event.ID
+	}))
+	mockDB.AssertNumberOfCalls(t, "UpsertDeadLetter", 1)
+	for _, task := range []deliveryTask{transformed, plain} {
+		mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+			return p.ID == task.event.ID && p.DeliveryStatus == "delivered"
+		}))
+	}
+	assert.Equal(t, BreakerClosed, ds.breakers.state(subscriber.ID.Bytes), "Transform failure does not count towards the breaker")
+}
+
+func TestProcessBatch_AllTransformsFailReleasesProbe(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.BatchSize = 1
+	})
+	task := newBatchedTask(subscriber)
+	task.subscription.Transform = []byte(`{"value": "$.nope"}`)
+	task.probe = true
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	registry.register(task.event.ID.Bytes, task.tracker)
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	ds.breakers = newBreakerRegistry(1, time.Minute)
+	ds.breakers.recordFailure(subscriber.ID.Bytes)
+	ds.breakers.breakers[subscriber.ID.Bytes].state = BreakerHalfOpen
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+
+	inflightWg.Add(1)
+	processBatch(app, []deliveryTask{task}, getSemaphore, ds)
+	inflightWg.Wait()
+
+	assert.Equal(t, int32(0), hits.Load(), "No request is sent for an empty batch")
+	allowed, probe, _ := ds.breakers.allow(subscriber.ID.Bytes)
+	assert.True(t, allowed, "The released probe slot goes to the next delivery")
+	assert.True(t, probe)
+}
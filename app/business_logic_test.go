@@ -26,11 +26,13 @@ func TestCheckSendScope(t *testing.T) {
 		{"empty pattern non-empty subject", "", "something", false},
 		{"non-empty pattern empty subject", "events.*", "", false},
 		{"glob matches empty string", "events.*", "events.", true},
+		{"percent wildcard", "events.%", "events.user.created", true},
+		{"percent matches all", "%", "anything.goes.here", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CheckSendScope(tt.subjectPattern, tt.subject)
+			result := CheckSendScope(PatternSyntaxLike, tt.subjectPattern, tt.subject)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -59,6 +61,20 @@ func TestMatchLikePattern(t *testing.T) {
 		{"underscore no match too long", "a_c", "abbc", false},
 		{"multiple underscores", "a__c", "abbc", true},
 
+		// SQL and shell spellings
+		{"percent matches any sequence", "a%c", "abbbc", true},
+		{"question mark matches one char", "a?c", "abc", true},
+		{"question mark no match too long", "a?c", "abbc", false},
+
+		// Escapes
+		{"escaped percent is literal", `100\%`, "100%", true},
+		{"escaped percent no match", `100\%`, "1000", false},
+		{"escaped question mark is literal", `what\?`, "what?", true},
+		{"escaped question mark no match", `what\?`, "whatx", false},
+		{"escaped underscore is literal", `a\_c`, "abc", false},
+		{"escaped backslash", `a\\*`, `a\b`, true},
+		{"trailing backslash is literal", `a\`, `a\`, true},
+
 		// Combined wildcards
 		{"glob and underscore", "a_*", "abcdef", true},
 		{"underscore and glob", "_bc*", "abcdef", true},
@@ -130,7 +146,7 @@ func TestMatchesFilter(t *testing.T) {
 	}
 }
 
-func TestCalculateBackoff(t *testing.T) {
+func TestExponentialBackoff(t *testing.T) {
 	tests := []struct {
 		name              string
 		attemptNum        int
@@ -150,7 +166,7 @@ func TestCalculateBackoff(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := calculateBackoff(tt.attemptNum, tt.maxBackoffSeconds)
+			result := exponentialBackoff(1, tt.attemptNum, tt.maxBackoffSeconds)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
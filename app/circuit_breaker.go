@@ -0,0 +1,170 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// BreakerState is the state of a subscriber's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// circuitBreaker tracks consecutive delivery failures for one subscriber.
+type circuitBreaker struct {
+	state    BreakerState
+	failures int
+	since    time.Time // when the breaker last opened or let a probe through
+}
+
+// breakerRegistry holds the circuit breakers of all subscribers, keyed by
+// subscriber UUID bytes. A breaker opens after threshold consecutive failures;
+// once cooldown has elapsed a single probe delivery is let through, and its
+// outcome either closes the breaker or opens it again for another cooldown.
+// A probe whose outcome is never reported does not hold the breaker half-open
+// forever: after another cooldown a new probe is let through.
+type breakerRegistry struct {
+	mu        sync.Mutex
+	breakers  map[[16]byte]*circuitBreaker
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+}
+
+func newBreakerRegistry(threshold int, cooldown time.Duration) *breakerRegistry {
+	return &breakerRegistry{
+		breakers:  make(map[[16]byte]*circuitBreaker),
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a delivery to the subscriber may be attempted now.
+// When the breaker has been open for its cooldown, or its probe has been out
+// for as long without reporting back, the caller's delivery becomes the
+// half-open probe and probe is true. When the delivery is refused, allow
+// returns the time at which the breaker will next consider letting one through.
+func (r *breakerRegistry) allow(id [16]byte) (allowed, probe bool, reopenAt time.Time) {
+	if r.threshold <= 0 {
+		return true, false, time.Time{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[id]
+	if !ok || b.state == BreakerClosed {
+		return true, false, time.Time{}
+	}
+	now := time.Now()
+	reopenAt = b.since.Add(r.cooldown)
+	if !now.Before(reopenAt) {
+		b.state = BreakerHalfOpen
+		b.since = now
+		return true, true, time.Time{}
+	}
+	return false, false, reopenAt
+}
+
+// recordSuccess resets the subscriber's failure count. Returns true if this
+// closed a breaker that was open or half-open.
+func (r *breakerRegistry) recordSuccess(id [16]byte) bool {
+	if r.threshold <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[id]
+	if !ok {
+		return false
+	}
+	recovered := b.state != BreakerClosed
+	delete(r.breakers, id)
+	return recovered
+}
+
+// recordFailure counts a failed delivery. Returns true if this opened the
+// breaker, either by reaching the threshold or by failing the probe.
+func (r *breakerRegistry) recordFailure(id [16]byte) bool {
+	if r.threshold <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[id]
+	if !ok {
+		b = &circuitBreaker{state: BreakerClosed}
+		r.breakers[id] = b
+	}
+	b.failures++
+	switch b.state {
+	case BreakerClosed:
+		if b.failures < r.threshold {
+			return false
+		}
+	case BreakerOpen:
+		// A delivery that was already in flight when the breaker opened
+		return false
+	}
+	b.state = BreakerOpen
+	b.since = time.Now()
+	return true
+}
+
+// releaseProbe gives up the subscriber's half-open probe when it ended before
+// its request was sent, so it has no outcome to report. The next delivery is
+// let through as a new probe.
+func (r *breakerRegistry) releaseProbe(id [16]byte) {
+	if r.threshold <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.breakers[id]; ok && b.state == BreakerHalfOpen {
+		b.state = BreakerOpen
+		b.since = time.Now().Add(-r.cooldown)
+	}
+}
+
+// state returns the current state of the subscriber's breaker.
+func (r *breakerRegistry) state(id [16]byte) BreakerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.breakers[id]; ok {
+		return b.state
+	}
+	return BreakerClosed
+}
+
+// SubscriberBreakerState returns the circuit breaker state of a subscriber.
+// Subscribers are reported as closed when the dispatcher is not running.
+func SubscriberBreakerState(slurpee *Application, subscriberID pgtype.UUID) BreakerState {
+	ds := slurpee.dispatcher
+	if ds == nil || ds.breakers == nil {
+		return BreakerClosed
+	}
+	return ds.breakers.state(subscriberID.Bytes)
+}
+
+// publishBreakerChange publishes a bus message when a subscriber's breaker
+// opens or closes.
+func publishBreakerChange(slurpee *Application, subscriber db.Subscriber, state BreakerState) {
+	msgType := BusMessageBreakerClosed
+	if state == BreakerOpen {
+		msgType = BusMessageBreakerOpened
+	}
+	slurpee.EventBus.Publish(BusMessage{
+		Type:               msgType,
+		SubscriberID:       UuidToString(subscriber.ID),
+		SubscriberEndpoint: subscriber.EndpointUrl,
+		BreakerState:       string(state),
+	})
+}
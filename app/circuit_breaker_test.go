@@ -0,0 +1,268 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestBreakerRegistry_OpensAfterThreshold(t *testing.T) {
+	breakers := newBreakerRegistry(3, time.Minute)
+	id := newTestUUID().Bytes
+
+	assert.False(t, breakers.recordFailure(id))
+	assert.False(t, breakers.recordFailure(id))
+	assert.Equal(t, BreakerClosed, breakers.state(id))
+
+	assert.True(t, breakers.recordFailure(id), "Third consecutive failure should open the breaker")
+	assert.Equal(t, BreakerOpen, breakers.state(id))
+
+	allowed, _, reopenAt := breakers.allow(id)
+	assert.False(t, allowed)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), reopenAt, 5*time.Second)
+}
+
+func TestBreakerRegistry_SuccessResetsFailureCount(t *testing.T) {
+	breakers := newBreakerRegistry(2, time.Minute)
+	id := newTestUUID().Bytes
+
+	breakers.recordFailure(id)
+	assert.False(t, breakers.recordSuccess(id), "Closed breaker should not report recovery")
+	assert.False(t, breakers.recordFailure(id), "Failure count should restart after a success")
+	assert.Equal(t, BreakerClosed, breakers.state(id))
+}
+
+func TestBreakerRegistry_HalfOpenProbe(t *testing.T) {
+	breakers := newBreakerRegistry(1, 0)
+	id := newTestUUID().Bytes
+
+	assert.True(t, breakers.recordFailure(id))
+
+	allowed, probe, _ := breakers.allow(id)
+	assert.True(t, allowed, "First delivery after cooldown should be let through as a probe")
+	assert.True(t, probe)
+	assert.Equal(t, BreakerHalfOpen, breakers.state(id))
+
+	breakers.cooldown = time.Minute
+	allowed, probe, _ = breakers.allow(id)
+	assert.False(t, allowed, "Only one probe should be in flight")
+	assert.False(t, probe)
+
+	// Failed probe reopens the breaker
+	assert.True(t, breakers.recordFailure(id))
+	assert.Equal(t, BreakerOpen, breakers.state(id))
+
+	breakers.cooldown = 0
+	allowed, _, _ = breakers.allow(id)
+	assert.True(t, allowed)
+	assert.True(t, breakers.recordSuccess(id), "Successful probe should close the breaker")
+	assert.Equal(t, BreakerClosed, breakers.state(id))
+}
+
+func TestBreakerRegistry_LostProbeIsReplaced(t *testing.T) {
+	breakers := newBreakerRegistry(1, time.Minute)
+	id := newTestUUID().Bytes
+
+	assert.True(t, breakers.recordFailure(id))
+	breakers.breakers[id].since = time.Now().Add(-time.Minute)
+
+	allowed, _, _ := breakers.allow(id)
+	assert.True(t, allowed, "First delivery after cooldown should be let through as a probe")
+	assert.Equal(t, BreakerHalfOpen, breakers.state(id))
+
+	// The probe never reports back
+	allowed, _, reopenAt := breakers.allow(id)
+	assert.False(t, allowed, "Only one probe should be in flight")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), reopenAt, 5*time.Second)
+
+	breakers.breakers[id].since = time.Now().Add(-time.Minute)
+	allowed, _, _ = breakers.allow(id)
+	assert.True(t, allowed, "A new probe should be let through once the lost one has been out for the cooldown")
+	assert.Equal(t, BreakerHalfOpen, breakers.state(id))
+
+	allowed, _, _ = breakers.allow(id)
+	assert.False(t, allowed, "The replacement probe should hold the breaker half-open")
+	assert.True(t, breakers.recordSuccess(id))
+	assert.Equal(t, BreakerClosed, breakers.state(id))
+}
+
+func TestBreakerRegistry_DisabledWithZeroThreshold(t *testing.T) {
+	breakers := newBreakerRegistry(0, time.Minute)
+	id := newTestUUID().Bytes
+
+	for i := 0; i < 10; i++ {
+		assert.False(t, breakers.recordFailure(id))
+	}
+	allowed, _, _ := breakers.allow(id)
+	assert.True(t, allowed)
+	assert.Equal(t, BreakerClosed, breakers.state(id))
+}
+
+func TestProcessDeliveryTask_OpenBreakerParksTask(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+	})
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+	task := deliveryTask{
+		event:        event,
+		subscription: subscription,
+		subscriber:   subscriber,
+		attemptNum:   2,
+		maxRetries:   3,
+		tracker:      tracker,
+	}
+
+	breakers := newBreakerRegistry(1, time.Minute)
+	breakers.recordFailure(subscriber.ID.Bytes)
+
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	var inflightWg sync.WaitGroup
+	inflightWg.Add(1)
+	ds := newTestDispatcherState(&inflightWg, nil, newEventRegistry())
+	ds.breakers = breakers
+	processDeliveryTask(app, task, getSemaphore, ds)
+
+	assert.Equal(t, int32(0), hits.Load(), "Parked task should not be attempted")
+	mockDB.AssertNotCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.Anything)
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == event.ID &&
+			p.SubscriberID == subscriber.ID &&
+			p.AttemptNum == 2 &&
+			p.NextAttemptAt.Time.After(time.Now().Add(50*time.Second))
+	}))
+
+	tracker.mu.Lock()
+	assert.Equal(t, 0, len(tracker.results), "Parked task should not record a result")
+	tracker.mu.Unlock()
+}
+
+func TestProcessDeliveryTask_BreakerTripAndRecoveryPublished(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	ch, unsubscribe := app.EventBus.Subscribe()
+	defer unsubscribe()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+	})
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	breakers := newBreakerRegistry(1, 0)
+	registry := newEventRegistry()
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	ds.breakers = breakers
+
+	run := func() {
+		event := newTestEvent()
+		tracker := &eventTracker{
+			event:    event,
+			expected: 1,
+			results:  make(map[[16]byte]deliveryResult),
+			logger:   slog.Default(),
+		}
+		registry.register(event.ID.Bytes, tracker)
+		inflightWg.Add(1)
+		processDeliveryTask(app, deliveryTask{
+			event:        event,
+			subscription: subscription,
+			subscriber:   subscriber,
+			maxRetries:   3,
+			tracker:      tracker,
+		}, getSemaphore, ds)
+	}
+
+	breakerMessages := func() []BusMessage {
+		var msgs []BusMessage
+		for {
+			select {
+			case msg := <-ch:
+				if msg.Type == BusMessageBreakerOpened || msg.Type == BusMessageBreakerClosed {
+					msgs = append(msgs, msg)
+				}
+			default:
+				return msgs
+			}
+		}
+	}
+
+	run()
+	msgs := breakerMessages()
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, BusMessageBreakerOpened, msgs[0].Type)
+		assert.Equal(t, UuidToString(subscriber.ID), msgs[0].SubscriberID)
+		assert.Equal(t, "open", msgs[0].BreakerState)
+	}
+
+	fail.Store(false)
+	run()
+	msgs = breakerMessages()
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, BusMessageBreakerClosed, msgs[0].Type)
+		assert.Equal(t, "closed", msgs[0].BreakerState)
+	}
+	assert.Equal(t, BreakerClosed, breakers.state(subscriber.ID.Bytes))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sweater-ventures/slurpee/config"
@@ -38,5 +39,8 @@ func connectToDB(config *config.AppConfig) (*pgxpool.Pool, error) {
 
 func (slurpee *Application) Close() {
 	slurpee.stopDelivery()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	slurpee.TracerProvider.Shutdown(ctx)
 	slurpee.dbconn.Close()
 }
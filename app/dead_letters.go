@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// ErrDispatcherNotRunning is returned by RedriveDeadLetters when StartDispatcher
+// has not been called.
+var ErrDispatcherNotRunning = errors.New("delivery dispatcher is not running")
+
+// RedriveResult summarises a bulk dead-letter redrive.
+type RedriveResult struct {
+	Redriven int
+	Skipped  int
+}
+
+// recordDeadLetter stores an exhausted delivery in the dead_letters table.
+// A later exhaustion for the same event and subscriber replaces the entry.
+func recordDeadLetter(ctx context.Context, slurpee *Application, task deliveryTask, lastError string) {
+	_, err := slurpee.DB.UpsertDeadLetter(ctx, db.UpsertDeadLetterParams{
+		ID:             pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true},
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		Attempts:       int32(task.attemptNum + 1),
+		LastError:      lastError,
+		ExhaustedAt:    pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		task.tracker.logger.Error("Failed to record dead letter", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID))
+	}
+}
+
+// RedriveDeadLetters re-enqueues the given dead letters into the dispatcher
+// with fresh retry budgets. Entries are removed from dead_letters once queued;
+// a redrive that exhausts its retries dead-letters the delivery again.
+// Entries whose event already has deliveries in flight, or whose subscriber or
+// subscription no longer exists, are skipped and left in place.
+func RedriveDeadLetters(ctx context.Context, slurpee *Application, ids []pgtype.UUID) (RedriveResult, error) {
+	var result RedriveResult
+	ds := slurpee.dispatcher
+	if ds == nil {
+		return result, ErrDispatcherNotRunning
+	}
+
+	letters, err := slurpee.DB.GetDeadLettersByIDs(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = len(ids) - len(letters)
+
+	// Group by event so each event gets a single tracker
+	var eventOrder [][16]byte
+	byEvent := make(map[[16]byte][]db.DeadLetter)
+	for _, dl := range letters {
+		if _, ok := byEvent[dl.EventID.Bytes]; !ok {
+			eventOrder = append(eventOrder, dl.EventID.Bytes)
+		}
+		byEvent[dl.EventID.Bytes] = append(byEvent[dl.EventID.Bytes], dl)
+	}
+
+	for _, eventID := range eventOrder {
+		group := byEvent[eventID]
+		n := redriveEvent(ctx, slurpee, ds, group)
+		result.Redriven += n
+		result.Skipped += len(group) - n
+	}
+	return result, nil
+}
+
+// redriveEvent enqueues the dead letters of a single event and returns how
+// many were redriven.
+func redriveEvent(ctx context.Context, slurpee *Application, ds *DispatcherState, letters []db.DeadLetter) int {
+	eventID := letters[0].EventID
+	logger := slog.Default().With("event_id", UuidToString(eventID), "redrive", true)
+
+	event, err := slurpee.DB.GetEventByID(ctx, eventID)
+	if err != nil {
+		logger.Error("Failed to load event for redrive", "error", err)
+		return 0
+	}
+	logger = logger.With("subject", event.Subject)
+
+	// Claim the event before touching its dead letters; the expected count is
+	// filled in once the tasks are known and before any is enqueued.
+	tracker := &eventTracker{
+		event:   event,
+		results: make(map[[16]byte]deliveryResult),
+		logger:  logger,
+		subset:  true,
+	}
+	if !ds.registry.registerIfAbsent(eventID.Bytes, tracker) {
+		logger.Warn("Skipping redrive for event with deliveries in flight")
+		return 0
+	}
+
+	var tasks []deliveryTask
+	for _, dl := range letters {
+		subscriber, err := slurpee.SubscriptionCache.GetSubscriberByID(ctx, dl.SubscriberID)
+		if err != nil {
+			logger.Warn("Skipping redrive for missing subscriber", "error", err, "subscriber_id", UuidToString(dl.SubscriberID))
+			continue
+		}
+		subscription, err := slurpee.SubscriptionCache.GetSubscriptionByID(ctx, dl.SubscriptionID)
+		if err != nil {
+			logger.Warn("Skipping redrive for missing subscription", "error", err, "subscription_id", UuidToString(dl.SubscriptionID))
+			continue
+		}
+		if err := slurpee.DB.DeleteDeadLetter(ctx, dl.ID); err != nil {
+			logger.Error("Failed to remove dead letter for redrive", "error", err, "dead_letter_id", UuidToString(dl.ID))
+			continue
+		}
+
+		maxRetries := slurpee.Config.MaxRetries
+		if subscription.MaxRetries.Valid {
+			maxRetries = int(subscription.MaxRetries.Int32)
+		}
+		tasks = append(tasks, deliveryTask{
+			event:        event,
+			subscription: subscription,
+			subscriber:   subscriber,
+			attemptNum:   0,
+			maxRetries:   maxRetries,
+		})
+	}
+
+	if len(tasks) == 0 {
+		ds.registry.remove(eventID.Bytes)
+		return 0
+	}
+
+	tracker.mu.Lock()
+	tracker.expected = len(tasks)
+	tracker.mu.Unlock()
+
+	logger.Info("Redriving dead letters", "subscribers", len(tasks))
+	updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "partial")
+
+	for i := range tasks {
+		tasks[i].tracker = tracker
+		recordDeliveryState(ctx, slurpee, tasks[i], DeliveryPending, time.Time{}, "")
+		ds.enqueue(tasks[i])
+	}
+	return len(tasks)
+}
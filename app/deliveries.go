@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Delivery states recorded in the deliveries table, one row per event and
+// subscriber.
+const (
+	DeliveryPending   = "pending"   // not attempted yet
+	DeliveryRetrying  = "retrying"  // attempted and failed; another attempt is due
+	DeliveryHeld      = "held"      // held while the subscriber is paused
+	DeliverySucceeded = "succeeded" // delivered
+	DeliveryFailed    = "failed"    // given up
+)
+
+// waitingDeliveryStatus is the state of a delivery that is waiting for its
+// next attempt: pending before the first attempt, retrying after it.
+func waitingDeliveryStatus(attemptNum int) string {
+	if attemptNum == 0 {
+		return DeliveryPending
+	}
+	return DeliveryRetrying
+}
+
+// recordDeliveryState stores the state of a task's delivery that changed
+// without an attempt, such as being queued, parked or held. A zero
+// nextAttemptAt is stored as unknown, and an empty lastError keeps the error
+// of the last attempt. Recording a delivery as pending starts a fresh retry
+// budget, as a redrive or replay does, so its attempt count is reset; resuming
+// on restart continues from that count.
+func recordDeliveryState(ctx context.Context, slurpee *Application, task deliveryTask, status string, nextAttemptAt time.Time, lastError string) {
+	err := slurpee.DB.UpsertDelivery(ctx, db.UpsertDeliveryParams{
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		Status:         status,
+		NextAttemptAt:  optionalTimestamp(nextAttemptAt),
+		LastError:      lastError,
+	})
+	if err != nil {
+		task.tracker.logger.Error("Failed to record delivery state", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID), "status", status)
+	}
+}
+
+// recordDeliveryOutcome stores the state of a task's delivery after an
+// attempt and counts the attempt. A successful delivery also stores its
+// end-to-end lag.
+func recordDeliveryOutcome(ctx context.Context, slurpee *Application, task deliveryTask, status string, attemptedAt, nextAttemptAt time.Time, lastError string) {
+	var lag pgtype.Int8
+	if status == DeliverySucceeded {
+		lag = deliveryLagMs(task.event, attemptedAt)
+	}
+	err := slurpee.DB.RecordDeliveryAttempt(ctx, db.RecordDeliveryAttemptParams{
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		Status:         status,
+		LastAttemptAt:  pgtype.Timestamptz{Time: attemptedAt, Valid: true},
+		NextAttemptAt:  optionalTimestamp(nextAttemptAt),
+		LastError:      lastError,
+		LagMs:          lag,
+	})
+	if err != nil {
+		task.tracker.logger.Error("Failed to record delivery outcome", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID), "status", status)
+	}
+}
+
+// deliveryLagMs is the time from Slurpee receiving the event to deliveredAt,
+// in milliseconds. It is unknown for events without a receive time.
+func deliveryLagMs(event db.Event, deliveredAt time.Time) pgtype.Int8 {
+	if !event.CreatedAt.Valid {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: max(deliveredAt.Sub(event.CreatedAt.Time).Milliseconds(), 0), Valid: true}
+}
+
+// attemptDurationMs is how long a delivery request took, in milliseconds. A
+// zero duration, for a request that was never sent, is stored as unknown.
+func attemptDurationMs(d time.Duration) pgtype.Int4 {
+	if d <= 0 {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(min(d.Milliseconds(), math.MaxInt32)), Valid: true}
+}
+
+func optionalTimestamp(t time.Time) pgtype.Timestamptz {
+	if t.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestDispatchEvent_RecordsPendingDeliveries(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "test.subject"
+	})
+	event := newTestEvent()
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry()))
+
+	assert.Equal(t, 1, len(taskQueue))
+	mockDB.AssertCalled(t, "UpsertDelivery", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryParams) bool {
+		return p.EventID == event.ID &&
+			p.SubscriberID == subscriber.ID &&
+			p.SubscriptionID == subscription.ID &&
+			p.Status == DeliveryPending &&
+			!p.NextAttemptAt.Valid
+	}))
+}
+
+func TestProcessDeliveryTask_RecordsDeliveryOutcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		attemptNum int
+		wantStatus string
+		wantNext   bool
+		wantError  string
+	}{
+		{name: "succeeded", statusCode: http.StatusOK, wantStatus: DeliverySucceeded},
+		{name: "retrying", statusCode: http.StatusInternalServerError, wantStatus: DeliveryRetrying, wantNext: true, wantError: "received HTTP 500"},
+		{name: "exhausted", statusCode: http.StatusInternalServerError, attemptNum: 3, wantStatus: DeliveryFailed, wantError: "received HTTP 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			mockDB := new(deliveryMockQuerier)
+			app := newDeliveryTestApp(mockDB)
+			mockSubscribers(mockDB)
+			mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+				Return(db.DeliveryAttempt{}, nil)
+			mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+				Return(nil)
+			mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+				Return(nil)
+			mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+				Return(db.DeadLetter{}, nil)
+			mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+				Return(db.Event{}, nil)
+
+			task := newBatchedTask(newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+			task.attemptNum = tt.attemptNum
+			start := time.Now().UTC()
+			runTask(app, task)
+
+			mockDB.AssertCalled(t, "RecordDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.RecordDeliveryAttemptParams) bool {
+				return p.EventID == task.event.ID &&
+					p.SubscriberID == task.subscriber.ID &&
+					p.SubscriptionID == task.subscription.ID &&
+					p.Status == tt.wantStatus &&
+					!p.LastAttemptAt.Time.Before(start) &&
+					p.NextAttemptAt.Valid == tt.wantNext &&
+					p.LastError == tt.wantError
+			}))
+			mockDB.AssertNotCalled(t, "UpsertDelivery", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestProcessDeliveryTask_RecordsDurationAndLag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := newBatchedTask(newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+	task.event.CreatedAt = pgtype.Timestamptz{Time: time.Now().Add(-5 * time.Second), Valid: true}
+	runTask(app, task)
+
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return p.DurationMs.Valid && p.DurationMs.Int32 >= 20
+	}))
+	mockDB.AssertCalled(t, "RecordDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.RecordDeliveryAttemptParams) bool {
+		return p.LagMs.Valid && p.LagMs.Int64 >= 5000
+	}))
+}
+
+func TestProcessDeliveryTask_FailedDeliveryHasNoLag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := newBatchedTask(newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+	task.event.CreatedAt = newTestTimestamp()
+	runTask(app, task)
+
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return p.Status == "failed" && p.DurationMs.Valid
+	}))
+	mockDB.AssertCalled(t, "RecordDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.RecordDeliveryAttemptParams) bool {
+		return p.Status == DeliveryRetrying && !p.LagMs.Valid
+	}))
+}
+
+func TestDeliverToSubscriber_PayloadFailureHasNoDuration(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	event := newTestEvent(func(e *db.Event) { e.Data = []byte("not json") })
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.PayloadFormat = PayloadFormatEnvelope })
+	outcome := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	assert.False(t, outcome.succeeded)
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return !p.DurationMs.Valid
+	}))
+}
+
+func TestProcessDeliveryTask_DisabledSubscriberFailsWithoutAttempt(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) {
+		s.DisabledAt = newTestTimestamp()
+	}))
+
+	mockDB.AssertCalled(t, "UpsertDelivery", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryParams) bool {
+		return p.EventID == task.event.ID && p.Status == DeliveryFailed && p.LastError == "subscriber disabled"
+	}))
+	mockDB.AssertNotCalled(t, "RecordDeliveryAttempt", mock.Anything, mock.Anything)
+}
+
+func TestResumePartialEvent_LeavesDeadLetteredDeliveries(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.DeliveryStatus = "partial"
+	})
+	delivered := newTestSubscriber()
+	deadLettered := newTestSubscriber()
+	pending := newTestSubscriber()
+	var subscriptions []db.Subscription
+	for _, s := range []db.Subscriber{delivered, deadLettered, pending} {
+		subscriptions = append(subscriptions, newTestSubscription(func(sub *db.Subscription) {
+			sub.SubscriberID = s.ID
+			sub.SubjectPattern = "orders.*"
+		}))
+	}
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{delivered, deadLettered, pending}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return(subscriptions, nil)
+	mockDB.On("ListDeliveriesForEvent", mock.Anything, event.ID).
+		Return([]db.ListDeliveriesForEventRow{
+			{SubscriberID: delivered.ID, Status: DeliverySucceeded, Attempts: 1},
+			{SubscriberID: deadLettered.ID, Status: DeliveryFailed, Attempts: 4},
+			{SubscriberID: pending.ID, Status: DeliveryRetrying, Attempts: 2},
+		}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	assert.True(t, resumePartialEvent(context.Background(), app, event, ds))
+
+	if assert.Equal(t, 1, len(taskQueue)) {
+		task := <-taskQueue
+		assert.Equal(t, pending.ID, task.subscriber.ID)
+		assert.Equal(t, 2, task.attemptNum, "Attempt number continues from the recorded attempts")
+	}
+	tracker := ds.registry.get(event.ID.Bytes)
+	if assert.NotNil(t, tracker) {
+		assert.Equal(t, 2, tracker.expected)
+		assert.Len(t, tracker.results, 1, "Dead-lettered delivery counts as exhausted")
+	}
+}
+
+func TestResumePartialEvent_MarksFailedWhenOnlyDeadLettersRemain(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.DeliveryStatus = "partial"
+	})
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ListDeliveriesForEvent", mock.Anything, event.ID).
+		Return([]db.ListDeliveriesForEventRow{{SubscriberID: subscriber.ID, Status: DeliveryFailed, Attempts: 4}}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, make(chan deliveryTask, 10), newEventRegistry())
+
+	assert.False(t, resumePartialEvent(context.Background(), app, event, ds))
+	mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.ID == event.ID && p.DeliveryStatus == "failed"
+	}))
+}
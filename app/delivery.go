@@ -1,13 +1,11 @@
 package app
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
 	"sync"
 	"time"
@@ -15,6 +13,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sweater-ventures/slurpee/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // deliveryTask represents a pending delivery with retry state.
@@ -25,6 +26,20 @@ type deliveryTask struct {
 	attemptNum   int // 0-indexed attempt number (0 = first attempt)
 	maxRetries   int // effective max retries for this subscription
 	tracker      *eventTracker
+	// firstAttemptAt is when the delivery was first attempted; zero before the
+	// first attempt. It bounds the subscription's max retry age.
+	firstAttemptAt time.Time
+	// tokenReserved is set once the task has waited for its subscriber's rate
+	// limit, so it is not held back a second time.
+	tokenReserved bool
+	// probe is set when the task was let through as its subscriber's half-open
+	// circuit breaker probe. A probe that waits for the rate limit still holds
+	// the probe slot when it comes back, so it is not checked again.
+	probe bool
+	// batch, when set, is a lingering batch handed back to the worker pool by
+	// the batcher; the task carries nothing else. Its tasks are already in
+	// flight.
+	batch []deliveryTask
 }
 
 // deliveryResult represents the final outcome of a delivery to a subscription.
@@ -34,6 +49,17 @@ type deliveryResult struct {
 	exhausted      bool // true if max retries exhausted without success
 }
 
+// deliveryOutcome describes the result of a single delivery attempt.
+type deliveryOutcome struct {
+	succeeded    bool
+	statusCode   int           // 0 if no response was received
+	err          string        // failure description; empty on success
+	permanent    bool          // true if the failure must not be retried
+	retryAfter   time.Duration // delay requested by the endpoint's Retry-After, if any
+	notAttempted bool          // true if the task was given up without an attempt
+	duration     time.Duration // how long the request ran; zero if it was never sent
+}
+
 // eventTracker collects delivery results for a single event.
 // Workers write results directly; no collector goroutine needed.
 type eventTracker struct {
@@ -42,6 +68,7 @@ type eventTracker struct {
 	results  map[[16]byte]deliveryResult
 	mu       sync.Mutex
 	logger   *slog.Logger
+	subset   bool // covers only some subscribers: a dead-letter redrive or single-subscriber replay
 }
 
 // record stores a delivery result and returns true exactly once —
@@ -69,49 +96,161 @@ func (r *eventRegistry) register(id [16]byte, t *eventTracker) {
 	r.trackers[id] = t
 }
 
+// registerIfAbsent registers t unless a tracker already exists for id.
+// Returns false if another tracker was already registered.
+func (r *eventRegistry) registerIfAbsent(id [16]byte, t *eventTracker) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.trackers[id]; ok {
+		return false
+	}
+	r.trackers[id] = t
+	return true
+}
+
+func (r *eventRegistry) get(id [16]byte) *eventTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.trackers[id]
+}
+
 func (r *eventRegistry) remove(id [16]byte) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.trackers, id)
 }
 
+// size returns the number of in-flight event trackers.
+func (r *eventRegistry) size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.trackers)
+}
+
+// semaphorePool holds the persistent per-subscriber semaphores that bound
+// concurrent requests to MaxParallel, keyed by UUID bytes.
+type semaphorePool struct {
+	mu   sync.Mutex
+	sems map[[16]byte]chan struct{}
+}
+
+func newSemaphorePool() *semaphorePool {
+	return &semaphorePool{sems: make(map[[16]byte]chan struct{})}
+}
+
+// get returns the subscriber's semaphore, replacing it when MaxParallel has
+// changed.
+func (p *semaphorePool) get(subscriberID [16]byte, maxParallel int32) chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sem, ok := p.sems[subscriberID]
+	if !ok || int32(cap(sem)) != maxParallel {
+		sem = make(chan struct{}, maxParallel)
+		p.sems[subscriberID] = sem
+	}
+	return sem
+}
+
+// semaphoreUsage is the number of requests in flight to a subscriber and its
+// MaxParallel limit.
+type semaphoreUsage struct {
+	inUse int
+	limit int
+}
+
+// usage returns the current usage of every subscriber's semaphore.
+func (p *semaphorePool) usage() map[[16]byte]semaphoreUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage := make(map[[16]byte]semaphoreUsage, len(p.sems))
+	for id, sem := range p.sems {
+		usage[id] = semaphoreUsage{inUse: len(sem), limit: cap(sem)}
+	}
+	return usage
+}
+
 // DispatcherState holds references to the internal dispatcher state needed by
 // ResumeUnfinishedDeliveries to enqueue partial-event delivery tasks directly.
 type DispatcherState struct {
 	inflightWg *sync.WaitGroup
 	taskQueue  chan<- deliveryTask
 	registry   *eventRegistry
+	breakers   *breakerRegistry
+	ordering   *orderingGate
+	batcher    *deliveryBatcher
+	rateLimits *rateLimiterRegistry
+	replays    *replayJobRegistry
+	semaphores *semaphorePool
+	shutdown   <-chan struct{} // closed when the dispatcher begins shutting down
+}
+
+// enqueue hands a task to the worker pool. Tasks of ordered subscriptions wait
+// in their ordering lane until earlier events for the same key are done.
+// Returns false if the task is waiting.
+func (ds *DispatcherState) enqueue(task deliveryTask) bool {
+	if task.subscription.Ordered && !ds.ordering.admit(task) {
+		return false
+	}
+	ds.inflightWg.Add(1)
+	ds.taskQueue <- task
+	return true
+}
+
+// advanceLane releases the ordering lane held by an event for a subscriber
+// once its delivery succeeded or was given up, and enqueues the next waiting
+// task. Deliveries to unordered subscriptions hold no lane.
+func (ds *DispatcherState) advanceLane(subscriberID, eventID pgtype.UUID) {
+	next, ok := ds.ordering.release(subscriberID.Bytes, eventID.Bytes)
+	if !ok {
+		return
+	}
+	// Called from workers: send from a goroutine so a full task queue cannot
+	// stall the worker pool
+	ds.inflightWg.Add(1)
+	go func() {
+		ds.taskQueue <- next
+	}()
 }
 
 // StartDispatcher launches the centralized event delivery dispatcher.
 // It reads events from app.DeliveryChan and delivers them to matching subscribers
-// using a fixed-size worker pool with non-blocking retries.
+// using a fixed-size worker pool. Failed deliveries are persisted to the
+// delivery_retries table and fed back into the worker pool by a retry poller.
 // Returns a DispatcherState for use by ResumeUnfinishedDeliveries.
 func StartDispatcher(slurpee *Application) *DispatcherState {
 	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 
-	// Persistent per-subscriber semaphores keyed by UUID bytes
-	var semMu sync.Mutex
-	semaphores := make(map[[16]byte]chan struct{})
-
-	getSemaphore := func(subscriberID [16]byte, maxParallel int32) chan struct{} {
-		semMu.Lock()
-		defer semMu.Unlock()
-
-		sem, ok := semaphores[subscriberID]
-		if !ok || int32(cap(sem)) != maxParallel {
-			semaphores[subscriberID] = make(chan struct{}, maxParallel)
-			return semaphores[subscriberID]
-		}
-		return sem
-	}
+	semaphores := newSemaphorePool()
+	getSemaphore := semaphores.get
 
 	taskQueue := make(chan deliveryTask, slurpee.Config.DeliveryQueueSize)
 	registry := newEventRegistry()
+	breakers := newBreakerRegistry(slurpee.Config.BreakerFailureThreshold,
+		time.Duration(slurpee.Config.BreakerCooldownSeconds)*time.Second)
 
 	var inflightWg sync.WaitGroup
 	var workerWg sync.WaitGroup
 
+	ds := &DispatcherState{
+		inflightWg: &inflightWg,
+		taskQueue:  taskQueue,
+		registry:   registry,
+		breakers:   breakers,
+		ordering:   newOrderingGate(),
+		rateLimits: newRateLimiterRegistry(),
+		replays:    newReplayJobRegistry(),
+		semaphores: semaphores,
+		shutdown:   shutdownCtx.Done(),
+	}
+	ds.batcher = newDeliveryBatcher(func(tasks []deliveryTask) {
+		processBatch(slurpee, tasks, getSemaphore, ds)
+	}, func(tasks []deliveryTask) {
+		// The batch's tasks are still in flight, so the queue stays open
+		taskQueue <- deliveryTask{batch: tasks}
+	})
+	slurpee.dispatcher = ds
+
 	// Start worker goroutines
 	numWorkers := slurpee.Config.DeliveryWorkers
 	workerWg.Add(numWorkers)
@@ -119,11 +258,18 @@ func StartDispatcher(slurpee *Application) *DispatcherState {
 		go func() {
 			defer workerWg.Done()
 			for task := range taskQueue {
-				processDeliveryTask(shutdownCtx, slurpee, task, getSemaphore, &inflightWg, taskQueue, registry)
+				processDeliveryTask(slurpee, task, getSemaphore, ds)
 			}
 		}()
 	}
 
+	// Retry poller: feeds due retries from the database into the task queue
+	pollerDone := make(chan struct{})
+	go func() {
+		defer close(pollerDone)
+		runRetryPoller(shutdownCtx, slurpee, ds)
+	}()
+
 	done := make(chan struct{})
 
 	// Dispatcher goroutine: reads events from DeliveryChan and dispatches them
@@ -131,10 +277,10 @@ func StartDispatcher(slurpee *Application) *DispatcherState {
 		defer close(done)
 
 		for event := range slurpee.DeliveryChan {
-			dispatchEvent(slurpee, event, &inflightWg, taskQueue, registry)
+			dispatchEvent(slurpee, event, ds)
 		}
 
-		// Channel closed — wait for all in-flight tasks (including pending retry timers)
+		// Channel closed — wait for all in-flight tasks
 		slog.Info("Delivery channel closed, waiting for in-flight deliveries")
 		inflightWg.Wait()
 
@@ -147,31 +293,37 @@ func StartDispatcher(slurpee *Application) *DispatcherState {
 	}()
 
 	slurpee.SetStopDelivery(func() {
-		shutdownCancel() // signal retry timers to abandon
+		// Replay jobs feed DeliveryChan; they resume on the next start
+		ds.replays.stop()
+		shutdownCancel() // stop the retry poller and park rate-limited tasks; scheduled retries stay in the database
+		<-pollerDone
 		close(slurpee.DeliveryChan)
 		<-done
 	})
 
-	return &DispatcherState{
-		inflightWg: &inflightWg,
-		taskQueue:  taskQueue,
-		registry:   registry,
-	}
+	return ds
 }
 
 // dispatchEvent finds matching subscriptions for an event and enqueues delivery tasks.
-func dispatchEvent(slurpee *Application, event db.Event, inflightWg *sync.WaitGroup, taskQueue chan<- deliveryTask, registry *eventRegistry) {
-	ctx := context.Background()
+func dispatchEvent(slurpee *Application, event db.Event, ds *DispatcherState) {
+	ctx, span := slurpee.tracer().Start(contextWithParent(context.Background(), eventSpanContext(event)), spanDispatchEvent,
+		trace.WithAttributes(eventSpanAttributes(event)...),
+	)
+	defer span.End()
 	logger := slog.Default().With("event_id", UuidToString(event.ID), "subject", event.Subject)
 
 	// Find all subscriptions whose subject_pattern matches the event subject
 	subscriptions, err := slurpee.SubscriptionCache.GetMatchingSubscriptions(ctx, event.Subject)
 	if err != nil {
 		logger.Error("Failed to find matching subscriptions", "error", err)
+		span.SetStatus(codes.Error, "failed to find matching subscriptions")
 		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "failed")
 		return
 	}
 
+	// Drop subscribers outside the publishing API secret's scope
+	subscriptions = filterSubscriptionsByScope(event, subscriptions, logger)
+
 	if len(subscriptions) == 0 {
 		logger.Info("No matching subscriptions for event")
 		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "recorded")
@@ -192,12 +344,24 @@ func dispatchEvent(slurpee *Application, event db.Event, inflightWg *sync.WaitGr
 			logger.Error("Failed to get subscriber", "error", err, "subscriber_id", UuidToString(subID))
 			continue
 		}
+		if subscriber.DisabledAt.Valid {
+			logger.Debug("Skipping disabled subscriber", "subscriber_id", UuidToString(subID))
+			delete(subscriberSubs, subID)
+			continue
+		}
 		subscribers[subID] = subscriber
 	}
 
+	if len(subscriberSubs) == 0 {
+		logger.Info("All matching subscribers are disabled")
+		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "recorded")
+		return
+	}
+
 	// Build initial delivery tasks, deduplicated to one per subscriber.
 	// When multiple subscriptions match for the same subscriber, we pick the
 	// one with the highest effective max retries.
+	filterData := newEventFilterData(event.Data)
 	var tasks []deliveryTask
 	for subID, subs := range subscriberSubs {
 		subscriber, ok := subscribers[subID]
@@ -208,7 +372,7 @@ func dispatchEvent(slurpee *Application, event db.Event, inflightWg *sync.WaitGr
 		var bestSub *db.Subscription
 		bestMaxRetries := -1
 		for i, sub := range subs {
-			if !matchesFilter(sub.Filter, event.Data) {
+			if !slurpee.SubscriptionCache.filterMatches(sub, filterData) {
 				logger.Debug("Subscription filter did not match event data",
 					"subscriber_id", UuidToString(subscriber.ID),
 					"subscription_id", UuidToString(sub.ID),
@@ -251,10 +415,13 @@ func dispatchEvent(slurpee *Application, event db.Event, inflightWg *sync.WaitGr
 
 	if len(tasks) == 0 {
 		logger.Warn("No valid subscribers found for matching subscriptions")
+		span.SetStatus(codes.Error, "no valid subscribers found for matching subscriptions")
 		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "failed")
 		return
 	}
 
+	span.SetAttributes(attribute.Int("slurpee.delivery.subscribers", len(tasks)))
+
 	// Create tracker for this event
 	tracker := &eventTracker{
 		event:    event,
@@ -262,105 +429,460 @@ func dispatchEvent(slurpee *Application, event db.Event, inflightWg *sync.WaitGr
 		results:  make(map[[16]byte]deliveryResult),
 		logger:   logger,
 	}
-	registry.register(event.ID.Bytes, tracker)
+	// Claim the event; a replay that raced a retry, redrive or another
+	// replay must not start a second fan-out alongside it
+	if !ds.registry.registerIfAbsent(event.ID.Bytes, tracker) {
+		logger.Warn("Skipping dispatch for event with deliveries in flight")
+		return
+	}
 
-	// Enqueue all tasks
+	// Record and enqueue all tasks
+	waiting := false
 	for i := range tasks {
 		tasks[i].tracker = tracker
-		inflightWg.Add(1)
-		taskQueue <- tasks[i]
+		recordDeliveryState(ctx, slurpee, tasks[i], DeliveryPending, time.Time{}, "")
+		if !ds.enqueue(tasks[i]) {
+			waiting = true
+		}
+	}
+
+	// Mark events held behind an earlier event as partial so a restart does
+	// not redeliver to subscribers that already received them
+	if waiting {
+		logger.Debug("Event waiting behind earlier events for ordered delivery")
+		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "partial")
 	}
 }
 
 // processDeliveryTask handles a single delivery attempt with retry logic.
+// Failed attempts with retries remaining are persisted to delivery_retries
+// for the retry poller to pick up once their backoff has elapsed. Tasks for a
+// subscriber whose circuit breaker is open are parked without being attempted,
+// and tasks for a paused subscriber are held until it is resumed.
+// Tasks beyond a subscriber's rate limit are held until their token is due,
+// or parked when a burst's worth of tokens is already reserved.
+// A task of an ordered subscription keeps the head of its ordering lane until
+// it succeeds or is dead-lettered. Tasks for subscribers with batching enabled
+// are handed to the batcher and stay in flight until their batch is sent; a
+// batch handed back by the batcher's linger timer is sent here.
+// The event's data is reshaped by the subscription's transform before sending.
 // Called by worker goroutines — not spawned in its own goroutine.
 func processDeliveryTask(
-	shutdownCtx context.Context,
 	slurpee *Application,
 	task deliveryTask,
 	getSemaphore func([16]byte, int32) chan struct{},
-	inflightWg *sync.WaitGroup,
-	taskQueue chan<- deliveryTask,
-	registry *eventRegistry,
+	ds *DispatcherState,
 ) {
-	defer inflightWg.Done()
+	if task.batch != nil {
+		processBatch(slurpee, task.batch, getSemaphore, ds)
+		return
+	}
 
 	ctx := context.Background()
 	logger := task.tracker.logger
 
+	// Fail deliveries to a subscriber disabled since the task was queued;
+	// they are dead-lettered and can be redriven once it is enabled again
+	current := currentSubscriber(ctx, slurpee, task.subscriber)
+	if current.DisabledAt.Valid {
+		defer ds.inflightWg.Done()
+		completeDeliveryTask(ctx, slurpee, task, deliveryOutcome{err: "subscriber disabled", permanent: true, notAttempted: true}, ds)
+		return
+	}
+
+	if current.PausedAt.Valid {
+		if holdDeliveryTask(ctx, slurpee, task) {
+			ds.inflightWg.Done()
+			return
+		}
+		// Holding failed; attempt the delivery rather than lose it
+	}
+
+	if !task.probe {
+		allowed, probe, reopenAt := ds.breakers.allow(task.subscriber.ID.Bytes)
+		if !allowed {
+			if parkDeliveryTask(ctx, slurpee, task, reopenAt) {
+				ds.inflightWg.Done()
+				return
+			}
+			// Parking failed; attempt the delivery rather than lose it
+		}
+		task.probe = probe
+	}
+
+	if !task.tokenReserved && ds.applyRateLimit(ctx, slurpee, task) {
+		return
+	}
+
+	if task.subscriber.BatchSize > 1 {
+		ds.batcher.add(task)
+		return
+	}
+	defer ds.inflightWg.Done()
+
+	// Reshape the event's data with the subscription's transform
+	event, err := slurpee.SubscriptionCache.transformEventData(task.event, task.subscription)
+	if err != nil {
+		failTransformedTask(ctx, slurpee, task, err, ds)
+		// No request was sent, so a probe has nothing to report
+		if task.probe {
+			ds.breakers.releaseProbe(task.subscriber.ID.Bytes)
+		}
+		return
+	}
+
 	sem := getSemaphore(task.subscriber.ID.Bytes, task.subscriber.MaxParallel)
 
 	// Acquire semaphore
 	sem <- struct{}{}
 
-	succeeded := deliverToSubscriber(ctx, slurpee, task.event, task.subscriber, task.attemptNum, logger)
+	outcome := deliverToSubscriber(ctx, slurpee, event, task.subscriber, task.attemptNum, logger)
 
 	// Release semaphore immediately — don't hold during queue operations
 	<-sem
 
+	recordBreakerOutcome(slurpee, ds, task.subscriber, outcome.succeeded, logger)
+	if outcome.statusCode == http.StatusGone && task.subscriber.DisableOnGone {
+		disableGoneSubscriber(ctx, slurpee, task.subscriber, logger)
+	}
+	completeDeliveryTask(ctx, slurpee, task, outcome, ds)
+}
+
+// failTransformedTask fails a task whose subscription transform could not be
+// applied. A transform that fails would fail again, so the delivery is not
+// retried.
+func failTransformedTask(ctx context.Context, slurpee *Application, task deliveryTask, err error, ds *DispatcherState) {
+	task.tracker.logger.Error("Failed to apply subscription transform",
+		"error", err,
+		"subscriber_id", UuidToString(task.subscriber.ID),
+		"subscription_id", UuidToString(task.subscription.ID),
+	)
+	errMsg := fmt.Sprintf("transform failed: %v", err)
+	attemptID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
+	recordFailedAttempt(ctx, slurpee, attemptID, task.event, task.subscriber, nil, time.Now().UTC(), 0, errMsg)
+	completeDeliveryTask(ctx, slurpee, task, deliveryOutcome{err: errMsg, permanent: true}, ds)
+}
+
+// recordBreakerOutcome feeds the result of a request to a subscriber into its
+// circuit breaker and publishes a bus message when the breaker opens or closes.
+func recordBreakerOutcome(slurpee *Application, ds *DispatcherState, subscriber db.Subscriber, succeeded bool, logger *slog.Logger) {
 	if succeeded {
+		if ds.breakers.recordSuccess(subscriber.ID.Bytes) {
+			logger.Info("Circuit breaker closed", "subscriber_id", UuidToString(subscriber.ID),
+				"endpoint_url", subscriber.EndpointUrl)
+			publishBreakerChange(slurpee, subscriber, BreakerClosed)
+		}
+	} else if ds.breakers.recordFailure(subscriber.ID.Bytes) {
+		logger.Warn("Circuit breaker opened", "subscriber_id", UuidToString(subscriber.ID),
+			"endpoint_url", subscriber.EndpointUrl, "cooldown_seconds", ds.breakers.cooldown.Seconds())
+		publishBreakerChange(slurpee, subscriber, BreakerOpen)
+	}
+}
+
+// completeDeliveryTask acts on the outcome of a delivery attempt: it records
+// the result of a success or an exhausted task, or schedules the next retry,
+// and stores the delivery's new state.
+// Non-retryable failures are dead-lettered without using up the retries, as are
+// failures whose next retry would fall past the subscription's max retry age.
+func completeDeliveryTask(ctx context.Context, slurpee *Application, task deliveryTask, outcome deliveryOutcome, ds *DispatcherState) {
+	logger := task.tracker.logger
+	now := time.Now().UTC()
+	recordState := func(status string, nextAttemptAt time.Time) {
+		slurpee.Metrics.recordDelivery(task, outcome, status, now)
+		if outcome.notAttempted {
+			recordDeliveryState(ctx, slurpee, task, status, nextAttemptAt, outcome.err)
+			return
+		}
+		recordDeliveryOutcome(ctx, slurpee, task, status, now, nextAttemptAt, outcome.err)
+	}
+
+	if outcome.succeeded {
+		recordState(DeliverySucceeded, time.Time{})
+		ds.advanceLane(task.subscriber.ID, task.event.ID)
 		result := deliveryResult{
 			subscriptionID: task.subscription.ID,
 			succeeded:      true,
 		}
 		if task.tracker.record(result) {
-			finalizeEvent(ctx, slurpee, task.tracker, registry)
+			finalizeEvent(ctx, slurpee, task.tracker, ds.registry)
 		}
 		return
 	}
 
 	// Delivery failed — check if we should retry
-	if task.attemptNum >= task.maxRetries {
+	firstAttemptAt := task.firstAttemptAt
+	if firstAttemptAt.IsZero() {
+		firstAttemptAt = now
+	}
+	delay := retryDelay(slurpee, task.subscription, task.attemptNum, outcome)
+
+	giveUp := true
+	switch {
+	case outcome.permanent:
+		logger.Warn("Delivery failed with non-retryable response",
+			"subscriber_id", UuidToString(task.subscriber.ID),
+			"endpoint_url", task.subscriber.EndpointUrl,
+			"attempt", task.attemptNum+1,
+			"error", outcome.err,
+		)
+	case task.attemptNum >= task.maxRetries:
 		logger.Warn("Max retries exhausted",
 			"subscriber_id", UuidToString(task.subscriber.ID),
 			"endpoint_url", task.subscriber.EndpointUrl,
 			"attempt", task.attemptNum+1,
 			"max_retries", task.maxRetries,
 		)
+	case retryAgeExceeded(task.subscription, firstAttemptAt, now.Add(delay)):
+		logger.Warn("Retry age limit reached",
+			"subscriber_id", UuidToString(task.subscriber.ID),
+			"endpoint_url", task.subscriber.EndpointUrl,
+			"attempt", task.attemptNum+1,
+			"max_age_seconds", task.subscription.RetryMaxAgeSeconds.Int32,
+		)
+	default:
+		giveUp = false
+	}
+	if giveUp {
+		recordDeadLetter(ctx, slurpee, task, outcome.err)
+		recordState(DeliveryFailed, time.Time{})
+		ds.advanceLane(task.subscriber.ID, task.event.ID)
 		result := deliveryResult{
 			subscriptionID: task.subscription.ID,
 			succeeded:      false,
 			exhausted:      true,
 		}
 		if task.tracker.record(result) {
-			finalizeEvent(ctx, slurpee, task.tracker, registry)
+			finalizeEvent(ctx, slurpee, task.tracker, ds.registry)
 		}
 		return
 	}
 
-	// Schedule retry with exponential backoff
-	delay := calculateBackoff(task.attemptNum, slurpee.Config.MaxBackoffSeconds)
+	// Schedule retry under the subscription's retry policy, or when the endpoint asked via Retry-After
 	logger.Info("Scheduling retry",
 		"subscriber_id", UuidToString(task.subscriber.ID),
 		"endpoint_url", task.subscriber.EndpointUrl,
 		"attempt", task.attemptNum+1,
 		"next_attempt", task.attemptNum+2,
 		"delay_seconds", delay.Seconds(),
+		"retry_policy", task.subscription.RetryPolicy,
+		"retry_after", outcome.retryAfter > 0,
 	)
 
+	_, span := slurpee.tracer().Start(contextWithParent(ctx, eventSpanContext(task.event)), spanScheduleRetry,
+		trace.WithAttributes(eventSpanAttributes(task.event)...),
+		trace.WithAttributes(
+			attribute.String("slurpee.subscriber.id", UuidToString(task.subscriber.ID)),
+			attribute.Int("slurpee.delivery.attempt", task.attemptNum+2),
+			attribute.Float64("slurpee.retry.delay_seconds", delay.Seconds()),
+			attribute.String("slurpee.retry.policy", task.subscription.RetryPolicy),
+		),
+	)
+	defer span.End()
+
+	// Persist the retry so it survives restarts; the retry poller enqueues it once due
+	err := slurpee.DB.UpsertDeliveryRetry(ctx, db.UpsertDeliveryRetryParams{
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		AttemptNum:     int32(task.attemptNum + 1),
+		MaxRetries:     int32(task.maxRetries),
+		NextAttemptAt:  pgtype.Timestamptz{Time: now.Add(delay), Valid: true},
+		FirstAttemptAt: pgtype.Timestamptz{Time: firstAttemptAt, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to schedule delivery retry", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID))
+		span.SetStatus(codes.Error, "failed to schedule delivery retry")
+		recordDeadLetter(ctx, slurpee, task, outcome.err)
+		recordState(DeliveryFailed, time.Time{})
+		ds.advanceLane(task.subscriber.ID, task.event.ID)
+		result := deliveryResult{
+			subscriptionID: task.subscription.ID,
+			succeeded:      false,
+			exhausted:      true,
+		}
+		if task.tracker.record(result) {
+			finalizeEvent(ctx, slurpee, task.tracker, ds.registry)
+		}
+		return
+	}
+
+	recordState(DeliveryRetrying, now.Add(delay))
+
 	// Update event status to partial while retrying
 	updateEventStatus(ctx, slurpee, task.event.ID, task.event.RetryCount+1, "partial")
+}
+
+// parkDeliveryTask persists a task that cannot be attempted yet to
+// delivery_retries, due at until: when an open circuit breaker next lets a
+// probe through, or when a rate-limited task held at shutdown would have been
+// sent. The attempt number is unchanged, so parking does not use up the retry
+// budget. Returns false if the task could not be persisted.
+func parkDeliveryTask(ctx context.Context, slurpee *Application, task deliveryTask, until time.Time) bool {
+	logger := task.tracker.logger
+	firstAttemptAt := task.firstAttemptAt
+	if firstAttemptAt.IsZero() {
+		firstAttemptAt = time.Now().UTC()
+	}
+	err := slurpee.DB.UpsertDeliveryRetry(ctx, db.UpsertDeliveryRetryParams{
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		AttemptNum:     int32(task.attemptNum),
+		MaxRetries:     int32(task.maxRetries),
+		NextAttemptAt:  pgtype.Timestamptz{Time: until.UTC(), Valid: true},
+		FirstAttemptAt: pgtype.Timestamptz{Time: firstAttemptAt, Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to park delivery", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID))
+		return false
+	}
+	logger.Debug("Parked delivery",
+		"subscriber_id", UuidToString(task.subscriber.ID),
+		"attempt", task.attemptNum+1,
+		"until", until,
+	)
+	recordDeliveryState(ctx, slurpee, task, waitingDeliveryStatus(task.attemptNum), until, "")
+	updateEventStatus(ctx, slurpee, task.event.ID, task.event.RetryCount, "partial")
+	return true
+}
+
+// runRetryPoller periodically claims due delivery retries and enqueues them
+// until ctx is cancelled.
+func runRetryPoller(ctx context.Context, slurpee *Application, ds *DispatcherState) {
+	interval := time.Duration(slurpee.Config.RetryPollSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	// Build next attempt task
-	nextTask := deliveryTask{
-		event:        task.event,
-		subscription: task.subscription,
-		subscriber:   task.subscriber,
-		attemptNum:   task.attemptNum + 1,
-		maxRetries:   task.maxRetries,
-		tracker:      task.tracker,
-	}
-	nextTask.event.RetryCount++
-
-	// Non-blocking retry: increment inflight before scheduling timer
-	inflightWg.Add(1)
-	time.AfterFunc(delay, func() {
-		if shutdownCtx.Err() != nil {
-			inflightWg.Done() // abandon retry on shutdown
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case <-ticker.C:
+			pollDueRetries(ctx, slurpee, ds)
 		}
-		taskQueue <- nextTask
-	})
+	}
+}
+
+// pollDueRetries claims retries whose next_attempt_at has passed and feeds them
+// into the task queue, continuing while full batches are returned. Retries due
+// at the same time, such as those released by resuming a paused subscriber,
+// are claimed in event order. Retries for
+// events with no in-memory tracker (e.g. after a restart) resume the whole event
+// via resumePartialEvent. Returns the number of retries claimed.
+func pollDueRetries(ctx context.Context, slurpee *Application, ds *DispatcherState) int {
+	batchSize := slurpee.Config.RetryPollBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	total := 0
+	for ctx.Err() == nil {
+		retries, err := slurpee.DB.ClaimDueDeliveryRetries(ctx, db.ClaimDueDeliveryRetriesParams{
+			DueBefore: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+			BatchSize: int32(batchSize),
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to claim due delivery retries", "error", err)
+			}
+			return total
+		}
+
+		resumed := make(map[[16]byte]bool)
+		for _, retry := range retries {
+			if resumed[retry.EventID.Bytes] {
+				continue
+			}
+			if enqueueRetry(ctx, slurpee, retry, ds) {
+				resumed[retry.EventID.Bytes] = true
+			}
+		}
+
+		total += len(retries)
+		if len(retries) < batchSize {
+			break
+		}
+	}
+	return total
+}
+
+// enqueueRetry feeds a claimed retry into the task queue. When no tracker is
+// registered for the event, the event is resumed as a whole and true is
+// returned so the caller can skip its other claimed retries.
+func enqueueRetry(ctx context.Context, slurpee *Application, retry db.DeliveryRetry, ds *DispatcherState) bool {
+	tracker := ds.registry.get(retry.EventID.Bytes)
+	if tracker == nil {
+		event, err := slurpee.DB.GetEventByID(ctx, retry.EventID)
+		if err != nil {
+			slog.Error("Failed to load event for delivery retry", "error", err, "event_id", UuidToString(retry.EventID))
+			return false
+		}
+		if resumePartialEvent(ctx, slurpee, event, ds) {
+			return true
+		}
+		// Another goroutine registered a tracker in the meantime
+		tracker = ds.registry.get(retry.EventID.Bytes)
+		if tracker == nil {
+			return false
+		}
+	}
+
+	logger := tracker.logger
+	subscriber, err := slurpee.SubscriptionCache.GetSubscriberByID(ctx, retry.SubscriberID)
+	if err == nil {
+		var subscription db.Subscription
+		subscription, err = slurpee.SubscriptionCache.GetSubscriptionByID(ctx, retry.SubscriptionID)
+		if err == nil {
+			event := tracker.event
+			event.RetryCount += retry.AttemptNum
+			// A delivery parked or held before its first attempt has not
+			// started its retry age yet
+			firstAttemptAt := retry.FirstAttemptAt.Time
+			if retry.AttemptNum == 0 {
+				firstAttemptAt = time.Time{}
+			}
+			ds.enqueue(deliveryTask{
+				event:          event,
+				subscription:   subscription,
+				subscriber:     subscriber,
+				attemptNum:     int(retry.AttemptNum),
+				maxRetries:     int(retry.MaxRetries),
+				firstAttemptAt: firstAttemptAt,
+				tracker:        tracker,
+			})
+			return false
+		}
+	}
+
+	logger.Warn("Dropping delivery retry for removed subscriber or subscription", "error", err,
+		"subscriber_id", UuidToString(retry.SubscriberID),
+		"subscription_id", UuidToString(retry.SubscriptionID),
+	)
+	// A removed subscriber takes its deliveries with it
+	if subscriber.ID.Valid {
+		dropped := deliveryTask{
+			event:        tracker.event,
+			subscription: db.Subscription{ID: retry.SubscriptionID},
+			subscriber:   subscriber,
+			tracker:      tracker,
+		}
+		recordDeliveryState(ctx, slurpee, dropped, DeliveryFailed, time.Time{}, "subscription removed")
+	}
+	ds.advanceLane(retry.SubscriberID, retry.EventID)
+	result := deliveryResult{
+		subscriptionID: retry.SubscriptionID,
+		succeeded:      false,
+		exhausted:      true,
+	}
+	if tracker.record(result) {
+		finalizeEvent(ctx, slurpee, tracker, ds.registry)
+	}
+	return false
 }
 
 // finalizeEvent determines the final event status from tracker results
@@ -379,6 +901,19 @@ func finalizeEvent(ctx context.Context, slurpee *Application, tracker *eventTrac
 	}
 	tracker.mu.Unlock()
 
+	// A redrive or replay only covers some subscribers; others may still be
+	// dead-lettered
+	if allSucceeded && tracker.subset {
+		remaining, err := slurpee.DB.CountDeadLettersForEvent(ctx, tracker.event.ID)
+		if err != nil {
+			tracker.logger.Error("Failed to count remaining dead letters", "error", err)
+		}
+		if err != nil || remaining > 0 {
+			allSucceeded = false
+			anyFailed = true
+		}
+	}
+
 	var finalStatus string
 	if allSucceeded {
 		finalStatus = "delivered"
@@ -394,102 +929,109 @@ func finalizeEvent(ctx context.Context, slurpee *Application, tracker *eventTrac
 	registry.remove(tracker.event.ID.Bytes)
 }
 
-// matchesFilter evaluates whether an event's data matches a subscription's filter.
-// Filter is a JSON object of key-value pairs; all pairs must match (AND logic) against top-level keys in event data.
-// If filter is nil or empty, returns true (match all).
-func matchesFilter(filter []byte, eventData []byte) bool {
-	// If filter is nil or empty, match all events
-	if len(filter) == 0 {
-		return true
+// filterSubscriptionsByScope drops subscriptions whose subscriber is not
+// within the subscriber scope of the API secret that published the event.
+// Unscoped events are returned unchanged.
+func filterSubscriptionsByScope(event db.Event, subscriptions []db.Subscription, logger *slog.Logger) []db.Subscription {
+	scope := EventSubscriberScope(event)
+	if scope == nil {
+		return subscriptions
 	}
 
-	// Parse filter JSON
-	var filterObj map[string]interface{}
-	if err := json.Unmarshal(filter, &filterObj); err != nil {
-		// Invalid filter JSON - treat as non-match for safety
-		return false
+	inScope := make([]db.Subscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		if !scope[sub.SubscriberID.Bytes] {
+			logger.Info("Skipping subscriber outside API secret scope",
+				"subscriber_id", UuidToString(sub.SubscriberID),
+				"subscription_id", UuidToString(sub.ID),
+				"api_secret_id", UuidToString(event.ApiSecretID),
+			)
+			continue
+		}
+		inScope = append(inScope, sub)
 	}
+	return inScope
+}
 
-	// Empty filter object matches all
-	if len(filterObj) == 0 {
-		return true
+// OutOfScopeSubscribers returns the subscribers that have a subscription matching
+// the event's subject but were skipped because they are outside the publishing
+// API secret's subscriber scope.
+func OutOfScopeSubscribers(ctx context.Context, slurpee *Application, event db.Event) ([]db.Subscriber, error) {
+	scope := EventSubscriberScope(event)
+	if scope == nil {
+		return nil, nil
 	}
 
-	// Parse event data JSON
-	var dataObj map[string]interface{}
-	if err := json.Unmarshal(eventData, &dataObj); err != nil {
-		// Can't parse event data - no match
-		return false
+	subscriptions, err := slurpee.SubscriptionCache.GetMatchingSubscriptions(ctx, event.Subject)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check each filter key-value pair (AND logic)
-	for key, filterValue := range filterObj {
-		dataValue, exists := dataObj[key]
-		if !exists {
-			return false
+	seen := make(map[[16]byte]bool)
+	var skipped []db.Subscriber
+	for _, sub := range subscriptions {
+		if scope[sub.SubscriberID.Bytes] || seen[sub.SubscriberID.Bytes] {
+			continue
 		}
-
-		// Compare values - use JSON comparison for type-safe equality
-		filterJSON, _ := json.Marshal(filterValue)
-		dataJSON, _ := json.Marshal(dataValue)
-
-		if string(filterJSON) != string(dataJSON) {
-			return false
+		seen[sub.SubscriberID.Bytes] = true
+		subscriber, err := slurpee.SubscriptionCache.GetSubscriberByID(ctx, sub.SubscriberID)
+		if err != nil {
+			return nil, err
 		}
+		skipped = append(skipped, subscriber)
 	}
-
-	return true
+	return skipped, nil
 }
 
-// calculateBackoff returns the delay duration for exponential backoff.
-// Base delay is 1 second, doubling each attempt, capped at maxBackoffSeconds.
-func calculateBackoff(attemptNum int, maxBackoffSeconds int) time.Duration {
-	// Exponential backoff: 1s, 2s, 4s, 8s, 16s, ...
-	delaySeconds := math.Pow(2, float64(attemptNum))
-	if delaySeconds > float64(maxBackoffSeconds) {
-		delaySeconds = float64(maxBackoffSeconds)
-	}
-	return time.Duration(delaySeconds) * time.Second
+// matchesFilter evaluates whether an event's data matches a subscription's
+// filter; see Filter for the filter language. If filter is nil or empty,
+// returns true (match all). Invalid filters match nothing.
+func matchesFilter(filter []byte, eventData []byte) bool {
+	f, err := ParseFilter(filter)
+	return newEventFilterData(eventData).matches(f, err)
 }
 
 // deliverToSubscriber sends the event to a single subscriber endpoint and records the delivery attempt.
-func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Event, subscriber db.Subscriber, attemptNum int, logger *slog.Logger) bool {
+func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Event, subscriber db.Subscriber, attemptNum int, logger *slog.Logger) deliveryOutcome {
 	attemptID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 	now := time.Now().UTC()
 
-	// Build the request body (event data as JSON)
-	body := event.Data
-
-	// Build request headers
-	reqHeaders := map[string]string{
-		"Content-Type":     "application/json",
-		"X-Slurpee-Secret": subscriber.AuthSecret,
-		"X-Event-ID":       UuidToString(event.ID),
-		"X-Event-Subject":  event.Subject,
-	}
-	reqHeadersJSON, _ := json.Marshal(reqHeaders)
+	// Each attempt is a child of the span that ingested the event
+	ctx, span := slurpee.tracer().Start(contextWithParent(ctx, eventSpanContext(event)), spanDeliver,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(eventSpanAttributes(event)...),
+		trace.WithAttributes(
+			attribute.String("slurpee.subscriber.id", UuidToString(subscriber.ID)),
+			attribute.String("url.full", subscriber.EndpointUrl),
+			attribute.Int("slurpee.delivery.attempt", attemptNum+1),
+			attribute.Bool("slurpee.delivery.retry", attemptNum > 0),
+		),
+	)
+	defer span.End()
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscriber.EndpointUrl, bytes.NewReader(body))
+	// Build the request body in the subscriber's payload format
+	body, payloadHeaders, err := buildDeliveryPayload(cloudEventsSource(slurpee), event, subscriber.PayloadFormat)
 	if err != nil {
-		logger.Error("Failed to create delivery request",
+		logger.Error("Failed to build delivery payload",
 			"error", err,
 			"subscriber_id", UuidToString(subscriber.ID),
-			"endpoint_url", subscriber.EndpointUrl,
+			"payload_format", subscriber.PayloadFormat,
 			"attempt", attemptNum+1,
 		)
-		recordFailedAttempt(ctx, slurpee, attemptID, event, subscriber, reqHeadersJSON, now, fmt.Sprintf("request creation failed: %v", err))
-		return false
+		errMsg := fmt.Sprintf("payload build failed: %v", err)
+		span.SetStatus(codes.Error, errMsg)
+		recordFailedAttempt(ctx, slurpee, attemptID, event, subscriber, nil, now, 0, errMsg)
+		return deliveryOutcome{err: errMsg}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Slurpee-Secret", subscriber.AuthSecret)
-	req.Header.Set("X-Event-ID", UuidToString(event.ID))
-	req.Header.Set("X-Event-Subject", event.Subject)
+	// Build request headers; the recorded copy never contains the auth secret
+	reqHeaders, recordedHeaders := buildDeliveryHeaders(event, subscriber, body, payloadHeaders, now)
+	injectTraceparent(span, reqHeaders, recordedHeaders)
+	reqHeadersJSON, _ := json.Marshal(recordedHeaders)
 
-	// Send the request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	// Send the request with the subscriber's pooled client
+	start := time.Now()
+	resp, err := sendDelivery(ctx, slurpee, subscriber, body, reqHeaders)
 	if err != nil {
 		logger.Error("Delivery request failed",
 			"error", err,
@@ -497,20 +1039,28 @@ func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Eve
 			"endpoint_url", subscriber.EndpointUrl,
 			"attempt", attemptNum+1,
 		)
-		recordFailedAttempt(ctx, slurpee, attemptID, event, subscriber, reqHeadersJSON, now, fmt.Sprintf("request failed: %v", err))
-		return false
+		errMsg := err.Error()
+		duration := time.Since(start)
+		span.SetStatus(codes.Error, errMsg)
+		recordFailedAttempt(ctx, slurpee, attemptID, event, subscriber, reqHeadersJSON, now, duration, errMsg)
+		return deliveryOutcome{err: errMsg, duration: duration}
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // Limit to 1MB
 	respHeadersJSON, _ := json.Marshal(resp.Header)
+	duration := time.Since(start)
 
 	// Determine status based on response code
 	status := "succeeded"
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		status = "failed"
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if status == "failed" {
+		span.SetStatus(codes.Error, fmt.Sprintf("received HTTP %d", resp.StatusCode))
+	}
 
 	// Record the delivery attempt
 	_, err = slurpee.DB.InsertDeliveryAttempt(ctx, db.InsertDeliveryAttemptParams{
@@ -524,6 +1074,7 @@ func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Eve
 		ResponseHeaders:    respHeadersJSON,
 		ResponseBody:       string(respBody),
 		Status:             status,
+		DurationMs:         attemptDurationMs(duration),
 	})
 	if err != nil {
 		logger.Error("Failed to record delivery attempt", "error", err, "subscriber_id", UuidToString(subscriber.ID))
@@ -547,6 +1098,7 @@ func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Eve
 			"endpoint_url", subscriber.EndpointUrl,
 			"status_code", resp.StatusCode,
 			"attempt", attemptNum+1,
+			"duration_ms", duration.Milliseconds(),
 		)
 	} else {
 		logger.Warn("Delivery failed",
@@ -554,14 +1106,26 @@ func deliverToSubscriber(ctx context.Context, slurpee *Application, event db.Eve
 			"endpoint_url", subscriber.EndpointUrl,
 			"status_code", resp.StatusCode,
 			"attempt", attemptNum+1,
+			"duration_ms", duration.Milliseconds(),
 		)
 	}
 
-	return status == "succeeded"
+	outcome := deliveryOutcome{
+		succeeded:  status == "succeeded",
+		statusCode: resp.StatusCode,
+		duration:   duration,
+	}
+	if !outcome.succeeded {
+		outcome.err = fmt.Sprintf("received HTTP %d", resp.StatusCode)
+		outcome.permanent = isNonRetryableStatus(subscriber, resp.StatusCode)
+		outcome.retryAfter = retryAfterDelay(resp, now)
+	}
+	return outcome
 }
 
-// recordFailedAttempt records a delivery attempt that failed before getting a response.
-func recordFailedAttempt(ctx context.Context, slurpee *Application, attemptID pgtype.UUID, event db.Event, subscriber db.Subscriber, reqHeadersJSON []byte, attemptedAt time.Time, errMsg string) {
+// recordFailedAttempt records a delivery attempt that failed before getting a
+// response. duration is how long the request ran, or zero if it was never sent.
+func recordFailedAttempt(ctx context.Context, slurpee *Application, attemptID pgtype.UUID, event db.Event, subscriber db.Subscriber, reqHeadersJSON []byte, attemptedAt time.Time, duration time.Duration, errMsg string) {
 	_, err := slurpee.DB.InsertDeliveryAttempt(ctx, db.InsertDeliveryAttemptParams{
 		ID:             attemptID,
 		EventID:        event.ID,
@@ -571,6 +1135,7 @@ func recordFailedAttempt(ctx context.Context, slurpee *Application, attemptID pg
 		RequestHeaders: reqHeadersJSON,
 		ResponseBody:   errMsg,
 		Status:         "failed",
+		DurationMs:     attemptDurationMs(duration),
 	})
 	if err != nil {
 		slog.Error("Failed to record failed delivery attempt", "error", err, "subscriber_id", UuidToString(subscriber.ID))
@@ -591,8 +1156,9 @@ func recordFailedAttempt(ctx context.Context, slurpee *Application, attemptID pg
 // ResumeUnfinishedDeliveries queries for events with 'pending' or 'partial' status
 // and feeds them back through the delivery pipeline. Pending events are sent to
 // DeliveryChan for normal dispatchEvent processing. Partial events are enqueued
-// directly into the dispatcher's task queue, skipping already-succeeded subscribers
-// and continuing retry counts. Call this after StartDispatcher.
+// directly into the dispatcher's task queue, skipping already-succeeded subscribers,
+// continuing retry counts, and leaving scheduled retries to the retry poller.
+// Call this after StartDispatcher.
 func ResumeUnfinishedDeliveries(slurpee *Application, ds *DispatcherState) {
 	ctx := context.Background()
 	events, err := slurpee.DB.GetResumableEvents(ctx)
@@ -619,6 +1185,15 @@ func ResumeUnfinishedDeliveries(slurpee *Application, ds *DispatcherState) {
 	slog.Info("Resuming unfinished deliveries on startup",
 		"pending", pendingCount, "partial", partialCount, "total", len(events))
 
+	// Resume partial events directly via the dispatcher's task queue. They go
+	// first so that ordered subscriptions see events that were already under
+	// way before the pending events queued behind them.
+	for _, event := range events {
+		if event.DeliveryStatus == "partial" {
+			resumePartialEvent(ctx, slurpee, event, ds)
+		}
+	}
+
 	// Feed pending events into DeliveryChan in a goroutine to avoid blocking
 	// if the channel buffer is smaller than the number of events.
 	go func() {
@@ -628,52 +1203,53 @@ func ResumeUnfinishedDeliveries(slurpee *Application, ds *DispatcherState) {
 			}
 		}
 	}()
-
-	// Resume partial events directly via the dispatcher's task queue
-	for _, event := range events {
-		if event.DeliveryStatus == "partial" {
-			resumePartialEvent(ctx, slurpee, event, ds)
-		}
-	}
 }
 
 // resumePartialEvent handles resumption of a single partial event by checking
-// delivery history, re-running subscription matching, and enqueuing tasks for
-// subscribers that still need delivery.
-func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Event, ds *DispatcherState) {
+// its deliveries, re-running subscription matching, and enqueuing tasks for
+// subscribers that still need delivery. Subscribers with a retry scheduled in
+// delivery_retries are tracked but left for the retry poller to enqueue, and
+// dead-lettered deliveries are left for a redrive.
+// Returns false if the event was not resumed, including when a tracker is
+// already registered for it.
+func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Event, ds *DispatcherState) bool {
 	logger := slog.Default().With("event_id", UuidToString(event.ID), "subject", event.Subject, "resume", true)
 
-	// Get per-subscriber delivery summary
-	summary, err := slurpee.DB.GetDeliverySummaryForEvent(ctx, event.ID)
+	// Get per-subscriber delivery state
+	deliveries, err := slurpee.DB.ListDeliveriesForEvent(ctx, event.ID)
 	if err != nil {
-		logger.Error("Failed to get delivery summary for partial event", "error", err)
-		return
+		logger.Error("Failed to list deliveries for partial event", "error", err)
+		return false
+	}
+	states := make(map[[16]byte]db.ListDeliveriesForEventRow, len(deliveries))
+	for _, d := range deliveries {
+		states[d.SubscriberID.Bytes] = d
 	}
 
-	// Build lookup: subscriber_id -> {failed_count, succeeded_count}
-	type subSummary struct {
-		failedCount    int64
-		succeededCount int64
+	// Subscribers whose next attempt is already scheduled
+	retries, err := slurpee.DB.ListDeliveryRetriesForEvent(ctx, event.ID)
+	if err != nil {
+		logger.Error("Failed to list scheduled retries for partial event", "error", err)
+		return false
 	}
-	summaryMap := make(map[[16]byte]subSummary)
-	for _, s := range summary {
-		summaryMap[s.SubscriberID.Bytes] = subSummary{
-			failedCount:    s.FailedCount,
-			succeededCount: s.SucceededCount,
-		}
+	scheduled := make(map[[16]byte]bool, len(retries))
+	for _, r := range retries {
+		scheduled[r.SubscriberID.Bytes] = true
 	}
 
 	// Re-run subscription matching (same logic as dispatchEvent)
 	subscriptions, err := slurpee.SubscriptionCache.GetMatchingSubscriptions(ctx, event.Subject)
 	if err != nil {
 		logger.Error("Failed to find matching subscriptions for partial event", "error", err)
-		return
+		return false
 	}
 
+	subscriptions = filterSubscriptionsByScope(event, subscriptions, logger)
+
 	if len(subscriptions) == 0 {
 		logger.Warn("No matching subscriptions for partial event, marking as recorded")
 		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "recorded")
-		return
+		return false
 	}
 
 	// Group subscriptions by subscriber
@@ -695,6 +1271,10 @@ func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Even
 
 	// Build delivery tasks, deduplicated per subscriber, skipping already-succeeded
 	var tasks []deliveryTask
+	var reserved []deliveryTask // scheduled retries holding an ordering lane
+	var deadLettered []deliveryResult
+	pendingRetries := 0
+	filterData := newEventFilterData(event.Data)
 	for subID, subs := range subscriberSubs {
 		subscriber, ok := subscribers[subID]
 		if !ok {
@@ -702,7 +1282,8 @@ func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Even
 		}
 
 		// Check if this subscriber already succeeded
-		if ss, found := summaryMap[subID.Bytes]; found && ss.succeededCount > 0 {
+		state, found := states[subID.Bytes]
+		if found && state.Status == DeliverySucceeded {
 			logger.Debug("Skipping already-succeeded subscriber",
 				"subscriber_id", UuidToString(subID))
 			continue
@@ -712,7 +1293,7 @@ func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Even
 		var bestSub *db.Subscription
 		bestMaxRetries := -1
 		for i, sub := range subs {
-			if !matchesFilter(sub.Filter, event.Data) {
+			if !slurpee.SubscriptionCache.filterMatches(sub, filterData) {
 				continue
 			}
 			maxRetries := slurpee.Config.MaxRetries
@@ -729,10 +1310,32 @@ func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Even
 			continue
 		}
 
-		// Continue retry count from prior failed attempts
+		if found && state.Status == DeliveryFailed {
+			logger.Debug("Leaving dead-lettered delivery for redrive",
+				"subscriber_id", UuidToString(subID))
+			deadLettered = append(deadLettered, deliveryResult{subscriptionID: bestSub.ID, exhausted: true})
+			continue
+		}
+
+		if scheduled[subID.Bytes] {
+			logger.Debug("Leaving scheduled retry to the retry poller",
+				"subscriber_id", UuidToString(subID))
+			pendingRetries++
+			if bestSub.Ordered {
+				reserved = append(reserved, deliveryTask{
+					event:        event,
+					subscription: *bestSub,
+					subscriber:   subscriber,
+					maxRetries:   bestMaxRetries,
+				})
+			}
+			continue
+		}
+
+		// Continue retry count from prior attempts
 		attemptNum := 0
-		if ss, found := summaryMap[subID.Bytes]; found {
-			attemptNum = int(ss.failedCount)
+		if found {
+			attemptNum = int(state.Attempts)
 		}
 
 		tasks = append(tasks, deliveryTask{
@@ -744,28 +1347,43 @@ func resumePartialEvent(ctx context.Context, slurpee *Application, event db.Even
 		})
 	}
 
-	if len(tasks) == 0 {
-		logger.Info("No subscribers need delivery for partial event, marking as delivered")
-		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "delivered")
-		return
+	if len(tasks) == 0 && pendingRetries == 0 {
+		status := "delivered"
+		if len(deadLettered) > 0 {
+			status = "failed"
+		}
+		logger.Info("No subscribers need delivery for partial event", "status", status)
+		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, status)
+		return false
 	}
 
-	logger.Info("Resuming partial event", "subscribers_remaining", len(tasks))
-
-	// Create tracker and enqueue tasks directly into the dispatcher
+	// Create tracker and enqueue tasks directly into the dispatcher.
+	// Dead-lettered deliveries count as exhausted towards the final status.
 	tracker := &eventTracker{
 		event:    event,
-		expected: len(tasks),
+		expected: len(tasks) + pendingRetries + len(deadLettered),
 		results:  make(map[[16]byte]deliveryResult),
 		logger:   logger,
 	}
-	ds.registry.register(event.ID.Bytes, tracker)
+	for _, r := range deadLettered {
+		tracker.results[r.subscriptionID.Bytes] = r
+	}
+	if !ds.registry.registerIfAbsent(event.ID.Bytes, tracker) {
+		logger.Debug("Partial event already has an in-flight tracker")
+		return false
+	}
 
+	logger.Info("Resuming partial event", "subscribers_remaining", len(tasks), "scheduled_retries", pendingRetries)
+
+	for i := range reserved {
+		reserved[i].tracker = tracker
+		ds.ordering.reserve(reserved[i])
+	}
 	for i := range tasks {
 		tasks[i].tracker = tracker
-		ds.inflightWg.Add(1)
-		ds.taskQueue <- tasks[i]
+		ds.enqueue(tasks[i])
 	}
+	return true
 }
 
 // PublishCreatedEvent publishes a 'created' bus message for SSE clients.
@@ -780,23 +1398,108 @@ func PublishCreatedEvent(slurpee *Application, event db.Event, props map[string]
 	})
 }
 
-// ReplayToSubscriber delivers an event to a single subscriber as a replay.
-// It resets the event status to pending, performs a single delivery attempt, and updates the event status.
-func ReplayToSubscriber(slurpee *Application, event db.Event, subscriber db.Subscriber) {
-	ctx := context.Background()
+// ReplayToSubscriber submits an event to the dispatcher for delivery to a
+// single subscriber, under the retry policy of the subscriber's best matching
+// subscription. The subscriber's dead letter for the event is dropped, and once
+// the delivery is done the event's status is worked out from all of its
+// subscribers, as for a dead-letter redrive.
+// Subscribers outside the publishing API secret's scope are never replayed to;
+// ErrReplayOutOfScope is returned for them. ErrReplayNotSubscribed is returned
+// if none of the subscriber's subscriptions match the event, and
+// ErrReplayInFlight if the event has deliveries in flight or scheduled.
+func ReplayToSubscriber(ctx context.Context, slurpee *Application, event db.Event, subscriber db.Subscriber) error {
+	ds := slurpee.dispatcher
+	if ds == nil {
+		return ErrDispatcherNotRunning
+	}
 	logger := slog.Default().With("event_id", UuidToString(event.ID), "subject", event.Subject, "replay", true)
 
-	updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "pending")
+	if scope := EventSubscriberScope(event); scope != nil && !scope[subscriber.ID.Bytes] {
+		logger.Warn("Refusing replay to subscriber outside API secret scope",
+			"subscriber_id", UuidToString(subscriber.ID),
+			"api_secret_id", UuidToString(event.ApiSecretID),
+		)
+		return ErrReplayOutOfScope
+	}
 
-	succeeded := deliverToSubscriber(ctx, slurpee, event, subscriber, 0, logger)
+	subscription, maxRetries, err := bestSubscriptionForSubscriber(ctx, slurpee, event, subscriber.ID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		return ErrReplayNotSubscribed
+	}
 
-	if succeeded {
-		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "delivered")
-		logger.Info("Replay delivery succeeded", "subscriber_id", UuidToString(subscriber.ID))
-	} else {
-		updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "failed")
-		logger.Warn("Replay delivery failed", "subscriber_id", UuidToString(subscriber.ID))
+	// Claim the event so the replay cannot race a dispatch, retry or redrive
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   logger,
+		subset:   true,
+	}
+	if !ds.registry.registerIfAbsent(event.ID.Bytes, tracker) {
+		return ErrReplayInFlight
+	}
+	// Scheduled retries would join this tracker when the poller claims them
+	retries, err := slurpee.DB.ListDeliveryRetriesForEvent(ctx, event.ID)
+	if err != nil || len(retries) > 0 {
+		ds.registry.remove(event.ID.Bytes)
+		if err != nil {
+			return err
+		}
+		return ErrReplayInFlight
+	}
+	err = slurpee.DB.DeleteDeadLetterForSubscriber(ctx, db.DeleteDeadLetterForSubscriberParams{
+		EventID:      event.ID,
+		SubscriberID: subscriber.ID,
+	})
+	if err != nil {
+		ds.registry.remove(event.ID.Bytes)
+		return err
+	}
+
+	logger.Info("Replaying event to subscriber", "subscriber_id", UuidToString(subscriber.ID))
+	updateEventStatus(ctx, slurpee, event.ID, event.RetryCount, "partial")
+
+	task := deliveryTask{
+		event:        event,
+		subscription: *subscription,
+		subscriber:   subscriber,
+		attemptNum:   0,
+		maxRetries:   maxRetries,
+		tracker:      tracker,
+	}
+	recordDeliveryState(ctx, slurpee, task, DeliveryPending, time.Time{}, "")
+	ds.enqueue(task)
+	return nil
+}
+
+// bestSubscriptionForSubscriber picks the subscriber's subscription that
+// matches the event with the highest effective max retries, the same
+// deduplication as dispatchEvent. Returns nil if none matches.
+func bestSubscriptionForSubscriber(ctx context.Context, slurpee *Application, event db.Event, subscriberID pgtype.UUID) (*db.Subscription, int, error) {
+	subscriptions, err := slurpee.SubscriptionCache.GetMatchingSubscriptions(ctx, event.Subject)
+	if err != nil {
+		return nil, 0, err
+	}
+	var best *db.Subscription
+	bestMaxRetries := -1
+	filterData := newEventFilterData(event.Data)
+	for i, sub := range subscriptions {
+		if sub.SubscriberID != subscriberID || !slurpee.SubscriptionCache.filterMatches(sub, filterData) {
+			continue
+		}
+		maxRetries := slurpee.Config.MaxRetries
+		if sub.MaxRetries.Valid {
+			maxRetries = int(sub.MaxRetries.Int32)
+		}
+		if maxRetries > bestMaxRetries {
+			best = &subscriptions[i]
+			bestMaxRetries = maxRetries
+		}
 	}
+	return best, bestMaxRetries, nil
 }
 
 // updateEventStatus updates the delivery_status, retry_count, and status_updated_at on an event.
@@ -3,10 +3,12 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,8 +16,10 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/sweater-ventures/slurpee/config"
 	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/webhook"
 )
 
 // --- local test helpers (avoid importing testutil to prevent import cycle) ---
@@ -30,10 +34,26 @@ var _ db.Querier = (*deliveryMockQuerier)(nil)
 func (m *deliveryMockQuerier) AddApiSecretSubscriber(ctx context.Context, arg db.AddApiSecretSubscriberParams) error {
 	return m.Called(ctx, arg).Error(0)
 }
+func (m *deliveryMockQuerier) ClaimDueDeliveryRetries(ctx context.Context, arg db.ClaimDueDeliveryRetriesParams) ([]db.DeliveryRetry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.DeliveryRetry), args.Error(1)
+}
+func (m *deliveryMockQuerier) CountDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
 func (m *deliveryMockQuerier) CountEventsAfterTimestamp(ctx context.Context, arg db.CountEventsAfterTimestampParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
 }
+func (m *deliveryMockQuerier) CountEventsForReplay(ctx context.Context, arg db.CountEventsForReplayParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *deliveryMockQuerier) CountHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, subscriberID)
+	return args.Get(0).(int64), args.Error(1)
+}
 func (m *deliveryMockQuerier) CreateSubscription(ctx context.Context, arg db.CreateSubscriptionParams) (db.Subscription, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Subscription), args.Error(1)
@@ -41,6 +61,18 @@ func (m *deliveryMockQuerier) CreateSubscription(ctx context.Context, arg db.Cre
 func (m *deliveryMockQuerier) DeleteApiSecret(ctx context.Context, id pgtype.UUID) error {
 	return m.Called(ctx, id).Error(0)
 }
+func (m *deliveryMockQuerier) DeleteDeadLetter(ctx context.Context, id pgtype.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *deliveryMockQuerier) DeleteDeadLetterForSubscriber(ctx context.Context, arg db.DeleteDeadLetterForSubscriberParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
+func (m *deliveryMockQuerier) DeleteDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) error {
+	return m.Called(ctx, eventID).Error(0)
+}
+func (m *deliveryMockQuerier) DeleteDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) error {
+	return m.Called(ctx, eventID).Error(0)
+}
 func (m *deliveryMockQuerier) DeleteLogConfigForSubject(ctx context.Context, subject string) error {
 	return m.Called(ctx, subject).Error(0)
 }
@@ -53,6 +85,15 @@ func (m *deliveryMockQuerier) DeleteSubscription(ctx context.Context, id pgtype.
 func (m *deliveryMockQuerier) DeleteSubscriptionsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) error {
 	return m.Called(ctx, subscriberID).Error(0)
 }
+func (m *deliveryMockQuerier) DisableSubscriber(ctx context.Context, arg db.DisableSubscriberParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
+func (m *deliveryMockQuerier) EnableSubscriber(ctx context.Context, id pgtype.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *deliveryMockQuerier) FinishReplayJob(ctx context.Context, arg db.FinishReplayJobParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
 func (m *deliveryMockQuerier) GetApiSecretByID(ctx context.Context, id pgtype.UUID) (db.ApiSecret, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(db.ApiSecret), args.Error(1)
@@ -61,14 +102,22 @@ func (m *deliveryMockQuerier) GetApiSecretSubscriberExists(ctx context.Context,
 	args := m.Called(ctx, arg)
 	return args.Get(0).(bool), args.Error(1)
 }
-func (m *deliveryMockQuerier) GetDeliverySummaryForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.GetDeliverySummaryForEventRow, error) {
-	args := m.Called(ctx, eventID)
-	return args.Get(0).([]db.GetDeliverySummaryForEventRow), args.Error(1)
+func (m *deliveryMockQuerier) GetDeadLettersByIDs(ctx context.Context, ids []pgtype.UUID) ([]db.DeadLetter, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]db.DeadLetter), args.Error(1)
+}
+func (m *deliveryMockQuerier) GetDeliverySummaryForSubscriber(ctx context.Context, arg db.GetDeliverySummaryForSubscriberParams) (db.GetDeliverySummaryForSubscriberRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.GetDeliverySummaryForSubscriberRow), args.Error(1)
 }
 func (m *deliveryMockQuerier) GetEventByID(ctx context.Context, id pgtype.UUID) (db.Event, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(db.Event), args.Error(1)
 }
+func (m *deliveryMockQuerier) GetReplayJob(ctx context.Context, id pgtype.UUID) (db.ReplayJob, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.ReplayJob), args.Error(1)
+}
 func (m *deliveryMockQuerier) GetResumableEvents(ctx context.Context) ([]db.Event, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -85,10 +134,6 @@ func (m *deliveryMockQuerier) GetSubscriberByID(ctx context.Context, id pgtype.U
 	args := m.Called(ctx, id)
 	return args.Get(0).(db.Subscriber), args.Error(1)
 }
-func (m *deliveryMockQuerier) GetSubscriptionsMatchingSubject(ctx context.Context, subjectPattern string) ([]db.Subscription, error) {
-	args := m.Called(ctx, subjectPattern)
-	return args.Get(0).([]db.Subscription), args.Error(1)
-}
 func (m *deliveryMockQuerier) InsertApiSecret(ctx context.Context, arg db.InsertApiSecretParams) (db.ApiSecret, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.ApiSecret), args.Error(1)
@@ -101,6 +146,10 @@ func (m *deliveryMockQuerier) InsertEvent(ctx context.Context, arg db.InsertEven
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Event), args.Error(1)
 }
+func (m *deliveryMockQuerier) InsertReplayJob(ctx context.Context, arg db.InsertReplayJobParams) (db.ReplayJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ReplayJob), args.Error(1)
+}
 func (m *deliveryMockQuerier) ListAllApiSecretHashes(ctx context.Context) ([]db.ListAllApiSecretHashesRow, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.ListAllApiSecretHashesRow), args.Error(1)
@@ -117,6 +166,18 @@ func (m *deliveryMockQuerier) ListApiSecretsForSubscriber(ctx context.Context, s
 	args := m.Called(ctx, subscriberID)
 	return args.Get(0).([]db.ApiSecret), args.Error(1)
 }
+func (m *deliveryMockQuerier) ListDeadLettersFiltered(ctx context.Context, arg db.ListDeadLettersFilteredParams) ([]db.ListDeadLettersFilteredRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListDeadLettersFilteredRow), args.Error(1)
+}
+func (m *deliveryMockQuerier) ListDeliveriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.ListDeliveriesForEventRow, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).([]db.ListDeliveriesForEventRow), args.Error(1)
+}
+func (m *deliveryMockQuerier) ListDeliveriesForSubscriber(ctx context.Context, arg db.ListDeliveriesForSubscriberParams) ([]db.ListDeliveriesForSubscriberRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListDeliveriesForSubscriberRow), args.Error(1)
+}
 func (m *deliveryMockQuerier) ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.DeliveryAttempt, error) {
 	args := m.Called(ctx, eventID)
 	return args.Get(0).([]db.DeliveryAttempt), args.Error(1)
@@ -125,6 +186,10 @@ func (m *deliveryMockQuerier) ListDeliveryAttemptsForSubscriber(ctx context.Cont
 	args := m.Called(ctx, subscriberID)
 	return args.Get(0).([]db.DeliveryAttempt), args.Error(1)
 }
+func (m *deliveryMockQuerier) ListDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.DeliveryRetry, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).([]db.DeliveryRetry), args.Error(1)
+}
 func (m *deliveryMockQuerier) ListEvents(ctx context.Context, arg db.ListEventsParams) ([]db.Event, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -133,10 +198,26 @@ func (m *deliveryMockQuerier) ListEventsAfterTimestamp(ctx context.Context, arg
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]db.Event), args.Error(1)
 }
+func (m *deliveryMockQuerier) ListEventsForReplay(ctx context.Context, arg db.ListEventsForReplayParams) ([]db.Event, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.Event), args.Error(1)
+}
 func (m *deliveryMockQuerier) ListLogConfigs(ctx context.Context) ([]db.LogConfig, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.LogConfig), args.Error(1)
 }
+func (m *deliveryMockQuerier) ListReplayJobs(ctx context.Context, limit int32) ([]db.ReplayJob, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]db.ReplayJob), args.Error(1)
+}
+func (m *deliveryMockQuerier) ListRunningReplayJobs(ctx context.Context) ([]db.ReplayJob, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]db.ReplayJob), args.Error(1)
+}
+func (m *deliveryMockQuerier) ListSubscriberLatencyStats(ctx context.Context, since pgtype.Timestamptz) ([]db.ListSubscriberLatencyStatsRow, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]db.ListSubscriberLatencyStatsRow), args.Error(1)
+}
 func (m *deliveryMockQuerier) ListSubscribers(ctx context.Context) ([]db.Subscriber, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.Subscriber), args.Error(1)
@@ -153,12 +234,28 @@ func (m *deliveryMockQuerier) ListSubscriptionsForSubscriber(ctx context.Context
 	args := m.Called(ctx, subscriberID)
 	return args.Get(0).([]db.Subscription), args.Error(1)
 }
+func (m *deliveryMockQuerier) PauseSubscriber(ctx context.Context, id pgtype.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *deliveryMockQuerier) RecordDeliveryAttempt(ctx context.Context, arg db.RecordDeliveryAttemptParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
+func (m *deliveryMockQuerier) ReleaseHeldDeliveries(ctx context.Context, subscriberID pgtype.UUID) error {
+	return m.Called(ctx, subscriberID).Error(0)
+}
+func (m *deliveryMockQuerier) ReleaseHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, subscriberID)
+	return args.Get(0).(int64), args.Error(1)
+}
 func (m *deliveryMockQuerier) RemoveAllApiSecretSubscribers(ctx context.Context, apiSecretID pgtype.UUID) error {
 	return m.Called(ctx, apiSecretID).Error(0)
 }
 func (m *deliveryMockQuerier) RemoveApiSecretSubscriber(ctx context.Context, arg db.RemoveApiSecretSubscriberParams) error {
 	return m.Called(ctx, arg).Error(0)
 }
+func (m *deliveryMockQuerier) ResumeSubscriber(ctx context.Context, id pgtype.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
 func (m *deliveryMockQuerier) SearchEventsByDataContent(ctx context.Context, arg db.SearchEventsByDataContentParams) ([]db.Event, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -191,6 +288,9 @@ func (m *deliveryMockQuerier) UpdateEventDeliveryStatus(ctx context.Context, arg
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Event), args.Error(1)
 }
+func (m *deliveryMockQuerier) UpdateReplayJobProgress(ctx context.Context, arg db.UpdateReplayJobProgressParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
 func (m *deliveryMockQuerier) UpdateSubscriber(ctx context.Context, arg db.UpdateSubscriberParams) (db.Subscriber, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Subscriber), args.Error(1)
@@ -199,6 +299,16 @@ func (m *deliveryMockQuerier) UpdateSubscription(ctx context.Context, arg db.Upd
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Subscription), args.Error(1)
 }
+func (m *deliveryMockQuerier) UpsertDeadLetter(ctx context.Context, arg db.UpsertDeadLetterParams) (db.DeadLetter, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.DeadLetter), args.Error(1)
+}
+func (m *deliveryMockQuerier) UpsertDelivery(ctx context.Context, arg db.UpsertDeliveryParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
+func (m *deliveryMockQuerier) UpsertDeliveryRetry(ctx context.Context, arg db.UpsertDeliveryRetryParams) error {
+	return m.Called(ctx, arg).Error(0)
+}
 func (m *deliveryMockQuerier) UpsertLogConfig(ctx context.Context, arg db.UpsertLogConfigParams) (db.LogConfig, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.LogConfig), args.Error(1)
@@ -234,13 +344,22 @@ func newTestEvent(opts ...func(*db.Event)) db.Event {
 
 func newTestSubscriber(opts ...func(*db.Subscriber)) db.Subscriber {
 	s := db.Subscriber{
-		ID:          newTestUUID(),
-		Name:        "test-subscriber",
-		EndpointUrl: "https://example.com/webhook",
-		AuthSecret:  "test-auth-secret",
-		MaxParallel: 1,
-		CreatedAt:   newTestTimestamp(),
-		UpdatedAt:   newTestTimestamp(),
+		ID:                   newTestUUID(),
+		Name:                 "test-subscriber",
+		EndpointUrl:          "https://example.com/webhook",
+		AuthSecret:           "test-auth-secret",
+		MaxParallel:          1,
+		CreatedAt:            newTestTimestamp(),
+		UpdatedAt:            newTestTimestamp(),
+		SigningMode:          "secret",
+		PayloadFormat:        "data",
+		BatchSize:            1,
+		NonRetryableStatuses: []int32{400, 404, 410},
+		HttpMethod:           "POST",
+		TimeoutSeconds:       30,
+		ExtraHeaders:         []byte("{}"),
+		AuthMode:             "none",
+		OauthScopes:          []string{},
 	}
 	for _, opt := range opts {
 		opt(&s)
@@ -250,11 +369,14 @@ func newTestSubscriber(opts ...func(*db.Subscriber)) db.Subscriber {
 
 func newTestSubscription(opts ...func(*db.Subscription)) db.Subscription {
 	s := db.Subscription{
-		ID:             newTestUUID(),
-		SubscriberID:   newTestUUID(),
-		SubjectPattern: "test.*",
-		CreatedAt:      newTestTimestamp(),
-		UpdatedAt:      newTestTimestamp(),
+		ID:                   newTestUUID(),
+		SubscriberID:         newTestUUID(),
+		SubjectPattern:       "test.*",
+		CreatedAt:            newTestTimestamp(),
+		UpdatedAt:            newTestTimestamp(),
+		RetryPolicy:          "exponential",
+		RetryIntervalSeconds: 1,
+		RetrySchedule:        []int32{},
 	}
 	for _, opt := range opts {
 		opt(&s)
@@ -279,6 +401,32 @@ func newDeliveryTestApp(mockDB *deliveryMockQuerier) *Application {
 		EventBus:          NewEventBus(),
 		Sessions:          NewSessionStore(),
 		SubscriptionCache: NewSubscriptionCache(mockDB),
+		HTTPClients:       NewHTTPClientPool(),
+		OAuthTokens:       NewOAuthTokenCache(),
+		Metrics:           NewMetrics(),
+		TracerProvider:    NewTracerProvider(),
+	}
+}
+
+// mockSubscribers makes the subscription cache load the given subscribers and
+// no subscriptions. processDeliveryTask checks each task's subscriber against
+// the cache; tasks for subscribers it does not hold keep their own snapshot.
+func mockSubscribers(mockDB *deliveryMockQuerier, subscribers ...db.Subscriber) {
+	mockDB.On("ListSubscribers", mock.Anything).Return(subscribers, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{}, nil)
+}
+
+// newTestDispatcherState builds a DispatcherState around test-owned channels,
+// with the circuit breaker disabled.
+func newTestDispatcherState(inflightWg *sync.WaitGroup, taskQueue chan deliveryTask, registry *eventRegistry) *DispatcherState {
+	return &DispatcherState{
+		inflightWg: inflightWg,
+		taskQueue:  taskQueue,
+		registry:   registry,
+		breakers:   newBreakerRegistry(0, 0),
+		ordering:   newOrderingGate(),
+		rateLimits: newRateLimiterRegistry(),
+		replays:    newReplayJobRegistry(),
 	}
 }
 
@@ -310,7 +458,7 @@ func TestDeliverToSubscriber_CorrectHeadersAndBody(t *testing.T) {
 
 	result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
 
-	assert.True(t, result)
+	assert.True(t, result.succeeded)
 	assert.Equal(t, "application/json", receivedHeaders.Get("Content-Type"))
 	assert.Equal(t, "webhook-secret", receivedHeaders.Get("X-Slurpee-Secret"))
 	assert.Equal(t, UuidToString(event.ID), receivedHeaders.Get("X-Event-ID"))
@@ -319,6 +467,190 @@ func TestDeliverToSubscriber_CorrectHeadersAndBody(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestDeliverToSubscriber_EnvelopePayloadFormat(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	traceID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.TraceID = traceID
+	})
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PayloadFormat = PayloadFormatEnvelope
+	})
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded)
+	var envelope EventEnvelope
+	assert.NoError(t, json.Unmarshal(receivedBody, &envelope))
+	assert.Equal(t, UuidToString(event.ID), envelope.ID)
+	assert.Equal(t, event.Subject, envelope.Subject)
+	assert.True(t, event.Timestamp.Time.Equal(envelope.Timestamp))
+	if assert.NotNil(t, envelope.TraceID) {
+		assert.Equal(t, UuidToString(traceID), *envelope.TraceID)
+	}
+	assert.JSONEq(t, string(event.Data), string(envelope.Data))
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeliverToSubscriber_CloudEventsStructuredPayloadFormat(t *testing.T) {
+	var receivedHeaders http.Header
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	app.Config.BaseURL = "https://slurpee.example.com"
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PayloadFormat = PayloadFormatCloudEventsStructured
+	})
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded)
+	assert.Equal(t, "application/cloudevents+json", receivedHeaders.Get("Content-Type"))
+	var ce CloudEvent
+	assert.NoError(t, json.Unmarshal(receivedBody, &ce))
+	assert.Equal(t, "1.0", ce.SpecVersion)
+	assert.Equal(t, UuidToString(event.ID), ce.ID)
+	assert.Equal(t, "https://slurpee.example.com", ce.Source)
+	assert.Equal(t, event.Subject, ce.Type)
+	assert.Equal(t, "application/json", ce.DataContentType)
+	assert.JSONEq(t, string(event.Data), string(ce.Data))
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeliverToSubscriber_CloudEventsBinaryPayloadFormat(t *testing.T) {
+	var receivedHeaders http.Header
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	traceID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.TraceID = traceID
+	})
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PayloadFormat = PayloadFormatCloudEventsBinary
+	})
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded)
+	assert.Equal(t, "application/json", receivedHeaders.Get("Content-Type"))
+	assert.Equal(t, "1.0", receivedHeaders.Get("ce-specversion"))
+	assert.Equal(t, UuidToString(event.ID), receivedHeaders.Get("ce-id"))
+	assert.Equal(t, "slurpee", receivedHeaders.Get("ce-source"))
+	assert.Equal(t, event.Subject, receivedHeaders.Get("ce-type"))
+	assert.NotEmpty(t, receivedHeaders.Get("ce-time"))
+	assert.Equal(t, UuidToString(traceID), receivedHeaders.Get("ce-traceid"))
+	assert.JSONEq(t, string(event.Data), string(receivedBody))
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeliverToSubscriber_HMACSigningMode(t *testing.T) {
+	var verifyErr error
+	var receivedHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		_, verifyErr = webhook.VerifyRequest(r, "webhook-secret", 0)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.AuthSecret = "webhook-secret"
+		s.SigningMode = SigningModeHMAC
+	})
+
+	var capturedParams db.InsertDeliveryAttemptParams
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Run(func(args mock.Arguments) {
+			capturedParams = args.Get(1).(db.InsertDeliveryAttemptParams)
+		}).
+		Return(db.DeliveryAttempt{}, nil)
+
+	result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded)
+	assert.NoError(t, verifyErr)
+	assert.Empty(t, receivedHeaders.Get("X-Slurpee-Secret"))
+	assert.NotEmpty(t, receivedHeaders.Get(webhook.HeaderSignature))
+	assert.NotEmpty(t, receivedHeaders.Get(webhook.HeaderTimestamp))
+	assert.NotContains(t, string(capturedParams.RequestHeaders), "webhook-secret")
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeliverToSubscriber_RedactsSecretInRecordedHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.AuthSecret = "webhook-secret"
+	})
+
+	var capturedParams db.InsertDeliveryAttemptParams
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Run(func(args mock.Arguments) {
+			capturedParams = args.Get(1).(db.InsertDeliveryAttemptParams)
+		}).
+		Return(db.DeliveryAttempt{}, nil)
+
+	deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	var recorded map[string]string
+	assert.NoError(t, json.Unmarshal(capturedParams.RequestHeaders, &recorded))
+	assert.Equal(t, "[REDACTED]", recorded["X-Slurpee-Secret"])
+	assert.NotContains(t, string(capturedParams.RequestHeaders), "webhook-secret")
+	mockDB.AssertExpectations(t)
+}
+
 func TestDeliverToSubscriber_Returns_True_For_2xx(t *testing.T) {
 	statusCodes := []int{200, 201, 202, 204, 299}
 
@@ -340,7 +672,7 @@ func TestDeliverToSubscriber_Returns_True_For_2xx(t *testing.T) {
 				Return(db.DeliveryAttempt{}, nil)
 
 			result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
-			assert.True(t, result)
+			assert.True(t, result.succeeded)
 			mockDB.AssertExpectations(t)
 		})
 	}
@@ -367,7 +699,7 @@ func TestDeliverToSubscriber_Returns_False_For_Non2xx(t *testing.T) {
 				Return(db.DeliveryAttempt{}, nil)
 
 			result := deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
-			assert.False(t, result)
+			assert.False(t, result.succeeded)
 			mockDB.AssertExpectations(t)
 		})
 	}
@@ -475,6 +807,8 @@ func TestDeliverToSubscriber_SendsEventDataAsBody(t *testing.T) {
 func TestDispatchEvent_FindsMatchingSubscriptionsAndEnqueuesTasks(t *testing.T) {
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
 
 	event := newTestEvent(func(e *db.Event) {
 		e.Subject = "orders.created"
@@ -495,7 +829,7 @@ func TestDispatchEvent_FindsMatchingSubscriptionsAndEnqueuesTasks(t *testing.T)
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	assert.Equal(t, 1, len(taskQueue))
 
@@ -509,9 +843,43 @@ func TestDispatchEvent_FindsMatchingSubscriptionsAndEnqueuesTasks(t *testing.T)
 	mockDB.AssertExpectations(t)
 }
 
+func TestDispatchEvent_SkipsEventWithDeliveriesInFlight(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+	})
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+	live := &eventTracker{event: event, logger: slog.Default()}
+	registry.register(event.ID.Bytes, live)
+
+	// A replay reaching the dispatcher after the event started delivering
+	// again must not replace its tracker or fan out a second time
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
+
+	assert.Empty(t, taskQueue)
+	assert.Same(t, live, registry.get(event.ID.Bytes))
+	mockDB.AssertNotCalled(t, "UpsertDelivery", mock.Anything, mock.Anything)
+}
+
 func TestDispatchEvent_SkipsSubscriptionsWhoseFiltersDontMatch(t *testing.T) {
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
 
 	event := newTestEvent(func(e *db.Event) {
 		e.Subject = "orders.created"
@@ -540,7 +908,7 @@ func TestDispatchEvent_SkipsSubscriptionsWhoseFiltersDontMatch(t *testing.T) {
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	assert.Equal(t, 1, len(taskQueue))
 
@@ -568,7 +936,7 @@ func TestDispatchEvent_NoMatchingSubscriptions_MarksDelivered(t *testing.T) {
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	assert.Equal(t, 0, len(taskQueue))
 	mockDB.AssertExpectations(t)
@@ -591,7 +959,7 @@ func TestDispatchEvent_SubscriptionDbError_MarksFailed(t *testing.T) {
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	assert.Equal(t, 0, len(taskQueue))
 	mockDB.AssertExpectations(t)
@@ -600,6 +968,8 @@ func TestDispatchEvent_SubscriptionDbError_MarksFailed(t *testing.T) {
 func TestDispatchEvent_UsesSubscriptionMaxRetries(t *testing.T) {
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
 
 	event := newTestEvent(func(e *db.Event) {
 		e.Subject = "orders.created"
@@ -621,7 +991,7 @@ func TestDispatchEvent_UsesSubscriptionMaxRetries(t *testing.T) {
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	task := <-taskQueue
 	assert.Equal(t, 10, task.maxRetries)
@@ -631,6 +1001,8 @@ func TestDispatchEvent_UsesSubscriptionMaxRetries(t *testing.T) {
 func TestDispatchEvent_MultipleSubscribers(t *testing.T) {
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
 
 	event := newTestEvent(func(e *db.Event) {
 		e.Subject = "orders.created"
@@ -662,7 +1034,7 @@ func TestDispatchEvent_MultipleSubscribers(t *testing.T) {
 	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 
-	dispatchEvent(app, event, &inflightWg, taskQueue, registry)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
 
 	assert.Equal(t, 2, len(taskQueue))
 
@@ -676,36 +1048,321 @@ func TestDispatchEvent_MultipleSubscribers(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
-// --- processDeliveryTask tests ---
-
-func TestProcessDeliveryTask_RetriesOnFailure(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-	}))
-	defer server.Close()
-
+func TestDispatchEvent_ScopedSecret_SkipsOutOfScopeSubscribers(t *testing.T) {
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
-	app.Config.MaxBackoffSeconds = 1
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
 
-	event := newTestEvent()
-	subscriber := newTestSubscriber(func(s *db.Subscriber) {
-		s.EndpointUrl = server.URL
-		s.MaxParallel = 1
+	secretID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.ApiSecretID = secretID
 	})
-	subscription := newTestSubscription(func(s *db.Subscription) {
-		s.SubscriberID = subscriber.ID
+
+	inScope := newTestSubscriber(func(s *db.Subscriber) {
+		s.Name = "in-scope"
+	})
+	outOfScope := newTestSubscriber(func(s *db.Subscriber) {
+		s.Name = "out-of-scope"
 	})
 
-	tracker := &eventTracker{
-		event:    event,
-		expected: 1,
-		results:  make(map[[16]byte]deliveryResult),
-		logger:   slog.Default(),
-	}
+	subscription1 := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = inScope.ID
+		s.SubjectPattern = "orders.*"
+	})
+	subscription2 := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = outOfScope.ID
+		s.SubjectPattern = "orders.*"
+	})
 
-	task := deliveryTask{
-		event:        event,
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{inScope, outOfScope}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription1, subscription2}, nil)
+	event.SubscriberScope = []pgtype.UUID{inScope.ID}
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
+
+	assert.Equal(t, 1, len(taskQueue))
+	task := <-taskQueue
+	assert.Equal(t, inScope.ID, task.subscriber.ID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDispatchEvent_SecretWithoutSubscribers_DeliversToAll(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+
+	secretID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.ApiSecretID = secretID
+	})
+
+	sub1 := newTestSubscriber()
+	sub2 := newTestSubscriber()
+	subscription1 := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = sub1.ID
+		s.SubjectPattern = "orders.*"
+	})
+	subscription2 := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = sub2.ID
+		s.SubjectPattern = "orders.*"
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{sub1, sub2}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription1, subscription2}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
+
+	assert.Equal(t, 2, len(taskQueue))
+	mockDB.AssertExpectations(t)
+}
+
+func TestDispatchEvent_AllSubscribersOutOfScope_MarksRecorded(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	secretID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.ApiSecretID = secretID
+	})
+
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	event.SubscriberScope = []pgtype.UUID{newTestUUID()}
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "recorded"
+	})).Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, registry))
+
+	assert.Equal(t, 0, len(taskQueue))
+	mockDB.AssertExpectations(t)
+}
+
+func TestDispatchEvent_DeletedSecret_KeepsSubscriberScope(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	// The publishing secret has since been deleted, clearing api_secret_id;
+	// the scope recorded on the event still applies
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.SubscriberScope = []pgtype.UUID{newTestUUID()}
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "recorded"
+	})).Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry()))
+
+	assert.Equal(t, 0, len(taskQueue))
+	mockDB.AssertExpectations(t)
+}
+
+func TestReplayToSubscriber_OutOfScopeSubscriber_NotDelivered(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	secretID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.ApiSecretID = secretID
+	})
+	subscriber := newTestSubscriber()
+
+	event.SubscriberScope = []pgtype.UUID{newTestUUID()}
+
+	err := ReplayToSubscriber(context.Background(), app, event, subscriber)
+
+	assert.ErrorIs(t, err, ErrReplayOutOfScope)
+	assert.Equal(t, 0, len(taskQueue))
+	mockDB.AssertExpectations(t)
+}
+
+func TestReplayToSubscriber_EnqueuesTaskUnderRetryPolicy(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, registry)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = event.Subject
+		s.MaxRetries = pgtype.Int4{Int32: 7, Valid: true}
+	})
+	other := newTestSubscription(func(s *db.Subscription) { s.SubjectPattern = event.Subject })
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription, other}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{}, nil)
+	mockDB.On("DeleteDeadLetterForSubscriber", mock.Anything, db.DeleteDeadLetterForSubscriberParams{
+		EventID:      event.ID,
+		SubscriberID: subscriber.ID,
+	}).Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "partial"
+	})).Return(db.Event{}, nil)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryParams) bool {
+		return p.SubscriberID == subscriber.ID && p.Status == DeliveryPending
+	})).Return(nil)
+
+	err := ReplayToSubscriber(context.Background(), app, event, subscriber)
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(taskQueue), "Only the target subscriber is replayed to") {
+		task := <-taskQueue
+		assert.Equal(t, subscription.ID, task.subscription.ID)
+		assert.Equal(t, 0, task.attemptNum)
+		assert.Equal(t, 7, task.maxRetries, "Replay uses the subscription's retry policy")
+		assert.True(t, task.tracker.subset, "Final status accounts for the other subscribers")
+		assert.Equal(t, 1, task.tracker.expected)
+	}
+	assert.NotNil(t, registry.get(event.ID.Bytes))
+	mockDB.AssertExpectations(t)
+}
+
+func TestReplayToSubscriber_NotSubscribed(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber()
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{newTestSubscription(func(s *db.Subscription) { s.SubjectPattern = event.Subject })}, nil)
+
+	err := ReplayToSubscriber(context.Background(), app, event, subscriber)
+
+	assert.ErrorIs(t, err, ErrReplayNotSubscribed)
+	assert.Equal(t, 0, len(taskQueue))
+}
+
+func TestReplayToSubscriber_SkipsEventWithScheduledRetries(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, registry)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = event.Subject
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{{EventID: event.ID, SubscriberID: newTestUUID()}}, nil)
+
+	err := ReplayToSubscriber(context.Background(), app, event, subscriber)
+
+	assert.ErrorIs(t, err, ErrReplayInFlight)
+	assert.Equal(t, 0, len(taskQueue))
+	assert.Nil(t, registry.get(event.ID.Bytes), "The claim on the event is released")
+	mockDB.AssertNotCalled(t, "DeleteDeadLetterForSubscriber", mock.Anything, mock.Anything)
+}
+
+func TestReplayToSubscriber_DispatcherNotRunning(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	err := ReplayToSubscriber(context.Background(), app, newTestEvent(), newTestSubscriber())
+
+	assert.ErrorIs(t, err, ErrDispatcherNotRunning)
+}
+
+// --- processDeliveryTask tests ---
+
+func TestProcessDeliveryTask_RetriesOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	app.Config.MaxBackoffSeconds = 1
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.MaxParallel = 1
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+	})
+
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+
+	task := deliveryTask{
+		event:        event,
 		subscription: subscription,
 		subscriber:   subscriber,
 		attemptNum:   0,
@@ -715,32 +1372,101 @@ func TestProcessDeliveryTask_RetriesOnFailure(t *testing.T) {
 
 	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
 		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
 	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
 		Return(db.Event{}, nil)
 
-	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
-	defer shutdownCancel()
-
 	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
 		return make(chan struct{}, maxParallel)
 	}
 
 	var inflightWg sync.WaitGroup
-	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 	registry.register(event.ID.Bytes, tracker)
 
 	inflightWg.Add(1)
-	processDeliveryTask(shutdownCtx, app, task, getSemaphore, &inflightWg, taskQueue, registry)
+	processDeliveryTask(app, task, getSemaphore, newTestDispatcherState(&inflightWg, nil, registry))
+
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == event.ID &&
+			p.SubscriberID == subscriber.ID &&
+			p.SubscriptionID == subscription.ID &&
+			p.AttemptNum == 1 &&
+			p.MaxRetries == 3
+	}))
 
-	// Wait for time.AfterFunc to fire (backoff for attempt 0 = 1s, capped at 1s)
-	time.Sleep(2 * time.Second)
+	tracker.mu.Lock()
+	assert.Equal(t, 0, len(tracker.results), "Retrying delivery should not record a result yet")
+	tracker.mu.Unlock()
+}
 
-	assert.Equal(t, 1, len(taskQueue), "Expected retry task to be enqueued")
+func TestProcessDeliveryTask_ScheduleFailureRecordsExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
 
-	retryTask := <-taskQueue
-	assert.Equal(t, 1, retryTask.attemptNum, "Retry should increment attempt number")
-	assert.Equal(t, event.ID, retryTask.event.ID)
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.MaxParallel = 1
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+	})
+
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+
+	task := deliveryTask{
+		event:        event,
+		subscription: subscription,
+		subscriber:   subscriber,
+		attemptNum:   0,
+		maxRetries:   3,
+		tracker:      tracker,
+	}
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(assert.AnError)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "failed"
+	})).Return(db.Event{}, nil)
+
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	registry.register(event.ID.Bytes, tracker)
+
+	inflightWg.Add(1)
+	processDeliveryTask(app, task, getSemaphore, newTestDispatcherState(&inflightWg, nil, registry))
+
+	tracker.mu.Lock()
+	assert.Equal(t, 1, len(tracker.results))
+	for _, r := range tracker.results {
+		assert.False(t, r.succeeded)
+		assert.True(t, r.exhausted)
+	}
+	tracker.mu.Unlock()
+	mockDB.AssertExpectations(t)
 }
 
 func TestProcessDeliveryTask_MaxRetriesExhausted(t *testing.T) {
@@ -751,6 +1477,9 @@ func TestProcessDeliveryTask_MaxRetriesExhausted(t *testing.T) {
 
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
 
 	event := newTestEvent()
 	subscriber := newTestSubscriber(func(s *db.Subscriber) {
@@ -779,26 +1508,29 @@ func TestProcessDeliveryTask_MaxRetriesExhausted(t *testing.T) {
 
 	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
 		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.MatchedBy(func(p db.UpsertDeadLetterParams) bool {
+		return p.EventID == event.ID &&
+			p.SubscriberID == subscriber.ID &&
+			p.SubscriptionID == subscription.ID &&
+			p.Attempts == 4 &&
+			p.LastError == "received HTTP 500"
+	})).Return(db.DeadLetter{}, nil)
 	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
 		Return(db.Event{}, nil)
 
-	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
-	defer shutdownCancel()
-
 	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
 		return make(chan struct{}, maxParallel)
 	}
 
 	var inflightWg sync.WaitGroup
-	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 	registry.register(event.ID.Bytes, tracker)
 
 	inflightWg.Add(1)
-	processDeliveryTask(shutdownCtx, app, task, getSemaphore, &inflightWg, taskQueue, registry)
+	processDeliveryTask(app, task, getSemaphore, newTestDispatcherState(&inflightWg, nil, registry))
 
-	time.Sleep(100 * time.Millisecond)
-	assert.Equal(t, 0, len(taskQueue), "No retry should be enqueued when max retries exhausted")
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
 
 	tracker.mu.Lock()
 	assert.Equal(t, 1, len(tracker.results))
@@ -817,6 +1549,9 @@ func TestProcessDeliveryTask_SuccessRecordsResult(t *testing.T) {
 
 	mockDB := new(deliveryMockQuerier)
 	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
 
 	event := newTestEvent()
 	subscriber := newTestSubscriber(func(s *db.Subscriber) {
@@ -848,20 +1583,16 @@ func TestProcessDeliveryTask_SuccessRecordsResult(t *testing.T) {
 	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
 		Return(db.Event{}, nil)
 
-	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
-	defer shutdownCancel()
-
 	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
 		return make(chan struct{}, maxParallel)
 	}
 
 	var inflightWg sync.WaitGroup
-	taskQueue := make(chan deliveryTask, 100)
 	registry := newEventRegistry()
 	registry.register(event.ID.Bytes, tracker)
 
 	inflightWg.Add(1)
-	processDeliveryTask(shutdownCtx, app, task, getSemaphore, &inflightWg, taskQueue, registry)
+	processDeliveryTask(app, task, getSemaphore, newTestDispatcherState(&inflightWg, nil, registry))
 
 	tracker.mu.Lock()
 	assert.Equal(t, 1, len(tracker.results))
@@ -871,7 +1602,376 @@ func TestProcessDeliveryTask_SuccessRecordsResult(t *testing.T) {
 	}
 	tracker.mu.Unlock()
 
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+}
+
+func TestProcessDeliveryTask_AppliesSubscriptionTransform(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PayloadFormat = PayloadFormatEnvelope
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.Transform = []byte(`{"renamed": "$.data.key", "kind": "$.subject"}`)
+	})
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+	task := deliveryTask{event: event, subscription: subscription, subscriber: subscriber, maxRetries: 3, tracker: tracker}
+
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	registry.register(event.ID.Bytes, tracker)
+
+	inflightWg.Add(1)
+	processDeliveryTask(app, task, getSemaphore, newTestDispatcherState(&inflightWg, nil, registry))
+
+	// The transformed data takes the place of the event's data in the envelope
+	var envelope EventEnvelope
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	assert.Equal(t, event.Subject, envelope.Subject)
+	assert.JSONEq(t, `{"renamed":"value","kind":"test.subject"}`, string(envelope.Data))
+}
+
+func TestProcessDeliveryTask_TransformFailureReleasesProbe(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.Transform = []byte(`{"value": "$.nope"}`)
+	})
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+	task := deliveryTask{event: event, subscription: subscription, subscriber: subscriber, maxRetries: 3, tracker: tracker}
+
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	var inflightWg sync.WaitGroup
+	registry := newEventRegistry()
+	registry.register(event.ID.Bytes, tracker)
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	ds.breakers = newBreakerRegistry(1, time.Minute)
+
+	// The task becomes the probe of a breaker whose cooldown is over
+	ds.breakers.recordFailure(subscriber.ID.Bytes)
+	ds.breakers.breakers[subscriber.ID.Bytes].since = time.Now().Add(-time.Minute)
+
+	inflightWg.Add(1)
+	processDeliveryTask(app, task, getSemaphore, ds)
+
+	assert.Equal(t, int32(0), hits.Load())
+	mockDB.AssertCalled(t, "UpsertDeadLetter", mock.Anything, mock.MatchedBy(func(p db.UpsertDeadLetterParams) bool {
+		return p.EventID == event.ID
+	}))
+	assert.Equal(t, BreakerOpen, ds.breakers.state(subscriber.ID.Bytes), "Transform failure is not a probe outcome")
+	allowed, probe, _ := ds.breakers.allow(subscriber.ID.Bytes)
+	assert.True(t, allowed, "The released probe slot goes to the next delivery")
+	assert.True(t, probe)
+}
+
+// --- retry poller tests ---
+
+func TestPollDueRetries_EnqueuesRetryForTrackedEvent(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ClaimDueDeliveryRetries", mock.Anything, mock.AnythingOfType("db.ClaimDueDeliveryRetriesParams")).
+		Return([]db.DeliveryRetry{{
+			EventID:        event.ID,
+			SubscriberID:   subscriber.ID,
+			SubscriptionID: subscription.ID,
+			AttemptNum:     2,
+			MaxRetries:     5,
+		}}, nil)
+
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+	}
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+	ds.registry.register(event.ID.Bytes, tracker)
+
+	claimed := pollDueRetries(context.Background(), app, ds)
+
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, 1, len(taskQueue))
+
+	task := <-taskQueue
+	assert.Equal(t, event.ID, task.event.ID)
+	assert.Equal(t, event.RetryCount+2, task.event.RetryCount)
+	assert.Equal(t, subscription.ID, task.subscription.ID)
+	assert.Equal(t, subscriber.ID, task.subscriber.ID)
+	assert.Equal(t, 2, task.attemptNum)
+	assert.Equal(t, 5, task.maxRetries)
+	assert.Same(t, tracker, task.tracker)
+	mockDB.AssertExpectations(t)
+}
+
+func TestPollDueRetries_ResumesUntrackedEvent(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.DeliveryStatus = "partial"
+	})
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ClaimDueDeliveryRetries", mock.Anything, mock.AnythingOfType("db.ClaimDueDeliveryRetriesParams")).
+		Return([]db.DeliveryRetry{{
+			EventID:        event.ID,
+			SubscriberID:   subscriber.ID,
+			SubscriptionID: subscription.ID,
+			AttemptNum:     2,
+			MaxRetries:     3,
+		}}, nil)
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+	mockDB.On("ListDeliveriesForEvent", mock.Anything, event.ID).
+		Return([]db.ListDeliveriesForEventRow{{SubscriberID: subscriber.ID, Status: DeliveryRetrying, Attempts: 2}}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	pollDueRetries(context.Background(), app, ds)
+
+	assert.Equal(t, 1, len(taskQueue))
+	task := <-taskQueue
+	assert.Equal(t, 2, task.attemptNum, "Attempt number should continue from prior failures")
+	assert.NotNil(t, ds.registry.get(event.ID.Bytes))
+	mockDB.AssertExpectations(t)
+}
+
+func TestResumePartialEvent_LeavesScheduledRetriesToPoller(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "orders.created"
+		e.DeliveryStatus = "partial"
+	})
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.*"
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ListDeliveriesForEvent", mock.Anything, event.ID).
+		Return([]db.ListDeliveriesForEventRow{{SubscriberID: subscriber.ID, Status: DeliveryRetrying, Attempts: 1}}, nil)
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return([]db.DeliveryRetry{{EventID: event.ID, SubscriberID: subscriber.ID, SubscriptionID: subscription.ID, AttemptNum: 1}}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	resumed := resumePartialEvent(context.Background(), app, event, ds)
+
+	assert.True(t, resumed)
+	assert.Equal(t, 0, len(taskQueue), "Scheduled retries should not be enqueued immediately")
+	tracker := ds.registry.get(event.ID.Bytes)
+	if assert.NotNil(t, tracker) {
+		assert.Equal(t, 1, tracker.expected)
+	}
+	mockDB.AssertNotCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.Anything)
+}
+
+// --- dead letter tests ---
+
+func TestRedriveDeadLetters_EnqueuesWithFreshRetryBudget(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+
+	event := newTestEvent(func(e *db.Event) {
+		e.DeliveryStatus = "failed"
+	})
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.MaxRetries = pgtype.Int4{Int32: 7, Valid: true}
+	})
+	deadLetter := db.DeadLetter{
+		ID:             newTestUUID(),
+		EventID:        event.ID,
+		SubscriberID:   subscriber.ID,
+		SubscriptionID: subscription.ID,
+		Attempts:       8,
+		LastError:      "received HTTP 500",
+	}
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("GetDeadLettersByIDs", mock.Anything, []pgtype.UUID{deadLetter.ID}).
+		Return([]db.DeadLetter{deadLetter}, nil)
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+	mockDB.On("DeleteDeadLetter", mock.Anything, deadLetter.ID).Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "partial"
+	})).Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	result, err := RedriveDeadLetters(context.Background(), app, []pgtype.UUID{deadLetter.ID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, RedriveResult{Redriven: 1}, result)
+	if assert.Equal(t, 1, len(taskQueue)) {
+		task := <-taskQueue
+		assert.Equal(t, 0, task.attemptNum, "Redrive should start a fresh retry budget")
+		assert.Equal(t, 7, task.maxRetries)
+		assert.Equal(t, subscriber.ID, task.subscriber.ID)
+		assert.True(t, task.tracker.subset)
+		assert.Equal(t, 1, task.tracker.expected)
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestRedriveDeadLetters_SkipsEventWithDeliveriesInFlight(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	deadLetter := db.DeadLetter{
+		ID:             newTestUUID(),
+		EventID:        event.ID,
+		SubscriberID:   newTestUUID(),
+		SubscriptionID: newTestUUID(),
+	}
+
+	mockDB.On("GetDeadLettersByIDs", mock.Anything, mock.Anything).
+		Return([]db.DeadLetter{deadLetter}, nil)
+	mockDB.On("GetEventByID", mock.Anything, event.ID).Return(event, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 100)
+	registry := newEventRegistry()
+	registry.register(event.ID.Bytes, &eventTracker{event: event, logger: slog.Default()})
+	app.dispatcher = newTestDispatcherState(&inflightWg, taskQueue, registry)
+
+	result, err := RedriveDeadLetters(context.Background(), app, []pgtype.UUID{deadLetter.ID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, RedriveResult{Skipped: 1}, result)
 	assert.Equal(t, 0, len(taskQueue))
+	mockDB.AssertNotCalled(t, "DeleteDeadLetter", mock.Anything, mock.Anything)
+}
+
+func TestRedriveDeadLetters_DispatcherNotRunning(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	_, err := RedriveDeadLetters(context.Background(), app, []pgtype.UUID{newTestUUID()})
+
+	assert.ErrorIs(t, err, ErrDispatcherNotRunning)
+}
+
+func TestFinalizeEvent_RedriveWithRemainingDeadLetters_MarksFailed(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	registry := newEventRegistry()
+	tracker := &eventTracker{
+		event:    event,
+		expected: 1,
+		results:  make(map[[16]byte]deliveryResult),
+		logger:   slog.Default(),
+		subset:   true,
+	}
+	registry.register(event.ID.Bytes, tracker)
+	tracker.record(deliveryResult{subscriptionID: newTestUUID(), succeeded: true})
+
+	mockDB.On("CountDeadLettersForEvent", mock.Anything, event.ID).Return(int64(1), nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "failed"
+	})).Return(db.Event{}, nil)
+
+	finalizeEvent(context.Background(), app, tracker, registry)
+
+	mockDB.AssertExpectations(t)
 }
 
 // --- finalizeEvent tests ---
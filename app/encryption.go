@@ -0,0 +1,83 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrEncryptionKeyMissing is returned when a secret must be encrypted or
+// decrypted but ENCRYPTION_KEY is not configured.
+var ErrEncryptionKeyMissing = errors.New("ENCRYPTION_KEY is not configured")
+
+// parseEncryptionKey decodes a base64-encoded 32-byte AES-256 key.
+func parseEncryptionKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, ErrEncryptionKeyMissing
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// ValidateEncryptionKey reports whether encoded is usable as ENCRYPTION_KEY.
+func ValidateEncryptionKey(encoded string) error {
+	_, err := parseEncryptionKey(encoded)
+	return err
+}
+
+func newGCM(encodedKey string) (cipher.AEAD, error) {
+	key, err := parseEncryptionKey(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret encrypts plaintext with AES-256-GCM under the base64-encoded
+// key and returns base64(nonce || ciphertext) for storage.
+func EncryptSecret(encodedKey, plaintext string) (string, error) {
+	gcm, err := newGCM(encodedKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret.
+func DecryptSecret(encodedKey, ciphertext string) (string, error) {
+	gcm, err := newGCM(encodedKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("decoding secret: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted secret is too short")
+	}
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting secret: %w", err)
+	}
+	return string(plaintext), nil
+}
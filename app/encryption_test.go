@@ -0,0 +1,41 @@
+package app
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestEncryptSecret_RoundTrip(t *testing.T) {
+	encrypted, err := EncryptSecret(testEncryptionKey, "client key material")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "client key material")
+
+	again, err := EncryptSecret(testEncryptionKey, "client key material")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "Each encryption uses a fresh nonce")
+
+	decrypted, err := DecryptSecret(testEncryptionKey, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "client key material", decrypted)
+}
+
+func TestDecryptSecret_WrongKey(t *testing.T) {
+	encrypted, err := EncryptSecret(testEncryptionKey, "client key material")
+	require.NoError(t, err)
+
+	otherKey := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	_, err = DecryptSecret(otherKey, encrypted)
+	assert.Error(t, err)
+}
+
+func TestValidateEncryptionKey(t *testing.T) {
+	assert.NoError(t, ValidateEncryptionKey(testEncryptionKey))
+	assert.ErrorIs(t, ValidateEncryptionKey(""), ErrEncryptionKeyMissing)
+	assert.Error(t, ValidateEncryptionKey("not base64!"))
+	assert.Error(t, ValidateEncryptionKey(base64.StdEncoding.EncodeToString([]byte("too short"))))
+}
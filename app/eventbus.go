@@ -10,9 +10,12 @@ import (
 type BusMessageType string
 
 const (
-	BusMessageCreated         BusMessageType = "created"
-	BusMessageStatusChanged   BusMessageType = "status_changed"
-	BusMessageDeliveryAttempt BusMessageType = "delivery_attempt"
+	BusMessageCreated            BusMessageType = "created"
+	BusMessageStatusChanged      BusMessageType = "status_changed"
+	BusMessageDeliveryAttempt    BusMessageType = "delivery_attempt"
+	BusMessageBreakerOpened      BusMessageType = "breaker_opened"
+	BusMessageBreakerClosed      BusMessageType = "breaker_closed"
+	BusMessageSubscriberDisabled BusMessageType = "subscriber_disabled"
 )
 
 // BusMessage is a message published to the EventBus.
@@ -29,6 +32,10 @@ type BusMessage struct {
 	SubscriberEndpoint string `json:"subscriber_endpoint,omitempty"`
 	AttemptStatus      string `json:"attempt_status,omitempty"`
 	ResponseStatusCode int    `json:"response_status_code,omitempty"`
+
+	// Subscriber fields (only set for breaker and subscriber_disabled messages)
+	SubscriberID string `json:"subscriber_id,omitempty"`
+	BreakerState string `json:"breaker_state,omitempty"`
 }
 
 const subscriberBufferSize = 64
@@ -36,6 +43,7 @@ const subscriberBufferSize = 64
 // EventBus is an in-memory pub/sub bus for broadcasting event updates to SSE clients.
 type EventBus struct {
 	nextID      atomic.Uint64
+	dropped     atomic.Uint64
 	mu          sync.RWMutex
 	subscribers map[chan BusMessage]struct{}
 }
@@ -81,6 +89,20 @@ func (b *EventBus) Publish(msg BusMessage) {
 		case ch <- msg:
 		default:
 			// Drop message for slow consumer
+			b.dropped.Add(1)
 		}
 	}
 }
+
+// SubscriberCount returns the number of current subscribers.
+func (b *EventBus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// Dropped returns the number of messages dropped for slow consumers since the
+// bus was created.
+func (b *EventBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
@@ -0,0 +1,423 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Subscription filters are JSON objects matched against event data. Each key
+// is a field path and each value the JSON the field must equal, so
+// {"type": "user"} matches events whose top-level "type" is "user". Paths are
+// dot-separated and may index arrays, as in "customer.address.country" or
+// "items.0.sku"; a key is first looked up as-is, so filters on keys that
+// contain dots keep working.
+//
+// A field's value may instead be an object of operators, all of whose keys
+// are operator names. Every operator must match:
+//
+//	$eq, $ne          equal, not equal
+//	$in, $nin         equal to one of, none of, a list of values
+//	$gt $gte $lt $lte numeric comparisons
+//	$exists           true if the field must be present, false if absent
+//	$prefix           string prefix
+//	$regex            RE2 regular expression, matched anywhere in a string
+//
+// A missing field fails every operator except $ne, $nin and $exists: false.
+// Filters combine with $all (every filter in a list matches), $any (at least
+// one does) and $not (a filter does not match); the clauses of one filter
+// object must all match.
+//
+// Filters saved before operators existed compared every value by equality
+// and looked keys up as-is. For them to keep matching, an object with any key
+// that is not an operator name is an object value to compare against, a
+// top-level "$" key that is not a combinator is a field name, and a key with
+// empty path segments is only looked up as-is.
+
+// Filter operators.
+const (
+	filterOpEq     = "$eq"
+	filterOpNe     = "$ne"
+	filterOpIn     = "$in"
+	filterOpNin    = "$nin"
+	filterOpGt     = "$gt"
+	filterOpGte    = "$gte"
+	filterOpLt     = "$lt"
+	filterOpLte    = "$lte"
+	filterOpExists = "$exists"
+	filterOpPrefix = "$prefix"
+	filterOpRegex  = "$regex"
+	filterOpAll    = "$all"
+	filterOpAny    = "$any"
+	filterOpNot    = "$not"
+)
+
+// Filter is a parsed subscription filter. The zero value and nil match every
+// event.
+type Filter struct {
+	clauses []filterClause
+}
+
+// filterClause is one condition of a filter: on a field, or a combinator
+// over other filters.
+type filterClause struct {
+	path  string
+	conds []fieldCondition
+
+	combinator string
+	filters    []*Filter
+}
+
+// fieldCondition is one operator applied to a field's value.
+type fieldCondition struct {
+	op     string
+	value  any
+	values []any
+	number float64
+	prefix string
+	regex  *regexp.Regexp
+}
+
+// FilterError describes an invalid filter. Path is the field or combinator
+// the problem was found at, empty for the filter as a whole.
+type FilterError struct {
+	Path    string
+	Message string
+}
+
+func (e *FilterError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ParseFilter parses and validates a subscription filter. An empty or null
+// filter matches every event and parses to nil.
+func ParseFilter(raw []byte) (*Filter, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, &FilterError{Message: "filter must be valid JSON"}
+	}
+	return parseFilterObject(v, "")
+}
+
+func parseFilterObject(v any, at string) (*Filter, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, &FilterError{Path: at, Message: "filter must be a JSON object"}
+	}
+
+	// Parse clauses in key order so errors are reported deterministically
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	f := &Filter{}
+	for _, key := range keys {
+		value := obj[key]
+		path := joinFilterPath(at, key)
+		if isCombinator(key) {
+			clause, err := parseCombinator(key, value, path)
+			if err != nil {
+				return nil, err
+			}
+			f.clauses = append(f.clauses, clause)
+			continue
+		}
+		conds, err := parseFieldConditions(value, path)
+		if err != nil {
+			return nil, err
+		}
+		f.clauses = append(f.clauses, filterClause{path: key, conds: conds})
+	}
+	return f, nil
+}
+
+func parseCombinator(op string, value any, at string) (filterClause, error) {
+	clause := filterClause{combinator: op}
+	switch op {
+	case filterOpAll, filterOpAny:
+		list, ok := value.([]any)
+		if !ok || len(list) == 0 {
+			return clause, &FilterError{Path: at, Message: op + " must be a non-empty array of filters"}
+		}
+		for i, item := range list {
+			sub, err := parseFilterObject(item, fmt.Sprintf("%s[%d]", at, i))
+			if err != nil {
+				return clause, err
+			}
+			clause.filters = append(clause.filters, sub)
+		}
+	case filterOpNot:
+		sub, err := parseFilterObject(value, at)
+		if err != nil {
+			return clause, err
+		}
+		clause.filters = []*Filter{sub}
+	}
+	return clause, nil
+}
+
+func isCombinator(key string) bool {
+	switch key {
+	case filterOpAll, filterOpAny, filterOpNot:
+		return true
+	}
+	return false
+}
+
+// parseFieldConditions parses the value a field is matched against: an
+// operator object, or any other JSON value the field must equal.
+func parseFieldConditions(value any, at string) ([]fieldCondition, error) {
+	obj, ok := value.(map[string]any)
+	if !ok || !isOperatorObject(obj) {
+		return []fieldCondition{{op: filterOpEq, value: value}}, nil
+	}
+
+	ops := make([]string, 0, len(obj))
+	for op := range obj {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	conds := make([]fieldCondition, 0, len(obj))
+	for _, op := range ops {
+		cond, err := parseOperator(op, obj[op], at)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// isOperatorObject reports whether obj is an operator object rather than an
+// object value to compare against. Only non-empty objects whose keys are all
+// operator or combinator names are operator objects.
+func isOperatorObject(obj map[string]any) bool {
+	for key := range obj {
+		if !isFieldOperator(key) && !isCombinator(key) {
+			return false
+		}
+	}
+	return len(obj) > 0
+}
+
+func isFieldOperator(key string) bool {
+	switch key {
+	case filterOpEq, filterOpNe, filterOpIn, filterOpNin, filterOpGt, filterOpGte,
+		filterOpLt, filterOpLte, filterOpExists, filterOpPrefix, filterOpRegex:
+		return true
+	}
+	return false
+}
+
+func parseOperator(op string, value any, at string) (fieldCondition, error) {
+	cond := fieldCondition{op: op}
+	switch op {
+	case filterOpEq, filterOpNe:
+		cond.value = value
+	case filterOpIn, filterOpNin:
+		list, ok := value.([]any)
+		if !ok {
+			return cond, &FilterError{Path: at, Message: op + " must be an array of values"}
+		}
+		cond.values = list
+	case filterOpGt, filterOpGte, filterOpLt, filterOpLte:
+		n, ok := value.(float64)
+		if !ok {
+			return cond, &FilterError{Path: at, Message: op + " must be a number"}
+		}
+		cond.number = n
+	case filterOpExists:
+		b, ok := value.(bool)
+		if !ok {
+			return cond, &FilterError{Path: at, Message: op + " must be true or false"}
+		}
+		cond.value = b
+	case filterOpPrefix:
+		s, ok := value.(string)
+		if !ok {
+			return cond, &FilterError{Path: at, Message: op + " must be a string"}
+		}
+		cond.prefix = s
+	case filterOpRegex:
+		s, ok := value.(string)
+		if !ok {
+			return cond, &FilterError{Path: at, Message: op + " must be a string"}
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return cond, &FilterError{Path: at, Message: fmt.Sprintf("%s is not a valid regular expression: %v", op, err)}
+		}
+		cond.regex = re
+	case filterOpAll, filterOpAny, filterOpNot:
+		return cond, &FilterError{Path: at, Message: fmt.Sprintf("%s combines filters and cannot be used on a field; wrap the field in a filter", op)}
+	}
+	return cond, nil
+}
+
+func joinFilterPath(at, key string) string {
+	if at == "" {
+		return key
+	}
+	return at + "." + key
+}
+
+func validFieldPath(path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether event data, decoded into a map, matches the filter.
+func (f *Filter) Matches(data map[string]any) bool {
+	if f == nil {
+		return true
+	}
+	for _, clause := range f.clauses {
+		if !clause.matches(data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c filterClause) matches(data map[string]any) bool {
+	switch c.combinator {
+	case filterOpAll:
+		for _, f := range c.filters {
+			if !f.Matches(data) {
+				return false
+			}
+		}
+		return true
+	case filterOpAny:
+		for _, f := range c.filters {
+			if f.Matches(data) {
+				return true
+			}
+		}
+		return false
+	case filterOpNot:
+		return !c.filters[0].Matches(data)
+	}
+
+	value, exists := lookupFilterPath(data, c.path)
+	for _, cond := range c.conds {
+		if !cond.matches(value, exists) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c fieldCondition) matches(value any, exists bool) bool {
+	switch c.op {
+	case filterOpExists:
+		return exists == c.value.(bool)
+	case filterOpNe:
+		return !exists || !jsonEqual(value, c.value)
+	case filterOpNin:
+		return !exists || !jsonEqualAny(value, c.values)
+	}
+	if !exists {
+		return false
+	}
+
+	switch c.op {
+	case filterOpEq:
+		return jsonEqual(value, c.value)
+	case filterOpIn:
+		return jsonEqualAny(value, c.values)
+	case filterOpGt, filterOpGte, filterOpLt, filterOpLte:
+		n, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch c.op {
+		case filterOpGt:
+			return n > c.number
+		case filterOpGte:
+			return n >= c.number
+		case filterOpLt:
+			return n < c.number
+		default:
+			return n <= c.number
+		}
+	case filterOpPrefix:
+		s, ok := value.(string)
+		return ok && strings.HasPrefix(s, c.prefix)
+	case filterOpRegex:
+		s, ok := value.(string)
+		return ok && c.regex.MatchString(s)
+	}
+	return false
+}
+
+// lookupFilterPath finds the value at a dot-separated path in event data,
+// trying the whole path as a top-level key first. A path with empty segments
+// is only looked up as a key.
+func lookupFilterPath(data map[string]any, path string) (any, bool) {
+	if value, ok := data[path]; ok {
+		return value, true
+	}
+	if !strings.Contains(path, ".") || !validFieldPath(path) {
+		return nil, false
+	}
+
+	var current any = data
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// jsonEqual compares two decoded JSON values by their encoding, so objects
+// are equal regardless of key order.
+func jsonEqual(a, b any) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
+
+func jsonEqualAny(value any, candidates []any) bool {
+	for _, candidate := range candidates {
+		if jsonEqual(value, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAll reports whether the filter has no conditions.
+func (f *Filter) matchesAll() bool {
+	return f == nil || len(f.clauses) == 0
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesFilter_EqualityCompatibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    string
+		eventData string
+		expected  bool
+	}{
+		{"object keys in any order", `{"meta":{"env":"prod","region":"eu"}}`, `{"meta":{"region":"eu","env":"prod"}}`, true},
+		{"object with extra keys does not match", `{"meta":{"env":"prod"}}`, `{"meta":{"env":"prod","region":"eu"}}`, false},
+		{"integer equals float", `{"count":42}`, `{"count":42.0}`, true},
+		{"array equality", `{"tags":["a","b"]}`, `{"tags":["a","b"]}`, true},
+		{"array order matters", `{"tags":["a","b"]}`, `{"tags":["b","a"]}`, false},
+		{"null value", `{"deleted_at":null}`, `{"deleted_at":null}`, true},
+		{"null does not match missing", `{"deleted_at":null}`, `{}`, false},
+		{"string does not equal number", `{"id":"1"}`, `{"id":1}`, false},
+		{"literal dotted key", `{"a.b":"x"}`, `{"a.b":"x"}`, true},
+		{"empty object value", `{"meta":{}}`, `{"meta":{}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesFilter([]byte(tt.filter), []byte(tt.eventData)))
+		})
+	}
+}
+
+func TestMatchesFilter_Operators(t *testing.T) {
+	const order = `{
+		"status": "shipped",
+		"amount": 120.5,
+		"customer": {"tier": "gold", "email": "ada@example.com"},
+		"items": [{"sku": "A-1"}, {"sku": "B-2"}],
+		"gift": false
+	}`
+	tests := []struct {
+		name     string
+		filter   string
+		expected bool
+	}{
+		{"nested path", `{"customer.tier":"gold"}`, true},
+		{"nested path mismatch", `{"customer.tier":"silver"}`, false},
+		{"array index path", `{"items.1.sku":"B-2"}`, true},
+		{"array index out of range", `{"items.5.sku":"B-2"}`, false},
+		{"path through scalar", `{"status.code":"x"}`, false},
+
+		{"$eq", `{"status":{"$eq":"shipped"}}`, true},
+		{"$eq object", `{"customer":{"$eq":{"email":"ada@example.com","tier":"gold"}}}`, true},
+		{"$ne", `{"status":{"$ne":"cancelled"}}`, true},
+		{"$ne equal value", `{"status":{"$ne":"shipped"}}`, false},
+		{"$ne missing field", `{"missing":{"$ne":"x"}}`, true},
+
+		{"$in", `{"status":{"$in":["paid","shipped"]}}`, true},
+		{"$in no match", `{"status":{"$in":["paid","refunded"]}}`, false},
+		{"$in missing field", `{"missing":{"$in":["x"]}}`, false},
+		{"$nin", `{"status":{"$nin":["cancelled","refunded"]}}`, true},
+		{"$nin match", `{"status":{"$nin":["shipped"]}}`, false},
+		{"$nin missing field", `{"missing":{"$nin":["x"]}}`, true},
+
+		{"$gt", `{"amount":{"$gt":100}}`, true},
+		{"$gt equal", `{"amount":{"$gt":120.5}}`, false},
+		{"$gte equal", `{"amount":{"$gte":120.5}}`, true},
+		{"$lt", `{"amount":{"$lt":100}}`, false},
+		{"$lte", `{"amount":{"$lte":120.5}}`, true},
+		{"range", `{"amount":{"$gte":100,"$lt":200}}`, true},
+		{"range excludes", `{"amount":{"$gte":100,"$lt":120}}`, false},
+		{"numeric comparison on string", `{"status":{"$gt":1}}`, false},
+		{"numeric comparison on missing field", `{"missing":{"$lt":1}}`, false},
+
+		{"$exists true", `{"customer.email":{"$exists":true}}`, true},
+		{"$exists true on false value", `{"gift":{"$exists":true}}`, true},
+		{"$exists false", `{"coupon":{"$exists":false}}`, true},
+		{"$exists false when present", `{"status":{"$exists":false}}`, false},
+
+		{"$prefix", `{"customer.email":{"$prefix":"ada@"}}`, true},
+		{"$prefix mismatch", `{"customer.email":{"$prefix":"bob@"}}`, false},
+		{"$prefix on number", `{"amount":{"$prefix":"1"}}`, false},
+		{"$regex", `{"customer.email":{"$regex":"@example\\.com$"}}`, true},
+		{"$regex unanchored", `{"status":{"$regex":"ship"}}`, true},
+		{"$regex mismatch", `{"status":{"$regex":"^paid$"}}`, false},
+
+		{"$any", `{"$any":[{"status":"paid"},{"amount":{"$gt":100}}]}`, true},
+		{"$any none match", `{"$any":[{"status":"paid"},{"amount":{"$gt":500}}]}`, false},
+		{"$all", `{"$all":[{"status":"shipped"},{"customer.tier":"gold"}]}`, true},
+		{"$all one fails", `{"$all":[{"status":"shipped"},{"customer.tier":"silver"}]}`, false},
+		{"$not", `{"$not":{"status":"cancelled"}}`, true},
+		{"$not matching", `{"$not":{"status":"shipped"}}`, false},
+		{"combinator with fields", `{"status":"shipped","$not":{"gift":true}}`, true},
+		{"nested combinators", `{"$any":[{"$not":{"customer.tier":"gold"}},{"$all":[{"gift":false},{"items.0.sku":{"$prefix":"A-"}}]}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFilter([]byte(tt.filter))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matchesFilter([]byte(tt.filter), []byte(order)))
+			assert.False(t, f.matchesAll())
+		})
+	}
+}
+
+func TestParseFilter_Empty(t *testing.T) {
+	for _, raw := range []string{"", "null", "{}"} {
+		f, err := ParseFilter([]byte(raw))
+		require.NoError(t, err, raw)
+		assert.True(t, f.matchesAll(), raw)
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		message string
+	}{
+		{"invalid JSON", `{"type":`, "filter must be valid JSON"},
+		{"not an object", `["type"]`, "filter must be a JSON object"},
+		{"$in not array", `{"status":{"$in":"paid"}}`, "status: $in must be an array of values"},
+		{"$gt not number", `{"amount":{"$gt":"100"}}`, "amount: $gt must be a number"},
+		{"$exists not bool", `{"amount":{"$exists":1}}`, "amount: $exists must be true or false"},
+		{"$prefix not string", `{"email":{"$prefix":1}}`, "email: $prefix must be a string"},
+		{"invalid regex", `{"email":{"$regex":"("}}`, "email: $regex is not a valid regular expression"},
+		{"combinator on field", `{"status":{"$any":["a"]}}`, "status: $any combines filters and cannot be used on a field"},
+		{"empty $any", `{"$any":[]}`, "$any: $any must be a non-empty array of filters"},
+		{"$all not array", `{"$all":{"a":1}}`, "$all: $all must be a non-empty array of filters"},
+		{"$not not object", `{"$not":[{"a":1}]}`, "$not: filter must be a JSON object"},
+		{"nested error path", `{"$any":[{"a":1},{"amount":{"$lt":"x"}}]}`, "$any[1].amount: $lt must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter([]byte(tt.filter))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			var filterErr *FilterError
+			assert.ErrorAs(t, err, &filterErr)
+		})
+	}
+}
+
+func TestMatchesFilter_BackwardCompatible(t *testing.T) {
+	// Filters saved before operators existed compared values by equality and
+	// looked keys up as-is; they must keep matching the same events
+	tests := []struct {
+		name     string
+		filter   string
+		data     string
+		expected bool
+	}{
+		{"unknown $ key in value", `{"range":{"$between":[1,2]}}`, `{"range":{"$between":[1,2]}}`, true},
+		{"unknown $ key in value differs", `{"range":{"$between":[1,2]}}`, `{"range":{"$between":[1,3]}}`, false},
+		{"operator mixed with field in value", `{"meta":{"$eq":1,"env":"prod"}}`, `{"meta":{"env":"prod","$eq":1}}`, true},
+		{"unknown $ key at top level", `{"$or":"x"}`, `{"$or":"x"}`, true},
+		{"unknown $ key at top level differs", `{"$or":"x"}`, `{"$or":"y"}`, false},
+		{"empty path segments", `{"customer..tier":"gold"}`, `{"customer..tier":"gold"}`, true},
+		{"empty key", `{"":"gold"}`, `{"":"gold"}`, true},
+		{"empty path segments are not split", `{"customer..tier":"gold"}`, `{"customer":{"":{"tier":"gold"}}}`, false},
+		{"trailing dot", `{"customer.":"gold"}`, `{"customer.":"gold"}`, true},
+		{"trailing dot is not split", `{"customer.":"gold"}`, `{"customer":{"":"gold"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter([]byte(tt.filter))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matchesFilter([]byte(tt.filter), []byte(tt.data)))
+		})
+	}
+}
+
+func TestMatchesFilter_InvalidFilterMatchesNothing(t *testing.T) {
+	assert.False(t, matchesFilter([]byte(`{"amount":{"$gt":"x"}}`), []byte(`{"amount":1}`)))
+	assert.False(t, matchesFilter([]byte(`[1]`), []byte(`{"amount":1}`)))
+}
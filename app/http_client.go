@@ -0,0 +1,232 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Limits on subscriber HTTP client settings.
+const (
+	DefaultDeliveryTimeoutSeconds = 30
+	MaxDeliveryTimeoutSeconds     = 300
+)
+
+// reservedHeaders are set by Slurpee on every delivery and cannot be
+// overridden by a subscriber's extra headers.
+var reservedHeaders = []string{"Content-Type", "Content-Length", "Host", "Transfer-Encoding", "Connection", "Traceparent"}
+
+// reservedHeaderPrefixes cover Slurpee's own and CloudEvents headers.
+var reservedHeaderPrefixes = []string{"X-Slurpee-", "X-Event-", "Ce-"}
+
+// ValidDeliveryMethod reports whether method may be used to deliver events.
+func ValidDeliveryMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// ValidDeliveryTimeout reports whether seconds is a usable delivery timeout.
+func ValidDeliveryTimeout(seconds int32) bool {
+	return seconds >= 1 && seconds <= MaxDeliveryTimeoutSeconds
+}
+
+// ValidExtraHeader reports whether name may be configured as a static extra
+// header. Headers Slurpee sets itself are reserved.
+func ValidExtraHeader(name string) bool {
+	canonical := http.CanonicalHeaderKey(name)
+	if canonical == "" || strings.ContainsAny(name, " :\r\n") {
+		return false
+	}
+	for _, reserved := range reservedHeaders {
+		if canonical == reserved {
+			return false
+		}
+	}
+	for _, prefix := range reservedHeaderPrefixes {
+		if strings.HasPrefix(canonical, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseExtraHeaders decodes a subscriber's extra_headers JSON object.
+func ParseExtraHeaders(raw []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	if len(raw) == 0 {
+		return headers, nil
+	}
+	if err := json.Unmarshal(raw, &headers); err != nil {
+		return nil, fmt.Errorf("extra headers must be a JSON object of strings: %w", err)
+	}
+	for name, value := range headers {
+		if !ValidExtraHeader(name) {
+			return nil, fmt.Errorf("header %q cannot be set as an extra header", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header %q has an invalid value", name)
+		}
+	}
+	return headers, nil
+}
+
+// ValidateTLSSettings checks a subscriber's PEM-encoded CA bundle and client
+// certificate and key. The certificate and key must be given together.
+func ValidateTLSSettings(caBundle, clientCert, clientKey string) error {
+	if caBundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(caBundle)) {
+		return errors.New("ca_bundle contains no PEM certificates")
+	}
+	if (clientCert == "") != (clientKey == "") {
+		return errors.New("client_cert and client_key must be set together")
+	}
+	if clientCert != "" {
+		if _, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey)); err != nil {
+			return fmt.Errorf("invalid client certificate: %w", err)
+		}
+	}
+	return nil
+}
+
+// pooledClient is a subscriber's HTTP client along with the subscriber
+// version it was built from.
+type pooledClient struct {
+	client    *http.Client
+	updatedAt time.Time
+}
+
+// HTTPClientPool keeps one HTTP client per subscriber so deliveries reuse
+// connections. A subscriber's client is rebuilt when the subscriber is
+// updated, and the old client's idle connections are closed.
+type HTTPClientPool struct {
+	mu      sync.Mutex
+	clients map[[16]byte]*pooledClient
+}
+
+func NewHTTPClientPool() *HTTPClientPool {
+	return &HTTPClientPool{clients: make(map[[16]byte]*pooledClient)}
+}
+
+// Client returns the HTTP client for subscriber, building it on first use.
+// encryptionKey decrypts the subscriber's mTLS client key.
+func (p *HTTPClientPool) Client(subscriber db.Subscriber, encryptionKey string) (*http.Client, error) {
+	id := subscriber.ID.Bytes
+	updatedAt := subscriber.UpdatedAt.Time
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing, ok := p.clients[id]
+	if ok && !updatedAt.After(existing.updatedAt) {
+		return existing.client, nil
+	}
+
+	client, err := newSubscriberClient(subscriber, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		existing.client.CloseIdleConnections()
+	}
+	p.clients[id] = &pooledClient{client: client, updatedAt: updatedAt}
+	return client, nil
+}
+
+// newSubscriberClient builds an HTTP client with the subscriber's timeout
+// and TLS settings. Custom CA certificates are trusted in addition to the
+// system roots.
+func newSubscriberClient(subscriber db.Subscriber, encryptionKey string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = max(int(subscriber.MaxParallel), http.DefaultMaxIdleConnsPerHost)
+
+	if subscriber.CaBundle != "" || subscriber.ClientCert != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if subscriber.CaBundle != "" {
+			roots, err := x509.SystemCertPool()
+			if err != nil {
+				roots = x509.NewCertPool()
+			}
+			if !roots.AppendCertsFromPEM([]byte(subscriber.CaBundle)) {
+				return nil, errors.New("ca_bundle contains no PEM certificates")
+			}
+			tlsConfig.RootCAs = roots
+		}
+		if subscriber.ClientCert != "" {
+			clientKey, err := DecryptSecret(encryptionKey, subscriber.ClientKeyEncrypted)
+			if err != nil {
+				return nil, fmt.Errorf("decrypting client key: %w", err)
+			}
+			cert, err := tls.X509KeyPair([]byte(subscriber.ClientCert), []byte(clientKey))
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	timeout := subscriber.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = DefaultDeliveryTimeoutSeconds
+	}
+	return &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+	}, nil
+}
+
+// sendDelivery sends a delivery request to the subscriber's endpoint with its
+// pooled client. Subscribers using OAuth2 get a bearer token; when the endpoint
+// rejects it with 401, the token is discarded and the request is sent once
+// more with a fresh one.
+func sendDelivery(ctx context.Context, slurpee *Application, subscriber db.Subscriber, body []byte, headers map[string]string) (*http.Response, error) {
+	client, err := slurpee.HTTPClients.Client(subscriber, slurpee.Config.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("client configuration failed: %w", err)
+	}
+
+	send := func(accessToken string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, subscriber.HttpMethod, subscriber.EndpointUrl, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("request creation failed: %w", err)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if accessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		return resp, nil
+	}
+
+	if subscriber.AuthMode != AuthModeOAuth2 {
+		return send("")
+	}
+	accessToken, err := slurpee.OAuthTokens.Token(ctx, client, subscriber, slurpee.Config.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	resp, err := send(accessToken)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	resp.Body.Close()
+	slurpee.OAuthTokens.Invalidate(subscriber, accessToken)
+	accessToken, err = slurpee.OAuthTokens.Token(ctx, client, subscriber, slurpee.Config.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	return send(accessToken)
+}
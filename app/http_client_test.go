@@ -0,0 +1,249 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// newTestCertificate returns a self-signed PEM certificate and private key
+// usable for both client and server authentication.
+func newTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "slurpee-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestValidExtraHeader(t *testing.T) {
+	for _, name := range []string{"Authorization", "X-Tenant", "x-api-key"} {
+		assert.True(t, ValidExtraHeader(name), name)
+	}
+	for _, name := range []string{"", "Content-Type", "host", "X-Slurpee-Secret", "x-event-id", "ce-type", "Bad Header"} {
+		assert.False(t, ValidExtraHeader(name), name)
+	}
+}
+
+func TestParseExtraHeaders(t *testing.T) {
+	headers, err := ParseExtraHeaders([]byte(`{"Authorization":"Bearer abc","X-Tenant":"acme"}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer abc", "X-Tenant": "acme"}, headers)
+
+	headers, err = ParseExtraHeaders(nil)
+	require.NoError(t, err)
+	assert.Empty(t, headers)
+
+	_, err = ParseExtraHeaders([]byte(`{"X-Slurpee-Secret":"override"}`))
+	assert.Error(t, err)
+	_, err = ParseExtraHeaders([]byte(`{"X-Tenant":"acme\r\nX-Injected: yes"}`))
+	assert.Error(t, err)
+	_, err = ParseExtraHeaders([]byte(`["X-Tenant"]`))
+	assert.Error(t, err)
+}
+
+func TestValidateTLSSettings(t *testing.T) {
+	cert, key := newTestCertificate(t)
+	assert.NoError(t, ValidateTLSSettings("", "", ""))
+	assert.NoError(t, ValidateTLSSettings(cert, cert, key))
+	assert.Error(t, ValidateTLSSettings("not a certificate", "", ""))
+	assert.Error(t, ValidateTLSSettings("", cert, ""))
+	assert.Error(t, ValidateTLSSettings("", cert, "not a key"))
+}
+
+func TestHTTPClientPool_ReusesClientUntilSubscriberUpdated(t *testing.T) {
+	pool := NewHTTPClientPool()
+	subscriber := newTestSubscriber()
+
+	first, err := pool.Client(subscriber, "")
+	require.NoError(t, err)
+	again, err := pool.Client(subscriber, "")
+	require.NoError(t, err)
+	assert.Same(t, first, again, "Unchanged subscriber should reuse its client")
+	assert.Equal(t, 30*time.Second, first.Timeout)
+
+	updated := subscriber
+	updated.TimeoutSeconds = 5
+	updated.UpdatedAt = pgtype.Timestamptz{Time: subscriber.UpdatedAt.Time.Add(time.Second), Valid: true}
+	rebuilt, err := pool.Client(updated, "")
+	require.NoError(t, err)
+	assert.NotSame(t, first, rebuilt, "Updated subscriber should get a new client")
+	assert.Equal(t, 5*time.Second, rebuilt.Timeout)
+
+	// A task queued before the update keeps using the newer client
+	stale, err := pool.Client(subscriber, "")
+	require.NoError(t, err)
+	assert.Same(t, rebuilt, stale)
+}
+
+func TestHTTPClientPool_ClientKeyRequiresEncryptionKey(t *testing.T) {
+	cert, key := newTestCertificate(t)
+	encrypted, err := EncryptSecret(testEncryptionKey, key)
+	require.NoError(t, err)
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.ClientCert = cert
+		s.ClientKeyEncrypted = encrypted
+	})
+
+	_, err = NewHTTPClientPool().Client(subscriber, "")
+	assert.ErrorIs(t, err, ErrEncryptionKeyMissing)
+
+	client, err := NewHTTPClientPool().Client(subscriber, testEncryptionKey)
+	require.NoError(t, err)
+	assert.Len(t, client.Transport.(*http.Transport).TLSClientConfig.Certificates, 1)
+}
+
+func TestDeliverToSubscriber_MethodAndExtraHeaders(t *testing.T) {
+	var receivedMethod string
+	var receivedHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		receivedHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.HttpMethod = http.MethodPut
+		s.ExtraHeaders = []byte(`{"Authorization":"Bearer gateway-token","X-Tenant":"acme"}`)
+	})
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded)
+	assert.Equal(t, http.MethodPut, receivedMethod)
+	assert.Equal(t, "Bearer gateway-token", receivedHeaders.Get("Authorization"))
+	assert.Equal(t, "acme", receivedHeaders.Get("X-Tenant"))
+	assert.Equal(t, "test-auth-secret", receivedHeaders.Get("X-Slurpee-Secret"))
+
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		var recorded map[string]string
+		if err := json.Unmarshal(p.RequestHeaders, &recorded); err != nil {
+			return false
+		}
+		return recorded["Authorization"] == redactedHeaderValue && recorded["X-Tenant"] == redactedHeaderValue
+	}))
+}
+
+func TestDeliverToSubscriber_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.TimeoutSeconds = 1
+	})
+	start := time.Now()
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.False(t, result.succeeded)
+	assert.True(t, strings.HasPrefix(result.err, "request failed"), result.err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDeliverToSubscriber_MutualTLS(t *testing.T) {
+	clientCert, clientKey := newTestCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(clientCert)))
+
+	var peerCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peerCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	app.Config.EncryptionKey = testEncryptionKey
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	// Without a client certificate the handshake is rejected
+	noCert := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.CaBundle = serverCA
+	})
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), noCert, 0, slog.Default())
+	assert.False(t, result.succeeded)
+
+	encryptedKey, err := EncryptSecret(testEncryptionKey, clientKey)
+	require.NoError(t, err)
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.CaBundle = serverCA
+		s.ClientCert = clientCert
+		s.ClientKeyEncrypted = encryptedKey
+	})
+	result = deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+	assert.True(t, result.succeeded, result.err)
+	assert.Equal(t, "slurpee-test", peerCN)
+}
+
+func TestDeliverToSubscriber_ClientConfigurationFailure(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	cert, _ := newTestCertificate(t)
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.ClientCert = cert
+		s.ClientKeyEncrypted = "encrypted-with-a-lost-key"
+	})
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.False(t, result.succeeded)
+	assert.True(t, strings.HasPrefix(result.err, "client configuration failed"), result.err)
+}
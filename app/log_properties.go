@@ -3,7 +3,9 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/sweater-ventures/slurpee/db"
 )
 
@@ -68,12 +70,17 @@ func BatchExtractLogProperties(ctx context.Context, slurpee *Application, events
 }
 
 // getLogConfig returns the LogConfig for a subject, using the app-level cache.
+// A config whose subject is the event subject itself wins; otherwise the
+// longest pattern matching the subject is used.
 func getLogConfig(ctx context.Context, slurpee *Application, subject string) (db.LogConfig, bool) {
 	lc, found, inCache := slurpee.LogConfigCache.Get(subject)
 	if inCache {
 		return lc, found
 	}
 	lc, err := slurpee.DB.GetLogConfigBySubject(ctx, subject)
+	if errors.Is(err, pgx.ErrNoRows) {
+		lc, err = matchLogConfigPattern(ctx, slurpee.DB, subject)
+	}
 	if err != nil {
 		slurpee.LogConfigCache.Set(subject, db.LogConfig{}, false)
 		return db.LogConfig{}, false
@@ -82,6 +89,26 @@ func getLogConfig(ctx context.Context, slurpee *Application, subject string) (db
 	return lc, true
 }
 
+// matchLogConfigPattern returns the log config with the longest subject
+// pattern matching subject, or pgx.ErrNoRows if none matches.
+func matchLogConfigPattern(ctx context.Context, queries db.Querier, subject string) (db.LogConfig, error) {
+	configs, err := queries.ListLogConfigs(ctx)
+	if err != nil {
+		return db.LogConfig{}, err
+	}
+	var best db.LogConfig
+	found := false
+	for _, lc := range configs {
+		if (!found || len(lc.Subject) > len(best.Subject)) && MatchSubjectPattern(lc.PatternSyntax, lc.Subject, subject) {
+			best, found = lc, true
+		}
+	}
+	if !found {
+		return db.LogConfig{}, pgx.ErrNoRows
+	}
+	return best, nil
+}
+
 // formatPropertyValue converts an arbitrary JSON value to a display string.
 func formatPropertyValue(val any) string {
 	switch v := val.(type) {
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func newLogConfigTestApp(mockDB *deliveryMockQuerier) *Application {
+	slurpee := newDeliveryTestApp(mockDB)
+	slurpee.LogConfigCache = NewCache[string, db.LogConfig]()
+	return slurpee
+}
+
+func TestExtractLogProperties_ExactSubject(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, "order.created").
+		Return(db.LogConfig{Subject: "order.created", LogProperties: []string{"order_id"}}, nil).Once()
+	slurpee := newLogConfigTestApp(mockDB)
+
+	data := []byte(`{"order_id":"123","amount":5}`)
+	assert.Equal(t, map[string]string{"order_id": "123"}, ExtractLogProperties(context.Background(), slurpee, "order.created", data))
+	// The second lookup is served from the cache
+	assert.Equal(t, map[string]string{"order_id": "123"}, ExtractLogProperties(context.Background(), slurpee, "order.created", data))
+	mockDB.AssertExpectations(t)
+}
+
+func TestExtractLogProperties_LongestMatchingPattern(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, mock.Anything).Return(db.LogConfig{}, pgx.ErrNoRows)
+	mockDB.On("ListLogConfigs", mock.Anything).Return([]db.LogConfig{
+		{Subject: ">", PatternSyntax: PatternSyntaxToken, LogProperties: []string{"tenant"}},
+		{Subject: "order.*", PatternSyntax: PatternSyntaxLike, LogProperties: []string{"amount"}},
+		{Subject: "order.line.>", PatternSyntax: PatternSyntaxToken, LogProperties: []string{"sku"}},
+	}, nil)
+	slurpee := newLogConfigTestApp(mockDB)
+
+	data := []byte(`{"tenant":"t1","amount":5,"sku":"A-1"}`)
+	ctx := context.Background()
+	assert.Equal(t, map[string]string{"sku": "A-1"}, ExtractLogProperties(ctx, slurpee, "order.line.added", data))
+	assert.Equal(t, map[string]string{"amount": "5"}, ExtractLogProperties(ctx, slurpee, "order.created", data))
+	assert.Equal(t, map[string]string{"tenant": "t1"}, ExtractLogProperties(ctx, slurpee, "user.created", data))
+}
+
+func TestExtractLogProperties_NoMatch(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, "user.created").Return(db.LogConfig{}, pgx.ErrNoRows)
+	mockDB.On("ListLogConfigs", mock.Anything).Return([]db.LogConfig{
+		{Subject: "order.>", PatternSyntax: PatternSyntaxToken, LogProperties: []string{"amount"}},
+	}, nil)
+	slurpee := newLogConfigTestApp(mockDB)
+
+	assert.Nil(t, ExtractLogProperties(context.Background(), slurpee, "user.created", []byte(`{"amount":5}`)))
+}
+
+func TestExtractLogProperties_TokenSyntaxSubjectIsExact(t *testing.T) {
+	// Log configs from before pattern syntax existed use token syntax, so a
+	// subject with _ still matches only itself
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("GetLogConfigBySubject", mock.Anything, "user.signedXup").Return(db.LogConfig{}, pgx.ErrNoRows)
+	mockDB.On("ListLogConfigs", mock.Anything).Return([]db.LogConfig{
+		{Subject: "user.signed_up", PatternSyntax: PatternSyntaxToken, LogProperties: []string{"email"}},
+	}, nil)
+	slurpee := newLogConfigTestApp(mockDB)
+
+	assert.Nil(t, ExtractLogProperties(context.Background(), slurpee, "user.signedXup", []byte(`{"email":"a@b.c"}`)))
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Delivery outcomes used as metric label values.
+const (
+	metricOutcomeSucceeded = "succeeded"
+	metricOutcomeFailed    = "failed"
+)
+
+// Events are counted by subject for up to maxIngestedSubjects distinct
+// subjects. Subjects are chosen by publishers and may embed IDs, so events
+// with any further subject are counted under metricSubjectOther rather than
+// adding a series each.
+const (
+	maxIngestedSubjects = 500
+	metricSubjectOther  = "_other"
+)
+
+var (
+	requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	deliveryLagBuckets     = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600}
+)
+
+// Metrics holds the counters and histograms updated as events are ingested
+// and delivered. Queue depths and other point-in-time values are read when
+// the metrics are gathered; see MetricsGatherer.
+type Metrics struct {
+	registry        *prometheus.Registry
+	eventsIngested  *prometheus.CounterVec
+	attempts        *prometheus.CounterVec
+	deliveries      *prometheus.CounterVec
+	retries         *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	deliveryLag     *prometheus.HistogramVec
+
+	mu       sync.Mutex
+	subjects map[string]bool // subjects with their own ingested series
+}
+
+// NewMetrics creates a Metrics with no series yet.
+func NewMetrics() *Metrics {
+	subscriberLabels := []string{"subscriber_id", "subscriber"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		eventsIngested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slurpee_events_ingested_total",
+			Help: "Events accepted for delivery, by subject; subjects beyond the first few hundred are counted as _other.",
+		}, []string{"subject"}),
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slurpee_delivery_attempts_total",
+			Help: "Delivery attempts, by subscriber and outcome.",
+		}, []string{"subscriber_id", "subscriber", "outcome"}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slurpee_deliveries_total",
+			Help: "Deliveries that succeeded or were given up, by subscriber and outcome.",
+		}, []string{"subscriber_id", "subscriber", "outcome"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slurpee_delivery_retries_scheduled_total",
+			Help: "Retries scheduled after a failed delivery attempt, by subscriber.",
+		}, subscriberLabels),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "slurpee_delivery_request_duration_seconds",
+			Help:    "Duration of delivery requests to subscribers, from sending the request to reading the response.",
+			Buckets: requestDurationBuckets,
+		}, subscriberLabels),
+		deliveryLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "slurpee_delivery_lag_seconds",
+			Help:    "Time from receiving an event to delivering it successfully, by subscriber.",
+			Buckets: deliveryLagBuckets,
+		}, subscriberLabels),
+		subjects: make(map[string]bool),
+	}
+	m.registry.MustRegister(m.eventsIngested, m.attempts, m.deliveries, m.retries, m.requestDuration, m.deliveryLag)
+	return m
+}
+
+// EventIngested counts an event stored for delivery, under its subject unless
+// maxIngestedSubjects other subjects have been counted already.
+func (m *Metrics) EventIngested(subject string) {
+	m.mu.Lock()
+	if !m.subjects[subject] {
+		if len(m.subjects) < maxIngestedSubjects {
+			m.subjects[subject] = true
+		} else {
+			subject = metricSubjectOther
+		}
+	}
+	m.mu.Unlock()
+	m.eventsIngested.WithLabelValues(subject).Inc()
+}
+
+// recordDelivery counts the completion of a delivery task with the new state
+// of its delivery: an attempt unless it was given up without one, and a
+// success, failure or scheduled retry.
+func (m *Metrics) recordDelivery(task deliveryTask, outcome deliveryOutcome, status string, now time.Time) {
+	subscriberID := UuidToString(task.subscriber.ID)
+	name := task.subscriber.Name
+
+	if !outcome.notAttempted {
+		result := metricOutcomeFailed
+		if outcome.succeeded {
+			result = metricOutcomeSucceeded
+		}
+		m.attempts.WithLabelValues(subscriberID, name, result).Inc()
+		if outcome.duration > 0 {
+			m.requestDuration.WithLabelValues(subscriberID, name).Observe(outcome.duration.Seconds())
+		}
+	}
+
+	switch status {
+	case DeliverySucceeded:
+		m.deliveries.WithLabelValues(subscriberID, name, metricOutcomeSucceeded).Inc()
+		if lag := deliveryLagMs(task.event, now); lag.Valid {
+			m.deliveryLag.WithLabelValues(subscriberID, name).Observe(float64(lag.Int64) / 1000)
+		}
+	case DeliveryFailed:
+		m.deliveries.WithLabelValues(subscriberID, name, metricOutcomeFailed).Inc()
+	case DeliveryRetrying:
+		m.retries.WithLabelValues(subscriberID, name).Inc()
+	}
+}
+
+// Descriptions of the point-in-time metrics reported by stateCollector.
+var (
+	deliveryChanDepthDesc = prometheus.NewDesc("slurpee_delivery_chan_depth",
+		"Events waiting to be dispatched.", nil, nil)
+	deliveryChanCapacityDesc = prometheus.NewDesc("slurpee_delivery_chan_capacity",
+		"Capacity of the dispatch channel (DELIVERY_CHAN_SIZE).", nil, nil)
+	sseClientsDesc = prometheus.NewDesc("slurpee_sse_clients",
+		"Connected live-update (SSE) clients.", nil, nil)
+	eventBusDroppedDesc = prometheus.NewDesc("slurpee_eventbus_dropped_messages_total",
+		"Live-update messages dropped because a client fell behind.", nil, nil)
+	taskQueueDepthDesc = prometheus.NewDesc("slurpee_task_queue_depth",
+		"Delivery tasks waiting for a worker.", nil, nil)
+	taskQueueCapacityDesc = prometheus.NewDesc("slurpee_task_queue_capacity",
+		"Capacity of the delivery task queue (DELIVERY_QUEUE_SIZE).", nil, nil)
+	inflightEventsDesc = prometheus.NewDesc("slurpee_inflight_events",
+		"Events with deliveries in progress.", nil, nil)
+	requestsInFlightDesc = prometheus.NewDesc("slurpee_subscriber_requests_in_flight",
+		"Delivery requests in progress, by subscriber.", []string{"subscriber_id", "subscriber"}, nil)
+	maxParallelDesc = prometheus.NewDesc("slurpee_subscriber_max_parallel",
+		"Concurrent delivery request limit (max_parallel), by subscriber.", []string{"subscriber_id", "subscriber"}, nil)
+)
+
+// stateCollector reads gauges of the dispatcher's queues, in-flight events
+// and per-subscriber concurrency and of the event bus each time it is
+// collected.
+type stateCollector struct {
+	slurpee *Application
+}
+
+func (c stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		deliveryChanDepthDesc, deliveryChanCapacityDesc, sseClientsDesc, eventBusDroppedDesc,
+		taskQueueDepthDesc, taskQueueCapacityDesc, inflightEventsDesc, requestsInFlightDesc, maxParallelDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (c stateCollector) Collect(ch chan<- prometheus.Metric) {
+	slurpee := c.slurpee
+	ch <- prometheus.MustNewConstMetric(deliveryChanDepthDesc, prometheus.GaugeValue, float64(len(slurpee.DeliveryChan)))
+	ch <- prometheus.MustNewConstMetric(deliveryChanCapacityDesc, prometheus.GaugeValue, float64(cap(slurpee.DeliveryChan)))
+	ch <- prometheus.MustNewConstMetric(sseClientsDesc, prometheus.GaugeValue, float64(slurpee.EventBus.SubscriberCount()))
+	ch <- prometheus.MustNewConstMetric(eventBusDroppedDesc, prometheus.CounterValue, float64(slurpee.EventBus.Dropped()))
+
+	ds := slurpee.dispatcher
+	if ds == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(taskQueueDepthDesc, prometheus.GaugeValue, float64(len(ds.taskQueue)))
+	ch <- prometheus.MustNewConstMetric(taskQueueCapacityDesc, prometheus.GaugeValue, float64(cap(ds.taskQueue)))
+	ch <- prometheus.MustNewConstMetric(inflightEventsDesc, prometheus.GaugeValue, float64(ds.registry.size()))
+	if ds.semaphores == nil {
+		return
+	}
+	for id, usage := range ds.semaphores.usage() {
+		subscriberID := UuidToString(pgtype.UUID{Bytes: id, Valid: true})
+		name := subscriberID
+		if subscriber, err := slurpee.SubscriptionCache.GetSubscriberByID(context.Background(), pgtype.UUID{Bytes: id, Valid: true}); err == nil {
+			name = subscriber.Name
+		}
+		ch <- prometheus.MustNewConstMetric(requestsInFlightDesc, prometheus.GaugeValue, float64(usage.inUse), subscriberID, name)
+		ch <- prometheus.MustNewConstMetric(maxParallelDesc, prometheus.GaugeValue, float64(usage.limit), subscriberID, name)
+	}
+}
+
+// MetricsGatherer gathers the application's metrics together with the
+// point-in-time gauges of stateCollector.
+func MetricsGatherer(slurpee *Application) prometheus.Gatherer {
+	state := prometheus.NewRegistry()
+	state.MustRegister(stateCollector{slurpee: slurpee})
+	return prometheus.Gatherers{slurpee.Metrics.registry, state}
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func writeTestMetrics(t *testing.T, slurpee *Application) string {
+	t.Helper()
+	families, err := MetricsGatherer(slurpee).Gather()
+	require.NoError(t, err)
+	var b strings.Builder
+	for _, family := range families {
+		_, err := expfmt.MetricFamilyToText(&b, family)
+		require.NoError(t, err)
+	}
+	return b.String()
+}
+
+func TestEventIngested_CapsDistinctSubjects(t *testing.T) {
+	app := newDeliveryTestApp(new(deliveryMockQuerier))
+	for i := 0; i < maxIngestedSubjects; i++ {
+		app.Metrics.EventIngested(fmt.Sprintf("order.%d", i))
+	}
+	app.Metrics.EventIngested("order.0")
+	app.Metrics.EventIngested("order.new")
+	app.Metrics.EventIngested("order.newer")
+
+	out := writeTestMetrics(t, app)
+	assert.Contains(t, out, `slurpee_events_ingested_total{subject="order.0"} 2`, "Known subjects keep their series")
+	assert.NotContains(t, out, `subject="order.new"`)
+	assert.Contains(t, out, `slurpee_events_ingested_total{subject="_other"} 2`)
+}
+
+func TestProcessDeliveryTask_RecordsSuccessMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	task := newBatchedTask(subscriber)
+	task.event.CreatedAt = pgtype.Timestamptz{Time: time.Now().Add(-2 * time.Second), Valid: true}
+	runTask(app, task)
+
+	labels := `subscriber="test-subscriber",subscriber_id="` + UuidToString(subscriber.ID) + `"`
+	out := writeTestMetrics(t, app)
+	assert.Contains(t, out, `slurpee_delivery_attempts_total{outcome="succeeded",`+labels+`} 1`)
+	assert.Contains(t, out, `slurpee_deliveries_total{outcome="succeeded",`+labels+`} 1`)
+	assert.Contains(t, out, `slurpee_delivery_request_duration_seconds_count{`+labels+`} 1`)
+	assert.Contains(t, out, `slurpee_delivery_lag_seconds_bucket{`+labels+`,le="1"} 0`)
+	assert.Contains(t, out, `slurpee_delivery_lag_seconds_bucket{`+labels+`,le="5"} 1`)
+	assert.NotContains(t, out, `slurpee_delivery_retries_scheduled_total{`)
+}
+
+func TestProcessDeliveryTask_RecordsRetryMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	runTask(app, newBatchedTask(subscriber))
+
+	labels := `subscriber="test-subscriber",subscriber_id="` + UuidToString(subscriber.ID) + `"`
+	out := writeTestMetrics(t, app)
+	assert.Contains(t, out, `slurpee_delivery_attempts_total{outcome="failed",`+labels+`} 1`)
+	assert.Contains(t, out, `slurpee_delivery_retries_scheduled_total{`+labels+`} 1`)
+	assert.NotContains(t, out, `slurpee_deliveries_total{`)
+}
+
+func TestProcessDeliveryTask_DisabledSubscriberCountsFailureWithoutAttempt(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.DisabledAt = newTestTimestamp() })
+	runTask(app, newBatchedTask(subscriber))
+
+	out := writeTestMetrics(t, app)
+	assert.Contains(t, out, `slurpee_deliveries_total{outcome="failed",subscriber="test-subscriber",subscriber_id="`+UuidToString(subscriber.ID)+`"} 1`)
+	assert.NotContains(t, out, `slurpee_delivery_attempts_total{`)
+}
+
+func TestMetricsGatherer_ReportsQueuesAndConcurrency(t *testing.T) {
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.MaxParallel = 4 })
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{}, nil)
+	app := newDeliveryTestApp(mockDB)
+
+	taskQueue := make(chan deliveryTask, 10)
+	taskQueue <- deliveryTask{}
+	taskQueue <- deliveryTask{}
+	registry := newEventRegistry()
+	registry.register(newTestUUID().Bytes, &eventTracker{})
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, taskQueue, registry)
+	ds.semaphores = newSemaphorePool()
+	ds.semaphores.get(subscriber.ID.Bytes, subscriber.MaxParallel) <- struct{}{}
+	app.dispatcher = ds
+
+	app.DeliveryChan <- db.Event{}
+	_, unsubscribe := app.EventBus.Subscribe()
+	defer unsubscribe()
+
+	labels := `subscriber="test-subscriber",subscriber_id="` + UuidToString(subscriber.ID) + `"`
+	out := writeTestMetrics(t, app)
+	assert.Contains(t, out, "slurpee_task_queue_depth 2\n")
+	assert.Contains(t, out, "slurpee_task_queue_capacity 10\n")
+	assert.Contains(t, out, "slurpee_delivery_chan_depth 1\n")
+	assert.Contains(t, out, "slurpee_delivery_chan_capacity 100\n")
+	assert.Contains(t, out, "slurpee_inflight_events 1\n")
+	assert.Contains(t, out, "slurpee_sse_clients 1\n")
+	assert.Contains(t, out, "slurpee_eventbus_dropped_messages_total 0\n")
+	assert.Contains(t, out, `slurpee_subscriber_requests_in_flight{`+labels+`} 1`)
+	assert.Contains(t, out, `slurpee_subscriber_max_parallel{`+labels+`} 4`)
+}
+
+func TestEventBus_CountsDroppedMessages(t *testing.T) {
+	bus := NewEventBus()
+	_, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for range subscriberBufferSize + 3 {
+		bus.Publish(BusMessage{Type: BusMessageCreated})
+	}
+
+	assert.Equal(t, uint64(3), bus.Dropped())
+	assert.Equal(t, 1, bus.SubscriberCount())
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Subscriber auth modes add authentication on top of the signing mode.
+const (
+	// AuthModeNone sends no additional authentication.
+	AuthModeNone = "none"
+	// AuthModeOAuth2 sends a bearer token obtained from the subscriber's token
+	// endpoint with the OAuth2 client credentials grant.
+	AuthModeOAuth2 = "oauth2"
+)
+
+// oauthRefreshMargin is how long before expiry a cached token is replaced,
+// so a token does not expire while a delivery is in flight.
+const oauthRefreshMargin = 30 * time.Second
+
+// ValidAuthMode reports whether mode is a supported subscriber auth mode.
+func ValidAuthMode(mode string) bool {
+	return mode == AuthModeNone || mode == AuthModeOAuth2
+}
+
+// ValidTokenURL reports whether s is an absolute http or https URL.
+func ValidTokenURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// oauthToken is a subscriber's cached access token. Its mutex is held while
+// a new token is fetched so concurrent deliveries share one token request.
+type oauthToken struct {
+	mu          sync.Mutex
+	accessToken string
+	refreshAt   time.Time // zero when the token endpoint gave no lifetime
+	updatedAt   time.Time // subscriber version the token was fetched for
+}
+
+func (t *oauthToken) usable(now time.Time, subscriberUpdatedAt time.Time) bool {
+	if t.accessToken == "" || subscriberUpdatedAt.After(t.updatedAt) {
+		return false
+	}
+	return t.refreshAt.IsZero() || now.Before(t.refreshAt)
+}
+
+// OAuthTokenCache holds OAuth2 access tokens per subscriber. A token is
+// fetched on first use and again when it is about to expire, when the
+// subscriber is updated, or after the endpoint rejects it with 401.
+type OAuthTokenCache struct {
+	mu     sync.Mutex
+	tokens map[[16]byte]*oauthToken
+}
+
+func NewOAuthTokenCache() *OAuthTokenCache {
+	return &OAuthTokenCache{tokens: make(map[[16]byte]*oauthToken)}
+}
+
+func (c *OAuthTokenCache) entry(id [16]byte) *oauthToken {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tokens[id]
+	if !ok {
+		t = &oauthToken{}
+		c.tokens[id] = t
+	}
+	return t
+}
+
+// Token returns subscriber's cached access token, fetching a new one with
+// client when needed. encryptionKey decrypts the subscriber's client secret.
+func (c *OAuthTokenCache) Token(ctx context.Context, client *http.Client, subscriber db.Subscriber, encryptionKey string) (string, error) {
+	t := c.entry(subscriber.ID.Bytes)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.usable(now, subscriber.UpdatedAt.Time) {
+		return t.accessToken, nil
+	}
+
+	clientSecret, err := DecryptSecret(encryptionKey, subscriber.OauthClientSecretEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("decrypting client secret: %w", err)
+	}
+	accessToken, lifetime, err := fetchOAuthToken(ctx, client, subscriber, clientSecret)
+	if err != nil {
+		return "", err
+	}
+
+	t.accessToken = accessToken
+	t.updatedAt = subscriber.UpdatedAt.Time
+	t.refreshAt = time.Time{}
+	if lifetime > 0 {
+		t.refreshAt = now.Add(lifetime - min(oauthRefreshMargin, lifetime/2))
+	}
+	return accessToken, nil
+}
+
+// Invalidate discards subscriber's cached token if it is still accessToken.
+// A token another delivery has already replaced is kept.
+func (c *OAuthTokenCache) Invalidate(subscriber db.Subscriber, accessToken string) {
+	t := c.entry(subscriber.ID.Bytes)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.accessToken == accessToken {
+		t.accessToken = ""
+	}
+}
+
+// oauthTokenResponse is a successful token endpoint response (RFC 6749 §5.1).
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// fetchOAuthToken requests an access token with the client credentials grant,
+// authenticating with HTTP Basic auth. Returns the token and its lifetime, or
+// 0 if the endpoint did not say.
+func fetchOAuthToken(ctx context.Context, client *http.Client, subscriber db.Subscriber, clientSecret string) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(subscriber.OauthScopes) > 0 {
+		form.Set("scope", strings.Join(subscriber.OauthScopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscriber.OauthTokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(subscriber.OauthClientID), url.QueryEscape(clientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token endpoint responded HTTP %d", resp.StatusCode)
+	}
+
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", 0, errors.New("token endpoint returned no access token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
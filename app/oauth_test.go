@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// tokenServer is a stand-in OAuth2 token endpoint that issues numbered tokens.
+type tokenServer struct {
+	*httptest.Server
+	requests  atomic.Int32
+	expiresIn int
+	lastForm  atomic.Value
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "slurpee" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		ts.lastForm.Store(r.PostForm)
+		n := ts.requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   ts.expiresIn,
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newOAuthSubscriber(t *testing.T, endpointURL, tokenURL string) db.Subscriber {
+	t.Helper()
+	secret, err := EncryptSecret(testEncryptionKey, "client-secret")
+	require.NoError(t, err)
+	return newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = endpointURL
+		s.AuthMode = AuthModeOAuth2
+		s.OauthTokenUrl = tokenURL
+		s.OauthClientID = "slurpee"
+		s.OauthClientSecretEncrypted = secret
+		s.OauthScopes = []string{"events:write", "events:read"}
+	})
+}
+
+func newOAuthTestApp(mockDB *deliveryMockQuerier) *Application {
+	app := newDeliveryTestApp(mockDB)
+	app.Config.EncryptionKey = testEncryptionKey
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	return app
+}
+
+func TestDeliverToSubscriber_OAuth2BearerToken(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	var received []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newOAuthTestApp(mockDB)
+	subscriber := newOAuthSubscriber(t, endpoint.URL, tokens.URL)
+
+	for i := 0; i < 3; i++ {
+		result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+		assert.True(t, result.succeeded, result.err)
+	}
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, received)
+	assert.Equal(t, int32(1), tokens.requests.Load(), "The token should be cached across deliveries")
+	form := tokens.lastForm.Load().(url.Values)
+	assert.Equal(t, []string{"client_credentials"}, form["grant_type"])
+	assert.Equal(t, []string{"events:write events:read"}, form["scope"])
+
+	mockDB.AssertCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.MatchedBy(func(p db.InsertDeliveryAttemptParams) bool {
+		return !strings.Contains(string(p.RequestHeaders), "token-1") &&
+			strings.Contains(string(p.RequestHeaders), `"Authorization":"[REDACTED]"`)
+	}))
+}
+
+func TestDeliverToSubscriber_OAuth2RefreshesExpiredToken(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	var received []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newOAuthTestApp(mockDB)
+	subscriber := newOAuthSubscriber(t, endpoint.URL, tokens.URL)
+
+	deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+	app.OAuthTokens.entry(subscriber.ID.Bytes).refreshAt = time.Now().Add(-time.Second)
+	deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, received)
+}
+
+func TestDeliverToSubscriber_OAuth2RetriesOnceAfter401(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	var received []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		// The gateway has revoked the first token
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newOAuthTestApp(mockDB)
+	subscriber := newOAuthSubscriber(t, endpoint.URL, tokens.URL)
+
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.True(t, result.succeeded, result.err)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, received)
+	mockDB.AssertNumberOfCalls(t, "InsertDeliveryAttempt", 1)
+}
+
+func TestDeliverToSubscriber_OAuth2PersistentUnauthorized(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer endpoint.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newOAuthTestApp(mockDB)
+	subscriber := newOAuthSubscriber(t, endpoint.URL, tokens.URL)
+
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.False(t, result.succeeded)
+	assert.Equal(t, http.StatusUnauthorized, result.statusCode)
+	assert.Equal(t, int32(2), tokens.requests.Load(), "A 401 should be retried only once")
+}
+
+func TestDeliverToSubscriber_OAuth2TokenEndpointFailure(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("The endpoint should not be called without a token")
+	}))
+	defer endpoint.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newOAuthTestApp(mockDB)
+	subscriber := newOAuthSubscriber(t, endpoint.URL, tokens.URL)
+	subscriber.OauthClientID = "someone-else"
+
+	result := deliverToSubscriber(context.Background(), app, newTestEvent(), subscriber, 0, slog.Default())
+
+	assert.False(t, result.succeeded)
+	assert.Equal(t, "token request failed: token endpoint responded HTTP 401", result.err)
+}
+
+func TestOAuthTokenCache_RefetchesAfterSubscriberUpdate(t *testing.T) {
+	tokens := newTokenServer(t, 0)
+	subscriber := newOAuthSubscriber(t, "https://example.com/webhook", tokens.URL)
+	cache := NewOAuthTokenCache()
+	client := http.DefaultClient
+
+	token, err := cache.Token(context.Background(), client, subscriber, testEncryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// A token without expires_in is kept until the subscriber changes
+	token, err = cache.Token(context.Background(), client, subscriber, testEncryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	subscriber.UpdatedAt.Time = subscriber.UpdatedAt.Time.Add(time.Second)
+	token, err = cache.Token(context.Background(), client, subscriber, testEncryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	// Invalidating a token that was already replaced keeps the newer one
+	cache.Invalidate(subscriber, "token-1")
+	token, err = cache.Token(context.Background(), client, subscriber, testEncryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// laneKey identifies an ordered delivery lane: one subscriber and one value
+// of its subscription's ordering key.
+type laneKey struct {
+	subscriber [16]byte
+	key        string
+}
+
+// headKey identifies the task at the head of a lane by subscriber and event.
+type headKey struct {
+	subscriber [16]byte
+	event      [16]byte
+}
+
+// laneEntry is a task waiting in an ordered lane. Scheduled entries belong to
+// a retry that the retry poller will enqueue once due.
+type laneEntry struct {
+	task      deliveryTask
+	scheduled bool
+}
+
+// orderedLane holds the event at the head of the lane and the tasks waiting
+// behind it, sorted by event timestamp and then event ID.
+type orderedLane struct {
+	head    [16]byte
+	waiting []laneEntry
+}
+
+// orderingGate serializes deliveries for ordered subscriptions. Only the head
+// of each lane is handed to the worker pool; it stays at the head through its
+// retries and the next task is released once it succeeds or is dead-lettered.
+type orderingGate struct {
+	mu    sync.Mutex
+	lanes map[laneKey]*orderedLane
+	heads map[headKey]laneKey
+}
+
+func newOrderingGate() *orderingGate {
+	return &orderingGate{
+		lanes: make(map[laneKey]*orderedLane),
+		heads: make(map[headKey]laneKey),
+	}
+}
+
+// admit returns true if the task may be enqueued now, either because its lane
+// is empty or because it is already the head of its lane. Otherwise the task
+// waits in the lane until release hands it back.
+func (g *orderingGate) admit(task deliveryTask) bool {
+	return g.add(laneEntry{task: task})
+}
+
+// reserve places a task whose retry is already scheduled in its lane without
+// enqueueing it. The task blocks the tasks behind it, and the retry poller
+// enqueues it once due.
+func (g *orderingGate) reserve(task deliveryTask) {
+	g.add(laneEntry{task: task, scheduled: true})
+}
+
+func (g *orderingGate) add(entry laneEntry) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	task := entry.task
+	hk := headKey{subscriber: task.subscriber.ID.Bytes, event: task.event.ID.Bytes}
+	if _, ok := g.heads[hk]; ok {
+		return true
+	}
+
+	lk := laneKey{subscriber: task.subscriber.ID.Bytes, key: orderingKeyValue(task.subscription, task.event)}
+	lane, ok := g.lanes[lk]
+	if !ok {
+		g.lanes[lk] = &orderedLane{head: task.event.ID.Bytes}
+		g.heads[hk] = lk
+		return true
+	}
+
+	// Replace an entry for the same event rather than queueing it twice
+	for i, w := range lane.waiting {
+		if w.task.event.ID.Bytes == task.event.ID.Bytes {
+			lane.waiting = append(lane.waiting[:i], lane.waiting[i+1:]...)
+			break
+		}
+	}
+	i := sort.Search(len(lane.waiting), func(i int) bool {
+		return eventBefore(task.event, lane.waiting[i].task.event)
+	})
+	lane.waiting = append(lane.waiting, laneEntry{})
+	copy(lane.waiting[i+1:], lane.waiting[i:])
+	lane.waiting[i] = entry
+	return false
+}
+
+// release removes the subscriber's head task for eventID from its lane and
+// promotes the next waiting task. The promoted task is returned if it should
+// be enqueued now; scheduled retries are left to the retry poller.
+func (g *orderingGate) release(subscriberID, eventID [16]byte) (deliveryTask, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	hk := headKey{subscriber: subscriberID, event: eventID}
+	lk, ok := g.heads[hk]
+	if !ok {
+		return deliveryTask{}, false
+	}
+	delete(g.heads, hk)
+
+	lane := g.lanes[lk]
+	if len(lane.waiting) == 0 {
+		delete(g.lanes, lk)
+		return deliveryTask{}, false
+	}
+
+	next := lane.waiting[0]
+	lane.waiting = lane.waiting[1:]
+	lane.head = next.task.event.ID.Bytes
+	g.heads[headKey{subscriber: subscriberID, event: lane.head}] = lk
+	if next.scheduled {
+		return deliveryTask{}, false
+	}
+	return next.task, true
+}
+
+// eventBefore orders events by timestamp, breaking ties by ID.
+func eventBefore(a, b db.Event) bool {
+	if !a.Timestamp.Time.Equal(b.Timestamp.Time) {
+		return a.Timestamp.Time.Before(b.Timestamp.Time)
+	}
+	return bytes.Compare(a.ID.Bytes[:], b.ID.Bytes[:]) < 0
+}
+
+// orderingKeyValue returns the value events are ordered by for an ordered
+// subscription: the event subject, or the value of the subscription's
+// ordering_key field in the event data. Nested fields use dot notation
+// (e.g. "customer.id"). Events missing the field share the empty key.
+func orderingKeyValue(sub db.Subscription, event db.Event) string {
+	if sub.OrderingKey == "" {
+		return event.Subject
+	}
+
+	raw := json.RawMessage(event.Data)
+	for _, part := range strings.Split(sub.OrderingKey, ".") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return ""
+		}
+		var ok bool
+		if raw, ok = obj[part]; !ok {
+			return ""
+		}
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func newOrderedTask(subscriber db.Subscriber, subscription db.Subscription, ts time.Time) deliveryTask {
+	return deliveryTask{
+		event: newTestEvent(func(e *db.Event) {
+			e.Timestamp = pgtype.Timestamptz{Time: ts, Valid: true}
+		}),
+		subscription: subscription,
+		subscriber:   subscriber,
+		maxRetries:   3,
+	}
+}
+
+func TestOrderingGate_ReleasesInTimestampOrder(t *testing.T) {
+	gate := newOrderingGate()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) { s.Ordered = true })
+	base := time.Now()
+
+	first := newOrderedTask(subscriber, subscription, base)
+	third := newOrderedTask(subscriber, subscription, base.Add(2*time.Second))
+	second := newOrderedTask(subscriber, subscription, base.Add(time.Second))
+
+	assert.True(t, gate.admit(first), "First task for a key should be enqueued")
+	assert.False(t, gate.admit(third))
+	assert.False(t, gate.admit(second))
+	assert.True(t, gate.admit(first), "Retry of the head task should be enqueued")
+
+	next, ok := gate.release(subscriber.ID.Bytes, first.event.ID.Bytes)
+	assert.True(t, ok)
+	assert.Equal(t, second.event.ID, next.event.ID, "Waiting tasks should be released oldest first")
+
+	next, ok = gate.release(subscriber.ID.Bytes, second.event.ID.Bytes)
+	assert.True(t, ok)
+	assert.Equal(t, third.event.ID, next.event.ID)
+
+	_, ok = gate.release(subscriber.ID.Bytes, third.event.ID.Bytes)
+	assert.False(t, ok)
+	assert.Empty(t, gate.lanes)
+	assert.Empty(t, gate.heads)
+}
+
+func TestOrderingGate_KeysAreIndependent(t *testing.T) {
+	gate := newOrderingGate()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.Ordered = true
+		s.OrderingKey = "customer"
+	})
+
+	a := newOrderedTask(subscriber, subscription, time.Now())
+	a.event.Data = json.RawMessage(`{"customer":"a"}`)
+	b := newOrderedTask(subscriber, subscription, time.Now())
+	b.event.Data = json.RawMessage(`{"customer":"b"}`)
+	other := newOrderedTask(newTestSubscriber(), subscription, time.Now())
+	other.event.Data = a.event.Data
+
+	assert.True(t, gate.admit(a))
+	assert.True(t, gate.admit(b), "A different ordering key should not wait")
+	assert.True(t, gate.admit(other), "A different subscriber should not wait")
+}
+
+func TestOrderingGate_ReservedTaskBlocksLaneWithoutEnqueue(t *testing.T) {
+	gate := newOrderingGate()
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) { s.Ordered = true })
+	base := time.Now()
+
+	head := newOrderedTask(subscriber, subscription, base)
+	scheduled := newOrderedTask(subscriber, subscription, base.Add(time.Second))
+	later := newOrderedTask(subscriber, subscription, base.Add(2*time.Second))
+
+	gate.admit(head)
+	gate.reserve(scheduled)
+	assert.False(t, gate.admit(later))
+
+	_, ok := gate.release(subscriber.ID.Bytes, head.event.ID.Bytes)
+	assert.False(t, ok, "Scheduled retry should be left to the retry poller")
+	assert.True(t, gate.admit(scheduled), "Scheduled retry should be enqueued once it holds the lane")
+
+	next, ok := gate.release(subscriber.ID.Bytes, scheduled.event.ID.Bytes)
+	assert.True(t, ok)
+	assert.Equal(t, later.event.ID, next.event.ID)
+}
+
+func TestOrderingKeyValue(t *testing.T) {
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "order.created"
+		e.Data = json.RawMessage(`{"customer":{"id":"c-1","tier":3},"region":"eu"}`)
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"defaults to subject", "", "order.created"},
+		{"top-level string field", "region", "eu"},
+		{"nested string field", "customer.id", "c-1"},
+		{"non-string value", "customer.tier", "3"},
+		{"object value", "customer", `{"id":"c-1","tier":3}`},
+		{"missing field", "account", ""},
+		{"path through scalar", "region.code", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubscription(func(s *db.Subscription) {
+				s.Ordered = true
+				s.OrderingKey = tt.key
+			})
+			assert.Equal(t, tt.want, orderingKeyValue(sub, event))
+		})
+	}
+}
+
+func TestDispatchEvent_OrderedSubscriptionWaitsBehindEarlierEvent(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "test.subject"
+		s.Ordered = true
+	})
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.DeliveryStatus == "partial"
+	})).Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+
+	first := newTestEvent()
+	second := newTestEvent(func(e *db.Event) {
+		e.Timestamp = pgtype.Timestamptz{Time: first.Timestamp.Time.Add(time.Second), Valid: true}
+	})
+
+	dispatchEvent(app, first, ds)
+	dispatchEvent(app, second, ds)
+
+	assert.Equal(t, 1, len(taskQueue), "Second event should wait for the first")
+	task := <-taskQueue
+	assert.Equal(t, first.ID, task.event.ID)
+	mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.ID == second.ID && p.DeliveryStatus == "partial"
+	}))
+}
+
+func TestProcessDeliveryTask_OrderedHeadBlocksUntilSuccess(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.Ordered = true
+	})
+
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+
+	base := time.Now()
+	head := newOrderedTask(subscriber, subscription, base)
+	next := newOrderedTask(subscriber, subscription, base.Add(time.Second))
+	for _, task := range []*deliveryTask{&head, &next} {
+		task.tracker = &eventTracker{
+			event:    task.event,
+			expected: 1,
+			results:  make(map[[16]byte]deliveryResult),
+			logger:   slog.Default(),
+		}
+		ds.registry.register(task.event.ID.Bytes, task.tracker)
+	}
+	assert.True(t, ds.enqueue(head))
+	assert.False(t, ds.enqueue(next))
+	<-taskQueue
+
+	// A failed head schedules a retry and keeps the lane
+	status.Store(http.StatusInternalServerError)
+	inflightWg.Add(1)
+	processDeliveryTask(app, head, getSemaphore, ds)
+	assert.Equal(t, 0, len(taskQueue))
+
+	// The retried head succeeds and releases the next event
+	status.Store(http.StatusOK)
+	head.attemptNum = 1
+	inflightWg.Add(1)
+	processDeliveryTask(app, head, getSemaphore, ds)
+
+	select {
+	case task := <-taskQueue:
+		assert.Equal(t, next.event.ID, task.event.ID)
+	case <-time.After(time.Second):
+		t.Fatal("Next ordered task was not enqueued after the head succeeded")
+	}
+}
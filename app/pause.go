@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// PauseSubscriber pauses deliveries to a subscriber. Events keep matching its
+// subscriptions while it is paused; their deliveries are held in
+// delivery_retries until it is resumed.
+func PauseSubscriber(ctx context.Context, slurpee *Application, subscriberID pgtype.UUID) error {
+	if err := slurpee.DB.PauseSubscriber(ctx, subscriberID); err != nil {
+		return err
+	}
+	slurpee.SubscriptionCache.Flush()
+	return nil
+}
+
+// ResumeSubscriber resumes deliveries to a paused subscriber and releases the
+// deliveries held while it was paused to the retry poller, which claims them
+// in event order.
+// Resuming a subscriber that is not paused releases any held deliveries left
+// behind by an earlier failed resume. Returns the number of deliveries released.
+func ResumeSubscriber(ctx context.Context, slurpee *Application, subscriberID pgtype.UUID) (int64, error) {
+	if err := slurpee.DB.ResumeSubscriber(ctx, subscriberID); err != nil {
+		return 0, err
+	}
+	// Flush before releasing so released deliveries see the subscriber as resumed
+	slurpee.SubscriptionCache.Flush()
+	released, err := slurpee.DB.ReleaseHeldDeliveryRetries(ctx, subscriberID)
+	if err != nil {
+		return 0, err
+	}
+	if err := slurpee.DB.ReleaseHeldDeliveries(ctx, subscriberID); err != nil {
+		slog.Error("Failed to record released deliveries", "error", err,
+			"subscriber_id", UuidToString(subscriberID))
+	}
+	return released, nil
+}
+
+// currentSubscriber returns the subscriber as the subscription cache has it
+// now. Tasks carry the subscriber as it was when they were queued, so a task
+// already in the task queue, held by the rate limiter or waiting in an
+// ordering lane must be checked against the cache to see a pause, resume or
+// disable since. The task's snapshot is returned if the cache cannot load or
+// no longer holds the subscriber.
+func currentSubscriber(ctx context.Context, slurpee *Application, subscriber db.Subscriber) db.Subscriber {
+	current, err := slurpee.SubscriptionCache.GetSubscriberByID(ctx, subscriber.ID)
+	if err != nil {
+		return subscriber
+	}
+	return current
+}
+
+// holdDeliveryTask persists a task for a paused subscriber to delivery_retries
+// as held. The retry poller skips held retries until the subscriber is
+// resumed. The attempt number is unchanged, so holding does not use up the
+// retry budget. Returns false if the task could not be persisted.
+func holdDeliveryTask(ctx context.Context, slurpee *Application, task deliveryTask) bool {
+	logger := task.tracker.logger
+	now := time.Now().UTC()
+	firstAttemptAt := task.firstAttemptAt
+	if firstAttemptAt.IsZero() {
+		firstAttemptAt = now
+	}
+	err := slurpee.DB.UpsertDeliveryRetry(ctx, db.UpsertDeliveryRetryParams{
+		EventID:        task.event.ID,
+		SubscriberID:   task.subscriber.ID,
+		SubscriptionID: task.subscription.ID,
+		AttemptNum:     int32(task.attemptNum),
+		MaxRetries:     int32(task.maxRetries),
+		NextAttemptAt:  pgtype.Timestamptz{Time: now, Valid: true},
+		FirstAttemptAt: pgtype.Timestamptz{Time: firstAttemptAt, Valid: true},
+		Held:           true,
+	})
+	if err != nil {
+		logger.Error("Failed to hold delivery for paused subscriber", "error", err,
+			"subscriber_id", UuidToString(task.subscriber.ID))
+		return false
+	}
+	logger.Debug("Held delivery for paused subscriber",
+		"subscriber_id", UuidToString(task.subscriber.ID),
+		"attempt", task.attemptNum+1,
+	)
+	recordDeliveryState(ctx, slurpee, task, DeliveryHeld, time.Time{}, "")
+	updateEventStatus(ctx, slurpee, task.event.ID, task.event.RetryCount, "partial")
+	return true
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestProcessDeliveryTask_HoldsPausedSubscriber(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PausedAt = newTestTimestamp()
+	})
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := newBatchedTask(subscriber)
+	task.attemptNum = 2
+	runTask(app, task)
+
+	assert.Equal(t, int32(0), hits.Load(), "Paused subscriber must not be called")
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == task.event.ID && p.Held && p.AttemptNum == 2
+	}))
+	mockDB.AssertCalled(t, "UpsertDelivery", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryParams) bool {
+		return p.EventID == task.event.ID && p.Status == DeliveryHeld
+	}))
+	mockDB.AssertNotCalled(t, "InsertDeliveryAttempt", mock.Anything, mock.Anything)
+}
+
+func TestProcessDeliveryTask_DeliversAfterResume(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resumed := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	// The task was queued while the subscriber was paused
+	snapshot := resumed
+	snapshot.PausedAt = newTestTimestamp()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{resumed}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, snapshot)
+
+	assert.Equal(t, int32(1), hits.Load())
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+}
+
+func TestProcessDeliveryTask_HoldsTaskQueuedBeforePause(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	paused := subscriber
+	paused.PausedAt = newTestTimestamp()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB, paused)
+	mockDB.On("PauseSubscriber", mock.Anything, subscriber.ID).
+		Return(nil)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	// The task is queued with the subscriber unpaused, then it is paused
+	task := newBatchedTask(subscriber)
+	require.NoError(t, PauseSubscriber(context.Background(), app, subscriber.ID))
+	runTask(app, task)
+
+	assert.Equal(t, int32(0), hits.Load(), "Paused subscriber must not be called")
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == task.event.ID && p.Held
+	}))
+}
+
+func TestProcessDeliveryTask_FailsTaskQueuedBeforeDisable(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	disabled := subscriber
+	disabled.DisabledAt = newTestTimestamp()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB, disabled)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	// The task still carries the subscriber as it was before it was disabled
+	task := runSingleDelivery(app, subscriber)
+
+	assert.Equal(t, int32(0), hits.Load(), "Disabled subscriber must not be called")
+	mockDB.AssertCalled(t, "UpsertDelivery", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryParams) bool {
+		return p.EventID == task.event.ID && p.Status == DeliveryFailed && p.LastError == "subscriber disabled"
+	}))
+}
+
+func TestProcessDeliveryTask_HoldFailureDelivers(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.PausedAt = newTestTimestamp()
+	})
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(errors.New("connection refused"))
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, subscriber)
+
+	assert.Equal(t, int32(1), hits.Load(), "Delivery is attempted rather than lost")
+}
+
+func TestResumeSubscriber_ReleasesHeldDeliveries(t *testing.T) {
+	subscriber := newTestSubscriber()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("ReleaseHeldDeliveries", mock.Anything, subscriber.ID).
+		Return(nil)
+	mockDB.On("ResumeSubscriber", mock.Anything, subscriber.ID).
+		Return(nil)
+	mockDB.On("ReleaseHeldDeliveryRetries", mock.Anything, subscriber.ID).
+		Return(int64(4), nil)
+
+	released, err := ResumeSubscriber(context.Background(), app, subscriber.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), released)
+}
+
+func TestResumeSubscriber_ResumeFailureKeepsDeliveriesHeld(t *testing.T) {
+	subscriber := newTestSubscriber()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("ResumeSubscriber", mock.Anything, subscriber.ID).
+		Return(errors.New("connection refused"))
+
+	_, err := ResumeSubscriber(context.Background(), app, subscriber.ID)
+	require.Error(t, err)
+	mockDB.AssertNotCalled(t, "ReleaseHeldDeliveryRetries", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Subscriber payload formats control the shape of the webhook body.
+const (
+	// PayloadFormatData sends only the event's data; metadata rides in X-Event-* headers.
+	PayloadFormatData = "data"
+	// PayloadFormatEnvelope sends a JSON envelope with id, subject, timestamp, trace_id and data.
+	PayloadFormatEnvelope = "envelope"
+	// PayloadFormatCloudEventsBinary sends the data as the body with CloudEvents ce-* headers.
+	PayloadFormatCloudEventsBinary = "cloudevents-binary"
+	// PayloadFormatCloudEventsStructured sends a CloudEvents 1.0 JSON event as the body.
+	PayloadFormatCloudEventsStructured = "cloudevents-structured"
+)
+
+const cloudEventsSpecVersion = "1.0"
+
+// ValidPayloadFormat reports whether format is a supported subscriber payload format.
+func ValidPayloadFormat(format string) bool {
+	switch format {
+	case PayloadFormatData, PayloadFormatEnvelope, PayloadFormatCloudEventsBinary, PayloadFormatCloudEventsStructured:
+		return true
+	}
+	return false
+}
+
+// EventEnvelope is the webhook body sent in the envelope payload format.
+type EventEnvelope struct {
+	ID        string          `json:"id"`
+	Subject   string          `json:"subject"`
+	Timestamp time.Time       `json:"timestamp"`
+	TraceID   *string         `json:"trace_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CloudEvent is a CloudEvents 1.0 event in JSON structured mode.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	TraceID         string          `json:"traceid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// buildDeliveryPayload renders the webhook body for the given payload format
+// and returns it with the format-specific headers, including Content-Type.
+// An empty or unknown format falls back to PayloadFormatData.
+func buildDeliveryPayload(source string, event db.Event, format string) ([]byte, map[string]string, error) {
+	eventID := UuidToString(event.ID)
+	var traceID string
+	if event.TraceID.Valid {
+		traceID = UuidToString(event.TraceID)
+	}
+
+	switch format {
+	case PayloadFormatEnvelope:
+		envelope := EventEnvelope{
+			ID:        eventID,
+			Subject:   event.Subject,
+			Timestamp: event.Timestamp.Time.UTC(),
+			Data:      json.RawMessage(event.Data),
+		}
+		if traceID != "" {
+			envelope.TraceID = &traceID
+		}
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, map[string]string{"Content-Type": "application/json"}, nil
+
+	case PayloadFormatCloudEventsStructured:
+		body, err := json.Marshal(CloudEvent{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              eventID,
+			Source:          source,
+			Type:            event.Subject,
+			Time:            event.Timestamp.Time.UTC(),
+			DataContentType: "application/json",
+			TraceID:         traceID,
+			Data:            json.RawMessage(event.Data),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, map[string]string{"Content-Type": "application/cloudevents+json"}, nil
+
+	case PayloadFormatCloudEventsBinary:
+		headers := map[string]string{
+			"Content-Type":   "application/json",
+			"ce-specversion": cloudEventsSpecVersion,
+			"ce-id":          eventID,
+			"ce-source":      source,
+			"ce-type":        event.Subject,
+			"ce-time":        event.Timestamp.Time.UTC().Format(time.RFC3339Nano),
+		}
+		if traceID != "" {
+			headers["ce-traceid"] = traceID
+		}
+		return event.Data, headers, nil
+
+	default:
+		return event.Data, map[string]string{"Content-Type": "application/json"}, nil
+	}
+}
+
+// buildBatchPayload renders the body of a batched delivery: a JSON array of
+// events. Batches always carry event metadata, so the data and envelope formats
+// send an array of EventEnvelope and both CloudEvents formats send a CloudEvents
+// JSON batch.
+func buildBatchPayload(source string, events []db.Event, format string) ([]byte, map[string]string, error) {
+	switch format {
+	case PayloadFormatCloudEventsBinary, PayloadFormatCloudEventsStructured:
+		batch := make([]CloudEvent, len(events))
+		for i, event := range events {
+			batch[i] = CloudEvent{
+				SpecVersion:     cloudEventsSpecVersion,
+				ID:              UuidToString(event.ID),
+				Source:          source,
+				Type:            event.Subject,
+				Time:            event.Timestamp.Time.UTC(),
+				DataContentType: "application/json",
+				Data:            json.RawMessage(event.Data),
+			}
+			if event.TraceID.Valid {
+				batch[i].TraceID = UuidToString(event.TraceID)
+			}
+		}
+		body, err := json.Marshal(batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, map[string]string{"Content-Type": "application/cloudevents-batch+json"}, nil
+
+	default:
+		batch := make([]EventEnvelope, len(events))
+		for i, event := range events {
+			batch[i] = EventEnvelope{
+				ID:        UuidToString(event.ID),
+				Subject:   event.Subject,
+				Timestamp: event.Timestamp.Time.UTC(),
+				Data:      json.RawMessage(event.Data),
+			}
+			if event.TraceID.Valid {
+				traceID := UuidToString(event.TraceID)
+				batch[i].TraceID = &traceID
+			}
+		}
+		body, err := json.Marshal(batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, map[string]string{"Content-Type": "application/json"}, nil
+	}
+}
+
+// cloudEventsSource returns the CloudEvents source attribute for this server.
+func cloudEventsSource(slurpee *Application) string {
+	if slurpee.Config.BaseURL != "" {
+		return slurpee.Config.BaseURL
+	}
+	return "slurpee"
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Limits on subscriber rate limit settings. A rate of 0 disables rate
+// limiting; a burst of 0 uses the default burst for the rate.
+const (
+	MaxRateLimitPerSecond = 10000
+	MaxRateLimitBurst     = 10000
+)
+
+// ValidRateLimit reports whether perSecond and burst are usable as a
+// subscriber's rate limit.
+func ValidRateLimit(perSecond float64, burst int32) bool {
+	return perSecond >= 0 && perSecond <= MaxRateLimitPerSecond && burst >= 0 && burst <= MaxRateLimitBurst
+}
+
+// tokenBucket paces deliveries to one subscriber. It refills at rate tokens
+// per second up to burst. Tokens are reserved ahead of time, so the balance
+// goes negative while reserved tasks wait for their turn, but by no more than
+// one burst.
+type tokenBucket struct {
+	rate            float64
+	configuredBurst int32 // as configured on the subscriber; 0 means the default
+	burst           float64
+	tokens          float64
+	last            time.Time
+}
+
+// newTokenBucket returns a full bucket. The default burst is one second's
+// worth of tokens, and at least one.
+func newTokenBucket(rate float64, burst int32, now time.Time) *tokenBucket {
+	capacity := float64(burst)
+	if burst <= 0 {
+		capacity = max(1, math.Ceil(rate))
+	}
+	return &tokenBucket{
+		rate:            rate,
+		configuredBurst: burst,
+		burst:           capacity,
+		tokens:          capacity,
+		last:            now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. When a burst's worth of tokens is already reserved, no token is
+// taken and reserve returns false, along with how long until the reserved
+// tokens are paid back.
+func (b *tokenBucket) reserve(now time.Time) (time.Duration, bool) {
+	b.refill(now)
+	if b.tokens-1 < -b.burst {
+		return b.wait(), false
+	}
+	b.tokens--
+	return b.wait(), true
+}
+
+// take takes a token however far ahead it has to be reserved and returns how
+// long the caller must wait before using it.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens--
+	return b.wait()
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns how long until the balance is back to zero.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// rateLimiterRegistry holds the token buckets of rate-limited subscribers and
+// counts the tasks held back by them, keyed by subscriber UUID bytes.
+type rateLimiterRegistry struct {
+	mu      sync.Mutex
+	buckets map[[16]byte]*tokenBucket
+	queued  map[[16]byte]int
+}
+
+func newRateLimiterRegistry() *rateLimiterRegistry {
+	return &rateLimiterRegistry{
+		buckets: make(map[[16]byte]*tokenBucket),
+		queued:  make(map[[16]byte]int),
+	}
+}
+
+// reserve takes a token from the subscriber's bucket and returns how long the
+// delivery must wait for it. It returns false without taking a token when the
+// subscriber already has a burst's worth reserved; the duration is then how
+// long until those are due. Subscribers without a rate limit never wait.
+func (r *rateLimiterRegistry) reserve(subscriber db.Subscriber, now time.Time) (time.Duration, bool) {
+	if subscriber.RateLimitPerSecond <= 0 {
+		return 0, true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bucket(subscriber, now).reserve(now)
+}
+
+// take takes a token from the subscriber's bucket however far ahead it has to
+// be reserved, for a task that cannot be parked.
+func (r *rateLimiterRegistry) take(subscriber db.Subscriber, now time.Time) time.Duration {
+	if subscriber.RateLimitPerSecond <= 0 {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bucket(subscriber, now).take(now)
+}
+
+// bucket returns the subscriber's bucket, rebuilding it when the subscriber's
+// rate limit has changed. Must be called with r.mu held.
+func (r *rateLimiterRegistry) bucket(subscriber db.Subscriber, now time.Time) *tokenBucket {
+	id := subscriber.ID.Bytes
+	b, ok := r.buckets[id]
+	if !ok || b.rate != subscriber.RateLimitPerSecond || b.configuredBurst != subscriber.RateLimitBurst {
+		b = newTokenBucket(subscriber.RateLimitPerSecond, subscriber.RateLimitBurst, now)
+		r.buckets[id] = b
+	}
+	return b
+}
+
+// hold counts a task held back by the subscriber's rate limit.
+func (r *rateLimiterRegistry) hold(id [16]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queued[id]++
+}
+
+// release stops counting a held task once it is requeued or parked.
+func (r *rateLimiterRegistry) release(id [16]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.queued[id] <= 1 {
+		delete(r.queued, id)
+		return
+	}
+	r.queued[id]--
+}
+
+// queuedTasks returns the number of tasks held back by the subscriber's rate limit.
+func (r *rateLimiterRegistry) queuedTasks(id [16]byte) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.queued[id]
+}
+
+// holdForRateLimit keeps a task that has reserved a rate-limit token out of
+// the worker pool until the token is due, then requeues it. The task stays in
+// flight while held. If the dispatcher shuts down first, the task is parked in
+// delivery_retries, due when its token would have been.
+func (ds *DispatcherState) holdForRateLimit(slurpee *Application, task deliveryTask, delay time.Duration) {
+	id := task.subscriber.ID.Bytes
+	task.tokenReserved = true
+	ds.rateLimits.hold(id)
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ds.shutdown:
+			if parkDeliveryTask(context.Background(), slurpee, task, time.Now().Add(delay)) {
+				ds.rateLimits.release(id)
+				ds.inflightWg.Done()
+				return
+			}
+			// Parking failed; attempt the delivery rather than lose it
+		}
+		ds.rateLimits.release(id)
+		ds.taskQueue <- task
+	}()
+}
+
+// applyRateLimit holds a task back until it is due under its subscriber's
+// rate limit. Tasks that fit within a burst of reserved tokens are held in
+// memory; later ones are parked in delivery_retries until the reserved tokens
+// are due, and take a fresh token when the retry poller claims them. Returns
+// false if the task may be delivered now.
+func (ds *DispatcherState) applyRateLimit(ctx context.Context, slurpee *Application, task deliveryTask) bool {
+	delay, reserved := ds.rateLimits.reserve(task.subscriber, time.Now())
+	if !reserved {
+		if parkDeliveryTask(ctx, slurpee, task, time.Now().Add(delay)) {
+			// A parked probe is not sent, so it has nothing to report
+			if task.probe {
+				ds.breakers.releaseProbe(task.subscriber.ID.Bytes)
+			}
+			ds.inflightWg.Done()
+			return true
+		}
+		// Parking failed; hold the task rather than lose it
+		delay = ds.rateLimits.take(task.subscriber, time.Now())
+	}
+	if delay <= 0 {
+		return false
+	}
+	ds.holdForRateLimit(slurpee, task, delay)
+	return true
+}
+
+// SubscriberQueuedTasks returns the number of deliveries to a subscriber held
+// back by its rate limit. It is 0 when the dispatcher is not running.
+func SubscriberQueuedTasks(slurpee *Application, subscriberID pgtype.UUID) int {
+	ds := slurpee.dispatcher
+	if ds == nil || ds.rateLimits == nil {
+		return 0
+	}
+	return ds.rateLimits.queuedTasks(subscriberID.Bytes)
+}
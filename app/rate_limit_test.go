@@ -0,0 +1,293 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// reserveDelay reserves a token from bucket and returns the wait for it,
+// failing the test if the reservation is refused.
+func reserveDelay(t *testing.T, bucket *tokenBucket, now time.Time) time.Duration {
+	t.Helper()
+	delay, ok := bucket.reserve(now)
+	require.True(t, ok, "reservation refused")
+	return delay
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(2, 2, now)
+
+	assert.Equal(t, time.Duration(0), reserveDelay(t, bucket, now))
+	assert.Equal(t, time.Duration(0), reserveDelay(t, bucket, now))
+	assert.Equal(t, 500*time.Millisecond, reserveDelay(t, bucket, now), "Tokens beyond the burst are spaced at the rate")
+	assert.Equal(t, time.Second, reserveDelay(t, bucket, now))
+
+	// Three seconds later the reserved tokens have been paid back, and the
+	// bucket holds no more than its burst
+	later := now.Add(3 * time.Second)
+	assert.Equal(t, time.Duration(0), reserveDelay(t, bucket, later))
+	assert.Equal(t, time.Duration(0), reserveDelay(t, bucket, later))
+	assert.Equal(t, 500*time.Millisecond, reserveDelay(t, bucket, later))
+}
+
+func TestTokenBucket_ReservesAtMostOneBurstAhead(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(1, 2, now)
+	for range 4 {
+		reserveDelay(t, bucket, now)
+	}
+
+	delay, ok := bucket.reserve(now)
+	assert.False(t, ok, "No more than a burst is reserved ahead")
+	assert.Equal(t, 2*time.Second, delay, "Refusal reports when the reserved tokens are due")
+
+	// take reserves regardless
+	assert.Equal(t, 3*time.Second, bucket.take(now))
+
+	// Once the reserved tokens are paid back, reservations are taken again
+	assert.Equal(t, time.Second, reserveDelay(t, bucket, now.Add(3*time.Second)))
+}
+
+func TestTokenBucket_DefaultBurst(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, 5.0, newTokenBucket(4.5, 0, now).burst, "Default burst is one second's worth")
+	assert.Equal(t, 1.0, newTokenBucket(0.2, 0, now).burst, "Default burst is at least one")
+
+	slow := newTokenBucket(0.5, 0, now)
+	assert.Equal(t, time.Duration(0), reserveDelay(t, slow, now))
+	assert.Equal(t, 2*time.Second, reserveDelay(t, slow, now))
+}
+
+func TestRateLimiterRegistry_Reserve(t *testing.T) {
+	limits := newRateLimiterRegistry()
+	now := time.Now()
+
+	unlimited := newTestSubscriber()
+	for i := 0; i < 100; i++ {
+		delay, ok := limits.reserve(unlimited, now)
+		assert.True(t, ok)
+		assert.Equal(t, time.Duration(0), delay)
+	}
+
+	limited := newTestSubscriber(func(s *db.Subscriber) {
+		s.RateLimitPerSecond = 1
+		s.RateLimitBurst = 1
+	})
+	delay, _ := limits.reserve(limited, now)
+	assert.Equal(t, time.Duration(0), delay)
+	delay, _ = limits.reserve(limited, now)
+	assert.Equal(t, time.Second, delay)
+	_, ok := limits.reserve(limited, now)
+	assert.False(t, ok)
+
+	// A changed rate limit starts a new, full bucket
+	limited.RateLimitPerSecond = 10
+	delay, _ = limits.reserve(limited, now)
+	assert.Equal(t, time.Duration(0), delay)
+	delay, _ = limits.reserve(limited, now)
+	assert.Equal(t, 100*time.Millisecond, delay)
+}
+
+func TestProcessDeliveryTask_RateLimitHoldsTask(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.RateLimitPerSecond = 10
+		s.RateLimitBurst = 1
+	})
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	taskQueue := make(chan deliveryTask, 1)
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+	app.dispatcher = ds
+
+	first, second := newBatchedTask(subscriber), newBatchedTask(subscriber)
+	inflightWg.Add(2)
+	start := time.Now()
+	processDeliveryTask(app, first, getSemaphore, ds)
+	processDeliveryTask(app, second, getSemaphore, ds)
+
+	assert.Equal(t, int32(1), hits.Load(), "Only the first task fits the burst")
+	assert.Equal(t, 1, SubscriberQueuedTasks(app, subscriber.ID))
+
+	select {
+	case requeued := <-taskQueue:
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "Held task waits for its token")
+		assert.True(t, requeued.tokenReserved)
+		assert.Equal(t, 0, SubscriberQueuedTasks(app, subscriber.ID))
+
+		processDeliveryTask(app, requeued, getSemaphore, ds)
+		assert.Equal(t, int32(2), hits.Load(), "Requeued task is delivered without waiting again")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Held task was not requeued")
+	}
+	inflightWg.Wait()
+}
+
+func TestProcessDeliveryTask_RateLimitedProbeKeepsProbeSlot(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.RateLimitPerSecond = 10
+		s.RateLimitBurst = 1
+	})
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	taskQueue := make(chan deliveryTask, 1)
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+	ds.breakers = newBreakerRegistry(1, time.Minute)
+	app.dispatcher = ds
+
+	// Use up the burst, then open the breaker with its cooldown already over
+	ds.rateLimits.reserve(subscriber, time.Now())
+	ds.breakers.recordFailure(subscriber.ID.Bytes)
+	ds.breakers.breakers[subscriber.ID.Bytes].since = time.Now().Add(-time.Minute)
+
+	inflightWg.Add(1)
+	processDeliveryTask(app, newBatchedTask(subscriber), getSemaphore, ds)
+	assert.Equal(t, int32(0), hits.Load(), "Probe waits for the rate limit")
+	assert.Equal(t, BreakerHalfOpen, ds.breakers.state(subscriber.ID.Bytes))
+
+	select {
+	case requeued := <-taskQueue:
+		assert.True(t, requeued.probe)
+		processDeliveryTask(app, requeued, getSemaphore, ds)
+		assert.Equal(t, int32(1), hits.Load(), "Requeued probe is delivered rather than parked")
+		assert.Equal(t, BreakerClosed, ds.breakers.state(subscriber.ID.Bytes), "Probe outcome closes the breaker")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Held probe was not requeued")
+	}
+	inflightWg.Wait()
+}
+
+func TestProcessDeliveryTask_RateLimitParksBeyondBurst(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.RateLimitPerSecond = 1
+		s.RateLimitBurst = 1
+	})
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	shutdown := make(chan struct{})
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, nil, newEventRegistry())
+	ds.shutdown = shutdown
+	app.dispatcher = ds
+
+	// Use up the burst and reserve one token ahead
+	ds.rateLimits.reserve(subscriber, time.Now())
+	inflightWg.Add(1)
+	processDeliveryTask(app, newBatchedTask(subscriber), getSemaphore, ds)
+	assert.Equal(t, 1, SubscriberQueuedTasks(app, subscriber.ID), "Task within a burst is held")
+
+	// The next task would wait beyond a burst, so it is parked instead
+	parked := newBatchedTask(subscriber)
+	inflightWg.Add(1)
+	processDeliveryTask(app, parked, getSemaphore, ds)
+	assert.Equal(t, 1, SubscriberQueuedTasks(app, subscriber.ID), "Parked task is not held")
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == parked.event.ID && p.NextAttemptAt.Time.After(time.Now())
+	}))
+
+	close(shutdown)
+	inflightWg.Wait()
+}
+
+func TestHoldForRateLimit_ParksOnShutdown(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber()
+	task := newBatchedTask(subscriber)
+	task.attemptNum = 1
+
+	shutdown := make(chan struct{})
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, nil, newEventRegistry())
+	ds.shutdown = shutdown
+
+	inflightWg.Add(1)
+	ds.holdForRateLimit(app, task, time.Hour)
+	assert.Equal(t, 1, ds.rateLimits.queuedTasks(subscriber.ID.Bytes))
+	close(shutdown)
+
+	done := make(chan struct{})
+	go func() {
+		inflightWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Held task did not finish on shutdown")
+	}
+
+	assert.Equal(t, 0, ds.rateLimits.queuedTasks(subscriber.ID.Bytes))
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return p.EventID == task.event.ID &&
+			p.AttemptNum == 1 &&
+			p.NextAttemptAt.Time.After(time.Now().Add(59*time.Minute))
+	}))
+}
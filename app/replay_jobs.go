@@ -0,0 +1,402 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Replay job states.
+const (
+	ReplayJobRunning   = "running"
+	ReplayJobCompleted = "completed"
+	ReplayJobCancelled = "cancelled"
+	ReplayJobFailed    = "failed"
+)
+
+// Limits on how fast a replay job replays events, in events per second.
+const (
+	DefaultReplayRatePerSecond = 10
+	MaxReplayRatePerSecond     = 1000
+)
+
+const (
+	replayJobBatchSize        = 100
+	replayJobProgressInterval = time.Second
+)
+
+var (
+	// ErrReplayOutOfScope is returned by ReplayToSubscriber for subscribers
+	// outside the publishing API secret's scope.
+	ErrReplayOutOfScope = errors.New("subscriber is outside the publishing API secret's scope")
+	// ErrReplayNotSubscribed is returned by ReplayToSubscriber when none of
+	// the subscriber's subscriptions match the event.
+	ErrReplayNotSubscribed = errors.New("subscriber has no subscription matching the event")
+	// ErrReplayInFlight is returned by ReplayEvent and ReplayToSubscriber for
+	// events with deliveries in flight, or by ReplayToSubscriber scheduled for
+	// retry.
+	ErrReplayInFlight = errors.New("event has deliveries in flight")
+	// ErrReplayJobNotRunning is returned by CancelReplayJob for jobs that
+	// already finished.
+	ErrReplayJobNotRunning = errors.New("replay job is not running")
+
+	errReplayJobCancelled = errors.New("replay job cancelled")
+)
+
+// ReplayJobSpec selects the events a replay job replays, using the filters of
+// the events search, and where it replays them to.
+type ReplayJobSpec struct {
+	SubjectFilter   string // SQL LIKE pattern
+	StatusFilter    string
+	StartTimeFilter pgtype.Timestamptz
+	EndTimeFilter   pgtype.Timestamptz // defaults to the time the job starts
+	DataFilter      []byte             // JSON the event data must contain
+	TraceIDFilter   string
+	SubscriberID    pgtype.UUID // replay to this subscriber only; to all matching subscribers if unset
+	RatePerSecond   int32       // defaults to DefaultReplayRatePerSecond
+}
+
+// ValidateReplayJobSpec checks a replay job spec before it is started. A spec
+// must filter events somehow so that a bare request can't replay everything.
+func ValidateReplayJobSpec(spec ReplayJobSpec) error {
+	if spec.SubjectFilter == "" && spec.StatusFilter == "" && !spec.StartTimeFilter.Valid &&
+		!spec.EndTimeFilter.Valid && len(spec.DataFilter) == 0 && spec.TraceIDFilter == "" {
+		return errors.New("at least one event filter is required")
+	}
+	if spec.StartTimeFilter.Valid && spec.EndTimeFilter.Valid && !spec.StartTimeFilter.Time.Before(spec.EndTimeFilter.Time) {
+		return errors.New("start_time must be before end_time")
+	}
+	if spec.RatePerSecond < 0 || spec.RatePerSecond > MaxReplayRatePerSecond {
+		return fmt.Errorf("rate_per_second must be between 1 and %d", MaxReplayRatePerSecond)
+	}
+	return nil
+}
+
+// replayJobRegistry tracks the replay jobs running in this process, keyed by
+// job UUID bytes.
+type replayJobRegistry struct {
+	mu      sync.Mutex
+	cancels map[[16]byte]context.CancelCauseFunc
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func newReplayJobRegistry() *replayJobRegistry {
+	return &replayJobRegistry{cancels: make(map[[16]byte]context.CancelCauseFunc)}
+}
+
+// start runs a job in a goroutine with a context that is cancelled when the
+// job is cancelled or the registry stops. Returns false once stopped.
+func (r *replayJobRegistry) start(id [16]byte, run func(ctx context.Context)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return false
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	r.cancels[id] = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		run(ctx)
+		r.mu.Lock()
+		delete(r.cancels, id)
+		r.mu.Unlock()
+		cancel(nil)
+	}()
+	return true
+}
+
+// cancel asks a running job to stop. Returns false if it is not running here.
+func (r *replayJobRegistry) cancel(id [16]byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.cancels[id]
+	if ok {
+		cancel(errReplayJobCancelled)
+	}
+	return ok
+}
+
+// stop interrupts all running jobs and waits for them to save their progress.
+// Interrupted jobs stay running in the database and resume on the next start.
+func (r *replayJobRegistry) stop() {
+	r.mu.Lock()
+	r.stopped = true
+	for _, cancel := range r.cancels {
+		cancel(nil)
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// StartReplayJob records a replay job for the events matching spec and runs it
+// in the background. Events are replayed oldest first at no more than the
+// job's rate; the returned job holds the number of matching events.
+func StartReplayJob(ctx context.Context, slurpee *Application, spec ReplayJobSpec) (db.ReplayJob, error) {
+	ds := slurpee.dispatcher
+	if ds == nil {
+		return db.ReplayJob{}, ErrDispatcherNotRunning
+	}
+
+	if spec.SubscriberID.Valid {
+		if _, err := slurpee.DB.GetSubscriberByID(ctx, spec.SubscriberID); err != nil {
+			return db.ReplayJob{}, err
+		}
+	}
+	// Events published after the job starts are not replayed
+	if !spec.EndTimeFilter.Valid {
+		spec.EndTimeFilter = pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+	}
+	if spec.RatePerSecond == 0 {
+		spec.RatePerSecond = DefaultReplayRatePerSecond
+	}
+
+	total, err := slurpee.DB.CountEventsForReplay(ctx, db.CountEventsForReplayParams{
+		SubjectFilter:   spec.SubjectFilter,
+		StatusFilter:    spec.StatusFilter,
+		StartTimeFilter: spec.StartTimeFilter,
+		EndTimeFilter:   spec.EndTimeFilter,
+		DataFilter:      spec.DataFilter,
+		TraceIDFilter:   spec.TraceIDFilter,
+	})
+	if err != nil {
+		return db.ReplayJob{}, err
+	}
+
+	job, err := slurpee.DB.InsertReplayJob(ctx, db.InsertReplayJobParams{
+		ID:              pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true},
+		SubjectFilter:   spec.SubjectFilter,
+		StatusFilter:    spec.StatusFilter,
+		StartTimeFilter: spec.StartTimeFilter,
+		EndTimeFilter:   spec.EndTimeFilter,
+		DataFilter:      spec.DataFilter,
+		TraceIDFilter:   spec.TraceIDFilter,
+		SubscriberID:    spec.SubscriberID,
+		RatePerSecond:   spec.RatePerSecond,
+		Total:           int32(total),
+	})
+	if err != nil {
+		return db.ReplayJob{}, err
+	}
+
+	slog.Info("Starting replay job", "replay_job_id", UuidToString(job.ID), "events", total)
+	ds.replays.start(job.ID.Bytes, func(ctx context.Context) {
+		runReplayJob(ctx, slurpee, ds, job)
+	})
+	return job, nil
+}
+
+// CancelReplayJob stops a running replay job. The job records its progress and
+// moves to cancelled once the event it is replaying is done.
+func CancelReplayJob(slurpee *Application, id pgtype.UUID) error {
+	ds := slurpee.dispatcher
+	if ds == nil || !ds.replays.cancel(id.Bytes) {
+		return ErrReplayJobNotRunning
+	}
+	return nil
+}
+
+// ResumeReplayJobs restarts replay jobs interrupted by a shutdown from the
+// last event they recorded. Call this after StartDispatcher.
+func ResumeReplayJobs(slurpee *Application, ds *DispatcherState) {
+	jobs, err := slurpee.DB.ListRunningReplayJobs(context.Background())
+	if err != nil {
+		slog.Error("Failed to query running replay jobs", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		slog.Info("Resuming replay job", "replay_job_id", UuidToString(job.ID), "processed", job.Processed, "total", job.Total)
+		ds.replays.start(job.ID.Bytes, func(ctx context.Context) {
+			runReplayJob(ctx, slurpee, ds, job)
+		})
+	}
+}
+
+type replayOutcome int
+
+const (
+	replayDone replayOutcome = iota
+	replaySkipped
+	replayFailed
+	replayAborted // interrupted before the event was replayed
+)
+
+// runReplayJob replays the job's events from its cursor until none are left
+// or ctx is cancelled, saving progress along the way.
+func runReplayJob(ctx context.Context, slurpee *Application, ds *DispatcherState, job db.ReplayJob) {
+	logger := slog.Default().With("replay_job_id", UuidToString(job.ID))
+	progress := db.UpdateReplayJobProgressParams{
+		ID:              job.ID,
+		Processed:       job.Processed,
+		Replayed:        job.Replayed,
+		Skipped:         job.Skipped,
+		Failed:          job.Failed,
+		CursorTimestamp: job.CursorTimestamp,
+		CursorEventID:   job.CursorEventID,
+	}
+
+	var target *db.Subscriber
+	if job.SubscriberID.Valid {
+		subscriber, err := slurpee.SubscriptionCache.GetSubscriberByID(ctx, job.SubscriberID)
+		if err != nil {
+			logger.Error("Failed to load replay job subscriber", "error", err)
+			finishReplayJob(slurpee, job.ID, ReplayJobFailed, "subscriber not found", logger)
+			return
+		}
+		target = &subscriber
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(job.RatePerSecond))
+	defer ticker.Stop()
+	lastSaved := time.Now()
+
+	for ctx.Err() == nil {
+		events, err := slurpee.DB.ListEventsForReplay(ctx, db.ListEventsForReplayParams{
+			SubjectFilter:   job.SubjectFilter,
+			StatusFilter:    job.StatusFilter,
+			StartTimeFilter: job.StartTimeFilter,
+			EndTimeFilter:   job.EndTimeFilter,
+			DataFilter:      job.DataFilter,
+			TraceIDFilter:   job.TraceIDFilter,
+			AfterTimestamp:  progress.CursorTimestamp,
+			AfterEventID:    progress.CursorEventID,
+			RowLimit:        replayJobBatchSize,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Error("Failed to list events for replay job", "error", err)
+			saveReplayProgress(slurpee, progress, logger)
+			finishReplayJob(slurpee, job.ID, ReplayJobFailed, "failed to list events", logger)
+			return
+		}
+		if len(events) == 0 {
+			saveReplayProgress(slurpee, progress, logger)
+			finishReplayJob(slurpee, job.ID, ReplayJobCompleted, "", logger)
+			logger.Info("Replay job completed", "processed", progress.Processed,
+				"replayed", progress.Replayed, "skipped", progress.Skipped, "failed", progress.Failed)
+			return
+		}
+
+		for _, event := range events {
+			outcome := replayJobEvent(ctx, slurpee, ds, target, event, logger)
+			if outcome == replayAborted {
+				break
+			}
+			switch outcome {
+			case replayDone:
+				progress.Replayed++
+			case replaySkipped:
+				progress.Skipped++
+			case replayFailed:
+				progress.Failed++
+			}
+			progress.Processed++
+			progress.CursorTimestamp = event.Timestamp
+			progress.CursorEventID = event.ID
+			if time.Since(lastSaved) >= replayJobProgressInterval {
+				saveReplayProgress(slurpee, progress, logger)
+				lastSaved = time.Now()
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	saveReplayProgress(slurpee, progress, logger)
+	if errors.Is(context.Cause(ctx), errReplayJobCancelled) {
+		finishReplayJob(slurpee, job.ID, ReplayJobCancelled, "", logger)
+		logger.Info("Replay job cancelled", "processed", progress.Processed)
+		return
+	}
+	logger.Info("Replay job interrupted by shutdown", "processed", progress.Processed)
+}
+
+// replayJobEvent replays one event. Without a target subscriber the event is
+// dispatched again to all matching subscribers, unless its deliveries are
+// still in flight. With one, it is submitted for delivery to that subscriber
+// if subscribed and nothing is in flight.
+func replayJobEvent(ctx context.Context, slurpee *Application, ds *DispatcherState, target *db.Subscriber, event db.Event, logger *slog.Logger) replayOutcome {
+	if target == nil {
+		if err := ReplayEvent(ctx, slurpee, event); err != nil {
+			if errors.Is(err, ErrReplayInFlight) {
+				return replaySkipped
+			}
+			if ctx.Err() != nil {
+				return replayAborted
+			}
+			logger.Error("Failed to replay event", "error", err, "event_id", UuidToString(event.ID))
+			return replayFailed
+		}
+		return replayDone
+	}
+
+	switch err := ReplayToSubscriber(ctx, slurpee, event, *target); {
+	case err == nil:
+		return replayDone
+	case errors.Is(err, ErrReplayOutOfScope), errors.Is(err, ErrReplayNotSubscribed), errors.Is(err, ErrReplayInFlight):
+		return replaySkipped
+	case ctx.Err() != nil:
+		return replayAborted
+	default:
+		logger.Error("Failed to replay event", "error", err, "event_id", UuidToString(event.ID),
+			"subscriber_id", UuidToString(target.ID))
+		return replayFailed
+	}
+}
+
+// ReplayEvent dispatches an event again to all matching subscribers. Its
+// scheduled retries and dead letters are dropped first: the replay supersedes
+// them, and exhausted deliveries are dead-lettered again. ErrReplayInFlight is
+// returned if the event has deliveries in flight. An event that starts
+// delivering before the replay reaches the dispatcher is not dispatched again.
+func ReplayEvent(ctx context.Context, slurpee *Application, event db.Event) error {
+	if ds := slurpee.dispatcher; ds != nil && ds.registry.get(event.ID.Bytes) != nil {
+		return ErrReplayInFlight
+	}
+	// Drop any scheduled retries so they don't race the fresh dispatch
+	if err := slurpee.DB.DeleteDeliveryRetriesForEvent(ctx, event.ID); err != nil {
+		return err
+	}
+	if err := slurpee.DB.DeleteDeadLettersForEvent(ctx, event.ID); err != nil {
+		return err
+	}
+	select {
+	case slurpee.DeliveryChan <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func saveReplayProgress(slurpee *Application, progress db.UpdateReplayJobProgressParams, logger *slog.Logger) {
+	if err := slurpee.DB.UpdateReplayJobProgress(context.Background(), progress); err != nil {
+		logger.Error("Failed to save replay job progress", "error", err)
+	}
+}
+
+func finishReplayJob(slurpee *Application, id pgtype.UUID, status, lastError string, logger *slog.Logger) {
+	err := slurpee.DB.FinishReplayJob(context.Background(), db.FinishReplayJobParams{
+		ID:        id,
+		Status:    status,
+		LastError: lastError,
+	})
+	if err != nil {
+		logger.Error("Failed to finish replay job", "error", err, "status", status)
+	}
+}
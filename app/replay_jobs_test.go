@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func newTestReplayJob(opts ...func(*db.ReplayJob)) db.ReplayJob {
+	job := db.ReplayJob{
+		ID:            newTestUUID(),
+		SubjectFilter: "orders.%",
+		EndTimeFilter: newTestTimestamp(),
+		RatePerSecond: MaxReplayRatePerSecond,
+		Status:        ReplayJobRunning,
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+	return job
+}
+
+func newReplayTestDispatcherState() *DispatcherState {
+	var inflightWg sync.WaitGroup
+	return newTestDispatcherState(&inflightWg, make(chan deliveryTask, 10), newEventRegistry())
+}
+
+// waitForReplayJobs waits for the jobs running in ds to finish on their own.
+func waitForReplayJobs(t *testing.T, ds *DispatcherState) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		ds.replays.mu.Lock()
+		defer ds.replays.mu.Unlock()
+		return len(ds.replays.cancels) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRunReplayJob_ReplaysMatchingEvents(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+	app.dispatcher = ds
+
+	job := newTestReplayJob()
+	first := newTestEvent()
+	second := newTestEvent()
+	inFlight := newTestEvent()
+	ds.registry.register(inFlight.ID.Bytes, &eventTracker{event: inFlight})
+
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.MatchedBy(func(p db.ListEventsForReplayParams) bool {
+		return p.SubjectFilter == "orders.%" && !p.AfterEventID.Valid
+	})).Return([]db.Event{first, inFlight, second}, nil).Once()
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.MatchedBy(func(p db.ListEventsForReplayParams) bool {
+		return p.AfterEventID == second.ID && p.AfterTimestamp == second.Timestamp
+	})).Return([]db.Event{}, nil).Once()
+	mockDB.On("DeleteDeliveryRetriesForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("DeleteDeadLettersForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, mock.AnythingOfType("db.UpdateReplayJobProgressParams")).
+		Return(nil)
+	mockDB.On("FinishReplayJob", mock.Anything, db.FinishReplayJobParams{ID: job.ID, Status: ReplayJobCompleted}).
+		Return(nil)
+
+	runReplayJob(context.Background(), app, ds, job)
+
+	require.Equal(t, 2, len(app.DeliveryChan))
+	assert.Equal(t, first.ID, (<-app.DeliveryChan).ID)
+	assert.Equal(t, second.ID, (<-app.DeliveryChan).ID)
+	mockDB.AssertCalled(t, "UpdateReplayJobProgress", mock.Anything, db.UpdateReplayJobProgressParams{
+		ID:              job.ID,
+		Processed:       3,
+		Replayed:        2,
+		Skipped:         1,
+		CursorTimestamp: second.Timestamp,
+		CursorEventID:   second.ID,
+	})
+	mockDB.AssertNotCalled(t, "DeleteDeliveryRetriesForEvent", mock.Anything, inFlight.ID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestRunReplayJob_TargetSubscriber(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	ds := newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry())
+	app.dispatcher = ds
+
+	subscriber := newTestSubscriber()
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "orders.created"
+	})
+	job := newTestReplayJob(func(j *db.ReplayJob) { j.SubscriberID = subscriber.ID })
+	subscribed := newTestEvent(func(e *db.Event) { e.Subject = "orders.created" })
+	notSubscribed := newTestEvent(func(e *db.Event) { e.Subject = "orders.shipped" })
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.MatchedBy(func(p db.ListEventsForReplayParams) bool {
+		return !p.AfterEventID.Valid
+	})).Return([]db.Event{subscribed, notSubscribed}, nil).Once()
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.AnythingOfType("db.ListEventsForReplayParams")).
+		Return([]db.Event{}, nil).Once()
+	mockDB.On("ListDeliveryRetriesForEvent", mock.Anything, subscribed.ID).
+		Return([]db.DeliveryRetry{}, nil)
+	mockDB.On("DeleteDeadLetterForSubscriber", mock.Anything, mock.AnythingOfType("db.DeleteDeadLetterForSubscriberParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+	mockDB.On("UpsertDelivery", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryParams")).
+		Return(nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, mock.AnythingOfType("db.UpdateReplayJobProgressParams")).
+		Return(nil)
+	mockDB.On("FinishReplayJob", mock.Anything, db.FinishReplayJobParams{ID: job.ID, Status: ReplayJobCompleted}).
+		Return(nil)
+
+	runReplayJob(context.Background(), app, ds, job)
+
+	if assert.Equal(t, 1, len(taskQueue), "Only the subscribed event is replayed") {
+		task := <-taskQueue
+		assert.Equal(t, subscribed.ID, task.event.ID)
+		assert.Equal(t, subscriber.ID, task.subscriber.ID)
+	}
+	assert.Equal(t, 0, len(app.DeliveryChan), "Targeted replays bypass the dispatch of other subscribers")
+	mockDB.AssertCalled(t, "UpdateReplayJobProgress", mock.Anything, mock.MatchedBy(func(p db.UpdateReplayJobProgressParams) bool {
+		return p.Processed == 2 && p.Replayed == 1 && p.Skipped == 1 && p.Failed == 0
+	}))
+}
+
+func TestRunReplayJob_MissingSubscriberFails(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	job := newTestReplayJob(func(j *db.ReplayJob) { j.SubscriberID = newTestUUID() })
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("FinishReplayJob", mock.Anything, db.FinishReplayJobParams{
+		ID:        job.ID,
+		Status:    ReplayJobFailed,
+		LastError: "subscriber not found",
+	}).Return(nil)
+
+	runReplayJob(context.Background(), app, newReplayTestDispatcherState(), job)
+
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "ListEventsForReplay", mock.Anything, mock.Anything)
+}
+
+func TestCancelReplayJob_StopsJob(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+	app.dispatcher = ds
+
+	// One event per second keeps the job waiting after its first event
+	job := newTestReplayJob(func(j *db.ReplayJob) { j.RatePerSecond = 1 })
+	events := []db.Event{newTestEvent(), newTestEvent()}
+
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.AnythingOfType("db.ListEventsForReplayParams")).
+		Return(events, nil)
+	mockDB.On("DeleteDeliveryRetriesForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("DeleteDeadLettersForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, mock.AnythingOfType("db.UpdateReplayJobProgressParams")).
+		Return(nil)
+	mockDB.On("FinishReplayJob", mock.Anything, db.FinishReplayJobParams{ID: job.ID, Status: ReplayJobCancelled}).
+		Return(nil)
+
+	ds.replays.start(job.ID.Bytes, func(ctx context.Context) {
+		runReplayJob(ctx, app, ds, job)
+	})
+	require.Eventually(t, func() bool { return len(app.DeliveryChan) == 1 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, CancelReplayJob(app, job.ID))
+	ds.replays.stop()
+
+	assert.Equal(t, 1, len(app.DeliveryChan))
+	mockDB.AssertCalled(t, "UpdateReplayJobProgress", mock.Anything, mock.MatchedBy(func(p db.UpdateReplayJobProgressParams) bool {
+		return p.Processed == 1 && p.CursorEventID == events[0].ID
+	}))
+	mockDB.AssertExpectations(t)
+	assert.ErrorIs(t, CancelReplayJob(app, job.ID), ErrReplayJobNotRunning)
+}
+
+func TestReplayJobRegistry_StopLeavesJobRunning(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+
+	job := newTestReplayJob(func(j *db.ReplayJob) { j.RatePerSecond = 1 })
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.AnythingOfType("db.ListEventsForReplayParams")).
+		Return([]db.Event{newTestEvent(), newTestEvent()}, nil)
+	mockDB.On("DeleteDeliveryRetriesForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("DeleteDeadLettersForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, mock.AnythingOfType("db.UpdateReplayJobProgressParams")).
+		Return(nil)
+
+	ds.replays.start(job.ID.Bytes, func(ctx context.Context) {
+		runReplayJob(ctx, app, ds, job)
+	})
+	require.Eventually(t, func() bool { return len(app.DeliveryChan) == 1 }, time.Second, 5*time.Millisecond)
+	ds.replays.stop()
+
+	mockDB.AssertCalled(t, "UpdateReplayJobProgress", mock.Anything, mock.MatchedBy(func(p db.UpdateReplayJobProgressParams) bool {
+		return p.Processed == 1
+	}))
+	mockDB.AssertNotCalled(t, "FinishReplayJob", mock.Anything, mock.Anything)
+	assert.False(t, ds.replays.start(newTestUUID().Bytes, func(context.Context) {}), "No jobs start once stopped")
+}
+
+func TestResumeReplayJobs_ContinuesFromCursor(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+
+	cursor := newTestEvent()
+	job := newTestReplayJob(func(j *db.ReplayJob) {
+		j.Total = 5
+		j.Processed = 3
+		j.Replayed = 3
+		j.CursorTimestamp = cursor.Timestamp
+		j.CursorEventID = cursor.ID
+	})
+
+	mockDB.On("ListRunningReplayJobs", mock.Anything).
+		Return([]db.ReplayJob{job}, nil)
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.MatchedBy(func(p db.ListEventsForReplayParams) bool {
+		return p.AfterTimestamp == cursor.Timestamp && p.AfterEventID == cursor.ID
+	})).Return([]db.Event{}, nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, db.UpdateReplayJobProgressParams{
+		ID:              job.ID,
+		Processed:       3,
+		Replayed:        3,
+		CursorTimestamp: cursor.Timestamp,
+		CursorEventID:   cursor.ID,
+	}).Return(nil)
+	mockDB.On("FinishReplayJob", mock.Anything, db.FinishReplayJobParams{ID: job.ID, Status: ReplayJobCompleted}).
+		Return(nil)
+
+	ResumeReplayJobs(app, ds)
+	waitForReplayJobs(t, ds)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestStartReplayJob_DispatcherNotRunning(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	_, err := StartReplayJob(context.Background(), app, ReplayJobSpec{SubjectFilter: "orders.%"})
+	assert.ErrorIs(t, err, ErrDispatcherNotRunning)
+}
+
+func TestStartReplayJob_RecordsJob(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+	app.dispatcher = ds
+
+	start := time.Now().UTC()
+	mockDB.On("CountEventsForReplay", mock.Anything, mock.MatchedBy(func(p db.CountEventsForReplayParams) bool {
+		return p.StatusFilter == "failed" && !p.EndTimeFilter.Time.Before(start)
+	})).Return(int64(42), nil)
+	mockDB.On("InsertReplayJob", mock.Anything, mock.MatchedBy(func(p db.InsertReplayJobParams) bool {
+		return p.ID.Valid &&
+			p.StatusFilter == "failed" &&
+			p.Total == 42 &&
+			p.RatePerSecond == DefaultReplayRatePerSecond &&
+			!p.EndTimeFilter.Time.Before(start)
+	})).Return(db.ReplayJob{ID: newTestUUID(), RatePerSecond: DefaultReplayRatePerSecond, Total: 42}, nil)
+	mockDB.On("ListEventsForReplay", mock.Anything, mock.AnythingOfType("db.ListEventsForReplayParams")).
+		Return([]db.Event{}, nil)
+	mockDB.On("UpdateReplayJobProgress", mock.Anything, mock.AnythingOfType("db.UpdateReplayJobProgressParams")).
+		Return(nil)
+	mockDB.On("FinishReplayJob", mock.Anything, mock.AnythingOfType("db.FinishReplayJobParams")).
+		Return(nil)
+
+	job, err := StartReplayJob(context.Background(), app, ReplayJobSpec{StatusFilter: "failed"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), job.Total)
+
+	waitForReplayJobs(t, ds)
+	mockDB.AssertCalled(t, "FinishReplayJob", mock.Anything, db.FinishReplayJobParams{ID: job.ID, Status: ReplayJobCompleted})
+}
+
+func TestReplayEvent_ClearsRetriesAndDeadLetters(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	event := newTestEvent()
+	mockDB.On("DeleteDeliveryRetriesForEvent", mock.Anything, event.ID).
+		Return(nil)
+	mockDB.On("DeleteDeadLettersForEvent", mock.Anything, event.ID).
+		Return(nil)
+
+	require.NoError(t, ReplayEvent(context.Background(), app, event))
+	assert.Equal(t, event.ID, (<-app.DeliveryChan).ID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestReplayEvent_InFlight(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	ds := newReplayTestDispatcherState()
+	app.dispatcher = ds
+
+	event := newTestEvent()
+	ds.registry.register(event.ID.Bytes, &eventTracker{event: event})
+
+	assert.ErrorIs(t, ReplayEvent(context.Background(), app, event), ErrReplayInFlight)
+	assert.Empty(t, app.DeliveryChan)
+	mockDB.AssertNotCalled(t, "DeleteDeliveryRetriesForEvent", mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "DeleteDeadLettersForEvent", mock.Anything, mock.Anything)
+}
+
+func TestReplayEvent_GivesUpWhenCancelled(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	app.DeliveryChan = make(chan db.Event) // nothing reads it
+
+	mockDB.On("DeleteDeliveryRetriesForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+	mockDB.On("DeleteDeadLettersForEvent", mock.Anything, mock.Anything).
+		Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, ReplayEvent(ctx, app, newTestEvent()), context.DeadlineExceeded)
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// DefaultNonRetryableStatuses are the response codes that fail a delivery
+// without retrying when a subscriber does not configure its own list.
+var DefaultNonRetryableStatuses = []int32{http.StatusBadRequest, http.StatusNotFound, http.StatusGone}
+
+// ValidNonRetryableStatus reports whether code may be configured as a
+// non-retryable response code. Only 4xx codes qualify; 408 and 429 always
+// ask the client to try again.
+func ValidNonRetryableStatus(code int32) bool {
+	return code >= 400 && code <= 499 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// ParseStatusList parses a comma-separated list of non-retryable response
+// codes, as entered in the web UI.
+func ParseStatusList(s string) ([]int32, error) {
+	codes := []int32{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, err := strconv.ParseInt(part, 10, 32)
+		if err != nil || !ValidNonRetryableStatus(int32(code)) {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		if !slices.Contains(codes, int32(code)) {
+			codes = append(codes, int32(code))
+		}
+	}
+	slices.Sort(codes)
+	return codes, nil
+}
+
+// isNonRetryableStatus reports whether a failed response should fail the
+// delivery immediately instead of scheduling a retry.
+func isNonRetryableStatus(subscriber db.Subscriber, statusCode int) bool {
+	return slices.Contains(subscriber.NonRetryableStatuses, int32(statusCode))
+}
+
+// retryAfterDelay returns the delay requested by a 429 or 503 response's
+// Retry-After header, given either as seconds or as an HTTP date. Returns 0
+// when the response carries no usable Retry-After.
+func retryAfterDelay(resp *http.Response, now time.Time) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryDelay returns how long to wait before the next attempt of a failed
+// delivery: the endpoint's Retry-After when it sent one, capped at
+// MAX_RETRY_AFTER_SECONDS, or the subscription's retry policy otherwise.
+func retryDelay(slurpee *Application, sub db.Subscription, attemptNum int, outcome deliveryOutcome) time.Duration {
+	if outcome.retryAfter > 0 {
+		maxDelay := time.Duration(slurpee.Config.MaxRetryAfterSeconds) * time.Second
+		if maxDelay > 0 && outcome.retryAfter > maxDelay {
+			return maxDelay
+		}
+		return outcome.retryAfter
+	}
+	return backoffDelay(sub, attemptNum, slurpee.Config.MaxBackoffSeconds)
+}
+
+// disableGoneSubscriber disables a subscriber that opted in to being disabled
+// when its endpoint responds 410 Gone. The subscription cache is flushed so
+// new events skip the subscriber, and a bus message is published.
+func disableGoneSubscriber(ctx context.Context, slurpee *Application, subscriber db.Subscriber, logger *slog.Logger) {
+	err := slurpee.DB.DisableSubscriber(ctx, db.DisableSubscriberParams{
+		ID:             subscriber.ID,
+		DisabledReason: "Endpoint responded 410 Gone",
+	})
+	if err != nil {
+		logger.Error("Failed to disable subscriber", "error", err, "subscriber_id", UuidToString(subscriber.ID))
+		return
+	}
+	slurpee.SubscriptionCache.Flush()
+	logger.Warn("Subscriber disabled after 410 Gone",
+		"subscriber_id", UuidToString(subscriber.ID),
+		"endpoint_url", subscriber.EndpointUrl,
+	)
+	slurpee.EventBus.Publish(BusMessage{
+		Type:               BusMessageSubscriberDisabled,
+		SubscriberID:       UuidToString(subscriber.ID),
+		SubscriberEndpoint: subscriber.EndpointUrl,
+	})
+}
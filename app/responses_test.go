@@ -0,0 +1,281 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       time.Duration
+	}{
+		{"seconds on 429", http.StatusTooManyRequests, "120", 2 * time.Minute},
+		{"seconds on 503", http.StatusServiceUnavailable, "5", 5 * time.Second},
+		{"http date", http.StatusServiceUnavailable, now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"date in the past", http.StatusTooManyRequests, now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"ignored on 500", http.StatusInternalServerError, "120", 0},
+		{"missing header", http.StatusTooManyRequests, "", 0},
+		{"invalid value", http.StatusTooManyRequests, "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			assert.Equal(t, tt.want, retryAfterDelay(resp, now))
+		})
+	}
+}
+
+func TestParseStatusList(t *testing.T) {
+	codes, err := ParseStatusList(" 410, 400,404,400 ")
+	require.NoError(t, err)
+	assert.Equal(t, []int32{400, 404, 410}, codes)
+
+	codes, err = ParseStatusList("")
+	require.NoError(t, err)
+	assert.Equal(t, []int32{}, codes, "An empty list retries every failure")
+
+	for _, invalid := range []string{"500", "429", "408", "abc"} {
+		_, err = ParseStatusList(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// runSingleDelivery delivers one task to a subscriber through processDeliveryTask.
+func runSingleDelivery(app *Application, subscriber db.Subscriber) deliveryTask {
+	task := newBatchedTask(subscriber)
+	runTask(app, task)
+	return task
+}
+
+// runTask runs a single task through processDeliveryTask with a fresh dispatcher state.
+func runTask(app *Application, task deliveryTask) {
+	registry := newEventRegistry()
+	registry.register(task.event.ID.Bytes, task.tracker)
+	var inflightWg sync.WaitGroup
+	ds := newTestDispatcherState(&inflightWg, nil, registry)
+	getSemaphore := func(id [16]byte, maxParallel int32) chan struct{} {
+		return make(chan struct{}, maxParallel)
+	}
+	inflightWg.Add(1)
+	processDeliveryTask(app, task, getSemaphore, ds)
+}
+
+func TestProcessDeliveryTask_RetryAfterOverridesBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		delay := time.Until(p.NextAttemptAt.Time)
+		return p.AttemptNum == 1 && delay > 110*time.Second && delay <= 120*time.Second
+	}))
+}
+
+func TestProcessDeliveryTask_RetryAfterIsCapped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	app.Config.MaxRetryAfterSeconds = 600
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		return time.Until(p.NextAttemptAt.Time) <= 600*time.Second
+	}))
+}
+
+func TestProcessDeliveryTask_NonRetryableStatusSkipsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+	mockDB.AssertCalled(t, "UpsertDeadLetter", mock.Anything, mock.MatchedBy(func(p db.UpsertDeadLetterParams) bool {
+		return p.EventID == task.event.ID && p.Attempts == 1 && p.LastError == "received HTTP 404"
+	}))
+	mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.ID == task.event.ID && p.DeliveryStatus == "failed"
+	}))
+}
+
+func TestProcessDeliveryTask_ConfiguredStatusesOverrideDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.NonRetryableStatuses = []int32{http.StatusBadRequest}
+	}))
+
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams"))
+	mockDB.AssertNotCalled(t, "UpsertDeadLetter", mock.Anything, mock.Anything)
+}
+
+func TestProcessDeliveryTask_GoneDisablesSubscriber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	ch, unsubscribe := app.EventBus.Subscribe()
+	defer unsubscribe()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.DisableOnGone = true
+	})
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("DisableSubscriber", mock.Anything, mock.MatchedBy(func(p db.DisableSubscriberParams) bool {
+		return p.ID == subscriber.ID
+	})).Return(nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, subscriber)
+
+	mockDB.AssertCalled(t, "DisableSubscriber", mock.Anything, mock.AnythingOfType("db.DisableSubscriberParams"))
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+
+	var disabled bool
+	for len(ch) > 0 {
+		if msg := <-ch; msg.Type == BusMessageSubscriberDisabled {
+			disabled = msg.SubscriberID == UuidToString(subscriber.ID)
+		}
+	}
+	assert.True(t, disabled, "A subscriber_disabled message should be published")
+}
+
+func TestProcessDeliveryTask_GoneWithoutOptInKeepsSubscriber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	runSingleDelivery(app, newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+
+	mockDB.AssertNotCalled(t, "DisableSubscriber", mock.Anything, mock.Anything)
+}
+
+func TestDispatchEvent_SkipsDisabledSubscriber(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.DisabledAt = newTestTimestamp()
+	})
+	subscription := newTestSubscription(func(s *db.Subscription) {
+		s.SubscriberID = subscriber.ID
+		s.SubjectPattern = "test.subject"
+	})
+	event := newTestEvent()
+
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{subscriber}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{subscription}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	var inflightWg sync.WaitGroup
+	taskQueue := make(chan deliveryTask, 10)
+	dispatchEvent(app, event, newTestDispatcherState(&inflightWg, taskQueue, newEventRegistry()))
+
+	assert.Equal(t, 0, len(taskQueue))
+	mockDB.AssertCalled(t, "UpdateEventDeliveryStatus", mock.Anything, mock.MatchedBy(func(p db.UpdateEventDeliveryStatusParams) bool {
+		return p.ID == event.ID && p.DeliveryStatus == "recorded"
+	}))
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Retry policies control how a subscription spaces out retries of failed deliveries.
+const (
+	// RetryPolicyExponential doubles the delay each attempt: interval, 2×interval, 4×interval, ...
+	RetryPolicyExponential = "exponential"
+	// RetryPolicyExponentialFullJitter picks a random delay between 0 and the exponential delay.
+	RetryPolicyExponentialFullJitter = "exponential-full-jitter"
+	// RetryPolicyExponentialEqualJitter keeps half the exponential delay and randomizes the other half.
+	RetryPolicyExponentialEqualJitter = "exponential-equal-jitter"
+	// RetryPolicyLinear grows the delay by one interval each attempt: interval, 2×interval, 3×interval, ...
+	RetryPolicyLinear = "linear"
+	// RetryPolicyFixed waits the same interval before every retry.
+	RetryPolicyFixed = "fixed"
+	// RetryPolicySchedule takes each delay from the subscription's retry schedule,
+	// repeating the last entry once the schedule runs out.
+	RetryPolicySchedule = "schedule"
+)
+
+// Limits on subscription retry settings.
+const (
+	MaxRetryIntervalSeconds = 86400
+	MaxRetryScheduleLength  = 100
+)
+
+// ValidRetryPolicy reports whether name is a supported retry policy.
+func ValidRetryPolicy(name string) bool {
+	switch name {
+	case RetryPolicyExponential, RetryPolicyExponentialFullJitter, RetryPolicyExponentialEqualJitter,
+		RetryPolicyLinear, RetryPolicyFixed, RetryPolicySchedule:
+		return true
+	}
+	return false
+}
+
+// ValidRetryInterval reports whether seconds is usable as a retry interval or
+// retry schedule entry.
+func ValidRetryInterval(seconds int32) bool {
+	return seconds >= 1 && seconds <= MaxRetryIntervalSeconds
+}
+
+// ParseRetrySchedule parses a comma-separated list of retry delays in seconds,
+// as entered in the web UI.
+func ParseRetrySchedule(s string) ([]int32, error) {
+	schedule := []int32{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		seconds, err := strconv.ParseInt(part, 10, 32)
+		if err != nil || !ValidRetryInterval(int32(seconds)) {
+			return nil, fmt.Errorf("invalid retry delay %q", part)
+		}
+		schedule = append(schedule, int32(seconds))
+	}
+	if len(schedule) > MaxRetryScheduleLength {
+		return nil, fmt.Errorf("retry schedule has more than %d entries", MaxRetryScheduleLength)
+	}
+	return schedule, nil
+}
+
+// backoffDelay returns the delay before the retry that follows attempt
+// attemptNum (0-indexed) under the subscription's retry policy. The
+// exponential and linear policies are capped at maxBackoffSeconds; fixed
+// intervals and schedules are used as configured.
+func backoffDelay(sub db.Subscription, attemptNum int, maxBackoffSeconds int) time.Duration {
+	interval := max(sub.RetryIntervalSeconds, 1)
+	switch sub.RetryPolicy {
+	case RetryPolicyFixed:
+		return time.Duration(interval) * time.Second
+	case RetryPolicyLinear:
+		delaySeconds := min(int64(interval)*int64(attemptNum+1), int64(maxBackoffSeconds))
+		return time.Duration(delaySeconds) * time.Second
+	case RetryPolicySchedule:
+		if len(sub.RetrySchedule) == 0 {
+			return time.Duration(interval) * time.Second
+		}
+		seconds := sub.RetrySchedule[min(attemptNum, len(sub.RetrySchedule)-1)]
+		return time.Duration(seconds) * time.Second
+	case RetryPolicyExponentialFullJitter:
+		delay := exponentialBackoff(interval, attemptNum, maxBackoffSeconds)
+		return time.Duration(rand.Int64N(int64(delay) + 1))
+	case RetryPolicyExponentialEqualJitter:
+		delay := exponentialBackoff(interval, attemptNum, maxBackoffSeconds)
+		half := delay / 2
+		return half + time.Duration(rand.Int64N(int64(delay-half)+1))
+	default:
+		return exponentialBackoff(interval, attemptNum, maxBackoffSeconds)
+	}
+}
+
+// exponentialBackoff returns intervalSeconds × 2^attemptNum, capped at maxBackoffSeconds.
+func exponentialBackoff(intervalSeconds int32, attemptNum int, maxBackoffSeconds int) time.Duration {
+	delaySeconds := float64(intervalSeconds) * math.Pow(2, float64(attemptNum))
+	if delaySeconds > float64(maxBackoffSeconds) {
+		delaySeconds = float64(maxBackoffSeconds)
+	}
+	return time.Duration(delaySeconds) * time.Second
+}
+
+// retryAgeExceeded reports whether a retry due at nextAttemptAt would fall
+// outside the subscription's maximum retry age, measured from the first
+// delivery attempt.
+func retryAgeExceeded(sub db.Subscription, firstAttemptAt, nextAttemptAt time.Time) bool {
+	if !sub.RetryMaxAgeSeconds.Valid {
+		return false
+	}
+	maxAge := time.Duration(sub.RetryMaxAgeSeconds.Int32) * time.Second
+	return nextAttemptAt.Sub(firstAttemptAt) > maxAge
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		interval   int32
+		schedule   []int32
+		attemptNum int
+		expected   time.Duration
+	}{
+		{"exponential from interval", RetryPolicyExponential, 3, nil, 2, 12 * time.Second},
+		{"exponential respects max cap", RetryPolicyExponential, 1, nil, 20, 300 * time.Second},
+		{"unset policy is exponential", "", 1, nil, 3, 8 * time.Second},
+		{"linear", RetryPolicyLinear, 10, nil, 2, 30 * time.Second},
+		{"linear respects max cap", RetryPolicyLinear, 100, nil, 5, 300 * time.Second},
+		{"fixed", RetryPolicyFixed, 45, nil, 7, 45 * time.Second},
+		{"fixed ignores max cap", RetryPolicyFixed, 600, nil, 0, 600 * time.Second},
+		{"schedule entry", RetryPolicySchedule, 1, []int32{5, 30, 600}, 1, 30 * time.Second},
+		{"schedule repeats last entry", RetryPolicySchedule, 1, []int32{5, 30, 600}, 9, 600 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubscription(func(s *db.Subscription) {
+				s.RetryPolicy = tt.policy
+				s.RetryIntervalSeconds = tt.interval
+				s.RetrySchedule = tt.schedule
+			})
+			assert.Equal(t, tt.expected, backoffDelay(sub, tt.attemptNum, 300))
+		})
+	}
+}
+
+func TestBackoffDelay_Jitter(t *testing.T) {
+	full := newTestSubscription(func(s *db.Subscription) { s.RetryPolicy = RetryPolicyExponentialFullJitter })
+	equal := newTestSubscription(func(s *db.Subscription) { s.RetryPolicy = RetryPolicyExponentialEqualJitter })
+
+	// Attempt 4 has an exponential delay of 16s
+	fullDelays := make(map[time.Duration]bool)
+	for i := 0; i < 200; i++ {
+		delay := backoffDelay(full, 4, 300)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 16*time.Second)
+		fullDelays[delay] = true
+
+		delay = backoffDelay(equal, 4, 300)
+		assert.GreaterOrEqual(t, delay, 8*time.Second)
+		assert.LessOrEqual(t, delay, 16*time.Second)
+	}
+	assert.Greater(t, len(fullDelays), 1, "Jittered delays should vary")
+
+	// The jittered delay stays within the max backoff cap
+	for i := 0; i < 50; i++ {
+		assert.LessOrEqual(t, backoffDelay(full, 20, 60), 60*time.Second)
+	}
+}
+
+func TestRetryAgeExceeded(t *testing.T) {
+	start := time.Now()
+	unlimited := newTestSubscription()
+	limited := newTestSubscription(func(s *db.Subscription) {
+		s.RetryMaxAgeSeconds = pgtype.Int4{Int32: 60, Valid: true}
+	})
+
+	assert.False(t, retryAgeExceeded(unlimited, start, start.Add(24*time.Hour)))
+	assert.False(t, retryAgeExceeded(limited, start, start.Add(60*time.Second)))
+	assert.True(t, retryAgeExceeded(limited, start, start.Add(61*time.Second)))
+}
+
+func TestParseRetrySchedule(t *testing.T) {
+	schedule, err := ParseRetrySchedule(" 5, 30,300 ,5")
+	require.NoError(t, err)
+	assert.Equal(t, []int32{5, 30, 300, 5}, schedule, "Schedule keeps its order and repeats")
+
+	schedule, err = ParseRetrySchedule("")
+	require.NoError(t, err)
+	assert.Equal(t, []int32{}, schedule)
+
+	for _, invalid := range []string{"0", "86401", "-5", "soon"} {
+		_, err = ParseRetrySchedule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestProcessDeliveryTask_UsesSubscriptionRetryPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	task := newBatchedTask(subscriber)
+	task.subscription.RetryPolicy = RetryPolicyFixed
+	task.subscription.RetryIntervalSeconds = 90
+	runTask(app, task)
+
+	mockDB.AssertCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.MatchedBy(func(p db.UpsertDeliveryRetryParams) bool {
+		delay := time.Until(p.NextAttemptAt.Time)
+		return delay > 80*time.Second && delay <= 90*time.Second && p.FirstAttemptAt.Valid
+	}))
+}
+
+func TestProcessDeliveryTask_MaxRetryAgeDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeadLetter", mock.Anything, mock.AnythingOfType("db.UpsertDeadLetterParams")).
+		Return(db.DeadLetter{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	task := newBatchedTask(subscriber)
+	task.subscription.RetryPolicy = RetryPolicyFixed
+	task.subscription.RetryIntervalSeconds = 60
+	task.subscription.RetryMaxAgeSeconds = pgtype.Int4{Int32: 300, Valid: true}
+	task.attemptNum = 1
+	task.firstAttemptAt = time.Now().Add(-250 * time.Second)
+	runTask(app, task)
+
+	mockDB.AssertNotCalled(t, "UpsertDeliveryRetry", mock.Anything, mock.Anything)
+	mockDB.AssertCalled(t, "UpsertDeadLetter", mock.Anything, mock.MatchedBy(func(p db.UpsertDeadLetterParams) bool {
+		return p.EventID == task.event.ID && p.Attempts == 2
+	}))
+}
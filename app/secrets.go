@@ -66,15 +66,34 @@ func CheckSubscriberScope(ctx context.Context, queries db.Querier, secretID pgty
 	})
 }
 
-// CheckSendScope returns true if the subject matches the secret's subject_pattern
-// using SQL LIKE semantics (% = any sequence, _ = single char).
-func CheckSendScope(subjectPattern, subject string) bool {
-	return MatchLikePattern(subjectPattern, subject)
+// EventSubscriberScope returns the set of subscriber IDs (keyed by UUID bytes)
+// allowed to receive the event: the subscribers associated with the API secret
+// that published it, as they were when it was published. A nil map means the
+// event is unscoped: it was not published with a secret, or the secret had no
+// subscriber associations. The scope is stored on the event, so deleting the
+// secret or changing its subscribers later does not change it.
+func EventSubscriberScope(event db.Event) map[[16]byte]bool {
+	if len(event.SubscriberScope) == 0 {
+		return nil
+	}
+	scope := make(map[[16]byte]bool, len(event.SubscriberScope))
+	for _, id := range event.SubscriberScope {
+		scope[id.Bytes] = true
+	}
+	return scope
+}
+
+// CheckSendScope returns true if the subject matches the secret's
+// subject_pattern in the secret's pattern syntax.
+func CheckSendScope(patternSyntax, subjectPattern, subject string) bool {
+	return MatchSubjectPattern(patternSyntax, subjectPattern, subject)
 }
 
 // MatchLikePattern implements glob-style matching in Go.
-// * matches any sequence of characters (including empty).
-// _ matches exactly one character.
+// * or % matches any sequence of characters (including empty).
+// _ or ? matches exactly one character.
+// A backslash matches the character after it literally, so \% matches a
+// percent sign; a trailing backslash matches itself.
 func MatchLikePattern(pattern, value string) bool {
 	return matchLike(pattern, 0, value, 0)
 }
@@ -82,9 +101,9 @@ func MatchLikePattern(pattern, value string) bool {
 func matchLike(pattern string, pi int, value string, vi int) bool {
 	for pi < len(pattern) {
 		switch pattern[pi] {
-		case '*':
-			// Skip consecutive * characters
-			for pi < len(pattern) && pattern[pi] == '*' {
+		case '*', '%':
+			// Skip consecutive wildcards
+			for pi < len(pattern) && (pattern[pi] == '*' || pattern[pi] == '%') {
 				pi++
 			}
 			if pi == len(pattern) {
@@ -98,12 +117,21 @@ func matchLike(pattern string, pi int, value string, vi int) bool {
 				vi++
 			}
 			return false
-		case '_':
+		case '_', '?':
 			if vi >= len(value) {
 				return false
 			}
 			pi++
 			vi++
+		case '\\':
+			if pi+1 < len(pattern) {
+				pi++
+			}
+			if vi >= len(value) || pattern[pi] != value[vi] {
+				return false
+			}
+			pi++
+			vi++
 		default:
 			if vi >= len(value) || pattern[pi] != value[vi] {
 				return false
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+	"github.com/sweater-ventures/slurpee/webhook"
+)
+
+// Subscriber signing modes control how deliveries are authenticated.
+const (
+	// SigningModeSecret sends the subscriber's auth_secret verbatim in X-Slurpee-Secret.
+	SigningModeSecret = "secret"
+	// SigningModeHMAC sends an HMAC-SHA256 signature in X-Slurpee-Signature instead.
+	SigningModeHMAC = "hmac"
+)
+
+const redactedHeaderValue = "[REDACTED]"
+
+// ValidSigningMode reports whether mode is a supported subscriber signing mode.
+func ValidSigningMode(mode string) bool {
+	return mode == SigningModeSecret || mode == SigningModeHMAC
+}
+
+// buildDeliveryHeaders returns the headers to send with a delivery and a copy
+// safe to persist in delivery_attempts, with the auth secret and extra header
+// values redacted.
+// payloadHeaders are the format-specific headers from buildDeliveryPayload.
+func buildDeliveryHeaders(event db.Event, subscriber db.Subscriber, body []byte, payloadHeaders map[string]string, now time.Time) (map[string]string, map[string]string) {
+	eventID := UuidToString(event.ID)
+	headers := make(map[string]string, len(payloadHeaders)+4)
+	for k, v := range payloadHeaders {
+		headers[k] = v
+	}
+	headers["X-Event-ID"] = eventID
+	headers["X-Event-Subject"] = event.Subject
+	return authenticateDelivery(headers, subscriber, webhook.KindEvent, eventID, body, now)
+}
+
+// buildBatchHeaders is buildDeliveryHeaders for a batched delivery. HMAC
+// signatures cover the batch ID in place of an event ID and are signed as a
+// batch.
+func buildBatchHeaders(batchID string, size int, subscriber db.Subscriber, body []byte, payloadHeaders map[string]string, now time.Time) (map[string]string, map[string]string) {
+	headers := make(map[string]string, len(payloadHeaders)+4)
+	for k, v := range payloadHeaders {
+		headers[k] = v
+	}
+	headers[webhook.HeaderBatchID] = batchID
+	headers[webhook.HeaderBatchSize] = strconv.Itoa(size)
+	return authenticateDelivery(headers, subscriber, webhook.KindBatch, batchID, body, now)
+}
+
+// authenticateDelivery adds the subscriber's extra and authentication headers
+// and returns them along with a redacted copy for delivery_attempts. Extra
+// headers often carry credentials, so their values are never recorded.
+// kind and signedID identify the delivery in an HMAC signature.
+func authenticateDelivery(headers map[string]string, subscriber db.Subscriber, kind, signedID string, body []byte, now time.Time) (map[string]string, map[string]string) {
+	// Extra headers are validated when saved and cannot collide with ours
+	extraHeaders, _ := ParseExtraHeaders(subscriber.ExtraHeaders)
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
+	if subscriber.SigningMode == SigningModeHMAC {
+		timestamp := now.Unix()
+		headers[webhook.HeaderTimestamp] = strconv.FormatInt(timestamp, 10)
+		headers[webhook.HeaderSignature] = webhook.Sign(subscriber.AuthSecret, timestamp, kind, signedID, body)
+	} else {
+		headers["X-Slurpee-Secret"] = subscriber.AuthSecret
+	}
+
+	recorded := make(map[string]string, len(headers))
+	for k, v := range headers {
+		recorded[k] = v
+	}
+	if _, ok := recorded["X-Slurpee-Secret"]; ok {
+		recorded["X-Slurpee-Secret"] = redactedHeaderValue
+	}
+	for k := range extraHeaders {
+		recorded[k] = redactedHeaderValue
+	}
+	if subscriber.AuthMode == AuthModeOAuth2 {
+		// The bearer token is attached when the request is sent
+		recorded["Authorization"] = redactedHeaderValue
+	}
+	return headers, recorded
+}
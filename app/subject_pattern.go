@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"strings"
+)
+
+// Subject pattern syntaxes control how subscriptions, API secret scopes and
+// log configs match event subjects.
+const (
+	// PatternSyntaxLike is the original syntax: * or % matches any run of
+	// characters, dots included, and ? or _ matches exactly one character.
+	// A backslash makes the character after it literal.
+	PatternSyntaxLike = "like"
+	// PatternSyntaxToken matches dot-separated tokens: * matches exactly one
+	// token and > matches one or more tokens at the end of the subject.
+	PatternSyntaxToken = "token"
+)
+
+// ValidPatternSyntax reports whether syntax is a supported pattern syntax.
+func ValidPatternSyntax(syntax string) bool {
+	return syntax == PatternSyntaxLike || syntax == PatternSyntaxToken
+}
+
+// ValidateSubjectPattern checks that pattern is well formed in the given
+// syntax. An empty syntax is treated as PatternSyntaxLike.
+func ValidateSubjectPattern(syntax, pattern string) error {
+	if pattern == "" {
+		return errors.New("pattern must not be empty")
+	}
+	switch syntax {
+	case "", PatternSyntaxLike:
+		return nil
+	case PatternSyntaxToken:
+		tokens := strings.Split(pattern, ".")
+		for i, token := range tokens {
+			switch {
+			case token == "":
+				return errors.New("tokens must not be empty; check for leading, trailing or repeated dots")
+			case token == ">" && i != len(tokens)-1:
+				return errors.New("> may only be the last token")
+			case token != "*" && token != ">" && strings.ContainsAny(token, "*>"):
+				return errors.New("* and > must be whole tokens, as in orders.* or orders.>")
+			}
+		}
+		return nil
+	default:
+		return errors.New("pattern syntax must be 'like' or 'token'")
+	}
+}
+
+// MatchSubjectPattern reports whether subject matches pattern in the given
+// syntax. An empty syntax is treated as PatternSyntaxLike; unknown syntaxes
+// match nothing.
+func MatchSubjectPattern(syntax, pattern, subject string) bool {
+	switch syntax {
+	case "", PatternSyntaxLike:
+		return MatchLikePattern(pattern, subject)
+	case PatternSyntaxToken:
+		return matchTokenPattern(pattern, subject)
+	}
+	return false
+}
+
+func matchTokenPattern(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" {
+			return i == len(patternTokens)-1 && len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(subjectTokens) == len(patternTokens)
+}
+
+// subjectPatternPrefix returns the literal text that every subject matching
+// pattern starts with. exact reports that the pattern has no wildcards and
+// matches only itself; whole reports that every subject with the prefix
+// matches.
+func subjectPatternPrefix(syntax, pattern string) (prefix string, exact, whole bool) {
+	if syntax == PatternSyntaxToken {
+		tokens := strings.Split(pattern, ".")
+		for i, token := range tokens {
+			if token == "*" || token == ">" {
+				prefix = strings.Join(tokens[:i], ".")
+				if i > 0 {
+					prefix += "."
+				}
+				return prefix, false, token == ">" && i == len(tokens)-1
+			}
+		}
+		return pattern, true, false
+	}
+
+	var literal strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '%', '?', '_':
+			return literal.String(), false, strings.Trim(pattern[i:], "*%") == ""
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+		}
+		literal.WriteByte(pattern[i])
+	}
+	return literal.String(), true, false
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSubjectPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		syntax   string
+		pattern  string
+		subject  string
+		expected bool
+	}{
+		{"like star crosses dots", PatternSyntaxLike, "order.*", "order.line.added", true},
+		{"like is the default", "", "order.*", "order.line.added", true},
+		{"like single char", PatternSyntaxLike, "order.?", "order.x", true},
+
+		{"token exact", PatternSyntaxToken, "order.created", "order.created", true},
+		{"token exact mismatch", PatternSyntaxToken, "order.created", "order.updated", false},
+		{"token star matches one token", PatternSyntaxToken, "order.*", "order.created", true},
+		{"token star does not cross dots", PatternSyntaxToken, "order.*", "order.line.added", false},
+		{"token star needs a token", PatternSyntaxToken, "order.*", "order", false},
+		{"token star in the middle", PatternSyntaxToken, "order.*.added", "order.line.added", true},
+		{"token star in the middle mismatch", PatternSyntaxToken, "order.*.added", "order.line.removed", false},
+		{"token leading star", PatternSyntaxToken, "*.created", "user.created", true},
+		{"token leading star one token only", PatternSyntaxToken, "*.created", "eu.user.created", false},
+		{"token tail matches one token", PatternSyntaxToken, "order.>", "order.created", true},
+		{"token tail matches many tokens", PatternSyntaxToken, "order.>", "order.line.added", true},
+		{"token tail needs a token", PatternSyntaxToken, "order.>", "order", false},
+		{"token tail alone", PatternSyntaxToken, ">", "anything.goes", true},
+		{"token star and tail", PatternSyntaxToken, "*.order.>", "eu.order.line.added", true},
+		{"token literal star is not a prefix", PatternSyntaxToken, "ord*", "order", false},
+		{"token underscore is literal", PatternSyntaxToken, "user_created", "userXcreated", false},
+		{"token question mark is literal", PatternSyntaxToken, "order.?", "order.x", false},
+
+		{"unknown syntax matches nothing", "regex", "order.created", "order.created", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchSubjectPattern(tt.syntax, tt.pattern, tt.subject))
+		})
+	}
+}
+
+func TestValidateSubjectPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		syntax  string
+		pattern string
+		message string
+	}{
+		{"like accepts anything", PatternSyntaxLike, "order.*x_%?", ""},
+		{"empty pattern", PatternSyntaxLike, "", "pattern must not be empty"},
+		{"token wildcards", PatternSyntaxToken, "*.order.*.>", ""},
+		{"token empty token", PatternSyntaxToken, "order..created", "tokens must not be empty"},
+		{"token trailing dot", PatternSyntaxToken, "order.", "tokens must not be empty"},
+		{"token tail not last", PatternSyntaxToken, "order.>.created", "> may only be the last token"},
+		{"token partial star", PatternSyntaxToken, "order.creat*", "* and > must be whole tokens"},
+		{"token partial tail", PatternSyntaxToken, "order.x>", "* and > must be whole tokens"},
+		{"unknown syntax", "regex", "order", "pattern syntax must be 'like' or 'token'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSubjectPattern(tt.syntax, tt.pattern)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func TestCheckSendScope_TokenSyntax(t *testing.T) {
+	assert.True(t, CheckSendScope(PatternSyntaxToken, "billing.>", "billing.invoice.paid"))
+	assert.False(t, CheckSendScope(PatternSyntaxToken, "billing.*", "billing.invoice.paid"))
+	assert.False(t, CheckSendScope(PatternSyntaxToken, "billing.>", "shipping.label.created"))
+}
@@ -3,7 +3,6 @@ package app
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -11,102 +10,165 @@ import (
 )
 
 // SubscriptionCache lazily bulk-loads all subscribers and subscriptions into
-// memory. Subject-pattern matching is performed in Go using MatchLikePattern.
+// memory and indexes subscriptions by subject pattern, compiling their
+// filters and transforms, so matching an event does not scan every
+// subscription and delivering one does not parse its transform.
 // Call Flush after any subscriber/subscription mutation; the next access
 // reloads from the database.
 type SubscriptionCache struct {
-	mu            sync.RWMutex
-	loaded        bool
-	subscribers   map[[16]byte]db.Subscriber      // keyed by UUID bytes
+	mu       sync.RWMutex
+	snapshot *cacheSnapshot
+	db       db.Querier
+}
+
+// cacheSnapshot is one load of the cache. It is read-only once built, so a
+// caller holding it can keep reading it after a Flush replaces it.
+type cacheSnapshot struct {
+	subscribers   map[[16]byte]db.Subscriber // keyed by UUID bytes
 	subscriptions []db.Subscription
-	db            db.Querier
+	index         *subscriptionIndex
 }
 
 func NewSubscriptionCache(querier db.Querier) *SubscriptionCache {
 	return &SubscriptionCache{db: querier}
 }
 
-// load performs lazy bulk loading with double-checked locking.
-func (c *SubscriptionCache) load(ctx context.Context) error {
+// load performs lazy bulk loading with double-checked locking and returns the
+// loaded snapshot. The snapshot is returned rather than re-read by the caller
+// so that a Flush between the two cannot leave the caller without one.
+func (c *SubscriptionCache) load(ctx context.Context) (*cacheSnapshot, error) {
 	c.mu.RLock()
-	if c.loaded {
-		c.mu.RUnlock()
-		return nil
-	}
+	snap := c.snapshot
 	c.mu.RUnlock()
+	if snap != nil {
+		return snap, nil
+	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	// Double-check after acquiring write lock
-	if c.loaded {
-		return nil
+	if c.snapshot != nil {
+		return c.snapshot, nil
 	}
 
 	subscribers, err := c.db.ListSubscribers(ctx)
 	if err != nil {
-		return fmt.Errorf("loading subscribers: %w", err)
+		return nil, fmt.Errorf("loading subscribers: %w", err)
 	}
 
 	subscriptions, err := c.db.ListAllSubscriptions(ctx)
 	if err != nil {
-		return fmt.Errorf("loading subscriptions: %w", err)
+		return nil, fmt.Errorf("loading subscriptions: %w", err)
 	}
 
-	c.subscribers = make(map[[16]byte]db.Subscriber, len(subscribers))
+	snap = &cacheSnapshot{
+		subscribers:   make(map[[16]byte]db.Subscriber, len(subscribers)),
+		subscriptions: subscriptions,
+		index:         newSubscriptionIndex(subscriptions),
+	}
 	for _, s := range subscribers {
-		c.subscribers[s.ID.Bytes] = s
+		snap.subscribers[s.ID.Bytes] = s
 	}
-	c.subscriptions = subscriptions
-	c.loaded = true
-	return nil
+	c.snapshot = snap
+	return snap, nil
 }
 
 // GetMatchingSubscriptions returns all subscriptions whose subject_pattern
-// matches the given subject. Patterns use * (any sequence) and ? (single char).
+// matches the given subject, in the syntax named by its pattern_syntax: LIKE
+// wildcards or NATS-style tokens; see MatchSubjectPattern. Candidates are
+// found with the prefix-trie index built when the cache loaded, so only
+// patterns that can match the subject are tested.
 func (c *SubscriptionCache) GetMatchingSubscriptions(ctx context.Context, subject string) ([]db.Subscription, error) {
-	if err := c.load(ctx); err != nil {
+	snap, err := c.load(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
 	var matched []db.Subscription
-	for _, sub := range c.subscriptions {
-		// Convert SQL-style wildcards to the glob style used by MatchLikePattern:
-		// subject_pattern already uses * and ? (not SQL % and _), so we can
-		// match directly. But the DB query used replace(*, '%') and replace(?, '_')
-		// meaning the stored pattern uses * for "any" and ? for "single char".
-		pattern := strings.ReplaceAll(sub.SubjectPattern, "?", "_")
-		if MatchLikePattern(pattern, subject) {
-			matched = append(matched, sub)
-		}
+	for _, i := range snap.index.match(subject) {
+		matched = append(matched, snap.subscriptions[i])
 	}
 	return matched, nil
 }
 
+// filterMatches reports whether event data matches a subscription's filter,
+// using the filter compiled when the cache loaded. Subscriptions not in the
+// cache, or whose filter has changed since, have their filter parsed anew.
+func (c *SubscriptionCache) filterMatches(sub db.Subscription, data *eventFilterData) bool {
+	c.mu.RLock()
+	snap := c.snapshot
+	c.mu.RUnlock()
+
+	var compiled compiledFilter
+	ok := false
+	if snap != nil {
+		compiled, ok = snap.index.filter(sub)
+	}
+	if !ok {
+		compiled.filter, compiled.err = ParseFilter(sub.Filter)
+	}
+	return data.matches(compiled.filter, compiled.err)
+}
+
+// transformEventData returns event with its data replaced by the output of
+// the subscription's transform, if it has one, using the transform compiled
+// when the cache loaded. Subscriptions not in the cache, or whose transform
+// has changed since, have their transform parsed anew.
+func (c *SubscriptionCache) transformEventData(event db.Event, sub db.Subscription) (db.Event, error) {
+	c.mu.RLock()
+	snap := c.snapshot
+	c.mu.RUnlock()
+
+	var compiled compiledTransform
+	ok := false
+	if snap != nil {
+		compiled, ok = snap.index.transform(sub)
+	}
+	if !ok {
+		compiled.transform, compiled.err = ParseTransform(sub.Transform)
+	}
+	if compiled.err != nil {
+		return event, compiled.err
+	}
+	data, err := compiled.transform.Apply(event)
+	if err != nil {
+		return event, err
+	}
+	event.Data = data
+	return event, nil
+}
+
 // GetSubscriberByID returns a subscriber by UUID, or an error if not found.
 func (c *SubscriptionCache) GetSubscriberByID(ctx context.Context, id pgtype.UUID) (db.Subscriber, error) {
-	if err := c.load(ctx); err != nil {
+	snap, err := c.load(ctx)
+	if err != nil {
 		return db.Subscriber{}, err
 	}
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	s, ok := c.subscribers[id.Bytes]
+	s, ok := snap.subscribers[id.Bytes]
 	if !ok {
 		return db.Subscriber{}, fmt.Errorf("subscriber not found: %x", id.Bytes)
 	}
 	return s, nil
 }
 
+// GetSubscriptionByID returns a subscription by UUID, or an error if not found.
+func (c *SubscriptionCache) GetSubscriptionByID(ctx context.Context, id pgtype.UUID) (db.Subscription, error) {
+	snap, err := c.load(ctx)
+	if err != nil {
+		return db.Subscription{}, err
+	}
+
+	if i, ok := snap.index.byID[id.Bytes]; ok {
+		return snap.subscriptions[i], nil
+	}
+	return db.Subscription{}, fmt.Errorf("subscription not found: %x", id.Bytes)
+}
+
 // Flush clears the cache. The next access will reload from the database.
 func (c *SubscriptionCache) Flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.loaded = false
-	c.subscribers = nil
-	c.subscriptions = nil
+	c.snapshot = nil
 }
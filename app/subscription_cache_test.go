@@ -2,11 +2,14 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/sweater-ventures/slurpee/db"
 )
 
@@ -205,3 +208,247 @@ func TestSubscriptionCache_LoadError_Propagates(t *testing.T) {
 	_, err = cache.GetSubscriberByID(ctx, pgtype.UUID{})
 	assert.Error(t, err)
 }
+
+func TestSubscriptionCache_IndexMatchesPatterns(t *testing.T) {
+	patterns := []string{
+		"orders.created", "orders.*", "orders.**", "orders*", "*", "**", "",
+		"orders.?", "orders.??eated", "*.created", "orders.*.eu", "ord*s.*",
+		"order_created", "orders.created*", "payments.*", "o", "o*",
+		"_rders.*", "*.eu", "orders.*.e?", "orders.*x*", "orders.%", "%.eu",
+		`orders.100\%`, `orders\_created`, `orders.\%*`, `orders.\\`, `orders.\`,
+	}
+	tokenPatterns := []string{
+		"orders.created", "orders.*", "orders.>", "*", ">", "*.created",
+		"orders.*.eu", "*.*.eu", "orders.x.>", "order_created",
+	}
+	subjects := []string{
+		"orders.created", "orders.", "orders", "orders.a", "orders.x.eu",
+		"payments.refunded", "order_created", "orderXcreated", "", "o", "other",
+		"orders.100%", "orders.1000", "orders_created", "orders.%x", `orders.\`,
+	}
+
+	var subscriptions []db.Subscription
+	for _, pattern := range patterns {
+		subscriptions = append(subscriptions, newTestSubscription(func(s *db.Subscription) { s.SubjectPattern = pattern }))
+	}
+	for _, pattern := range tokenPatterns {
+		subscriptions = append(subscriptions, newTestSubscription(func(s *db.Subscription) {
+			s.SubjectPattern = pattern
+			s.PatternSyntax = PatternSyntaxToken
+		}))
+	}
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return(subscriptions, nil)
+	cache := NewSubscriptionCache(mockDB)
+
+	for _, subject := range subjects {
+		var expected []string
+		for _, sub := range subscriptions {
+			if MatchSubjectPattern(sub.PatternSyntax, sub.SubjectPattern, subject) {
+				expected = append(expected, sub.PatternSyntax+":"+sub.SubjectPattern)
+			}
+		}
+
+		subs, err := cache.GetMatchingSubscriptions(context.Background(), subject)
+		require.NoError(t, err)
+		var got []string
+		for _, sub := range subs {
+			got = append(got, sub.PatternSyntax+":"+sub.SubjectPattern)
+		}
+		assert.Equal(t, expected, got, "subject %q", subject)
+	}
+}
+
+func TestSubscriptionCache_GetSubscriptionByID(t *testing.T) {
+	sub := newTestSubscription(func(s *db.Subscription) { s.SubjectPattern = "orders.*" })
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{newTestSubscription(), sub}, nil)
+	cache := NewSubscriptionCache(mockDB)
+
+	found, err := cache.GetSubscriptionByID(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sub.ID, found.ID)
+
+	_, err = cache.GetSubscriptionByID(context.Background(), newTestUUID())
+	assert.ErrorContains(t, err, "subscription not found")
+}
+
+func TestSubscriptionCache_FilterMatchesUsesCompiledFilter(t *testing.T) {
+	sub := newTestSubscription(func(s *db.Subscription) {
+		s.Filter = []byte(`{"amount":{"$gte":100}}`)
+	})
+	invalid := newTestSubscription(func(s *db.Subscription) {
+		s.Filter = []byte(`{"amount":{"$gte":"x"}}`)
+	})
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{sub, invalid}, nil)
+	cache := NewSubscriptionCache(mockDB)
+	_, err := cache.GetMatchingSubscriptions(context.Background(), "test.x")
+	require.NoError(t, err)
+
+	data := newEventFilterData([]byte(`{"amount":150}`))
+	assert.True(t, cache.filterMatches(sub, data))
+	assert.False(t, cache.filterMatches(invalid, data))
+	assert.False(t, cache.filterMatches(sub, newEventFilterData([]byte(`{"amount":50}`))))
+
+	// A subscription whose filter differs from the cached one is parsed anew
+	changed := sub
+	changed.Filter = []byte(`{"amount":{"$lt":100}}`)
+	assert.False(t, cache.filterMatches(changed, data))
+
+	// So is one the cache does not hold
+	assert.True(t, cache.filterMatches(newTestSubscription(), data))
+	assert.False(t, cache.filterMatches(sub, newEventFilterData([]byte(`not json`))))
+}
+
+func TestSubscriptionCache_TransformEventDataUsesCompiledTransform(t *testing.T) {
+	sub := newTestSubscription(func(s *db.Subscription) {
+		s.Transform = []byte(`{"total":"$.data.amount"}`)
+	})
+	invalid := newTestSubscription(func(s *db.Subscription) {
+		s.Transform = []byte(`{"total":"$.nope"}`)
+	})
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{sub, invalid}, nil)
+	cache := NewSubscriptionCache(mockDB)
+	snap, err := cache.load(context.Background())
+	require.NoError(t, err)
+
+	event := db.Event{Data: []byte(`{"amount":150}`)}
+	transformed, err := cache.transformEventData(event, sub)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":150}`, string(transformed.Data))
+	_, err = cache.transformEventData(event, invalid)
+	assert.Error(t, err)
+
+	// The compiled transform is used while the subscription is unchanged
+	compiled, ok := snap.index.transform(sub)
+	require.True(t, ok)
+	snap.index.transforms[snap.index.byID[sub.ID.Bytes]].transform, _ = ParseTransform([]byte(`{"cached":true}`))
+	transformed, err = cache.transformEventData(event, sub)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cached":true}`, string(transformed.Data))
+	snap.index.transforms[snap.index.byID[sub.ID.Bytes]] = compiled
+
+	// A subscription whose transform differs from the cached one is parsed anew
+	changed := sub
+	changed.Transform = []byte(`{"amount":"$.data.amount"}`)
+	transformed, err = cache.transformEventData(event, changed)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":150}`, string(transformed.Data))
+
+	// So is one the cache does not hold, and no transform leaves data unchanged
+	transformed, err = cache.transformEventData(event, newTestSubscription())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":150}`, string(transformed.Data))
+}
+
+func TestSubscriptionCache_ConcurrentFlush(t *testing.T) {
+	sub := newTestSubscription(func(s *db.Subscription) { s.SubjectPattern = "orders.*" })
+	mockDB := new(deliveryMockQuerier)
+	mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).Return([]db.Subscription{sub}, nil)
+	cache := NewSubscriptionCache(mockDB)
+	ctx := context.Background()
+
+	// Lookups racing a Flush must see either the old or the new load, never
+	// a cleared cache
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				subs, err := cache.GetMatchingSubscriptions(ctx, "orders.created")
+				assert.NoError(t, err)
+				assert.Len(t, subs, 1)
+				_, err = cache.GetSubscriptionByID(ctx, sub.ID)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for range 1000 {
+		cache.Flush()
+	}
+	wg.Wait()
+}
+
+// benchmarkSubscriptions returns n subscriptions spread over distinct exact,
+// prefix and wildcard patterns, as a large deployment might have.
+func benchmarkSubscriptions(n int) []db.Subscription {
+	subscriptions := make([]db.Subscription, n)
+	for i := range subscriptions {
+		var pattern string
+		switch i % 4 {
+		case 0, 1:
+			pattern = fmt.Sprintf("service%d.entity%d.created", i%97, i)
+		case 2:
+			pattern = fmt.Sprintf("service%d.entity%d.*", i%97, i)
+		default:
+			pattern = fmt.Sprintf("service%d.*.entity%d", i%97, i)
+		}
+		subscriptions[i] = newTestSubscription(func(s *db.Subscription) {
+			s.SubjectPattern = pattern
+			s.Filter = []byte(fmt.Sprintf(`{"tenant":{"$in":["t%d","t%d"]},"amount":{"$gte":%d}}`, i, i+1, i%100))
+		})
+	}
+	return subscriptions
+}
+
+// scanMatchingSubscriptions is the linear scan the index replaces, kept as
+// the benchmark's baseline.
+func scanMatchingSubscriptions(subscriptions []db.Subscription, subject string) []db.Subscription {
+	var matched []db.Subscription
+	for _, sub := range subscriptions {
+		if MatchSubjectPattern(sub.PatternSyntax, sub.SubjectPattern, subject) {
+			matched = append(matched, sub)
+		}
+	}
+	return matched
+}
+
+// BenchmarkSubscriptionMatching matches an event against n subscriptions and
+// evaluates the matched subscriptions' filters, as dispatchEvent does. The
+// indexed cost stays flat as n grows; the scan grows linearly.
+func BenchmarkSubscriptionMatching(b *testing.B) {
+	const subject = "service1.entity1.created"
+	eventData := []byte(`{"tenant":"t1","amount":50}`)
+
+	for _, n := range []int{10, 100, 1000, 10000} {
+		subscriptions := benchmarkSubscriptions(n)
+		mockDB := new(deliveryMockQuerier)
+		mockDB.On("ListSubscribers", mock.Anything).Return([]db.Subscriber{}, nil)
+		mockDB.On("ListAllSubscriptions", mock.Anything).Return(subscriptions, nil)
+		cache := NewSubscriptionCache(mockDB)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("index/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				subs, err := cache.GetMatchingSubscriptions(ctx, subject)
+				if err != nil || len(subs) == 0 {
+					b.Fatal("expected a match")
+				}
+				data := newEventFilterData(eventData)
+				for _, sub := range subs {
+					cache.filterMatches(sub, data)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				subs := scanMatchingSubscriptions(subscriptions, subject)
+				if len(subs) == 0 {
+					b.Fatal("expected a match")
+				}
+				for _, sub := range subs {
+					matchesFilter(sub.Filter, eventData)
+				}
+			}
+		})
+	}
+}
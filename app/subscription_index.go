@@ -0,0 +1,183 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// subscriptionIndex finds the subscriptions whose subject pattern matches a
+// subject without testing every pattern. It is built when the subscription
+// cache loads and is read-only afterwards.
+//
+// Patterns without wildcards are looked up in a hash map. Other patterns are
+// kept in a trie under their literal prefix, the text before the first
+// wildcard, so only patterns whose prefix the subject starts with are
+// considered. Those that match anything after the prefix, such as "orders.*"
+// in LIKE syntax or "orders.>" in token syntax, match outright; the rest are
+// confirmed with MatchSubjectPattern. Subscriptions are identified by their
+// position in the cache's list, and matches are returned in that order.
+type subscriptionIndex struct {
+	exact      map[string][]int
+	prefixes   *prefixNode
+	byID       map[[16]byte]int
+	filters    []compiledFilter
+	transforms []compiledTransform
+}
+
+// prefixNode is a trie node for the literal prefix spelled by the path to it.
+// subs holds the subscriptions whose pattern matches every subject with the
+// prefix, and patterns those that must be matched in full.
+type prefixNode struct {
+	children map[byte]*prefixNode
+	subs     []int
+	patterns []indexedPattern
+}
+
+// indexedPattern is a subject pattern that must be matched in full.
+type indexedPattern struct {
+	syntax  string
+	pattern string
+	sub     int
+}
+
+// compiledFilter is a subscription's filter parsed at load time, along with
+// the raw filter it was parsed from.
+type compiledFilter struct {
+	raw    []byte
+	filter *Filter
+	err    error
+}
+
+// compiledTransform is a subscription's transform parsed at load time, along
+// with the raw transform it was parsed from.
+type compiledTransform struct {
+	raw       []byte
+	transform *Transform
+	err       error
+}
+
+func newSubscriptionIndex(subscriptions []db.Subscription) *subscriptionIndex {
+	idx := &subscriptionIndex{
+		exact:      make(map[string][]int),
+		prefixes:   &prefixNode{},
+		byID:       make(map[[16]byte]int, len(subscriptions)),
+		filters:    make([]compiledFilter, len(subscriptions)),
+		transforms: make([]compiledTransform, len(subscriptions)),
+	}
+	for i, sub := range subscriptions {
+		idx.byID[sub.ID.Bytes] = i
+		filter, err := ParseFilter(sub.Filter)
+		idx.filters[i] = compiledFilter{raw: sub.Filter, filter: filter, err: err}
+		transform, err := ParseTransform(sub.Transform)
+		idx.transforms[i] = compiledTransform{raw: sub.Transform, transform: transform, err: err}
+
+		prefix, exact, whole := subjectPatternPrefix(sub.PatternSyntax, sub.SubjectPattern)
+		if exact {
+			idx.exact[prefix] = append(idx.exact[prefix], i)
+			continue
+		}
+		node := idx.prefixes.node(prefix)
+		if whole {
+			node.subs = append(node.subs, i)
+		} else {
+			node.patterns = append(node.patterns, indexedPattern{syntax: sub.PatternSyntax, pattern: sub.SubjectPattern, sub: i})
+		}
+	}
+	return idx
+}
+
+// node returns the node for prefix, creating it and its parents as needed.
+func (n *prefixNode) node(prefix string) *prefixNode {
+	for i := 0; i < len(prefix); i++ {
+		if n.children == nil {
+			n.children = make(map[byte]*prefixNode)
+		}
+		child, ok := n.children[prefix[i]]
+		if !ok {
+			child = &prefixNode{}
+			n.children[prefix[i]] = child
+		}
+		n = child
+	}
+	return n
+}
+
+// match returns the positions of the subscriptions matching subject, in
+// ascending order.
+func (idx *subscriptionIndex) match(subject string) []int {
+	matched := append([]int(nil), idx.exact[subject]...)
+
+	for i, n := 0, idx.prefixes; n != nil; i++ {
+		matched = append(matched, n.subs...)
+		for _, p := range n.patterns {
+			if MatchSubjectPattern(p.syntax, p.pattern, subject) {
+				matched = append(matched, p.sub)
+			}
+		}
+		if i == len(subject) {
+			break
+		}
+		n = n.children[subject[i]]
+	}
+
+	slices.Sort(matched)
+	return matched
+}
+
+// filter returns the compiled filter of the subscription with the given ID,
+// if the index holds the same filter the caller has.
+func (idx *subscriptionIndex) filter(sub db.Subscription) (compiledFilter, bool) {
+	i, ok := idx.byID[sub.ID.Bytes]
+	if !ok || !bytes.Equal(idx.filters[i].raw, sub.Filter) {
+		return compiledFilter{}, false
+	}
+	return idx.filters[i], true
+}
+
+// transform returns the compiled transform of the subscription with the
+// given ID, if the index holds the same transform the caller has.
+func (idx *subscriptionIndex) transform(sub db.Subscription) (compiledTransform, bool) {
+	i, ok := idx.byID[sub.ID.Bytes]
+	if !ok || !bytes.Equal(idx.transforms[i].raw, sub.Transform) {
+		return compiledTransform{}, false
+	}
+	return idx.transforms[i], true
+}
+
+// eventFilterData decodes an event's data for filter matching once, on first
+// use, however many subscriptions are checked against it.
+type eventFilterData struct {
+	raw     []byte
+	decoded bool
+	data    map[string]any
+	valid   bool
+}
+
+func newEventFilterData(raw []byte) *eventFilterData {
+	return &eventFilterData{raw: raw}
+}
+
+// get returns the decoded data, or false if it is not a JSON object.
+func (d *eventFilterData) get() (map[string]any, bool) {
+	if !d.decoded {
+		d.decoded = true
+		d.valid = json.Unmarshal(d.raw, &d.data) == nil && d.data != nil
+	}
+	return d.data, d.valid
+}
+
+// matches reports whether the event data matches f. Invalid filters match
+// nothing and empty filters match everything.
+func (d *eventFilterData) matches(f *Filter, err error) bool {
+	if err != nil {
+		return false
+	}
+	if f.matchesAll() {
+		return true
+	}
+	data, ok := d.get()
+	return ok && f.Matches(data)
+}
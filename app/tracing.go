@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/config"
+	"github.com/sweater-ventures/slurpee/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span names, so the same operation is named consistently wherever it is traced.
+const (
+	spanCreateEvent   = "createEventHandler"
+	spanInsertEvent   = "db.InsertEvent"
+	spanDispatchEvent = "dispatchEvent"
+	spanDeliver       = "deliverToSubscriber"
+	spanDeliverBatch  = "deliverBatch"
+	spanScheduleRetry = "scheduleRetry"
+)
+
+// tracerName is the instrumentation scope of Slurpee's spans.
+const tracerName = "github.com/sweater-ventures/slurpee"
+
+// TraceparentHeader is the W3C Trace Context header carrying a span context.
+const TraceparentHeader = "traceparent"
+
+// otlpExportTimeout bounds a single request to the trace collector.
+const otlpExportTimeout = 10 * time.Second
+
+// NewTracerProvider creates a tracer provider whose spans can continue a trace
+// named only by its trace ID; see contextWithParent.
+func NewTracerProvider(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append(opts, sdktrace.WithIDGenerator(traceIDGenerator{}))
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// newTracerProvider creates the tracer provider for the application. Spans
+// are exported to the configured OTLP collector; without one they are only
+// used to propagate trace context.
+func newTracerProvider(config *config.AppConfig) *sdktrace.TracerProvider {
+	if config.OTLPEndpoint == "" {
+		return NewTracerProvider()
+	}
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimRight(config.OTLPEndpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithTimeout(otlpExportTimeout),
+	)
+	if err != nil {
+		slog.Error("Failed to create OTLP exporter, traces will not be exported", "error", err)
+		return NewTracerProvider()
+	}
+	return NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+}
+
+// tracer returns the tracer Slurpee's spans are started with.
+func (slurpee *Application) tracer() trace.Tracer {
+	return slurpee.TracerProvider.Tracer(tracerName)
+}
+
+type rootTraceIDKey struct{}
+
+// contextWithParent makes spans started from ctx children of parent, such as
+// a span received in a traceparent header, instead of the span in ctx. A
+// parent with only a trace ID starts a root span in that trace, and an empty
+// parent leaves ctx unchanged.
+func contextWithParent(ctx context.Context, parent trace.SpanContext) context.Context {
+	if parent.IsValid() {
+		return trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+	if parent.TraceID().IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, trace.SpanContext{})
+		return context.WithValue(ctx, rootTraceIDKey{}, parent.TraceID())
+	}
+	return ctx
+}
+
+// traceIDGenerator generates random IDs, except that root spans take the
+// trace ID set by contextWithParent.
+type traceIDGenerator struct{}
+
+func (traceIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	traceID, _ := ctx.Value(rootTraceIDKey{}).(trace.TraceID)
+	for !traceID.IsValid() {
+		rand.Read(traceID[:])
+	}
+	return traceID, newSpanID()
+}
+
+func (traceIDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	return newSpanID()
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// ParseTraceparent returns the span context in a traceparent header value,
+// or an empty SpanContext if it is not valid.
+func ParseTraceparent(value string) trace.SpanContext {
+	carrier := propagation.MapCarrier{TraceparentHeader: value}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), carrier)
+	return trace.SpanContextFromContext(ctx)
+}
+
+// formatTraceparent returns the traceparent header value for sc.
+func formatTraceparent(sc trace.SpanContext) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	return carrier.Get(TraceparentHeader)
+}
+
+// IngestSpanParent returns the parent for the span ingesting an event: the
+// caller's traceparent if it sent one, otherwise the trace named by the
+// event's trace_id so the event's spans can be found by that ID. It returns
+// an empty SpanContext, starting a new trace, when neither is usable.
+func IngestSpanParent(traceparent trace.SpanContext, traceID string) trace.SpanContext {
+	if traceparent.IsValid() {
+		return traceparent
+	}
+	if parsed, err := uuid.Parse(traceID); err == nil {
+		return trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID(parsed)})
+	}
+	return trace.SpanContext{}
+}
+
+// StartCreateEventSpan starts the server span for an event ingest request.
+func StartCreateEventSpan(ctx context.Context, slurpee *Application, parent trace.SpanContext, subject string) (context.Context, trace.Span) {
+	return slurpee.tracer().Start(contextWithParent(ctx, parent), spanCreateEvent,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("slurpee.event.subject", subject)),
+	)
+}
+
+// InsertEvent stores a new event under a database span, recording the
+// traceparent of the ingest span in ctx so deliveries join its trace.
+func InsertEvent(ctx context.Context, slurpee *Application, params db.InsertEventParams) (db.Event, error) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		params.Traceparent = pgtype.Text{String: formatTraceparent(sc), Valid: true}
+	}
+	ctx, span := slurpee.tracer().Start(ctx, spanInsertEvent,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", "InsertEvent"),
+		),
+	)
+	defer span.End()
+
+	event, err := slurpee.DB.InsertEvent(ctx, params)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return event, err
+}
+
+// eventSpanContext returns the span that ingested event, which delivery spans
+// are children of. Events stored without a traceparent fall back to their
+// trace_id, and events with neither start a new trace for each operation.
+func eventSpanContext(event db.Event) trace.SpanContext {
+	if event.Traceparent.Valid {
+		if sc := ParseTraceparent(event.Traceparent.String); sc.IsValid() {
+			return sc
+		}
+	}
+	if event.TraceID.Valid {
+		return trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID(event.TraceID.Bytes)})
+	}
+	return trace.SpanContext{}
+}
+
+// eventSpanAttributes describes the event a span operates on.
+func eventSpanAttributes(event db.Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("slurpee.event.id", UuidToString(event.ID)),
+		attribute.String("slurpee.event.subject", event.Subject),
+	}
+}
+
+// injectTraceparent propagates span to the subscriber in a traceparent
+// header, which is recorded with the attempt like the other headers.
+func injectTraceparent(span trace.Span, reqHeaders, recordedHeaders map[string]string) {
+	traceparent := formatTraceparent(span.SpanContext())
+	reqHeaders[TraceparentHeader] = traceparent
+	recordedHeaders[TraceparentHeader] = traceparent
+}
@@ -0,0 +1,285 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/config"
+	"github.com/sweater-ventures/slurpee/db"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// withTestExporter records the app's spans in memory as they end.
+func withTestExporter(t *testing.T, app *Application) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	app.TracerProvider = NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { app.TracerProvider.Shutdown(context.Background()) })
+	return exporter
+}
+
+// spansNamed returns the recorded spans with the given name.
+func spansNamed(exporter *tracetest.InMemoryExporter, name string) []tracetest.SpanStub {
+	var spans []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// spanAttribute returns the value of a span's attribute as a string.
+func spanAttribute(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == attribute.Key(key) {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func newTracedTestEvent() db.Event {
+	return newTestEvent(func(e *db.Event) {
+		e.Traceparent = pgtype.Text{String: testTraceparent, Valid: true}
+	})
+}
+
+func TestDeliverToSubscriber_InjectsTraceparentAsChildOfIngestSpan(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	exporter := withTestExporter(t, app)
+	var recorded db.InsertDeliveryAttemptParams
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(db.InsertDeliveryAttemptParams) }).
+		Return(db.DeliveryAttempt{}, nil)
+
+	event := newTracedTestEvent()
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	result := deliverToSubscriber(context.Background(), app, event, subscriber, 1, slog.Default())
+
+	require.True(t, result.succeeded)
+	sc := ParseTraceparent(received)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+
+	var headers map[string]string
+	require.NoError(t, json.Unmarshal(recorded.RequestHeaders, &headers))
+	assert.Equal(t, received, headers["traceparent"])
+
+	spans := spansNamed(exporter, "deliverToSubscriber")
+	require.Len(t, spans, 1)
+	assert.Equal(t, sc.SpanID().String(), spans[0].SpanContext.SpanID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Equal(t, UuidToString(subscriber.ID), spanAttribute(spans[0], "slurpee.subscriber.id"))
+	assert.Equal(t, "2", spanAttribute(spans[0], "slurpee.delivery.attempt"))
+	assert.Equal(t, "true", spanAttribute(spans[0], "slurpee.delivery.retry"))
+	assert.Equal(t, "200", spanAttribute(spans[0], "http.response.status_code"))
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+}
+
+func TestDeliverToSubscriber_UsesEventTraceIDWithoutTraceparent(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	traceID := uuid.MustParse("4bf92f35-77b3-4da6-a3ce-929d0e0e4736")
+	event := newTestEvent(func(e *db.Event) { e.TraceID = pgtype.UUID{Bytes: traceID, Valid: true} })
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	deliverToSubscriber(context.Background(), app, event, subscriber, 0, slog.Default())
+
+	sc := ParseTraceparent(received)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+}
+
+func TestDeliverToSubscriber_FailedAttemptMarksSpanError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	exporter := withTestExporter(t, app)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL })
+	deliverToSubscriber(context.Background(), app, newTracedTestEvent(), subscriber, 0, slog.Default())
+
+	spans := spansNamed(exporter, "deliverToSubscriber")
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "received HTTP 502", spans[0].Status.Description)
+}
+
+func TestProcessDeliveryTask_RetryIsTraced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	mockSubscribers(mockDB)
+	exporter := withTestExporter(t, app)
+	mockDB.On("RecordDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.RecordDeliveryAttemptParams")).
+		Return(nil)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+	mockDB.On("UpsertDeliveryRetry", mock.Anything, mock.AnythingOfType("db.UpsertDeliveryRetryParams")).
+		Return(nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	task := newBatchedTask(newTestSubscriber(func(s *db.Subscriber) { s.EndpointUrl = server.URL }))
+	task.event = newTracedTestEvent()
+	task.tracker.event = task.event
+	runTask(app, task)
+
+	spans := spansNamed(exporter, "scheduleRetry")
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, "2", spanAttribute(spans[0], "slurpee.delivery.attempt"))
+	assert.NotEmpty(t, spanAttribute(spans[0], "slurpee.retry.delay_seconds"))
+}
+
+func TestDispatchEvent_IsTracedUnderIngestSpan(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	exporter := withTestExporter(t, app)
+	mockDB.On("ListSubscribers", mock.Anything).
+		Return([]db.Subscriber{}, nil)
+	mockDB.On("ListAllSubscriptions", mock.Anything).
+		Return([]db.Subscription{}, nil)
+	mockDB.On("UpdateEventDeliveryStatus", mock.Anything, mock.AnythingOfType("db.UpdateEventDeliveryStatusParams")).
+		Return(db.Event{}, nil)
+
+	event := newTracedTestEvent()
+	dispatchEvent(app, event, newTestDispatcherState(nil, make(chan deliveryTask, 1), newEventRegistry()))
+
+	spans := spansNamed(exporter, "dispatchEvent")
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, UuidToString(event.ID), spanAttribute(spans[0], "slurpee.event.id"))
+	assert.Equal(t, event.Subject, spanAttribute(spans[0], "slurpee.event.subject"))
+}
+
+func TestDeliverBatch_LinksEachEventTrace(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber := newTestSubscriber(func(s *db.Subscriber) {
+		s.EndpointUrl = server.URL
+		s.BatchSize = 2
+	})
+	tasks := []deliveryTask{newBatchedTask(subscriber), newBatchedTask(subscriber)}
+	tasks[0].event = newTracedTestEvent()
+
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	exporter := withTestExporter(t, app)
+	mockDB.On("InsertDeliveryAttempt", mock.Anything, mock.AnythingOfType("db.InsertDeliveryAttemptParams")).
+		Return(db.DeliveryAttempt{}, nil)
+
+	deliverBatch(t.Context(), app, subscriber, "test-batch", tasks, taskEvents(tasks), slog.Default())
+
+	spans := spansNamed(exporter, "deliverBatch")
+	require.Len(t, spans, 1)
+	require.Len(t, spans[0].Links, 1)
+	assert.Equal(t, testTraceparent, formatTraceparent(spans[0].Links[0].SpanContext))
+	assert.Equal(t, "2", spanAttribute(spans[0], "slurpee.batch.size"))
+	sc := ParseTraceparent(received)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, spans[0].SpanContext.SpanID().String(), sc.SpanID().String())
+}
+
+func TestInsertEvent_RecordsIngestTraceparent(t *testing.T) {
+	mockDB := new(deliveryMockQuerier)
+	app := newDeliveryTestApp(mockDB)
+	exporter := withTestExporter(t, app)
+	var params db.InsertEventParams
+	mockDB.On("InsertEvent", mock.Anything, mock.AnythingOfType("db.InsertEventParams")).
+		Run(func(args mock.Arguments) { params = args.Get(1).(db.InsertEventParams) }).
+		Return(db.Event{}, nil)
+
+	ctx, span := StartCreateEventSpan(context.Background(), app, trace.SpanContext{}, "orders.created")
+	_, err := InsertEvent(ctx, app, db.InsertEventParams{Subject: "orders.created"})
+	span.End()
+
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Text{String: formatTraceparent(span.SpanContext()), Valid: true}, params.Traceparent)
+	spans := spansNamed(exporter, "db.InsertEvent")
+	require.Len(t, spans, 1)
+	assert.Equal(t, span.SpanContext().SpanID().String(), spans[0].Parent.SpanID().String())
+	assert.Equal(t, "postgresql", spanAttribute(spans[0], "db.system"))
+}
+
+func TestIngestSpanParent(t *testing.T) {
+	header := ParseTraceparent(testTraceparent)
+	require.True(t, header.IsValid())
+
+	assert.Equal(t, header, IngestSpanParent(header, "0190a0b0-0000-7000-8000-000000000000"))
+
+	fromTraceID := IngestSpanParent(trace.SpanContext{}, "4bf92f35-77b3-4da6-a3ce-929d0e0e4736")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fromTraceID.TraceID().String())
+	assert.False(t, fromTraceID.SpanID().IsValid())
+
+	assert.Equal(t, trace.SpanContext{}, IngestSpanParent(trace.SpanContext{}, "not-a-uuid"))
+}
+
+func TestNewTracerProvider_ExportsToCollectorTracesPath(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := newTracerProvider(&config.AppConfig{OTLPEndpoint: server.URL + "/", ServiceName: "slurpee-test"})
+	_, span := provider.Tracer(tracerName).Start(context.Background(), spanDispatchEvent)
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	r := <-requests
+	assert.Equal(t, http.MethodPost, r.Method)
+	assert.Equal(t, "/v1/traces", r.URL.Path)
+}
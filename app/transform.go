@@ -0,0 +1,222 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+// Subscription transforms reshape an event's data before it is delivered. A
+// transform is a JSON template: objects, arrays and other values are copied
+// to the output as they are, except strings starting with "$", which are
+// references replaced with part of the event:
+//
+//	$                 the event as an envelope: id, subject, timestamp, trace_id and data
+//	$.id $.subject    envelope fields; also $.timestamp and $.trace_id
+//	$.data            the event's data
+//	$.data.<path>     a field of the data, with the same paths as filters
+//
+// so {"order": "$.data.id", "kind": "$.subject", "source": "shop"} picks and
+// renames the data's id, embeds the subject and adds a constant field. Object
+// fields whose reference is missing from the event are left out, and missing
+// array elements are null. A string starting with a literal "$" is written
+// with "$$", as in "$$5.00".
+//
+// The output replaces the event's data in the subscriber's payload format, so
+// the envelope and CloudEvents formats carry it as their data.
+
+// Transform references.
+const (
+	transformRef       = "$"
+	transformRefData   = "data"
+	transformRefEscape = "$$"
+)
+
+// transformEnvelopeFields are the fields a reference may name after "$.".
+var transformEnvelopeFields = []string{"id", "subject", "timestamp", "trace_id", transformRefData}
+
+// Transform is a parsed subscription transform. A nil Transform leaves event
+// data unchanged.
+type Transform struct {
+	template any
+}
+
+// TransformError describes an invalid transform. Path is where in the
+// template the problem was found, empty for the template as a whole.
+type TransformError struct {
+	Path    string
+	Message string
+}
+
+func (e *TransformError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ParseTransform parses and validates a subscription transform. An empty or
+// null transform leaves event data unchanged and parses to nil.
+func ParseTransform(raw []byte) (*Transform, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	template, err := decodeJSONNumbers(raw)
+	if err != nil {
+		return nil, &TransformError{Message: "transform must be valid JSON"}
+	}
+	if err := validateTransform(template, ""); err != nil {
+		return nil, err
+	}
+	return &Transform{template: template}, nil
+}
+
+func validateTransform(v any, at string) error {
+	switch node := v.(type) {
+	case map[string]any:
+		// Validate fields in key order so errors are reported deterministically
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if err := validateTransform(node[key], joinFilterPath(at, key)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, value := range node {
+			if err := validateTransform(value, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case string:
+		if isTransformRef(node) {
+			if _, _, err := parseTransformRef(node); err != nil {
+				return &TransformError{Path: at, Message: err.Error()}
+			}
+		}
+	}
+	return nil
+}
+
+func isTransformRef(s string) bool {
+	return strings.HasPrefix(s, transformRef) && !strings.HasPrefix(s, transformRefEscape)
+}
+
+// parseTransformRef splits a reference into the envelope field it names and
+// the path within the event's data, if any. An empty field means the whole
+// envelope.
+func parseTransformRef(ref string) (field, dataPath string, err error) {
+	if ref == transformRef {
+		return "", "", nil
+	}
+	rest, ok := strings.CutPrefix(ref, transformRef+".")
+	if !ok {
+		return "", "", fmt.Errorf("reference %q must be $ or start with $.; write a literal $ as $$", ref)
+	}
+	field, dataPath, _ = strings.Cut(rest, ".")
+	switch {
+	case field == transformRefData && strings.HasPrefix(rest, transformRefData+"."):
+		if !validFieldPath(dataPath) {
+			return "", "", fmt.Errorf("reference %q must not contain empty path segments", ref)
+		}
+		return field, dataPath, nil
+	case rest == field && slices.Contains(transformEnvelopeFields, field):
+		return field, "", nil
+	}
+	return "", "", fmt.Errorf("unknown reference %q; use $, $.id, $.subject, $.timestamp, $.trace_id, $.data or $.data.<path>", ref)
+}
+
+// Apply renders the transform for event and returns the JSON that replaces
+// its data.
+func (t *Transform) Apply(event db.Event) ([]byte, error) {
+	if t == nil {
+		return event.Data, nil
+	}
+	data, err := decodeJSONNumbers(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("event data is not valid JSON: %w", err)
+	}
+	envelope := map[string]any{
+		"id":        UuidToString(event.ID),
+		"subject":   event.Subject,
+		"timestamp": event.Timestamp.Time.UTC().Format(time.RFC3339Nano),
+		"trace_id":  nil,
+		"data":      data,
+	}
+	if event.TraceID.Valid {
+		envelope["trace_id"] = UuidToString(event.TraceID)
+	}
+
+	out, _ := renderTransform(t.template, envelope)
+	return json.Marshal(out)
+}
+
+// renderTransform renders a template node, reporting false for a reference
+// that is missing from the event.
+func renderTransform(v any, envelope map[string]any) (any, bool) {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for key, value := range node {
+			if rendered, ok := renderTransform(value, envelope); ok {
+				out[key] = rendered
+			}
+		}
+		return out, true
+	case []any:
+		out := make([]any, len(node))
+		for i, value := range node {
+			out[i], _ = renderTransform(value, envelope)
+		}
+		return out, true
+	case string:
+		if strings.HasPrefix(node, transformRefEscape) {
+			return node[1:], true
+		}
+		if isTransformRef(node) {
+			return resolveTransformRef(node, envelope)
+		}
+	}
+	return v, true
+}
+
+func resolveTransformRef(ref string, envelope map[string]any) (any, bool) {
+	field, dataPath, err := parseTransformRef(ref)
+	switch {
+	case err != nil:
+		return nil, false
+	case field == "":
+		return envelope, true
+	case dataPath == "":
+		return envelope[field], true
+	}
+	data, ok := envelope[transformRefData].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupFilterPath(data, dataPath)
+}
+
+// decodeJSONNumbers decodes JSON keeping numbers as json.Number, so large
+// integers pass through a transform unchanged.
+func decodeJSONNumbers(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func TestTransformApply(t *testing.T) {
+	event := newTestEvent(func(e *db.Event) {
+		e.Subject = "order.created"
+		e.Timestamp = pgtype.Timestamptz{Time: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC), Valid: true}
+		e.Data = json.RawMessage(`{
+			"id": 12345678901234567890,
+			"customer": {"email": "ada@example.com", "tier": "gold"},
+			"items": [{"sku": "A-1"}, {"sku": "B-2"}],
+			"a.b": "dotted"
+		}`)
+	})
+	eventID := UuidToString(event.ID)
+	timestamp := `"2025-01-15T10:30:00Z"`
+
+	tests := []struct {
+		name      string
+		transform string
+		expected  string
+	}{
+		{"pick and rename", `{"order_id": "$.data.id", "email": "$.data.customer.email"}`, `{"email":"ada@example.com","order_id":12345678901234567890}`},
+		{"constant fields", `{"source": "shop", "version": 2, "live": true, "tags": ["a"]}`, `{"live":true,"source":"shop","tags":["a"],"version":2}`},
+		{"envelope fields", `{"kind": "$.subject", "at": "$.timestamp", "event": "$.id", "trace": "$.trace_id"}`, `{"at":` + timestamp + `,"event":"` + eventID + `","kind":"order.created","trace":null}`},
+		{"nested template", `{"customer": {"tier": "$.data.customer.tier"}, "skus": ["$.data.items.0.sku", "$.data.items.1.sku"]}`, `{"customer":{"tier":"gold"},"skus":["A-1","B-2"]}`},
+		{"whole data", `{"payload": "$.data", "kind": "$.subject"}`, `{"kind":"order.created","payload":{"a.b":"dotted","customer":{"email":"ada@example.com","tier":"gold"},"id":12345678901234567890,"items":[{"sku":"A-1"},{"sku":"B-2"}]}}`},
+		{"projection", `"$.data.customer"`, `{"email":"ada@example.com","tier":"gold"}`},
+		{"missing fields are left out", `{"coupon": "$.data.coupon", "email": "$.data.customer.email"}`, `{"email":"ada@example.com"}`},
+		{"missing array elements are null", `["$.data.items.5.sku", "$.subject"]`, `[null,"order.created"]`},
+		{"dotted key", `{"value": "$.data.a.b"}`, `{"value":"dotted"}`},
+		{"escaped dollar", `{"price": "$$5.00", "note": "costs $5"}`, `{"note":"costs $5","price":"$5.00"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transform, err := ParseTransform([]byte(tt.transform))
+			require.NoError(t, err)
+			output, err := transform.Apply(event)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestTransformApply_PreservesLargeNumbers(t *testing.T) {
+	event := newTestEvent(func(e *db.Event) { e.Data = json.RawMessage(`{"id":12345678901234567890,"amount":0.1}`) })
+	transform, err := ParseTransform([]byte(`{"order_id":"$.data.id","amount":"$.data.amount"}`))
+	require.NoError(t, err)
+
+	output, err := transform.Apply(event)
+	require.NoError(t, err)
+	assert.Equal(t, `{"amount":0.1,"order_id":12345678901234567890}`, string(output))
+}
+
+func TestTransformApply_Envelope(t *testing.T) {
+	traceID := newTestUUID()
+	event := newTestEvent(func(e *db.Event) {
+		e.Timestamp = pgtype.Timestamptz{Time: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC), Valid: true}
+		e.TraceID = traceID
+	})
+	transform, err := ParseTransform([]byte(`"$"`))
+	require.NoError(t, err)
+
+	output, err := transform.Apply(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "`+UuidToString(event.ID)+`",
+		"subject": "test.subject",
+		"timestamp": "2025-01-15T10:30:00Z",
+		"trace_id": "`+UuidToString(traceID)+`",
+		"data": {"key": "value"}
+	}`, string(output))
+}
+
+func TestParseTransform_Empty(t *testing.T) {
+	for _, raw := range []string{"", "null"} {
+		transform, err := ParseTransform([]byte(raw))
+		require.NoError(t, err, raw)
+		assert.Nil(t, transform, raw)
+
+		event := newTestEvent()
+		output, err := transform.Apply(event)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(event.Data), output)
+	}
+}
+
+func TestParseTransform_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		message   string
+	}{
+		{"invalid JSON", `{"a":`, "transform must be valid JSON"},
+		{"trailing data", `{} {}`, "transform must be valid JSON"},
+		{"unknown field", `{"a": "$.source"}`, `a: unknown reference "$.source"`},
+		{"field of envelope field", `{"a": "$.subject.name"}`, `a: unknown reference "$.subject.name"`},
+		{"missing dot", `{"a": "$data"}`, `a: reference "$data" must be $ or start with $.`},
+		{"empty path segment", `{"a": {"b": "$.data..id"}}`, `a.b: reference "$.data..id" must not contain empty path segments`},
+		{"in array", `{"a": ["$.data.id", "$.nope"]}`, `a[1]: unknown reference "$.nope"`},
+		{"first error by key", `{"b": "$.x", "a": "$.y"}`, `a: unknown reference "$.y"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTransform([]byte(tt.transform))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			var transformErr *TransformError
+			assert.ErrorAs(t, err, &transformErr)
+		})
+	}
+}
@@ -9,25 +9,34 @@ import (
 )
 
 type AppConfig struct {
-	DevMode    bool   `arg:"--dev,env:DEV_MODE" default:"false"`
-	Port       int    `arg:"-p,--port,env:LISTEN_PORT" default:"8005"`
-	LogLevel   string `arg:"--log-level,env:LOG_LEVEL" default:"default" help:"Log level to use.  Valid values are: debug, info, and warn/warning.  If default the level will be info or debug in dev mode."`
-	DBHost     string `arg:"--db-host,env:DB_HOST" default:"localhost"`
-	DBName     string `arg:"--db-name,env:DB_NAME" default:"slurpee"`
-	DBPort     int    `arg:"--db-port,env:DB_PORT" default:"5432"`
-	DBMaxConns int    `arg:"--db-max-conns,env:DB_MAX_CONNS" default:"10"`
-	DBMinConns int    `arg:"--db-min-conns,env:DB_MIN_CONNS" default:"1"`
-	DBSSLMode  string `arg:"--db-ssl-mode,env:DB_SSL_MODE" default:"disable"`
-	DBUsername string `arg:"--db-username,env:DB_USERNAME" default:"slurpee"`
-	DBPassword string `arg:"--db-password,env:DB_PASSWORD" default:"badpassword"`
-	BaseURL     string `arg:"--base-url,env:BASE_URL" default:"http://localhost:8005" help:"Base URL for the application."`
-	AdminSecret       string `arg:"--admin-secret,env:ADMIN_SECRET" default:"" help:"Pre-shared secret for admin API endpoints (X-Slurpee-Admin-Secret header)."`
-	MaxParallel       int    `arg:"--max-parallel,env:MAX_PARALLEL" default:"1" help:"Default max parallel deliveries per subscriber."`
-	MaxRetries        int    `arg:"--max-retries,env:MAX_RETRIES" default:"5" help:"Maximum number of delivery retry attempts per subscription."`
-	MaxBackoffSeconds int    `arg:"--max-backoff-seconds,env:MAX_BACKOFF_SECONDS" default:"300" help:"Maximum backoff delay in seconds between retries (cap for exponential backoff)."`
-	DeliveryQueueSize int    `arg:"--delivery-queue-size,env:DELIVERY_QUEUE_SIZE" default:"5000" help:"Capacity of the internal delivery task queue."`
-	DeliveryWorkers   int    `arg:"--delivery-workers,env:DELIVERY_WORKERS" default:"10" help:"Number of concurrent delivery worker goroutines."`
-	DeliveryChanSize  int    `arg:"--delivery-chan-size,env:DELIVERY_CHAN_SIZE" default:"1000" help:"Buffer size of the inbound event delivery channel."`
+	DevMode                 bool   `arg:"--dev,env:DEV_MODE" default:"false"`
+	Port                    int    `arg:"-p,--port,env:LISTEN_PORT" default:"8005"`
+	LogLevel                string `arg:"--log-level,env:LOG_LEVEL" default:"default" help:"Log level to use.  Valid values are: debug, info, and warn/warning.  If default the level will be info or debug in dev mode."`
+	DBHost                  string `arg:"--db-host,env:DB_HOST" default:"localhost"`
+	DBName                  string `arg:"--db-name,env:DB_NAME" default:"slurpee"`
+	DBPort                  int    `arg:"--db-port,env:DB_PORT" default:"5432"`
+	DBMaxConns              int    `arg:"--db-max-conns,env:DB_MAX_CONNS" default:"10"`
+	DBMinConns              int    `arg:"--db-min-conns,env:DB_MIN_CONNS" default:"1"`
+	DBSSLMode               string `arg:"--db-ssl-mode,env:DB_SSL_MODE" default:"disable"`
+	DBUsername              string `arg:"--db-username,env:DB_USERNAME" default:"slurpee"`
+	DBPassword              string `arg:"--db-password,env:DB_PASSWORD" default:"badpassword"`
+	BaseURL                 string `arg:"--base-url,env:BASE_URL" default:"http://localhost:8005" help:"Base URL for the application."`
+	AdminSecret             string `arg:"--admin-secret,env:ADMIN_SECRET" default:"" help:"Pre-shared secret for admin API endpoints (X-Slurpee-Admin-Secret header)."`
+	MaxParallel             int    `arg:"--max-parallel,env:MAX_PARALLEL" default:"1" help:"Default max parallel deliveries per subscriber."`
+	MaxRetries              int    `arg:"--max-retries,env:MAX_RETRIES" default:"5" help:"Maximum number of delivery retry attempts per subscription."`
+	MaxBackoffSeconds       int    `arg:"--max-backoff-seconds,env:MAX_BACKOFF_SECONDS" default:"300" help:"Maximum backoff delay in seconds between retries (cap for exponential backoff)."`
+	DeliveryQueueSize       int    `arg:"--delivery-queue-size,env:DELIVERY_QUEUE_SIZE" default:"5000" help:"Capacity of the internal delivery task queue."`
+	DeliveryWorkers         int    `arg:"--delivery-workers,env:DELIVERY_WORKERS" default:"10" help:"Number of concurrent delivery worker goroutines."`
+	DeliveryChanSize        int    `arg:"--delivery-chan-size,env:DELIVERY_CHAN_SIZE" default:"1000" help:"Buffer size of the inbound event delivery channel."`
+	RetryPollSeconds        int    `arg:"--retry-poll-seconds,env:RETRY_POLL_SECONDS" default:"1" help:"How often the retry poller checks for due delivery retries."`
+	RetryPollBatchSize      int    `arg:"--retry-poll-batch-size,env:RETRY_POLL_BATCH_SIZE" default:"500" help:"Maximum number of due retries claimed per poll."`
+	BreakerFailureThreshold int    `arg:"--breaker-failure-threshold,env:BREAKER_FAILURE_THRESHOLD" default:"5" help:"Consecutive delivery failures that open a subscriber's circuit breaker. 0 disables the breaker."`
+	BreakerCooldownSeconds  int    `arg:"--breaker-cooldown-seconds,env:BREAKER_COOLDOWN_SECONDS" default:"30" help:"Seconds an open circuit breaker waits before letting a probe delivery through."`
+	MaxRetryAfterSeconds    int    `arg:"--max-retry-after-seconds,env:MAX_RETRY_AFTER_SECONDS" default:"3600" help:"Maximum retry delay in seconds accepted from a subscriber's Retry-After header."`
+	EncryptionKey           string `arg:"--encryption-key,env:ENCRYPTION_KEY" default:"" help:"Base64-encoded 32-byte key used to encrypt subscriber secrets at rest, such as mTLS client keys."`
+	MetricsToken            string `arg:"--metrics-token,env:METRICS_TOKEN" default:"" help:"Bearer token required to scrape /metrics. The endpoint is disabled when empty."`
+	OTLPEndpoint            string `arg:"--otlp-endpoint,env:OTEL_EXPORTER_OTLP_ENDPOINT" default:"" help:"Base URL of an OpenTelemetry collector accepting OTLP/HTTP, e.g. http://localhost:4318. Trace export is disabled when empty."`
+	ServiceName             string `arg:"--service-name,env:OTEL_SERVICE_NAME" default:"slurpee" help:"Service name reported with exported traces."`
 }
 
 func LoadConfig() (*AppConfig, error) {
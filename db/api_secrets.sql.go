@@ -37,7 +37,7 @@ func (q *Queries) DeleteApiSecret(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getApiSecretByID = `-- name: GetApiSecretByID :one
-SELECT id, name, secret_hash, subject_pattern, created_at FROM api_secrets WHERE id = $1
+SELECT id, name, secret_hash, subject_pattern, created_at, pattern_syntax FROM api_secrets WHERE id = $1
 `
 
 func (q *Queries) GetApiSecretByID(ctx context.Context, id pgtype.UUID) (ApiSecret, error) {
@@ -49,6 +49,7 @@ func (q *Queries) GetApiSecretByID(ctx context.Context, id pgtype.UUID) (ApiSecr
 		&i.SecretHash,
 		&i.SubjectPattern,
 		&i.CreatedAt,
+		&i.PatternSyntax,
 	)
 	return i, err
 }
@@ -73,9 +74,9 @@ func (q *Queries) GetApiSecretSubscriberExists(ctx context.Context, arg GetApiSe
 }
 
 const insertApiSecret = `-- name: InsertApiSecret :one
-INSERT INTO api_secrets (id, name, secret_hash, subject_pattern, created_at)
-VALUES ($1, $2, $3, $4, now())
-RETURNING id, name, secret_hash, subject_pattern, created_at
+INSERT INTO api_secrets (id, name, secret_hash, subject_pattern, pattern_syntax, created_at)
+VALUES ($1, $2, $3, $4, $5, now())
+RETURNING id, name, secret_hash, subject_pattern, created_at, pattern_syntax
 `
 
 type InsertApiSecretParams struct {
@@ -83,6 +84,7 @@ type InsertApiSecretParams struct {
 	Name           string
 	SecretHash     string
 	SubjectPattern string
+	PatternSyntax  string
 }
 
 func (q *Queries) InsertApiSecret(ctx context.Context, arg InsertApiSecretParams) (ApiSecret, error) {
@@ -91,6 +93,7 @@ func (q *Queries) InsertApiSecret(ctx context.Context, arg InsertApiSecretParams
 		arg.Name,
 		arg.SecretHash,
 		arg.SubjectPattern,
+		arg.PatternSyntax,
 	)
 	var i ApiSecret
 	err := row.Scan(
@@ -99,18 +102,20 @@ func (q *Queries) InsertApiSecret(ctx context.Context, arg InsertApiSecretParams
 		&i.SecretHash,
 		&i.SubjectPattern,
 		&i.CreatedAt,
+		&i.PatternSyntax,
 	)
 	return i, err
 }
 
 const listAllApiSecretHashes = `-- name: ListAllApiSecretHashes :many
-SELECT id, secret_hash, subject_pattern FROM api_secrets
+SELECT id, secret_hash, subject_pattern, pattern_syntax FROM api_secrets
 `
 
 type ListAllApiSecretHashesRow struct {
 	ID             pgtype.UUID
 	SecretHash     string
 	SubjectPattern string
+	PatternSyntax  string
 }
 
 func (q *Queries) ListAllApiSecretHashes(ctx context.Context) ([]ListAllApiSecretHashesRow, error) {
@@ -122,7 +127,12 @@ func (q *Queries) ListAllApiSecretHashes(ctx context.Context) ([]ListAllApiSecre
 	var items []ListAllApiSecretHashesRow
 	for rows.Next() {
 		var i ListAllApiSecretHashesRow
-		if err := rows.Scan(&i.ID, &i.SecretHash, &i.SubjectPattern); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.SecretHash,
+			&i.SubjectPattern,
+			&i.PatternSyntax,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -135,7 +145,7 @@ func (q *Queries) ListAllApiSecretHashes(ctx context.Context) ([]ListAllApiSecre
 
 const listApiSecrets = `-- name: ListApiSecrets :many
 SELECT
-    s.id, s.name, s.secret_hash, s.subject_pattern, s.created_at,
+    s.id, s.name, s.secret_hash, s.subject_pattern, s.created_at, s.pattern_syntax,
     COALESCE(
         string_agg(sub.name, ', ' ORDER BY sub.name),
         ''
@@ -153,6 +163,7 @@ type ListApiSecretsRow struct {
 	SecretHash      string
 	SubjectPattern  string
 	CreatedAt       pgtype.Timestamptz
+	PatternSyntax   string
 	SubscriberNames string
 }
 
@@ -171,6 +182,7 @@ func (q *Queries) ListApiSecrets(ctx context.Context) ([]ListApiSecretsRow, erro
 			&i.SecretHash,
 			&i.SubjectPattern,
 			&i.CreatedAt,
+			&i.PatternSyntax,
 			&i.SubscriberNames,
 		); err != nil {
 			return nil, err
@@ -184,7 +196,7 @@ func (q *Queries) ListApiSecrets(ctx context.Context) ([]ListApiSecretsRow, erro
 }
 
 const listApiSecretsForSubscriber = `-- name: ListApiSecretsForSubscriber :many
-SELECT s.id, s.name, s.secret_hash, s.subject_pattern, s.created_at
+SELECT s.id, s.name, s.secret_hash, s.subject_pattern, s.created_at, s.pattern_syntax
 FROM api_secrets s
 JOIN api_secret_subscribers ass ON ass.api_secret_id = s.id
 WHERE ass.subscriber_id = $1
@@ -206,6 +218,7 @@ func (q *Queries) ListApiSecretsForSubscriber(ctx context.Context, subscriberID
 			&i.SecretHash,
 			&i.SubjectPattern,
 			&i.CreatedAt,
+			&i.PatternSyntax,
 		); err != nil {
 			return nil, err
 		}
@@ -218,7 +231,7 @@ func (q *Queries) ListApiSecretsForSubscriber(ctx context.Context, subscriberID
 }
 
 const listSubscribersForApiSecret = `-- name: ListSubscribersForApiSecret :many
-SELECT sub.id, sub.name, sub.endpoint_url, sub.auth_secret, sub.max_parallel, sub.created_at, sub.updated_at
+SELECT sub.id, sub.name, sub.endpoint_url, sub.auth_secret, sub.max_parallel, sub.created_at, sub.updated_at, sub.signing_mode, sub.payload_format, sub.batch_size, sub.batch_linger_ms, sub.non_retryable_statuses, sub.disable_on_gone, sub.disabled_at, sub.disabled_reason, sub.http_method, sub.timeout_seconds, sub.extra_headers, sub.ca_bundle, sub.client_cert, sub.client_key_encrypted, sub.auth_mode, sub.oauth_token_url, sub.oauth_client_id, sub.oauth_client_secret_encrypted, sub.oauth_scopes, sub.rate_limit_per_second, sub.rate_limit_burst, sub.paused_at
 FROM subscribers sub
 JOIN api_secret_subscribers ass ON ass.subscriber_id = sub.id
 WHERE ass.api_secret_id = $1
@@ -242,6 +255,28 @@ func (q *Queries) ListSubscribersForApiSecret(ctx context.Context, apiSecretID p
 			&i.MaxParallel,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SigningMode,
+			&i.PayloadFormat,
+			&i.BatchSize,
+			&i.BatchLingerMs,
+			&i.NonRetryableStatuses,
+			&i.DisableOnGone,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.HttpMethod,
+			&i.TimeoutSeconds,
+			&i.ExtraHeaders,
+			&i.CaBundle,
+			&i.ClientCert,
+			&i.ClientKeyEncrypted,
+			&i.AuthMode,
+			&i.OauthTokenUrl,
+			&i.OauthClientID,
+			&i.OauthClientSecretEncrypted,
+			&i.OauthScopes,
+			&i.RateLimitPerSecond,
+			&i.RateLimitBurst,
+			&i.PausedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -280,19 +315,26 @@ func (q *Queries) RemoveApiSecretSubscriber(ctx context.Context, arg RemoveApiSe
 const updateApiSecret = `-- name: UpdateApiSecret :one
 UPDATE api_secrets SET
     name = $1,
-    subject_pattern = $2
-WHERE id = $3
-RETURNING id, name, secret_hash, subject_pattern, created_at
+    subject_pattern = $2,
+    pattern_syntax = $3
+WHERE id = $4
+RETURNING id, name, secret_hash, subject_pattern, created_at, pattern_syntax
 `
 
 type UpdateApiSecretParams struct {
 	Name           string
 	SubjectPattern string
+	PatternSyntax  string
 	ID             pgtype.UUID
 }
 
 func (q *Queries) UpdateApiSecret(ctx context.Context, arg UpdateApiSecretParams) (ApiSecret, error) {
-	row := q.db.QueryRow(ctx, updateApiSecret, arg.Name, arg.SubjectPattern, arg.ID)
+	row := q.db.QueryRow(ctx, updateApiSecret,
+		arg.Name,
+		arg.SubjectPattern,
+		arg.PatternSyntax,
+		arg.ID,
+	)
 	var i ApiSecret
 	err := row.Scan(
 		&i.ID,
@@ -300,6 +342,7 @@ func (q *Queries) UpdateApiSecret(ctx context.Context, arg UpdateApiSecretParams
 		&i.SecretHash,
 		&i.SubjectPattern,
 		&i.CreatedAt,
+		&i.PatternSyntax,
 	)
 	return i, err
 }
@@ -11,46 +11,10 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const getDeliverySummaryForEvent = `-- name: GetDeliverySummaryForEvent :many
-SELECT
-  subscriber_id,
-  COUNT(*) FILTER (WHERE status = 'failed')::bigint AS failed_count,
-  COUNT(*) FILTER (WHERE status = 'succeeded')::bigint AS succeeded_count
-FROM delivery_attempts
-WHERE event_id = $1
-GROUP BY subscriber_id
-`
-
-type GetDeliverySummaryForEventRow struct {
-	SubscriberID   pgtype.UUID
-	FailedCount    int64
-	SucceededCount int64
-}
-
-func (q *Queries) GetDeliverySummaryForEvent(ctx context.Context, eventID pgtype.UUID) ([]GetDeliverySummaryForEventRow, error) {
-	rows, err := q.db.Query(ctx, getDeliverySummaryForEvent, eventID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetDeliverySummaryForEventRow
-	for rows.Next() {
-		var i GetDeliverySummaryForEventRow
-		if err := rows.Scan(&i.SubscriberID, &i.FailedCount, &i.SucceededCount); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const insertDeliveryAttempt = `-- name: InsertDeliveryAttempt :one
-INSERT INTO delivery_attempts (id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status
+INSERT INTO delivery_attempts (id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status, duration_ms
 `
 
 type InsertDeliveryAttemptParams struct {
@@ -64,6 +28,7 @@ type InsertDeliveryAttemptParams struct {
 	ResponseHeaders    []byte
 	ResponseBody       string
 	Status             string
+	DurationMs         pgtype.Int4
 }
 
 func (q *Queries) InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryAttemptParams) (DeliveryAttempt, error) {
@@ -78,6 +43,7 @@ func (q *Queries) InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryA
 		arg.ResponseHeaders,
 		arg.ResponseBody,
 		arg.Status,
+		arg.DurationMs,
 	)
 	var i DeliveryAttempt
 	err := row.Scan(
@@ -91,12 +57,13 @@ func (q *Queries) InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryA
 		&i.ResponseHeaders,
 		&i.ResponseBody,
 		&i.Status,
+		&i.DurationMs,
 	)
 	return i, err
 }
 
 const listDeliveryAttemptsForEvent = `-- name: ListDeliveryAttemptsForEvent :many
-SELECT id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status FROM delivery_attempts WHERE event_id = $1 ORDER BY attempted_at
+SELECT id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status, duration_ms FROM delivery_attempts WHERE event_id = $1 ORDER BY attempted_at
 `
 
 func (q *Queries) ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgtype.UUID) ([]DeliveryAttempt, error) {
@@ -119,6 +86,7 @@ func (q *Queries) ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgty
 			&i.ResponseHeaders,
 			&i.ResponseBody,
 			&i.Status,
+			&i.DurationMs,
 		); err != nil {
 			return nil, err
 		}
@@ -131,7 +99,7 @@ func (q *Queries) ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgty
 }
 
 const listDeliveryAttemptsForSubscriber = `-- name: ListDeliveryAttemptsForSubscriber :many
-SELECT id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status FROM delivery_attempts WHERE subscriber_id = $1 ORDER BY attempted_at
+SELECT id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status, duration_ms FROM delivery_attempts WHERE subscriber_id = $1 ORDER BY attempted_at
 `
 
 func (q *Queries) ListDeliveryAttemptsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) ([]DeliveryAttempt, error) {
@@ -154,6 +122,7 @@ func (q *Queries) ListDeliveryAttemptsForSubscriber(ctx context.Context, subscri
 			&i.ResponseHeaders,
 			&i.ResponseBody,
 			&i.Status,
+			&i.DurationMs,
 		); err != nil {
 			return nil, err
 		}
@@ -166,7 +135,7 @@ func (q *Queries) ListDeliveryAttemptsForSubscriber(ctx context.Context, subscri
 }
 
 const updateDeliveryAttemptStatus = `-- name: UpdateDeliveryAttemptStatus :one
-UPDATE delivery_attempts SET status = $1, response_status_code = $2, response_headers = $3, response_body = $4 WHERE id = $5 RETURNING id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status
+UPDATE delivery_attempts SET status = $1, response_status_code = $2, response_headers = $3, response_body = $4 WHERE id = $5 RETURNING id, event_id, subscriber_id, endpoint_url, attempted_at, request_headers, response_status_code, response_headers, response_body, status, duration_ms
 `
 
 type UpdateDeliveryAttemptStatusParams struct {
@@ -197,6 +166,7 @@ func (q *Queries) UpdateDeliveryAttemptStatus(ctx context.Context, arg UpdateDel
 		&i.ResponseHeaders,
 		&i.ResponseBody,
 		&i.Status,
+		&i.DurationMs,
 	)
 	return i, err
 }
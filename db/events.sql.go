@@ -41,8 +41,42 @@ func (q *Queries) CountEventsAfterTimestamp(ctx context.Context, arg CountEvents
 	return count, err
 }
 
+const countEventsForReplay = `-- name: CountEventsForReplay :one
+SELECT count(*) FROM events
+WHERE
+  ($1::text = '' OR subject LIKE $1)
+  AND ($2::text = '' OR delivery_status = $2)
+  AND ($3::timestamptz IS NULL OR timestamp >= $3)
+  AND timestamp <= $4
+  AND ($5::jsonb IS NULL OR data @> $5)
+  AND ($6::text = '' OR trace_id::text = $6)
+`
+
+type CountEventsForReplayParams struct {
+	SubjectFilter   string
+	StatusFilter    string
+	StartTimeFilter pgtype.Timestamptz
+	EndTimeFilter   pgtype.Timestamptz
+	DataFilter      []byte
+	TraceIDFilter   string
+}
+
+func (q *Queries) CountEventsForReplay(ctx context.Context, arg CountEventsForReplayParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countEventsForReplay,
+		arg.SubjectFilter,
+		arg.StatusFilter,
+		arg.StartTimeFilter,
+		arg.EndTimeFilter,
+		arg.DataFilter,
+		arg.TraceIDFilter,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getEventByID = `-- name: GetEventByID :one
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE id = $1
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE id = $1
 `
 
 func (q *Queries) GetEventByID(ctx context.Context, id pgtype.UUID) (Event, error) {
@@ -57,12 +91,16 @@ func (q *Queries) GetEventByID(ctx context.Context, id pgtype.UUID) (Event, erro
 		&i.RetryCount,
 		&i.DeliveryStatus,
 		&i.StatusUpdatedAt,
+		&i.ApiSecretID,
+		&i.CreatedAt,
+		&i.Traceparent,
+		&i.SubscriberScope,
 	)
 	return i, err
 }
 
 const getResumableEvents = `-- name: GetResumableEvents :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE delivery_status IN ('pending', 'partial') ORDER BY timestamp ASC
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE delivery_status IN ('pending', 'partial') ORDER BY timestamp ASC
 `
 
 func (q *Queries) GetResumableEvents(ctx context.Context) ([]Event, error) {
@@ -83,6 +121,10 @@ func (q *Queries) GetResumableEvents(ctx context.Context) ([]Event, error) {
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -95,9 +137,9 @@ func (q *Queries) GetResumableEvents(ctx context.Context) ([]Event, error) {
 }
 
 const insertEvent = `-- name: InsertEvent :one
-INSERT INTO events (id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at
+INSERT INTO events (id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, traceparent, subscriber_scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, (SELECT array_agg(subscriber_id) FROM api_secret_subscribers WHERE api_secret_id = $9))
+RETURNING id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope
 `
 
 type InsertEventParams struct {
@@ -109,6 +151,8 @@ type InsertEventParams struct {
 	RetryCount      int32
 	DeliveryStatus  string
 	StatusUpdatedAt pgtype.Timestamptz
+	ApiSecretID     pgtype.UUID
+	Traceparent     pgtype.Text
 }
 
 func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event, error) {
@@ -121,6 +165,8 @@ func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event
 		arg.RetryCount,
 		arg.DeliveryStatus,
 		arg.StatusUpdatedAt,
+		arg.ApiSecretID,
+		arg.Traceparent,
 	)
 	var i Event
 	err := row.Scan(
@@ -132,12 +178,16 @@ func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event
 		&i.RetryCount,
 		&i.DeliveryStatus,
 		&i.StatusUpdatedAt,
+		&i.ApiSecretID,
+		&i.CreatedAt,
+		&i.Traceparent,
+		&i.SubscriberScope,
 	)
 	return i, err
 }
 
 const listEvents = `-- name: ListEvents :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events ORDER BY timestamp DESC LIMIT $1 OFFSET $2
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events ORDER BY timestamp DESC LIMIT $1 OFFSET $2
 `
 
 type ListEventsParams struct {
@@ -163,6 +213,10 @@ func (q *Queries) ListEvents(ctx context.Context, arg ListEventsParams) ([]Event
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -175,7 +229,7 @@ func (q *Queries) ListEvents(ctx context.Context, arg ListEventsParams) ([]Event
 }
 
 const listEventsAfterTimestamp = `-- name: ListEventsAfterTimestamp :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events
 WHERE timestamp > $1::timestamptz
   AND ($2::text = '' OR subject LIKE $2)
   AND ($3::text = '' OR delivery_status = $3)
@@ -217,6 +271,80 @@ func (q *Queries) ListEventsAfterTimestamp(ctx context.Context, arg ListEventsAf
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsForReplay = `-- name: ListEventsForReplay :many
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events
+WHERE
+  ($1::text = '' OR subject LIKE $1)
+  AND ($2::text = '' OR delivery_status = $2)
+  AND ($3::timestamptz IS NULL OR timestamp >= $3)
+  AND timestamp <= $4
+  AND ($5::jsonb IS NULL OR data @> $5)
+  AND ($6::text = '' OR trace_id::text = $6)
+  AND ($7::timestamptz IS NULL
+    OR (timestamp, id) > ($7, $8::uuid))
+ORDER BY timestamp, id
+LIMIT $9
+`
+
+type ListEventsForReplayParams struct {
+	SubjectFilter   string
+	StatusFilter    string
+	StartTimeFilter pgtype.Timestamptz
+	EndTimeFilter   pgtype.Timestamptz
+	DataFilter      []byte
+	TraceIDFilter   string
+	AfterTimestamp  pgtype.Timestamptz
+	AfterEventID    pgtype.UUID
+	RowLimit        int32
+}
+
+func (q *Queries) ListEventsForReplay(ctx context.Context, arg ListEventsForReplayParams) ([]Event, error) {
+	rows, err := q.db.Query(ctx, listEventsForReplay,
+		arg.SubjectFilter,
+		arg.StatusFilter,
+		arg.StartTimeFilter,
+		arg.EndTimeFilter,
+		arg.DataFilter,
+		arg.TraceIDFilter,
+		arg.AfterTimestamp,
+		arg.AfterEventID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.Timestamp,
+			&i.TraceID,
+			&i.Data,
+			&i.RetryCount,
+			&i.DeliveryStatus,
+			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -229,7 +357,7 @@ func (q *Queries) ListEventsAfterTimestamp(ctx context.Context, arg ListEventsAf
 }
 
 const searchEventsByDataContent = `-- name: SearchEventsByDataContent :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE data @> $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE data @> $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
 `
 
 type SearchEventsByDataContentParams struct {
@@ -256,6 +384,10 @@ func (q *Queries) SearchEventsByDataContent(ctx context.Context, arg SearchEvent
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -268,7 +400,7 @@ func (q *Queries) SearchEventsByDataContent(ctx context.Context, arg SearchEvent
 }
 
 const searchEventsByDateRange = `-- name: SearchEventsByDateRange :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE timestamp >= $3 AND timestamp <= $4 ORDER BY timestamp DESC LIMIT $1 OFFSET $2
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE timestamp >= $3 AND timestamp <= $4 ORDER BY timestamp DESC LIMIT $1 OFFSET $2
 `
 
 type SearchEventsByDateRangeParams struct {
@@ -301,6 +433,10 @@ func (q *Queries) SearchEventsByDateRange(ctx context.Context, arg SearchEventsB
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -313,7 +449,7 @@ func (q *Queries) SearchEventsByDateRange(ctx context.Context, arg SearchEventsB
 }
 
 const searchEventsByDeliveryStatus = `-- name: SearchEventsByDeliveryStatus :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE delivery_status = $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE delivery_status = $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
 `
 
 type SearchEventsByDeliveryStatusParams struct {
@@ -340,6 +476,10 @@ func (q *Queries) SearchEventsByDeliveryStatus(ctx context.Context, arg SearchEv
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -352,7 +492,7 @@ func (q *Queries) SearchEventsByDeliveryStatus(ctx context.Context, arg SearchEv
 }
 
 const searchEventsBySubject = `-- name: SearchEventsBySubject :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events WHERE subject = $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events WHERE subject = $1 ORDER BY timestamp DESC LIMIT $2 OFFSET $3
 `
 
 type SearchEventsBySubjectParams struct {
@@ -379,6 +519,10 @@ func (q *Queries) SearchEventsBySubject(ctx context.Context, arg SearchEventsByS
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -391,7 +535,7 @@ func (q *Queries) SearchEventsBySubject(ctx context.Context, arg SearchEventsByS
 }
 
 const searchEventsFiltered = `-- name: SearchEventsFiltered :many
-SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at FROM events
+SELECT id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope FROM events
 WHERE
   ($3::text = '' OR subject LIKE $3)
   AND ($4::text = '' OR delivery_status = $4)
@@ -440,6 +584,10 @@ func (q *Queries) SearchEventsFiltered(ctx context.Context, arg SearchEventsFilt
 			&i.RetryCount,
 			&i.DeliveryStatus,
 			&i.StatusUpdatedAt,
+			&i.ApiSecretID,
+			&i.CreatedAt,
+			&i.Traceparent,
+			&i.SubscriberScope,
 		); err != nil {
 			return nil, err
 		}
@@ -452,7 +600,7 @@ func (q *Queries) SearchEventsFiltered(ctx context.Context, arg SearchEventsFilt
 }
 
 const updateEventDeliveryStatus = `-- name: UpdateEventDeliveryStatus :one
-UPDATE events SET delivery_status = $1, retry_count = $2, status_updated_at = $3 WHERE id = $4 RETURNING id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at
+UPDATE events SET delivery_status = $1, retry_count = $2, status_updated_at = $3 WHERE id = $4 RETURNING id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, created_at, traceparent, subscriber_scope
 `
 
 type UpdateEventDeliveryStatusParams struct {
@@ -479,6 +627,10 @@ func (q *Queries) UpdateEventDeliveryStatus(ctx context.Context, arg UpdateEvent
 		&i.RetryCount,
 		&i.DeliveryStatus,
 		&i.StatusUpdatedAt,
+		&i.ApiSecretID,
+		&i.CreatedAt,
+		&i.Traceparent,
+		&i.SubscriberScope,
 	)
 	return i, err
 }
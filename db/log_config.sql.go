@@ -21,18 +21,23 @@ func (q *Queries) DeleteLogConfigForSubject(ctx context.Context, subject string)
 }
 
 const getLogConfigBySubject = `-- name: GetLogConfigBySubject :one
-SELECT id, subject, log_properties FROM log_config WHERE subject = $1
+SELECT id, subject, log_properties, pattern_syntax FROM log_config WHERE subject = $1
 `
 
 func (q *Queries) GetLogConfigBySubject(ctx context.Context, subject string) (LogConfig, error) {
 	row := q.db.QueryRow(ctx, getLogConfigBySubject, subject)
 	var i LogConfig
-	err := row.Scan(&i.ID, &i.Subject, &i.LogProperties)
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.LogProperties,
+		&i.PatternSyntax,
+	)
 	return i, err
 }
 
 const listLogConfigs = `-- name: ListLogConfigs :many
-SELECT id, subject, log_properties FROM log_config ORDER BY subject
+SELECT id, subject, log_properties, pattern_syntax FROM log_config ORDER BY subject
 `
 
 func (q *Queries) ListLogConfigs(ctx context.Context) ([]LogConfig, error) {
@@ -44,7 +49,12 @@ func (q *Queries) ListLogConfigs(ctx context.Context) ([]LogConfig, error) {
 	var items []LogConfig
 	for rows.Next() {
 		var i LogConfig
-		if err := rows.Scan(&i.ID, &i.Subject, &i.LogProperties); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Subject,
+			&i.LogProperties,
+			&i.PatternSyntax,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -56,22 +66,34 @@ func (q *Queries) ListLogConfigs(ctx context.Context) ([]LogConfig, error) {
 }
 
 const upsertLogConfig = `-- name: UpsertLogConfig :one
-INSERT INTO log_config (id, subject, log_properties)
-VALUES ($1, $2, $3)
+INSERT INTO log_config (id, subject, log_properties, pattern_syntax)
+VALUES ($1, $2, $3, $4)
 ON CONFLICT (subject) DO UPDATE SET
-    log_properties = EXCLUDED.log_properties
-RETURNING id, subject, log_properties
+    log_properties = EXCLUDED.log_properties,
+    pattern_syntax = EXCLUDED.pattern_syntax
+RETURNING id, subject, log_properties, pattern_syntax
 `
 
 type UpsertLogConfigParams struct {
 	ID            pgtype.UUID
 	Subject       string
 	LogProperties []string
+	PatternSyntax string
 }
 
 func (q *Queries) UpsertLogConfig(ctx context.Context, arg UpsertLogConfigParams) (LogConfig, error) {
-	row := q.db.QueryRow(ctx, upsertLogConfig, arg.ID, arg.Subject, arg.LogProperties)
+	row := q.db.QueryRow(ctx, upsertLogConfig,
+		arg.ID,
+		arg.Subject,
+		arg.LogProperties,
+		arg.PatternSyntax,
+	)
 	var i LogConfig
-	err := row.Scan(&i.ID, &i.Subject, &i.LogProperties)
+	err := row.Scan(
+		&i.ID,
+		&i.Subject,
+		&i.LogProperties,
+		&i.PatternSyntax,
+	)
 	return i, err
 }
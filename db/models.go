@@ -14,6 +14,7 @@ type ApiSecret struct {
 	SecretHash     string
 	SubjectPattern string
 	CreatedAt      pgtype.Timestamptz
+	PatternSyntax  string
 }
 
 type ApiSecretSubscriber struct {
@@ -21,6 +22,30 @@ type ApiSecretSubscriber struct {
 	SubscriberID pgtype.UUID
 }
 
+type DeadLetter struct {
+	ID             pgtype.UUID
+	EventID        pgtype.UUID
+	SubscriberID   pgtype.UUID
+	SubscriptionID pgtype.UUID
+	Attempts       int32
+	LastError      string
+	ExhaustedAt    pgtype.Timestamptz
+}
+
+type Delivery struct {
+	EventID        pgtype.UUID
+	SubscriberID   pgtype.UUID
+	SubscriptionID pgtype.UUID
+	Status         string
+	Attempts       int32
+	LastAttemptAt  pgtype.Timestamptz
+	NextAttemptAt  pgtype.Timestamptz
+	LastError      string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	LagMs          pgtype.Int8
+}
+
 type DeliveryAttempt struct {
 	ID                 pgtype.UUID
 	EventID            pgtype.UUID
@@ -32,6 +57,19 @@ type DeliveryAttempt struct {
 	ResponseHeaders    []byte
 	ResponseBody       string
 	Status             string
+	DurationMs         pgtype.Int4
+}
+
+type DeliveryRetry struct {
+	EventID        pgtype.UUID
+	SubscriberID   pgtype.UUID
+	SubscriptionID pgtype.UUID
+	AttemptNum     int32
+	MaxRetries     int32
+	NextAttemptAt  pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	FirstAttemptAt pgtype.Timestamptz
+	Held           bool
 }
 
 type Event struct {
@@ -43,30 +81,89 @@ type Event struct {
 	RetryCount      int32
 	DeliveryStatus  string
 	StatusUpdatedAt pgtype.Timestamptz
+	ApiSecretID     pgtype.UUID
+	CreatedAt       pgtype.Timestamptz
+	Traceparent     pgtype.Text
+	SubscriberScope []pgtype.UUID
 }
 
 type LogConfig struct {
 	ID            pgtype.UUID
 	Subject       string
 	LogProperties []string
+	PatternSyntax string
+}
+
+type ReplayJob struct {
+	ID              pgtype.UUID
+	SubjectFilter   string
+	StatusFilter    string
+	StartTimeFilter pgtype.Timestamptz
+	EndTimeFilter   pgtype.Timestamptz
+	DataFilter      []byte
+	TraceIDFilter   string
+	SubscriberID    pgtype.UUID
+	RatePerSecond   int32
+	Status          string
+	Total           int32
+	Processed       int32
+	Replayed        int32
+	Skipped         int32
+	Failed          int32
+	CursorTimestamp pgtype.Timestamptz
+	CursorEventID   pgtype.UUID
+	LastError       string
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	FinishedAt      pgtype.Timestamptz
 }
 
 type Subscriber struct {
-	ID          pgtype.UUID
-	Name        string
-	EndpointUrl string
-	AuthSecret  string
-	MaxParallel int32
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+	ID                         pgtype.UUID
+	Name                       string
+	EndpointUrl                string
+	AuthSecret                 string
+	MaxParallel                int32
+	CreatedAt                  pgtype.Timestamptz
+	UpdatedAt                  pgtype.Timestamptz
+	SigningMode                string
+	PayloadFormat              string
+	BatchSize                  int32
+	BatchLingerMs              int32
+	NonRetryableStatuses       []int32
+	DisableOnGone              bool
+	DisabledAt                 pgtype.Timestamptz
+	DisabledReason             string
+	HttpMethod                 string
+	TimeoutSeconds             int32
+	ExtraHeaders               []byte
+	CaBundle                   string
+	ClientCert                 string
+	ClientKeyEncrypted         string
+	AuthMode                   string
+	OauthTokenUrl              string
+	OauthClientID              string
+	OauthClientSecretEncrypted string
+	OauthScopes                []string
+	RateLimitPerSecond         float64
+	RateLimitBurst             int32
+	PausedAt                   pgtype.Timestamptz
 }
 
 type Subscription struct {
-	ID             pgtype.UUID
-	SubscriberID   pgtype.UUID
-	SubjectPattern string
-	Filter         []byte
-	MaxRetries     pgtype.Int4
-	CreatedAt      pgtype.Timestamptz
-	UpdatedAt      pgtype.Timestamptz
+	ID                   pgtype.UUID
+	SubscriberID         pgtype.UUID
+	SubjectPattern       string
+	Filter               []byte
+	MaxRetries           pgtype.Int4
+	CreatedAt            pgtype.Timestamptz
+	UpdatedAt            pgtype.Timestamptz
+	Ordered              bool
+	OrderingKey          string
+	RetryPolicy          string
+	RetryIntervalSeconds int32
+	RetrySchedule        []int32
+	RetryMaxAgeSeconds   pgtype.Int4
+	PatternSyntax        string
+	Transform            []byte
 }
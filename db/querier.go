@@ -12,40 +12,66 @@ import (
 
 type Querier interface {
 	AddApiSecretSubscriber(ctx context.Context, arg AddApiSecretSubscriberParams) error
+	ClaimDueDeliveryRetries(ctx context.Context, arg ClaimDueDeliveryRetriesParams) ([]DeliveryRetry, error)
+	CountDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) (int64, error)
 	CountEventsAfterTimestamp(ctx context.Context, arg CountEventsAfterTimestampParams) (int64, error)
+	CountEventsForReplay(ctx context.Context, arg CountEventsForReplayParams) (int64, error)
+	CountHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error)
 	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
 	DeleteApiSecret(ctx context.Context, id pgtype.UUID) error
+	DeleteDeadLetter(ctx context.Context, id pgtype.UUID) error
+	DeleteDeadLetterForSubscriber(ctx context.Context, arg DeleteDeadLetterForSubscriberParams) error
+	DeleteDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) error
+	DeleteDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) error
 	DeleteLogConfigForSubject(ctx context.Context, subject string) error
 	DeleteSubscriber(ctx context.Context, id pgtype.UUID) error
 	DeleteSubscription(ctx context.Context, id pgtype.UUID) error
 	DeleteSubscriptionsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) error
+	DisableSubscriber(ctx context.Context, arg DisableSubscriberParams) error
+	EnableSubscriber(ctx context.Context, id pgtype.UUID) error
+	FinishReplayJob(ctx context.Context, arg FinishReplayJobParams) error
 	GetApiSecretByID(ctx context.Context, id pgtype.UUID) (ApiSecret, error)
 	GetApiSecretSubscriberExists(ctx context.Context, arg GetApiSecretSubscriberExistsParams) (bool, error)
-	GetDeliverySummaryForEvent(ctx context.Context, eventID pgtype.UUID) ([]GetDeliverySummaryForEventRow, error)
+	GetDeadLettersByIDs(ctx context.Context, ids []pgtype.UUID) ([]DeadLetter, error)
+	GetDeliverySummaryForSubscriber(ctx context.Context, arg GetDeliverySummaryForSubscriberParams) (GetDeliverySummaryForSubscriberRow, error)
 	GetEventByID(ctx context.Context, id pgtype.UUID) (Event, error)
 	GetLogConfigBySubject(ctx context.Context, subject string) (LogConfig, error)
+	GetReplayJob(ctx context.Context, id pgtype.UUID) (ReplayJob, error)
 	GetResumableEvents(ctx context.Context) ([]Event, error)
 	GetSubscriberByEndpointURL(ctx context.Context, endpointUrl string) (Subscriber, error)
 	GetSubscriberByID(ctx context.Context, id pgtype.UUID) (Subscriber, error)
-	GetSubscriptionsMatchingSubject(ctx context.Context, subjectPattern string) ([]Subscription, error)
 	InsertApiSecret(ctx context.Context, arg InsertApiSecretParams) (ApiSecret, error)
 	InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryAttemptParams) (DeliveryAttempt, error)
 	InsertEvent(ctx context.Context, arg InsertEventParams) (Event, error)
+	InsertReplayJob(ctx context.Context, arg InsertReplayJobParams) (ReplayJob, error)
 	ListAllApiSecretHashes(ctx context.Context) ([]ListAllApiSecretHashesRow, error)
 	ListAllSubscriptions(ctx context.Context) ([]Subscription, error)
 	ListApiSecrets(ctx context.Context) ([]ListApiSecretsRow, error)
 	ListApiSecretsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) ([]ApiSecret, error)
+	ListDeadLettersFiltered(ctx context.Context, arg ListDeadLettersFilteredParams) ([]ListDeadLettersFilteredRow, error)
+	ListDeliveriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]ListDeliveriesForEventRow, error)
+	ListDeliveriesForSubscriber(ctx context.Context, arg ListDeliveriesForSubscriberParams) ([]ListDeliveriesForSubscriberRow, error)
 	ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgtype.UUID) ([]DeliveryAttempt, error)
 	ListDeliveryAttemptsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) ([]DeliveryAttempt, error)
+	ListDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]DeliveryRetry, error)
 	ListEvents(ctx context.Context, arg ListEventsParams) ([]Event, error)
 	ListEventsAfterTimestamp(ctx context.Context, arg ListEventsAfterTimestampParams) ([]Event, error)
+	ListEventsForReplay(ctx context.Context, arg ListEventsForReplayParams) ([]Event, error)
 	ListLogConfigs(ctx context.Context) ([]LogConfig, error)
+	ListReplayJobs(ctx context.Context, limit int32) ([]ReplayJob, error)
+	ListRunningReplayJobs(ctx context.Context) ([]ReplayJob, error)
+	ListSubscriberLatencyStats(ctx context.Context, since pgtype.Timestamptz) ([]ListSubscriberLatencyStatsRow, error)
 	ListSubscribers(ctx context.Context) ([]Subscriber, error)
 	ListSubscribersForApiSecret(ctx context.Context, apiSecretID pgtype.UUID) ([]Subscriber, error)
 	ListSubscribersWithCounts(ctx context.Context) ([]ListSubscribersWithCountsRow, error)
 	ListSubscriptionsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) ([]Subscription, error)
+	PauseSubscriber(ctx context.Context, id pgtype.UUID) error
+	RecordDeliveryAttempt(ctx context.Context, arg RecordDeliveryAttemptParams) error
+	ReleaseHeldDeliveries(ctx context.Context, subscriberID pgtype.UUID) error
+	ReleaseHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error)
 	RemoveAllApiSecretSubscribers(ctx context.Context, apiSecretID pgtype.UUID) error
 	RemoveApiSecretSubscriber(ctx context.Context, arg RemoveApiSecretSubscriberParams) error
+	ResumeSubscriber(ctx context.Context, id pgtype.UUID) error
 	SearchEventsByDataContent(ctx context.Context, arg SearchEventsByDataContentParams) ([]Event, error)
 	SearchEventsByDateRange(ctx context.Context, arg SearchEventsByDateRangeParams) ([]Event, error)
 	SearchEventsByDeliveryStatus(ctx context.Context, arg SearchEventsByDeliveryStatusParams) ([]Event, error)
@@ -54,8 +80,12 @@ type Querier interface {
 	UpdateApiSecret(ctx context.Context, arg UpdateApiSecretParams) (ApiSecret, error)
 	UpdateDeliveryAttemptStatus(ctx context.Context, arg UpdateDeliveryAttemptStatusParams) (DeliveryAttempt, error)
 	UpdateEventDeliveryStatus(ctx context.Context, arg UpdateEventDeliveryStatusParams) (Event, error)
+	UpdateReplayJobProgress(ctx context.Context, arg UpdateReplayJobProgressParams) error
 	UpdateSubscriber(ctx context.Context, arg UpdateSubscriberParams) (Subscriber, error)
 	UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) (Subscription, error)
+	UpsertDeadLetter(ctx context.Context, arg UpsertDeadLetterParams) (DeadLetter, error)
+	UpsertDelivery(ctx context.Context, arg UpsertDeliveryParams) error
+	UpsertDeliveryRetry(ctx context.Context, arg UpsertDeliveryRetryParams) error
 	UpsertLogConfig(ctx context.Context, arg UpsertLogConfigParams) (LogConfig, error)
 	UpsertSubscriber(ctx context.Context, arg UpsertSubscriberParams) (Subscriber, error)
 }
@@ -12,17 +12,25 @@ import (
 )
 
 const createSubscription = `-- name: CreateSubscription :one
-INSERT INTO subscriptions (id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, now(), now())
-RETURNING id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at
+INSERT INTO subscriptions (id, subscriber_id, subject_pattern, filter, max_retries, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now(), now())
+RETURNING id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform
 `
 
 type CreateSubscriptionParams struct {
-	ID             pgtype.UUID
-	SubscriberID   pgtype.UUID
-	SubjectPattern string
-	Filter         []byte
-	MaxRetries     pgtype.Int4
+	ID                   pgtype.UUID
+	SubscriberID         pgtype.UUID
+	SubjectPattern       string
+	Filter               []byte
+	MaxRetries           pgtype.Int4
+	Ordered              bool
+	OrderingKey          string
+	RetryPolicy          string
+	RetryIntervalSeconds int32
+	RetrySchedule        []int32
+	RetryMaxAgeSeconds   pgtype.Int4
+	PatternSyntax        string
+	Transform            []byte
 }
 
 func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
@@ -32,6 +40,14 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		arg.SubjectPattern,
 		arg.Filter,
 		arg.MaxRetries,
+		arg.Ordered,
+		arg.OrderingKey,
+		arg.RetryPolicy,
+		arg.RetryIntervalSeconds,
+		arg.RetrySchedule,
+		arg.RetryMaxAgeSeconds,
+		arg.PatternSyntax,
+		arg.Transform,
 	)
 	var i Subscription
 	err := row.Scan(
@@ -42,6 +58,14 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		&i.MaxRetries,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Ordered,
+		&i.OrderingKey,
+		&i.RetryPolicy,
+		&i.RetryIntervalSeconds,
+		&i.RetrySchedule,
+		&i.RetryMaxAgeSeconds,
+		&i.PatternSyntax,
+		&i.Transform,
 	)
 	return i, err
 }
@@ -73,8 +97,39 @@ func (q *Queries) DeleteSubscriptionsForSubscriber(ctx context.Context, subscrib
 	return err
 }
 
+const disableSubscriber = `-- name: DisableSubscriber :exec
+UPDATE subscribers SET
+    disabled_at = now(),
+    disabled_reason = $1,
+    updated_at = now()
+WHERE id = $2 AND disabled_at IS NULL
+`
+
+type DisableSubscriberParams struct {
+	DisabledReason string
+	ID             pgtype.UUID
+}
+
+func (q *Queries) DisableSubscriber(ctx context.Context, arg DisableSubscriberParams) error {
+	_, err := q.db.Exec(ctx, disableSubscriber, arg.DisabledReason, arg.ID)
+	return err
+}
+
+const enableSubscriber = `-- name: EnableSubscriber :exec
+UPDATE subscribers SET
+    disabled_at = NULL,
+    disabled_reason = '',
+    updated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) EnableSubscriber(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, enableSubscriber, id)
+	return err
+}
+
 const getSubscriberByEndpointURL = `-- name: GetSubscriberByEndpointURL :one
-SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at FROM subscribers WHERE endpoint_url = $1
+SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, disabled_at, disabled_reason, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, paused_at FROM subscribers WHERE endpoint_url = $1
 `
 
 func (q *Queries) GetSubscriberByEndpointURL(ctx context.Context, endpointUrl string) (Subscriber, error) {
@@ -88,12 +143,34 @@ func (q *Queries) GetSubscriberByEndpointURL(ctx context.Context, endpointUrl st
 		&i.MaxParallel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SigningMode,
+		&i.PayloadFormat,
+		&i.BatchSize,
+		&i.BatchLingerMs,
+		&i.NonRetryableStatuses,
+		&i.DisableOnGone,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.HttpMethod,
+		&i.TimeoutSeconds,
+		&i.ExtraHeaders,
+		&i.CaBundle,
+		&i.ClientCert,
+		&i.ClientKeyEncrypted,
+		&i.AuthMode,
+		&i.OauthTokenUrl,
+		&i.OauthClientID,
+		&i.OauthClientSecretEncrypted,
+		&i.OauthScopes,
+		&i.RateLimitPerSecond,
+		&i.RateLimitBurst,
+		&i.PausedAt,
 	)
 	return i, err
 }
 
 const getSubscriberByID = `-- name: GetSubscriberByID :one
-SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at FROM subscribers WHERE id = $1
+SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, disabled_at, disabled_reason, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, paused_at FROM subscribers WHERE id = $1
 `
 
 func (q *Queries) GetSubscriberByID(ctx context.Context, id pgtype.UUID) (Subscriber, error) {
@@ -107,44 +184,34 @@ func (q *Queries) GetSubscriberByID(ctx context.Context, id pgtype.UUID) (Subscr
 		&i.MaxParallel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SigningMode,
+		&i.PayloadFormat,
+		&i.BatchSize,
+		&i.BatchLingerMs,
+		&i.NonRetryableStatuses,
+		&i.DisableOnGone,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.HttpMethod,
+		&i.TimeoutSeconds,
+		&i.ExtraHeaders,
+		&i.CaBundle,
+		&i.ClientCert,
+		&i.ClientKeyEncrypted,
+		&i.AuthMode,
+		&i.OauthTokenUrl,
+		&i.OauthClientID,
+		&i.OauthClientSecretEncrypted,
+		&i.OauthScopes,
+		&i.RateLimitPerSecond,
+		&i.RateLimitBurst,
+		&i.PausedAt,
 	)
 	return i, err
 }
 
-const getSubscriptionsMatchingSubject = `-- name: GetSubscriptionsMatchingSubject :many
-SELECT id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at FROM subscriptions WHERE $1 LIKE replace(replace(subject_pattern, '*', '%'), '?', '_')
-`
-
-func (q *Queries) GetSubscriptionsMatchingSubject(ctx context.Context, subjectPattern string) ([]Subscription, error) {
-	rows, err := q.db.Query(ctx, getSubscriptionsMatchingSubject, subjectPattern)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Subscription
-	for rows.Next() {
-		var i Subscription
-		if err := rows.Scan(
-			&i.ID,
-			&i.SubscriberID,
-			&i.SubjectPattern,
-			&i.Filter,
-			&i.MaxRetries,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const listAllSubscriptions = `-- name: ListAllSubscriptions :many
-SELECT id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at FROM subscriptions ORDER BY created_at
+SELECT id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform FROM subscriptions ORDER BY created_at
 `
 
 func (q *Queries) ListAllSubscriptions(ctx context.Context) ([]Subscription, error) {
@@ -164,6 +231,14 @@ func (q *Queries) ListAllSubscriptions(ctx context.Context) ([]Subscription, err
 			&i.MaxRetries,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Ordered,
+			&i.OrderingKey,
+			&i.RetryPolicy,
+			&i.RetryIntervalSeconds,
+			&i.RetrySchedule,
+			&i.RetryMaxAgeSeconds,
+			&i.PatternSyntax,
+			&i.Transform,
 		); err != nil {
 			return nil, err
 		}
@@ -176,7 +251,7 @@ func (q *Queries) ListAllSubscriptions(ctx context.Context) ([]Subscription, err
 }
 
 const listSubscribers = `-- name: ListSubscribers :many
-SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at FROM subscribers ORDER BY created_at DESC
+SELECT id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, disabled_at, disabled_reason, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, paused_at FROM subscribers ORDER BY created_at DESC
 `
 
 func (q *Queries) ListSubscribers(ctx context.Context) ([]Subscriber, error) {
@@ -196,6 +271,28 @@ func (q *Queries) ListSubscribers(ctx context.Context) ([]Subscriber, error) {
 			&i.MaxParallel,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SigningMode,
+			&i.PayloadFormat,
+			&i.BatchSize,
+			&i.BatchLingerMs,
+			&i.NonRetryableStatuses,
+			&i.DisableOnGone,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.HttpMethod,
+			&i.TimeoutSeconds,
+			&i.ExtraHeaders,
+			&i.CaBundle,
+			&i.ClientCert,
+			&i.ClientKeyEncrypted,
+			&i.AuthMode,
+			&i.OauthTokenUrl,
+			&i.OauthClientID,
+			&i.OauthClientSecretEncrypted,
+			&i.OauthScopes,
+			&i.RateLimitPerSecond,
+			&i.RateLimitBurst,
+			&i.PausedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -208,7 +305,7 @@ func (q *Queries) ListSubscribers(ctx context.Context) ([]Subscriber, error) {
 }
 
 const listSubscribersWithCounts = `-- name: ListSubscribersWithCounts :many
-SELECT s.id, s.name, s.endpoint_url, s.auth_secret, s.max_parallel, s.created_at, s.updated_at, COUNT(sub.id)::int AS subscription_count
+SELECT s.id, s.name, s.endpoint_url, s.auth_secret, s.max_parallel, s.created_at, s.updated_at, s.signing_mode, s.payload_format, s.batch_size, s.batch_linger_ms, s.non_retryable_statuses, s.disable_on_gone, s.disabled_at, s.disabled_reason, s.http_method, s.timeout_seconds, s.extra_headers, s.ca_bundle, s.client_cert, s.client_key_encrypted, s.auth_mode, s.oauth_token_url, s.oauth_client_id, s.oauth_client_secret_encrypted, s.oauth_scopes, s.rate_limit_per_second, s.rate_limit_burst, s.paused_at, COUNT(sub.id)::int AS subscription_count
 FROM subscribers s
 LEFT JOIN subscriptions sub ON sub.subscriber_id = s.id
 GROUP BY s.id
@@ -216,14 +313,36 @@ ORDER BY s.created_at DESC
 `
 
 type ListSubscribersWithCountsRow struct {
-	ID                pgtype.UUID
-	Name              string
-	EndpointUrl       string
-	AuthSecret        string
-	MaxParallel       int32
-	CreatedAt         pgtype.Timestamptz
-	UpdatedAt         pgtype.Timestamptz
-	SubscriptionCount int32
+	ID                         pgtype.UUID
+	Name                       string
+	EndpointUrl                string
+	AuthSecret                 string
+	MaxParallel                int32
+	CreatedAt                  pgtype.Timestamptz
+	UpdatedAt                  pgtype.Timestamptz
+	SigningMode                string
+	PayloadFormat              string
+	BatchSize                  int32
+	BatchLingerMs              int32
+	NonRetryableStatuses       []int32
+	DisableOnGone              bool
+	DisabledAt                 pgtype.Timestamptz
+	DisabledReason             string
+	HttpMethod                 string
+	TimeoutSeconds             int32
+	ExtraHeaders               []byte
+	CaBundle                   string
+	ClientCert                 string
+	ClientKeyEncrypted         string
+	AuthMode                   string
+	OauthTokenUrl              string
+	OauthClientID              string
+	OauthClientSecretEncrypted string
+	OauthScopes                []string
+	RateLimitPerSecond         float64
+	RateLimitBurst             int32
+	PausedAt                   pgtype.Timestamptz
+	SubscriptionCount          int32
 }
 
 func (q *Queries) ListSubscribersWithCounts(ctx context.Context) ([]ListSubscribersWithCountsRow, error) {
@@ -243,6 +362,28 @@ func (q *Queries) ListSubscribersWithCounts(ctx context.Context) ([]ListSubscrib
 			&i.MaxParallel,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SigningMode,
+			&i.PayloadFormat,
+			&i.BatchSize,
+			&i.BatchLingerMs,
+			&i.NonRetryableStatuses,
+			&i.DisableOnGone,
+			&i.DisabledAt,
+			&i.DisabledReason,
+			&i.HttpMethod,
+			&i.TimeoutSeconds,
+			&i.ExtraHeaders,
+			&i.CaBundle,
+			&i.ClientCert,
+			&i.ClientKeyEncrypted,
+			&i.AuthMode,
+			&i.OauthTokenUrl,
+			&i.OauthClientID,
+			&i.OauthClientSecretEncrypted,
+			&i.OauthScopes,
+			&i.RateLimitPerSecond,
+			&i.RateLimitBurst,
+			&i.PausedAt,
 			&i.SubscriptionCount,
 		); err != nil {
 			return nil, err
@@ -256,7 +397,7 @@ func (q *Queries) ListSubscribersWithCounts(ctx context.Context) ([]ListSubscrib
 }
 
 const listSubscriptionsForSubscriber = `-- name: ListSubscriptionsForSubscriber :many
-SELECT id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at FROM subscriptions WHERE subscriber_id = $1 ORDER BY created_at
+SELECT id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform FROM subscriptions WHERE subscriber_id = $1 ORDER BY created_at
 `
 
 func (q *Queries) ListSubscriptionsForSubscriber(ctx context.Context, subscriberID pgtype.UUID) ([]Subscription, error) {
@@ -276,6 +417,14 @@ func (q *Queries) ListSubscriptionsForSubscriber(ctx context.Context, subscriber
 			&i.MaxRetries,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Ordered,
+			&i.OrderingKey,
+			&i.RetryPolicy,
+			&i.RetryIntervalSeconds,
+			&i.RetrySchedule,
+			&i.RetryMaxAgeSeconds,
+			&i.PatternSyntax,
+			&i.Transform,
 		); err != nil {
 			return nil, err
 		}
@@ -287,21 +436,83 @@ func (q *Queries) ListSubscriptionsForSubscriber(ctx context.Context, subscriber
 	return items, nil
 }
 
+const pauseSubscriber = `-- name: PauseSubscriber :exec
+UPDATE subscribers SET
+    paused_at = now(),
+    updated_at = now()
+WHERE id = $1 AND paused_at IS NULL
+`
+
+func (q *Queries) PauseSubscriber(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, pauseSubscriber, id)
+	return err
+}
+
+const resumeSubscriber = `-- name: ResumeSubscriber :exec
+UPDATE subscribers SET
+    paused_at = NULL,
+    updated_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) ResumeSubscriber(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, resumeSubscriber, id)
+	return err
+}
+
 const updateSubscriber = `-- name: UpdateSubscriber :one
 UPDATE subscribers SET
     name = $1,
     auth_secret = $2,
     max_parallel = $3,
+    signing_mode = $4,
+    payload_format = $5,
+    batch_size = $6,
+    batch_linger_ms = $7,
+    non_retryable_statuses = $8,
+    disable_on_gone = $9,
+    http_method = $10,
+    timeout_seconds = $11,
+    extra_headers = $12,
+    ca_bundle = $13,
+    client_cert = $14,
+    client_key_encrypted = $15,
+    auth_mode = $16,
+    oauth_token_url = $17,
+    oauth_client_id = $18,
+    oauth_client_secret_encrypted = $19,
+    oauth_scopes = $20,
+    rate_limit_per_second = $21,
+    rate_limit_burst = $22,
     updated_at = now()
-WHERE id = $4
-RETURNING id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at
+WHERE id = $23
+RETURNING id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, disabled_at, disabled_reason, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, paused_at
 `
 
 type UpdateSubscriberParams struct {
-	Name        string
-	AuthSecret  string
-	MaxParallel int32
-	ID          pgtype.UUID
+	Name                       string
+	AuthSecret                 string
+	MaxParallel                int32
+	SigningMode                string
+	PayloadFormat              string
+	BatchSize                  int32
+	BatchLingerMs              int32
+	NonRetryableStatuses       []int32
+	DisableOnGone              bool
+	HttpMethod                 string
+	TimeoutSeconds             int32
+	ExtraHeaders               []byte
+	CaBundle                   string
+	ClientCert                 string
+	ClientKeyEncrypted         string
+	AuthMode                   string
+	OauthTokenUrl              string
+	OauthClientID              string
+	OauthClientSecretEncrypted string
+	OauthScopes                []string
+	RateLimitPerSecond         float64
+	RateLimitBurst             int32
+	ID                         pgtype.UUID
 }
 
 func (q *Queries) UpdateSubscriber(ctx context.Context, arg UpdateSubscriberParams) (Subscriber, error) {
@@ -309,6 +520,25 @@ func (q *Queries) UpdateSubscriber(ctx context.Context, arg UpdateSubscriberPara
 		arg.Name,
 		arg.AuthSecret,
 		arg.MaxParallel,
+		arg.SigningMode,
+		arg.PayloadFormat,
+		arg.BatchSize,
+		arg.BatchLingerMs,
+		arg.NonRetryableStatuses,
+		arg.DisableOnGone,
+		arg.HttpMethod,
+		arg.TimeoutSeconds,
+		arg.ExtraHeaders,
+		arg.CaBundle,
+		arg.ClientCert,
+		arg.ClientKeyEncrypted,
+		arg.AuthMode,
+		arg.OauthTokenUrl,
+		arg.OauthClientID,
+		arg.OauthClientSecretEncrypted,
+		arg.OauthScopes,
+		arg.RateLimitPerSecond,
+		arg.RateLimitBurst,
 		arg.ID,
 	)
 	var i Subscriber
@@ -320,6 +550,28 @@ func (q *Queries) UpdateSubscriber(ctx context.Context, arg UpdateSubscriberPara
 		&i.MaxParallel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SigningMode,
+		&i.PayloadFormat,
+		&i.BatchSize,
+		&i.BatchLingerMs,
+		&i.NonRetryableStatuses,
+		&i.DisableOnGone,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.HttpMethod,
+		&i.TimeoutSeconds,
+		&i.ExtraHeaders,
+		&i.CaBundle,
+		&i.ClientCert,
+		&i.ClientKeyEncrypted,
+		&i.AuthMode,
+		&i.OauthTokenUrl,
+		&i.OauthClientID,
+		&i.OauthClientSecretEncrypted,
+		&i.OauthScopes,
+		&i.RateLimitPerSecond,
+		&i.RateLimitBurst,
+		&i.PausedAt,
 	)
 	return i, err
 }
@@ -328,19 +580,47 @@ const updateSubscription = `-- name: UpdateSubscription :one
 UPDATE subscriptions SET
     filter = $2,
     max_retries = $3,
+    ordered = $4,
+    ordering_key = $5,
+    retry_policy = $6,
+    retry_interval_seconds = $7,
+    retry_schedule = $8,
+    retry_max_age_seconds = $9,
+    pattern_syntax = $10,
+    transform = $11,
     updated_at = now()
 WHERE id = $1
-RETURNING id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at
+RETURNING id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform
 `
 
 type UpdateSubscriptionParams struct {
-	ID         pgtype.UUID
-	Filter     []byte
-	MaxRetries pgtype.Int4
+	ID                   pgtype.UUID
+	Filter               []byte
+	MaxRetries           pgtype.Int4
+	Ordered              bool
+	OrderingKey          string
+	RetryPolicy          string
+	RetryIntervalSeconds int32
+	RetrySchedule        []int32
+	RetryMaxAgeSeconds   pgtype.Int4
+	PatternSyntax        string
+	Transform            []byte
 }
 
 func (q *Queries) UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) (Subscription, error) {
-	row := q.db.QueryRow(ctx, updateSubscription, arg.ID, arg.Filter, arg.MaxRetries)
+	row := q.db.QueryRow(ctx, updateSubscription,
+		arg.ID,
+		arg.Filter,
+		arg.MaxRetries,
+		arg.Ordered,
+		arg.OrderingKey,
+		arg.RetryPolicy,
+		arg.RetryIntervalSeconds,
+		arg.RetrySchedule,
+		arg.RetryMaxAgeSeconds,
+		arg.PatternSyntax,
+		arg.Transform,
+	)
 	var i Subscription
 	err := row.Scan(
 		&i.ID,
@@ -350,27 +630,73 @@ func (q *Queries) UpdateSubscription(ctx context.Context, arg UpdateSubscription
 		&i.MaxRetries,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Ordered,
+		&i.OrderingKey,
+		&i.RetryPolicy,
+		&i.RetryIntervalSeconds,
+		&i.RetrySchedule,
+		&i.RetryMaxAgeSeconds,
+		&i.PatternSyntax,
+		&i.Transform,
 	)
 	return i, err
 }
 
 const upsertSubscriber = `-- name: UpsertSubscriber :one
-INSERT INTO subscribers (id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, now(), now())
+INSERT INTO subscribers (id, name, endpoint_url, auth_secret, max_parallel, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, now(), now())
 ON CONFLICT (endpoint_url) DO UPDATE SET
     name = EXCLUDED.name,
     auth_secret = EXCLUDED.auth_secret,
     max_parallel = EXCLUDED.max_parallel,
+    signing_mode = EXCLUDED.signing_mode,
+    payload_format = EXCLUDED.payload_format,
+    batch_size = EXCLUDED.batch_size,
+    batch_linger_ms = EXCLUDED.batch_linger_ms,
+    non_retryable_statuses = EXCLUDED.non_retryable_statuses,
+    disable_on_gone = EXCLUDED.disable_on_gone,
+    http_method = EXCLUDED.http_method,
+    timeout_seconds = EXCLUDED.timeout_seconds,
+    extra_headers = EXCLUDED.extra_headers,
+    ca_bundle = EXCLUDED.ca_bundle,
+    client_cert = EXCLUDED.client_cert,
+    client_key_encrypted = EXCLUDED.client_key_encrypted,
+    auth_mode = EXCLUDED.auth_mode,
+    oauth_token_url = EXCLUDED.oauth_token_url,
+    oauth_client_id = EXCLUDED.oauth_client_id,
+    oauth_client_secret_encrypted = EXCLUDED.oauth_client_secret_encrypted,
+    oauth_scopes = EXCLUDED.oauth_scopes,
+    rate_limit_per_second = EXCLUDED.rate_limit_per_second,
+    rate_limit_burst = EXCLUDED.rate_limit_burst,
     updated_at = now()
-RETURNING id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at
+RETURNING id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, disabled_at, disabled_reason, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, paused_at
 `
 
 type UpsertSubscriberParams struct {
-	ID          pgtype.UUID
-	Name        string
-	EndpointUrl string
-	AuthSecret  string
-	MaxParallel int32
+	ID                         pgtype.UUID
+	Name                       string
+	EndpointUrl                string
+	AuthSecret                 string
+	MaxParallel                int32
+	SigningMode                string
+	PayloadFormat              string
+	BatchSize                  int32
+	BatchLingerMs              int32
+	NonRetryableStatuses       []int32
+	DisableOnGone              bool
+	HttpMethod                 string
+	TimeoutSeconds             int32
+	ExtraHeaders               []byte
+	CaBundle                   string
+	ClientCert                 string
+	ClientKeyEncrypted         string
+	AuthMode                   string
+	OauthTokenUrl              string
+	OauthClientID              string
+	OauthClientSecretEncrypted string
+	OauthScopes                []string
+	RateLimitPerSecond         float64
+	RateLimitBurst             int32
 }
 
 func (q *Queries) UpsertSubscriber(ctx context.Context, arg UpsertSubscriberParams) (Subscriber, error) {
@@ -380,6 +706,25 @@ func (q *Queries) UpsertSubscriber(ctx context.Context, arg UpsertSubscriberPara
 		arg.EndpointUrl,
 		arg.AuthSecret,
 		arg.MaxParallel,
+		arg.SigningMode,
+		arg.PayloadFormat,
+		arg.BatchSize,
+		arg.BatchLingerMs,
+		arg.NonRetryableStatuses,
+		arg.DisableOnGone,
+		arg.HttpMethod,
+		arg.TimeoutSeconds,
+		arg.ExtraHeaders,
+		arg.CaBundle,
+		arg.ClientCert,
+		arg.ClientKeyEncrypted,
+		arg.AuthMode,
+		arg.OauthTokenUrl,
+		arg.OauthClientID,
+		arg.OauthClientSecretEncrypted,
+		arg.OauthScopes,
+		arg.RateLimitPerSecond,
+		arg.RateLimitBurst,
 	)
 	var i Subscriber
 	err := row.Scan(
@@ -390,6 +735,28 @@ func (q *Queries) UpsertSubscriber(ctx context.Context, arg UpsertSubscriberPara
 		&i.MaxParallel,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SigningMode,
+		&i.PayloadFormat,
+		&i.BatchSize,
+		&i.BatchLingerMs,
+		&i.NonRetryableStatuses,
+		&i.DisableOnGone,
+		&i.DisabledAt,
+		&i.DisabledReason,
+		&i.HttpMethod,
+		&i.TimeoutSeconds,
+		&i.ExtraHeaders,
+		&i.CaBundle,
+		&i.ClientCert,
+		&i.ClientKeyEncrypted,
+		&i.AuthMode,
+		&i.OauthTokenUrl,
+		&i.OauthClientID,
+		&i.OauthClientSecretEncrypted,
+		&i.OauthScopes,
+		&i.RateLimitPerSecond,
+		&i.RateLimitBurst,
+		&i.PausedAt,
 	)
 	return i, err
 }
@@ -55,12 +55,15 @@ func main() {
 	}
 	views.AddViews(slurpee, router)
 	api.AddApis(slurpee, router)
+	api.AddMetrics(slurpee, router)
 
 	// Start the centralized delivery dispatcher
 	ds := app.StartDispatcher(slurpee)
 
 	// Resume any events left in pending/partial status from before shutdown
 	app.ResumeUnfinishedDeliveries(slurpee, ds)
+	// Resume replay jobs interrupted by the last shutdown
+	app.ResumeReplayJobs(slurpee, ds)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", appConfig.Port),
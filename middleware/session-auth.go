@@ -12,14 +12,14 @@ type sessionContextKey struct{}
 
 // SessionAuthMiddleware returns a middleware that checks the slurpee_session cookie
 // against the SessionStore. Exempt routes: GET /login, POST /login, GET /version,
-// and static assets (paths starting with /static/).
+// GET /metrics, and static assets (paths starting with /static/).
 // On valid session, injects SessionInfo into request context.
 func SessionAuthMiddleware(slurpee *app.Application) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Exempt routes
 			path := r.URL.Path
-			if path == "/login" || strings.HasPrefix(path, "/static/") || path == "/version" || strings.HasPrefix(path, "/api/") {
+			if path == "/login" || strings.HasPrefix(path, "/static/") || path == "/version" || path == "/metrics" || strings.HasPrefix(path, "/api/") {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -1,6 +1,6 @@
 -- name: InsertApiSecret :one
-INSERT INTO api_secrets (id, name, secret_hash, subject_pattern, created_at)
-VALUES ($1, $2, $3, $4, now())
+INSERT INTO api_secrets (id, name, secret_hash, subject_pattern, pattern_syntax, created_at)
+VALUES ($1, $2, $3, $4, $5, now())
 RETURNING *;
 
 -- name: GetApiSecretByID :one
@@ -48,7 +48,8 @@ ORDER BY s.created_at DESC;
 -- name: UpdateApiSecret :one
 UPDATE api_secrets SET
     name = sqlc.arg(name),
-    subject_pattern = sqlc.arg(subject_pattern)
+    subject_pattern = sqlc.arg(subject_pattern),
+    pattern_syntax = sqlc.arg(pattern_syntax)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
@@ -56,7 +57,7 @@ RETURNING *;
 DELETE FROM api_secret_subscribers WHERE api_secret_id = $1;
 
 -- name: ListAllApiSecretHashes :many
-SELECT id, secret_hash, subject_pattern FROM api_secrets;
+SELECT id, secret_hash, subject_pattern, pattern_syntax FROM api_secrets;
 
 -- name: GetApiSecretSubscriberExists :one
 SELECT EXISTS(
@@ -0,0 +1,34 @@
+-- name: UpsertDeadLetter :one
+INSERT INTO dead_letters (id, event_id, subscriber_id, subscription_id, attempts, last_error, exhausted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (event_id, subscriber_id) DO UPDATE
+SET subscription_id = EXCLUDED.subscription_id,
+    attempts = EXCLUDED.attempts,
+    last_error = EXCLUDED.last_error,
+    exhausted_at = EXCLUDED.exhausted_at
+RETURNING *;
+
+-- name: ListDeadLettersFiltered :many
+SELECT dl.*, e.subject, s.name AS subscriber_name, s.endpoint_url
+FROM dead_letters dl
+JOIN events e ON e.id = dl.event_id
+JOIN subscribers s ON s.id = dl.subscriber_id
+WHERE
+  (sqlc.narg(subscriber_filter)::uuid IS NULL OR dl.subscriber_id = sqlc.narg(subscriber_filter))
+  AND (sqlc.arg(subject_filter)::text = '' OR e.subject LIKE sqlc.arg(subject_filter))
+ORDER BY dl.exhausted_at DESC LIMIT $1 OFFSET $2;
+
+-- name: GetDeadLettersByIDs :many
+SELECT * FROM dead_letters WHERE id = ANY(sqlc.arg(ids)::uuid[]) ORDER BY exhausted_at;
+
+-- name: CountDeadLettersForEvent :one
+SELECT count(*) FROM dead_letters WHERE event_id = $1;
+
+-- name: DeleteDeadLetter :exec
+DELETE FROM dead_letters WHERE id = $1;
+
+-- name: DeleteDeadLettersForEvent :exec
+DELETE FROM dead_letters WHERE event_id = $1;
+
+-- name: DeleteDeadLetterForSubscriber :exec
+DELETE FROM dead_letters WHERE event_id = $1 AND subscriber_id = $2;
@@ -0,0 +1,110 @@
+-- name: UpsertDelivery :exec
+INSERT INTO deliveries (event_id, subscriber_id, subscription_id, status, next_attempt_at, last_error)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (event_id, subscriber_id) DO UPDATE SET
+    subscription_id = EXCLUDED.subscription_id,
+    status = EXCLUDED.status,
+    attempts = CASE WHEN EXCLUDED.status = 'pending' THEN 0 ELSE deliveries.attempts END,
+    next_attempt_at = EXCLUDED.next_attempt_at,
+    last_error = CASE WHEN EXCLUDED.last_error = '' THEN deliveries.last_error ELSE EXCLUDED.last_error END,
+    updated_at = now();
+
+-- name: RecordDeliveryAttempt :exec
+INSERT INTO deliveries (event_id, subscriber_id, subscription_id, status, attempts, last_attempt_at, next_attempt_at, last_error, lag_ms)
+VALUES ($1, $2, $3, $4, 1, $5, $6, $7, $8)
+ON CONFLICT (event_id, subscriber_id) DO UPDATE SET
+    subscription_id = COALESCE(EXCLUDED.subscription_id, deliveries.subscription_id),
+    status = EXCLUDED.status,
+    attempts = deliveries.attempts + 1,
+    last_attempt_at = EXCLUDED.last_attempt_at,
+    next_attempt_at = EXCLUDED.next_attempt_at,
+    last_error = EXCLUDED.last_error,
+    lag_ms = EXCLUDED.lag_ms,
+    updated_at = now();
+
+-- name: ListDeliveriesForEvent :many
+SELECT d.*, s.name AS subscriber_name, s.endpoint_url
+FROM deliveries d
+JOIN subscribers s ON s.id = d.subscriber_id
+WHERE d.event_id = $1
+ORDER BY d.created_at, s.name;
+
+-- name: ReleaseHeldDeliveries :exec
+UPDATE deliveries SET
+    status = CASE WHEN attempts = 0 THEN 'pending' ELSE 'retrying' END,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE subscriber_id = $1 AND status = 'held';
+
+-- name: ListDeliveriesForSubscriber :many
+SELECT d.*, e.subject
+FROM deliveries d
+JOIN events e ON e.id = d.event_id
+WHERE d.subscriber_id = sqlc.arg(subscriber_id)
+  AND (sqlc.arg(status_filter)::text = '' OR d.status = sqlc.arg(status_filter))
+  AND (sqlc.arg(subject_filter)::text = '' OR e.subject LIKE sqlc.arg(subject_filter) ESCAPE '\')
+  AND (sqlc.narg(since)::timestamptz IS NULL OR d.created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR d.created_at < sqlc.narg(until))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (d.created_at, d.event_id) < (sqlc.narg(after_created_at), sqlc.narg(after_event_id)::uuid))
+ORDER BY d.created_at DESC, d.event_id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetDeliverySummaryForSubscriber :one
+WITH matching AS (
+    SELECT d.event_id, d.status
+    FROM deliveries d
+    JOIN events e ON e.id = d.event_id
+    WHERE d.subscriber_id = sqlc.arg(subscriber_id)
+      AND (sqlc.arg(subject_filter)::text = '' OR e.subject LIKE sqlc.arg(subject_filter) ESCAPE '\')
+      AND (sqlc.narg(since)::timestamptz IS NULL OR d.created_at >= sqlc.narg(since))
+      AND (sqlc.narg(until)::timestamptz IS NULL OR d.created_at < sqlc.narg(until))
+), durations AS (
+    SELECT
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY a.duration_ms) AS p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY a.duration_ms) AS p95
+    FROM delivery_attempts a
+    JOIN matching m ON m.event_id = a.event_id
+    WHERE a.subscriber_id = sqlc.arg(subscriber_id) AND a.duration_ms IS NOT NULL
+)
+SELECT
+    (SELECT count(*) FROM matching WHERE status = 'succeeded') AS succeeded,
+    (SELECT count(*) FROM matching WHERE status = 'failed') AS failed,
+    (SELECT count(*) FROM matching WHERE status IN ('pending', 'retrying', 'held')) AS pending,
+    COALESCE(du.p50, 0)::float8 AS latency_p50_ms,
+    COALESCE(du.p95, 0)::float8 AS latency_p95_ms
+FROM durations du;
+
+-- name: ListSubscriberLatencyStats :many
+WITH durations AS (
+    SELECT a.subscriber_id,
+        count(*) AS attempts,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY a.duration_ms) AS p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY a.duration_ms) AS p95,
+        percentile_cont(0.99) WITHIN GROUP (ORDER BY a.duration_ms) AS p99
+    FROM delivery_attempts a
+    WHERE a.attempted_at >= sqlc.arg(since) AND a.duration_ms IS NOT NULL
+    GROUP BY a.subscriber_id
+), lags AS (
+    SELECT d.subscriber_id,
+        count(*) AS delivered,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY d.lag_ms) AS p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY d.lag_ms) AS p95,
+        percentile_cont(0.99) WITHIN GROUP (ORDER BY d.lag_ms) AS p99
+    FROM deliveries d
+    WHERE d.status = 'succeeded' AND d.last_attempt_at >= sqlc.arg(since) AND d.lag_ms IS NOT NULL
+    GROUP BY d.subscriber_id
+)
+SELECT s.id AS subscriber_id,
+    COALESCE(du.attempts, 0)::bigint AS attempts,
+    COALESCE(du.p50, 0)::float8 AS duration_p50_ms,
+    COALESCE(du.p95, 0)::float8 AS duration_p95_ms,
+    COALESCE(du.p99, 0)::float8 AS duration_p99_ms,
+    COALESCE(l.delivered, 0)::bigint AS delivered,
+    COALESCE(l.p50, 0)::float8 AS lag_p50_ms,
+    COALESCE(l.p95, 0)::float8 AS lag_p95_ms,
+    COALESCE(l.p99, 0)::float8 AS lag_p99_ms
+FROM subscribers s
+LEFT JOIN durations du ON du.subscriber_id = s.id
+LEFT JOIN lags l ON l.subscriber_id = s.id
+WHERE du.subscriber_id IS NOT NULL OR l.subscriber_id IS NOT NULL;
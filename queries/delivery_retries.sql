@@ -0,0 +1,42 @@
+-- name: UpsertDeliveryRetry :exec
+INSERT INTO delivery_retries (event_id, subscriber_id, subscription_id, attempt_num, max_retries, next_attempt_at, first_attempt_at, held, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+ON CONFLICT (event_id, subscriber_id) DO UPDATE SET
+    subscription_id = EXCLUDED.subscription_id,
+    attempt_num = EXCLUDED.attempt_num,
+    max_retries = EXCLUDED.max_retries,
+    next_attempt_at = EXCLUDED.next_attempt_at,
+    first_attempt_at = EXCLUDED.first_attempt_at,
+    held = EXCLUDED.held;
+
+-- name: ClaimDueDeliveryRetries :many
+WITH claimed AS (
+    DELETE FROM delivery_retries
+    WHERE (event_id, subscriber_id) IN (
+        SELECT dr.event_id, dr.subscriber_id FROM delivery_retries dr
+        JOIN events e ON e.id = dr.event_id
+        WHERE dr.next_attempt_at <= sqlc.arg(due_before) AND NOT dr.held
+        ORDER BY dr.next_attempt_at, e.timestamp, e.id
+        LIMIT sqlc.arg(batch_size)
+        FOR UPDATE OF dr SKIP LOCKED
+    )
+    RETURNING *
+)
+SELECT c.* FROM claimed c
+JOIN events e ON e.id = c.event_id
+ORDER BY c.next_attempt_at, e.timestamp, e.id;
+
+-- name: ListDeliveryRetriesForEvent :many
+SELECT * FROM delivery_retries WHERE event_id = $1 ORDER BY next_attempt_at;
+
+-- name: DeleteDeliveryRetriesForEvent :exec
+DELETE FROM delivery_retries WHERE event_id = $1;
+
+-- name: ReleaseHeldDeliveryRetries :execrows
+UPDATE delivery_retries SET
+    held = false,
+    next_attempt_at = now()
+WHERE subscriber_id = $1 AND held;
+
+-- name: CountHeldDeliveryRetries :one
+SELECT COUNT(*) FROM delivery_retries WHERE subscriber_id = $1 AND held;
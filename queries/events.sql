@@ -1,6 +1,6 @@
 -- name: InsertEvent :one
-INSERT INTO events (id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO events (id, subject, timestamp, trace_id, data, retry_count, delivery_status, status_updated_at, api_secret_id, traceparent, subscriber_scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, (SELECT array_agg(subscriber_id) FROM api_secret_subscribers WHERE api_secret_id = $9))
 RETURNING *;
 
 -- name: GetEventByID :one
@@ -55,3 +55,27 @@ SELECT * FROM events WHERE delivery_status IN ('pending', 'partial') ORDER BY ti
 
 -- name: UpdateEventDeliveryStatus :one
 UPDATE events SET delivery_status = $1, retry_count = $2, status_updated_at = $3 WHERE id = $4 RETURNING *;
+
+-- name: CountEventsForReplay :one
+SELECT count(*) FROM events
+WHERE
+  (sqlc.arg(subject_filter)::text = '' OR subject LIKE sqlc.arg(subject_filter))
+  AND (sqlc.arg(status_filter)::text = '' OR delivery_status = sqlc.arg(status_filter))
+  AND (sqlc.narg(start_time_filter)::timestamptz IS NULL OR timestamp >= sqlc.narg(start_time_filter))
+  AND timestamp <= sqlc.arg(end_time_filter)
+  AND (sqlc.narg(data_filter)::jsonb IS NULL OR data @> sqlc.narg(data_filter))
+  AND (sqlc.arg(trace_id_filter)::text = '' OR trace_id::text = sqlc.arg(trace_id_filter));
+
+-- name: ListEventsForReplay :many
+SELECT * FROM events
+WHERE
+  (sqlc.arg(subject_filter)::text = '' OR subject LIKE sqlc.arg(subject_filter))
+  AND (sqlc.arg(status_filter)::text = '' OR delivery_status = sqlc.arg(status_filter))
+  AND (sqlc.narg(start_time_filter)::timestamptz IS NULL OR timestamp >= sqlc.narg(start_time_filter))
+  AND timestamp <= sqlc.arg(end_time_filter)
+  AND (sqlc.narg(data_filter)::jsonb IS NULL OR data @> sqlc.narg(data_filter))
+  AND (sqlc.arg(trace_id_filter)::text = '' OR trace_id::text = sqlc.arg(trace_id_filter))
+  AND (sqlc.narg(after_timestamp)::timestamptz IS NULL
+    OR (timestamp, id) > (sqlc.narg(after_timestamp), sqlc.narg(after_event_id)::uuid))
+ORDER BY timestamp, id
+LIMIT sqlc.arg(row_limit);
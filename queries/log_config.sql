@@ -1,8 +1,9 @@
 -- name: UpsertLogConfig :one
-INSERT INTO log_config (id, subject, log_properties)
-VALUES ($1, $2, $3)
+INSERT INTO log_config (id, subject, log_properties, pattern_syntax)
+VALUES ($1, $2, $3, $4)
 ON CONFLICT (subject) DO UPDATE SET
-    log_properties = EXCLUDED.log_properties
+    log_properties = EXCLUDED.log_properties,
+    pattern_syntax = EXCLUDED.pattern_syntax
 RETURNING *;
 
 -- name: GetLogConfigBySubject :one
@@ -0,0 +1,32 @@
+-- name: InsertReplayJob :one
+INSERT INTO replay_jobs (id, subject_filter, status_filter, start_time_filter, end_time_filter, data_filter, trace_id_filter, subscriber_id, rate_per_second, status, total)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'running', $10)
+RETURNING *;
+
+-- name: GetReplayJob :one
+SELECT * FROM replay_jobs WHERE id = $1;
+
+-- name: ListReplayJobs :many
+SELECT * FROM replay_jobs ORDER BY created_at DESC LIMIT $1;
+
+-- name: ListRunningReplayJobs :many
+SELECT * FROM replay_jobs WHERE status = 'running' ORDER BY created_at;
+
+-- name: UpdateReplayJobProgress :exec
+UPDATE replay_jobs SET
+    processed = $2,
+    replayed = $3,
+    skipped = $4,
+    failed = $5,
+    cursor_timestamp = $6,
+    cursor_event_id = $7,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: FinishReplayJob :exec
+UPDATE replay_jobs SET
+    status = $2,
+    last_error = $3,
+    finished_at = now(),
+    updated_at = now()
+WHERE id = $1 AND status = 'running';
@@ -1,10 +1,29 @@
 -- name: UpsertSubscriber :one
-INSERT INTO subscribers (id, name, endpoint_url, auth_secret, max_parallel, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, now(), now())
+INSERT INTO subscribers (id, name, endpoint_url, auth_secret, max_parallel, signing_mode, payload_format, batch_size, batch_linger_ms, non_retryable_statuses, disable_on_gone, http_method, timeout_seconds, extra_headers, ca_bundle, client_cert, client_key_encrypted, auth_mode, oauth_token_url, oauth_client_id, oauth_client_secret_encrypted, oauth_scopes, rate_limit_per_second, rate_limit_burst, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, now(), now())
 ON CONFLICT (endpoint_url) DO UPDATE SET
     name = EXCLUDED.name,
     auth_secret = EXCLUDED.auth_secret,
     max_parallel = EXCLUDED.max_parallel,
+    signing_mode = EXCLUDED.signing_mode,
+    payload_format = EXCLUDED.payload_format,
+    batch_size = EXCLUDED.batch_size,
+    batch_linger_ms = EXCLUDED.batch_linger_ms,
+    non_retryable_statuses = EXCLUDED.non_retryable_statuses,
+    disable_on_gone = EXCLUDED.disable_on_gone,
+    http_method = EXCLUDED.http_method,
+    timeout_seconds = EXCLUDED.timeout_seconds,
+    extra_headers = EXCLUDED.extra_headers,
+    ca_bundle = EXCLUDED.ca_bundle,
+    client_cert = EXCLUDED.client_cert,
+    client_key_encrypted = EXCLUDED.client_key_encrypted,
+    auth_mode = EXCLUDED.auth_mode,
+    oauth_token_url = EXCLUDED.oauth_token_url,
+    oauth_client_id = EXCLUDED.oauth_client_id,
+    oauth_client_secret_encrypted = EXCLUDED.oauth_client_secret_encrypted,
+    oauth_scopes = EXCLUDED.oauth_scopes,
+    rate_limit_per_second = EXCLUDED.rate_limit_per_second,
+    rate_limit_burst = EXCLUDED.rate_limit_burst,
     updated_at = now()
 RETURNING *;
 
@@ -27,9 +46,35 @@ ORDER BY s.created_at DESC;
 -- name: DeleteSubscriber :exec
 DELETE FROM subscribers WHERE id = $1;
 
+-- name: DisableSubscriber :exec
+UPDATE subscribers SET
+    disabled_at = now(),
+    disabled_reason = sqlc.arg(disabled_reason),
+    updated_at = now()
+WHERE id = sqlc.arg(id) AND disabled_at IS NULL;
+
+-- name: EnableSubscriber :exec
+UPDATE subscribers SET
+    disabled_at = NULL,
+    disabled_reason = '',
+    updated_at = now()
+WHERE id = $1;
+
+-- name: PauseSubscriber :exec
+UPDATE subscribers SET
+    paused_at = now(),
+    updated_at = now()
+WHERE id = $1 AND paused_at IS NULL;
+
+-- name: ResumeSubscriber :exec
+UPDATE subscribers SET
+    paused_at = NULL,
+    updated_at = now()
+WHERE id = $1;
+
 -- name: CreateSubscription :one
-INSERT INTO subscriptions (id, subscriber_id, subject_pattern, filter, max_retries, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, now(), now())
+INSERT INTO subscriptions (id, subscriber_id, subject_pattern, filter, max_retries, ordered, ordering_key, retry_policy, retry_interval_seconds, retry_schedule, retry_max_age_seconds, pattern_syntax, transform, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now(), now())
 RETURNING *;
 
 -- name: ListSubscriptionsForSubscriber :many
@@ -38,14 +83,30 @@ SELECT * FROM subscriptions WHERE subscriber_id = $1 ORDER BY created_at;
 -- name: DeleteSubscriptionsForSubscriber :exec
 DELETE FROM subscriptions WHERE subscriber_id = $1;
 
--- name: GetSubscriptionsMatchingSubject :many
-SELECT * FROM subscriptions WHERE $1 LIKE replace(replace(subject_pattern, '*', '%'), '?', '_');
-
 -- name: UpdateSubscriber :one
 UPDATE subscribers SET
     name = sqlc.arg(name),
     auth_secret = sqlc.arg(auth_secret),
     max_parallel = sqlc.arg(max_parallel),
+    signing_mode = sqlc.arg(signing_mode),
+    payload_format = sqlc.arg(payload_format),
+    batch_size = sqlc.arg(batch_size),
+    batch_linger_ms = sqlc.arg(batch_linger_ms),
+    non_retryable_statuses = sqlc.arg(non_retryable_statuses),
+    disable_on_gone = sqlc.arg(disable_on_gone),
+    http_method = sqlc.arg(http_method),
+    timeout_seconds = sqlc.arg(timeout_seconds),
+    extra_headers = sqlc.arg(extra_headers),
+    ca_bundle = sqlc.arg(ca_bundle),
+    client_cert = sqlc.arg(client_cert),
+    client_key_encrypted = sqlc.arg(client_key_encrypted),
+    auth_mode = sqlc.arg(auth_mode),
+    oauth_token_url = sqlc.arg(oauth_token_url),
+    oauth_client_id = sqlc.arg(oauth_client_id),
+    oauth_client_secret_encrypted = sqlc.arg(oauth_client_secret_encrypted),
+    oauth_scopes = sqlc.arg(oauth_scopes),
+    rate_limit_per_second = sqlc.arg(rate_limit_per_second),
+    rate_limit_burst = sqlc.arg(rate_limit_burst),
     updated_at = now()
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -54,6 +115,14 @@ RETURNING *;
 UPDATE subscriptions SET
     filter = $2,
     max_retries = $3,
+    ordered = $4,
+    ordering_key = $5,
+    retry_policy = $6,
+    retry_interval_seconds = $7,
+    retry_schedule = $8,
+    retry_max_age_seconds = $9,
+    pattern_syntax = $10,
+    transform = $11,
     updated_at = now()
 WHERE id = $1
 RETURNING *;
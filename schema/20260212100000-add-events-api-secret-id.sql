@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE events ADD COLUMN IF NOT EXISTS api_secret_id UUID REFERENCES api_secrets(id) ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE events DROP COLUMN IF EXISTS api_secret_id;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS signing_mode TEXT NOT NULL DEFAULT 'secret';
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS signing_mode;
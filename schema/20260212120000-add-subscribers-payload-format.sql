@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS payload_format TEXT NOT NULL DEFAULT 'data';
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS payload_format;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS delivery_retries (
+    event_id         UUID        NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    subscriber_id    UUID        NOT NULL REFERENCES subscribers(id) ON DELETE CASCADE,
+    subscription_id  UUID        NOT NULL,
+    attempt_num      INTEGER     NOT NULL,
+    max_retries      INTEGER     NOT NULL,
+    next_attempt_at  TIMESTAMPTZ NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (event_id, subscriber_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_retries_next_attempt_at ON delivery_retries(next_attempt_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS delivery_retries;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id               UUID        PRIMARY KEY,
+    event_id         UUID        NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    subscriber_id    UUID        NOT NULL REFERENCES subscribers(id) ON DELETE CASCADE,
+    subscription_id  UUID        NOT NULL,
+    attempts         INTEGER     NOT NULL,
+    last_error       TEXT        NOT NULL,
+    exhausted_at     TIMESTAMPTZ NOT NULL,
+    UNIQUE (event_id, subscriber_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_subscriber_id ON dead_letters(subscriber_id);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_exhausted_at ON dead_letters(exhausted_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS dead_letters;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS ordered BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS ordering_key TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS ordering_key;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS ordered;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS batch_size INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS batch_linger_ms INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS batch_linger_ms;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS batch_size;
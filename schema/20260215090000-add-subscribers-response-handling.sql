@@ -0,0 +1,13 @@
+-- +migrate Up
+-- Existing subscribers keep retrying every failure; only new subscribers get the default list
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS non_retryable_statuses INTEGER[] NOT NULL DEFAULT '{}';
+ALTER TABLE subscribers ALTER COLUMN non_retryable_statuses SET DEFAULT '{400,404,410}';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS disable_on_gone BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS disabled_reason TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS disabled_reason;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS disable_on_gone;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS non_retryable_statuses;
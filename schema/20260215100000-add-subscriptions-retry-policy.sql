@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS retry_policy TEXT NOT NULL DEFAULT 'exponential';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS retry_interval_seconds INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS retry_schedule INTEGER[] NOT NULL DEFAULT '{}';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS retry_max_age_seconds INTEGER;
+ALTER TABLE delivery_retries ADD COLUMN IF NOT EXISTS first_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- +migrate Down
+ALTER TABLE delivery_retries DROP COLUMN IF EXISTS first_attempt_at;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS retry_max_age_seconds;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS retry_schedule;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS retry_interval_seconds;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS retry_policy;
@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS http_method TEXT NOT NULL DEFAULT 'POST';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS timeout_seconds INTEGER NOT NULL DEFAULT 30;
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS extra_headers JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS ca_bundle TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS client_cert TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS client_key_encrypted TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS client_key_encrypted;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS client_cert;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS ca_bundle;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS extra_headers;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS timeout_seconds;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS http_method;
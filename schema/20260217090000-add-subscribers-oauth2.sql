@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS auth_mode TEXT NOT NULL DEFAULT 'none';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS oauth_token_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS oauth_client_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS oauth_client_secret_encrypted TEXT NOT NULL DEFAULT '';
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS oauth_scopes TEXT[] NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS oauth_scopes;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS oauth_client_secret_encrypted;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS oauth_client_id;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS oauth_token_url;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS auth_mode;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS rate_limit_per_second DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE subscribers DROP COLUMN IF EXISTS rate_limit_burst;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS rate_limit_per_second;
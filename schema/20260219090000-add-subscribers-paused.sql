@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE subscribers ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ;
+ALTER TABLE delivery_retries ADD COLUMN IF NOT EXISTS held BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_delivery_retries_held ON delivery_retries(subscriber_id) WHERE held;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_delivery_retries_held;
+ALTER TABLE delivery_retries DROP COLUMN IF EXISTS held;
+ALTER TABLE subscribers DROP COLUMN IF EXISTS paused_at;
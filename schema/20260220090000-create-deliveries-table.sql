@@ -0,0 +1,52 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS deliveries (
+    event_id         UUID        NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    subscriber_id    UUID        NOT NULL REFERENCES subscribers(id) ON DELETE CASCADE,
+    subscription_id  UUID,
+    status           TEXT        NOT NULL,
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    last_attempt_at  TIMESTAMPTZ,
+    next_attempt_at  TIMESTAMPTZ,
+    last_error       TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (event_id, subscriber_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_subscriber_status ON deliveries(subscriber_id, status);
+
+-- Backfill from delivery history, scheduled retries and dead letters
+INSERT INTO deliveries (event_id, subscriber_id, subscription_id, status, attempts, last_attempt_at, next_attempt_at, last_error, created_at, updated_at)
+SELECT a.event_id, a.subscriber_id,
+    COALESCE(dr.subscription_id, dl.subscription_id),
+    CASE
+        WHEN a.succeeded THEN 'succeeded'
+        WHEN dl.event_id IS NOT NULL THEN 'failed'
+        WHEN dr.held THEN 'held'
+        ELSE 'retrying'
+    END,
+    a.attempts, a.last_attempt_at, dr.next_attempt_at, COALESCE(dl.last_error, ''),
+    a.first_attempt_at, a.last_attempt_at
+FROM (
+    SELECT event_id, subscriber_id,
+        COUNT(*) AS attempts,
+        bool_or(status = 'succeeded') AS succeeded,
+        MIN(attempted_at) AS first_attempt_at,
+        MAX(attempted_at) AS last_attempt_at
+    FROM delivery_attempts
+    GROUP BY event_id, subscriber_id
+) a
+LEFT JOIN delivery_retries dr ON dr.event_id = a.event_id AND dr.subscriber_id = a.subscriber_id
+LEFT JOIN dead_letters dl ON dl.event_id = a.event_id AND dl.subscriber_id = a.subscriber_id
+ON CONFLICT (event_id, subscriber_id) DO NOTHING;
+
+-- Retries parked or held before their first attempt
+INSERT INTO deliveries (event_id, subscriber_id, subscription_id, status, next_attempt_at, created_at)
+SELECT event_id, subscriber_id, subscription_id,
+    CASE WHEN held THEN 'held' ELSE 'pending' END,
+    next_attempt_at, created_at
+FROM delivery_retries
+ON CONFLICT (event_id, subscriber_id) DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS deliveries;
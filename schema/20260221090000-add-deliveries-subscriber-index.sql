@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_deliveries_subscriber_created ON deliveries(subscriber_id, created_at DESC, event_id DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_deliveries_subscriber_created;
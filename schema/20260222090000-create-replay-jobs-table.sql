@@ -0,0 +1,33 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS replay_jobs (
+    id                 UUID        PRIMARY KEY,
+    subject_filter     TEXT        NOT NULL DEFAULT '',
+    status_filter      TEXT        NOT NULL DEFAULT '',
+    start_time_filter  TIMESTAMPTZ,
+    end_time_filter    TIMESTAMPTZ NOT NULL,
+    data_filter        JSONB,
+    trace_id_filter    TEXT        NOT NULL DEFAULT '',
+    subscriber_id      UUID,
+    rate_per_second    INTEGER     NOT NULL,
+    status             TEXT        NOT NULL,
+    total              INTEGER     NOT NULL DEFAULT 0,
+    processed          INTEGER     NOT NULL DEFAULT 0,
+    replayed           INTEGER     NOT NULL DEFAULT 0,
+    skipped            INTEGER     NOT NULL DEFAULT 0,
+    failed             INTEGER     NOT NULL DEFAULT 0,
+    cursor_timestamp   TIMESTAMPTZ,
+    cursor_event_id    UUID,
+    last_error         TEXT        NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_status ON replay_jobs(status);
+
+-- Replay jobs walk matching events oldest first
+CREATE INDEX IF NOT EXISTS idx_events_timestamp_id ON events(timestamp, id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_events_timestamp_id;
+DROP TABLE IF EXISTS replay_jobs;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- When Slurpee received the event; timestamp is the publisher's and may be older
+ALTER TABLE events ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+UPDATE events SET created_at = timestamp WHERE created_at IS NULL;
+ALTER TABLE events ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE events ALTER COLUMN created_at SET NOT NULL;
+
+ALTER TABLE delivery_attempts ADD COLUMN IF NOT EXISTS duration_ms INTEGER;
+CREATE INDEX IF NOT EXISTS idx_delivery_attempts_attempted_at ON delivery_attempts(attempted_at);
+
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS lag_ms BIGINT;
+UPDATE deliveries d SET lag_ms = GREATEST(extract(epoch FROM d.last_attempt_at - e.created_at) * 1000, 0)::bigint
+FROM events e
+WHERE e.id = d.event_id AND d.status = 'succeeded' AND d.lag_ms IS NULL;
+
+-- +migrate Down
+ALTER TABLE deliveries DROP COLUMN IF EXISTS lag_ms;
+DROP INDEX IF EXISTS idx_delivery_attempts_attempted_at;
+ALTER TABLE delivery_attempts DROP COLUMN IF EXISTS duration_ms;
+ALTER TABLE events DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- W3C traceparent of the span that ingested the event; deliveries are traced as its children
+ALTER TABLE events ADD COLUMN IF NOT EXISTS traceparent TEXT;
+
+-- +migrate Down
+ALTER TABLE events DROP COLUMN IF EXISTS traceparent;
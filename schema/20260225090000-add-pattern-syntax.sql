@@ -0,0 +1,26 @@
+-- +migrate Up
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS pattern_syntax TEXT NOT NULL DEFAULT 'like';
+ALTER TABLE api_secrets ADD COLUMN IF NOT EXISTS pattern_syntax TEXT NOT NULL DEFAULT 'like';
+-- Existing log configs matched their subject exactly, as token syntax does
+-- for subjects without * or >; only new log configs default to LIKE syntax
+ALTER TABLE log_config ADD COLUMN IF NOT EXISTS pattern_syntax TEXT NOT NULL DEFAULT 'token';
+ALTER TABLE log_config ALTER COLUMN pattern_syntax SET DEFAULT 'like';
+-- LIKE syntax adds % to subscription patterns and % and ? to secret scopes as
+-- wildcards; escape them in existing patterns, where they were literal
+UPDATE subscriptions
+SET subject_pattern = replace(replace(subject_pattern, '\', '\\'), '%', '\%')
+WHERE subject_pattern ~ '[\\%]';
+UPDATE api_secrets
+SET subject_pattern = replace(replace(replace(subject_pattern, '\', '\\'), '%', '\%'), '?', '\?')
+WHERE subject_pattern ~ '[\\%?]';
+
+-- +migrate Down
+UPDATE api_secrets
+SET subject_pattern = replace(replace(replace(subject_pattern, '\?', '?'), '\%', '%'), '\\', '\')
+WHERE subject_pattern LIKE '%\\%';
+UPDATE subscriptions
+SET subject_pattern = replace(replace(subject_pattern, '\%', '%'), '\\', '\')
+WHERE subject_pattern LIKE '%\\%';
+ALTER TABLE log_config DROP COLUMN IF EXISTS pattern_syntax;
+ALTER TABLE api_secrets DROP COLUMN IF EXISTS pattern_syntax;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS pattern_syntax;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS transform JSONB;
+
+-- +migrate Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS transform;
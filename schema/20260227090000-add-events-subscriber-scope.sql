@@ -0,0 +1,17 @@
+-- +migrate Up
+-- Snapshot the publishing secret's subscriber scope onto each event, so that
+-- deleting the secret or changing its subscribers does not widen delivery of
+-- events it already published
+ALTER TABLE events ADD COLUMN IF NOT EXISTS subscriber_scope UUID[];
+UPDATE events e SET subscriber_scope = s.subscriber_ids
+FROM (
+    SELECT api_secret_id, array_agg(subscriber_id) AS subscriber_ids
+    FROM api_secret_subscribers
+    GROUP BY api_secret_id
+) s
+WHERE e.api_secret_id = s.api_secret_id;
+CREATE INDEX IF NOT EXISTS idx_events_api_secret_id ON events(api_secret_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_events_api_secret_id;
+ALTER TABLE events DROP COLUMN IF EXISTS subscriber_scope;
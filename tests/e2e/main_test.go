@@ -132,6 +132,8 @@ func truncateAll(t *testing.T) {
 	t.Helper()
 	tables := []string{
 		"delivery_attempts",
+		"delivery_retries",
+		"dead_letters",
 		"api_secret_subscribers",
 		"subscriptions",
 		"subscribers",
@@ -168,6 +170,10 @@ func newTestApp(t *testing.T) *app.Application {
 		SecretCache:       app.NewCache[pgtype.UUID, db.ApiSecret](),
 		LogConfigCache:    app.NewCache[string, db.LogConfig](),
 		SubscriptionCache: app.NewSubscriptionCache(queries),
+		HTTPClients:       app.NewHTTPClientPool(),
+		OAuthTokens:       app.NewOAuthTokenCache(),
+		Metrics:           app.NewMetrics(),
+		TracerProvider:    app.NewTracerProvider(),
 	}
 }
 
@@ -188,11 +194,20 @@ func newUUID() pgtype.UUID {
 func seedSubscriber(t *testing.T, queries db.Querier, name, endpointURL, authSecret string) db.Subscriber {
 	t.Helper()
 	sub, err := queries.UpsertSubscriber(context.Background(), db.UpsertSubscriberParams{
-		ID:          newUUID(),
-		Name:        name,
-		EndpointUrl: endpointURL,
-		AuthSecret:  authSecret,
-		MaxParallel: 1,
+		ID:                   newUUID(),
+		Name:                 name,
+		EndpointUrl:          endpointURL,
+		AuthSecret:           authSecret,
+		MaxParallel:          1,
+		SigningMode:          "secret",
+		PayloadFormat:        "data",
+		BatchSize:            1,
+		NonRetryableStatuses: []int32{400, 404, 410},
+		HttpMethod:           "POST",
+		TimeoutSeconds:       30,
+		ExtraHeaders:         []byte("{}"),
+		AuthMode:             "none",
+		OauthScopes:          []string{},
 	})
 	if err != nil {
 		t.Fatalf("seedSubscriber: %v", err)
@@ -208,11 +223,14 @@ func seedSubscription(t *testing.T, queries db.Querier, subscriberID pgtype.UUID
 		mr = pgtype.Int4{Int32: *maxRetries, Valid: true}
 	}
 	sub, err := queries.CreateSubscription(context.Background(), db.CreateSubscriptionParams{
-		ID:             newUUID(),
-		SubscriberID:   subscriberID,
-		SubjectPattern: subjectPattern,
-		Filter:         filter,
-		MaxRetries:     mr,
+		ID:                   newUUID(),
+		SubscriberID:         subscriberID,
+		SubjectPattern:       subjectPattern,
+		Filter:               filter,
+		MaxRetries:           mr,
+		RetryPolicy:          "exponential",
+		RetryIntervalSeconds: 1,
+		RetrySchedule:        []int32{},
 	})
 	if err != nil {
 		t.Fatalf("seedSubscription: %v", err)
@@ -47,13 +47,22 @@ type SubscriberOpt func(*db.Subscriber)
 // NewSubscriber creates a db.Subscriber with sensible defaults.
 func NewSubscriber(opts ...SubscriberOpt) db.Subscriber {
 	s := db.Subscriber{
-		ID:          NewUUID(),
-		Name:        "test-subscriber",
-		EndpointUrl: "https://example.com/webhook",
-		AuthSecret:  "test-auth-secret",
-		MaxParallel: 1,
-		CreatedAt:   NewTimestamp(),
-		UpdatedAt:   NewTimestamp(),
+		ID:                   NewUUID(),
+		Name:                 "test-subscriber",
+		EndpointUrl:          "https://example.com/webhook",
+		AuthSecret:           "test-auth-secret",
+		MaxParallel:          1,
+		CreatedAt:            NewTimestamp(),
+		UpdatedAt:            NewTimestamp(),
+		SigningMode:          "secret",
+		PayloadFormat:        "data",
+		BatchSize:            1,
+		NonRetryableStatuses: []int32{400, 404, 410},
+		HttpMethod:           "POST",
+		TimeoutSeconds:       30,
+		ExtraHeaders:         []byte("{}"),
+		AuthMode:             "none",
+		OauthScopes:          []string{},
 	}
 	for _, opt := range opts {
 		opt(&s)
@@ -67,11 +76,14 @@ type SubscriptionOpt func(*db.Subscription)
 // NewSubscription creates a db.Subscription with sensible defaults.
 func NewSubscription(opts ...SubscriptionOpt) db.Subscription {
 	s := db.Subscription{
-		ID:             NewUUID(),
-		SubscriberID:   NewUUID(),
-		SubjectPattern: "test.%",
-		CreatedAt:      NewTimestamp(),
-		UpdatedAt:      NewTimestamp(),
+		ID:                   NewUUID(),
+		SubscriberID:         NewUUID(),
+		SubjectPattern:       "test.%",
+		CreatedAt:            NewTimestamp(),
+		UpdatedAt:            NewTimestamp(),
+		RetryPolicy:          "exponential",
+		RetryIntervalSeconds: 1,
+		RetrySchedule:        []int32{},
 	}
 	for _, opt := range opts {
 		opt(&s)
@@ -138,6 +150,10 @@ func NewTestApp(mockDB *MockQuerier, opts ...AppOpt) *app.Application {
 		SecretCache:       app.NewCache[pgtype.UUID, db.ApiSecret](),
 		LogConfigCache:    app.NewCache[string, db.LogConfig](),
 		SubscriptionCache: app.NewSubscriptionCache(mockDB),
+		HTTPClients:       app.NewHTTPClientPool(),
+		OAuthTokens:       app.NewOAuthTokenCache(),
+		Metrics:           app.NewMetrics(),
+		TracerProvider:    app.NewTracerProvider(),
 	}
 	for _, opt := range opts {
 		opt(a)
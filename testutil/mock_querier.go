@@ -20,11 +20,31 @@ func (m *MockQuerier) AddApiSecretSubscriber(ctx context.Context, arg db.AddApiS
 	return args.Error(0)
 }
 
+func (m *MockQuerier) ClaimDueDeliveryRetries(ctx context.Context, arg db.ClaimDueDeliveryRetriesParams) ([]db.DeliveryRetry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.DeliveryRetry), args.Error(1)
+}
+
+func (m *MockQuerier) CountDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) CountEventsAfterTimestamp(ctx context.Context, arg db.CountEventsAfterTimestampParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockQuerier) CountEventsForReplay(ctx context.Context, arg db.CountEventsForReplayParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) CountHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, subscriberID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) CreateSubscription(ctx context.Context, arg db.CreateSubscriptionParams) (db.Subscription, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Subscription), args.Error(1)
@@ -35,6 +55,26 @@ func (m *MockQuerier) DeleteApiSecret(ctx context.Context, id pgtype.UUID) error
 	return args.Error(0)
 }
 
+func (m *MockQuerier) DeleteDeadLetter(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) DeleteDeadLetterForSubscriber(ctx context.Context, arg db.DeleteDeadLetterForSubscriberParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) DeleteDeadLettersForEvent(ctx context.Context, eventID pgtype.UUID) error {
+	args := m.Called(ctx, eventID)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) DeleteDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) error {
+	args := m.Called(ctx, eventID)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) DeleteLogConfigForSubject(ctx context.Context, subject string) error {
 	args := m.Called(ctx, subject)
 	return args.Error(0)
@@ -55,6 +95,21 @@ func (m *MockQuerier) DeleteSubscriptionsForSubscriber(ctx context.Context, subs
 	return args.Error(0)
 }
 
+func (m *MockQuerier) DisableSubscriber(ctx context.Context, arg db.DisableSubscriberParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) EnableSubscriber(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) FinishReplayJob(ctx context.Context, arg db.FinishReplayJobParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) GetApiSecretByID(ctx context.Context, id pgtype.UUID) (db.ApiSecret, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(db.ApiSecret), args.Error(1)
@@ -65,9 +120,14 @@ func (m *MockQuerier) GetApiSecretSubscriberExists(ctx context.Context, arg db.G
 	return args.Get(0).(bool), args.Error(1)
 }
 
-func (m *MockQuerier) GetDeliverySummaryForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.GetDeliverySummaryForEventRow, error) {
-	args := m.Called(ctx, eventID)
-	return args.Get(0).([]db.GetDeliverySummaryForEventRow), args.Error(1)
+func (m *MockQuerier) GetDeadLettersByIDs(ctx context.Context, ids []pgtype.UUID) ([]db.DeadLetter, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]db.DeadLetter), args.Error(1)
+}
+
+func (m *MockQuerier) GetDeliverySummaryForSubscriber(ctx context.Context, arg db.GetDeliverySummaryForSubscriberParams) (db.GetDeliverySummaryForSubscriberRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.GetDeliverySummaryForSubscriberRow), args.Error(1)
 }
 
 func (m *MockQuerier) GetEventByID(ctx context.Context, id pgtype.UUID) (db.Event, error) {
@@ -75,6 +135,11 @@ func (m *MockQuerier) GetEventByID(ctx context.Context, id pgtype.UUID) (db.Even
 	return args.Get(0).(db.Event), args.Error(1)
 }
 
+func (m *MockQuerier) GetReplayJob(ctx context.Context, id pgtype.UUID) (db.ReplayJob, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(db.ReplayJob), args.Error(1)
+}
+
 func (m *MockQuerier) GetResumableEvents(ctx context.Context) ([]db.Event, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -95,11 +160,6 @@ func (m *MockQuerier) GetSubscriberByID(ctx context.Context, id pgtype.UUID) (db
 	return args.Get(0).(db.Subscriber), args.Error(1)
 }
 
-func (m *MockQuerier) GetSubscriptionsMatchingSubject(ctx context.Context, subjectPattern string) ([]db.Subscription, error) {
-	args := m.Called(ctx, subjectPattern)
-	return args.Get(0).([]db.Subscription), args.Error(1)
-}
-
 func (m *MockQuerier) InsertApiSecret(ctx context.Context, arg db.InsertApiSecretParams) (db.ApiSecret, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.ApiSecret), args.Error(1)
@@ -115,6 +175,11 @@ func (m *MockQuerier) InsertEvent(ctx context.Context, arg db.InsertEventParams)
 	return args.Get(0).(db.Event), args.Error(1)
 }
 
+func (m *MockQuerier) InsertReplayJob(ctx context.Context, arg db.InsertReplayJobParams) (db.ReplayJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ReplayJob), args.Error(1)
+}
+
 func (m *MockQuerier) ListAllApiSecretHashes(ctx context.Context) ([]db.ListAllApiSecretHashesRow, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.ListAllApiSecretHashesRow), args.Error(1)
@@ -135,6 +200,21 @@ func (m *MockQuerier) ListApiSecretsForSubscriber(ctx context.Context, subscribe
 	return args.Get(0).([]db.ApiSecret), args.Error(1)
 }
 
+func (m *MockQuerier) ListDeadLettersFiltered(ctx context.Context, arg db.ListDeadLettersFilteredParams) ([]db.ListDeadLettersFilteredRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListDeadLettersFilteredRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDeliveriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.ListDeliveriesForEventRow, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).([]db.ListDeliveriesForEventRow), args.Error(1)
+}
+
+func (m *MockQuerier) ListDeliveriesForSubscriber(ctx context.Context, arg db.ListDeliveriesForSubscriberParams) ([]db.ListDeliveriesForSubscriberRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ListDeliveriesForSubscriberRow), args.Error(1)
+}
+
 func (m *MockQuerier) ListDeliveryAttemptsForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.DeliveryAttempt, error) {
 	args := m.Called(ctx, eventID)
 	return args.Get(0).([]db.DeliveryAttempt), args.Error(1)
@@ -145,6 +225,11 @@ func (m *MockQuerier) ListDeliveryAttemptsForSubscriber(ctx context.Context, sub
 	return args.Get(0).([]db.DeliveryAttempt), args.Error(1)
 }
 
+func (m *MockQuerier) ListDeliveryRetriesForEvent(ctx context.Context, eventID pgtype.UUID) ([]db.DeliveryRetry, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).([]db.DeliveryRetry), args.Error(1)
+}
+
 func (m *MockQuerier) ListEvents(ctx context.Context, arg db.ListEventsParams) ([]db.Event, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -155,11 +240,31 @@ func (m *MockQuerier) ListEventsAfterTimestamp(ctx context.Context, arg db.ListE
 	return args.Get(0).([]db.Event), args.Error(1)
 }
 
+func (m *MockQuerier) ListEventsForReplay(ctx context.Context, arg db.ListEventsForReplayParams) ([]db.Event, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.Event), args.Error(1)
+}
+
 func (m *MockQuerier) ListLogConfigs(ctx context.Context) ([]db.LogConfig, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.LogConfig), args.Error(1)
 }
 
+func (m *MockQuerier) ListReplayJobs(ctx context.Context, limit int32) ([]db.ReplayJob, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]db.ReplayJob), args.Error(1)
+}
+
+func (m *MockQuerier) ListRunningReplayJobs(ctx context.Context) ([]db.ReplayJob, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]db.ReplayJob), args.Error(1)
+}
+
+func (m *MockQuerier) ListSubscriberLatencyStats(ctx context.Context, since pgtype.Timestamptz) ([]db.ListSubscriberLatencyStatsRow, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]db.ListSubscriberLatencyStatsRow), args.Error(1)
+}
+
 func (m *MockQuerier) ListSubscribers(ctx context.Context) ([]db.Subscriber, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]db.Subscriber), args.Error(1)
@@ -180,6 +285,26 @@ func (m *MockQuerier) ListSubscriptionsForSubscriber(ctx context.Context, subscr
 	return args.Get(0).([]db.Subscription), args.Error(1)
 }
 
+func (m *MockQuerier) PauseSubscriber(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) RecordDeliveryAttempt(ctx context.Context, arg db.RecordDeliveryAttemptParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) ReleaseHeldDeliveries(ctx context.Context, subscriberID pgtype.UUID) error {
+	args := m.Called(ctx, subscriberID)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) ReleaseHeldDeliveryRetries(ctx context.Context, subscriberID pgtype.UUID) (int64, error) {
+	args := m.Called(ctx, subscriberID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQuerier) RemoveAllApiSecretSubscribers(ctx context.Context, apiSecretID pgtype.UUID) error {
 	args := m.Called(ctx, apiSecretID)
 	return args.Error(0)
@@ -190,6 +315,11 @@ func (m *MockQuerier) RemoveApiSecretSubscriber(ctx context.Context, arg db.Remo
 	return args.Error(0)
 }
 
+func (m *MockQuerier) ResumeSubscriber(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) SearchEventsByDataContent(ctx context.Context, arg db.SearchEventsByDataContentParams) ([]db.Event, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]db.Event), args.Error(1)
@@ -230,6 +360,11 @@ func (m *MockQuerier) UpdateEventDeliveryStatus(ctx context.Context, arg db.Upda
 	return args.Get(0).(db.Event), args.Error(1)
 }
 
+func (m *MockQuerier) UpdateReplayJobProgress(ctx context.Context, arg db.UpdateReplayJobProgressParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) UpdateSubscriber(ctx context.Context, arg db.UpdateSubscriberParams) (db.Subscriber, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.Subscriber), args.Error(1)
@@ -240,6 +375,21 @@ func (m *MockQuerier) UpdateSubscription(ctx context.Context, arg db.UpdateSubsc
 	return args.Get(0).(db.Subscription), args.Error(1)
 }
 
+func (m *MockQuerier) UpsertDeadLetter(ctx context.Context, arg db.UpsertDeadLetterParams) (db.DeadLetter, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.DeadLetter), args.Error(1)
+}
+
+func (m *MockQuerier) UpsertDelivery(ctx context.Context, arg db.UpsertDeliveryParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpsertDeliveryRetry(ctx context.Context, arg db.UpsertDeliveryRetryParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockQuerier) UpsertLogConfig(ctx context.Context, arg db.UpsertLogConfigParams) (db.LogConfig, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(db.LogConfig), args.Error(1)
@@ -0,0 +1,144 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("GET /dead-letters", routeHandler(slurpee, deadLettersListHandler))
+		router.Handle("POST /dead-letters/redrive", routeHandler(slurpee, deadLettersRedriveHandler))
+	})
+}
+
+const deadLettersPerPage = 50
+
+func deadLettersListHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	renderDeadLettersPage(slurpee, w, r, "", "")
+}
+
+func deadLettersRedriveHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	var ids []pgtype.UUID
+	for _, s := range r.PostForm["id"] {
+		parsed, err := uuid.Parse(s)
+		if err != nil {
+			renderDeadLettersPage(slurpee, w, r, "", "Invalid dead letter ID")
+			return
+		}
+		ids = append(ids, pgtype.UUID{Bytes: parsed, Valid: true})
+	}
+	if len(ids) == 0 {
+		renderDeadLettersPage(slurpee, w, r, "", "Select at least one dead letter to redrive")
+		return
+	}
+
+	result, err := app.RedriveDeadLetters(r.Context(), slurpee, ids)
+	if err != nil {
+		if errors.Is(err, app.ErrDispatcherNotRunning) {
+			renderDeadLettersPage(slurpee, w, r, "", "Delivery dispatcher is not running")
+			return
+		}
+		log(r.Context()).Error("Error redriving dead letters", "err", err)
+		renderDeadLettersPage(slurpee, w, r, "", "Failed to redrive dead letters")
+		return
+	}
+
+	msg := fmt.Sprintf("Redrove %d dead letter(s)", result.Redriven)
+	if result.Skipped > 0 {
+		msg += fmt.Sprintf(", skipped %d (deliveries in flight or subscriber removed)", result.Skipped)
+	}
+	renderDeadLettersPage(slurpee, w, r, msg, "")
+}
+
+func renderDeadLettersPage(slurpee *app.Application, w http.ResponseWriter, r *http.Request, successMsg, errorMsg string) {
+	query := r.URL.Query()
+	subscriberID := query.Get("subscriber_id")
+	subject := query.Get("subject")
+
+	page := 1
+	if p := query.Get("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	params := db.ListDeadLettersFilteredParams{
+		Limit:  deadLettersPerPage + 1,
+		Offset: int32((page - 1) * deadLettersPerPage),
+	}
+	if subscriberID != "" {
+		parsed, err := uuid.Parse(subscriberID)
+		if err == nil {
+			params.SubscriberFilter = pgtype.UUID{Bytes: parsed, Valid: true}
+		}
+	}
+	// Subject filter: use LIKE with % wildcards for partial matching
+	if subject != "" {
+		params.SubjectFilter = "%" + subject + "%"
+	}
+
+	deadLetters, err := slurpee.DB.ListDeadLettersFiltered(r.Context(), params)
+	if err != nil {
+		log(r.Context()).Error("Error listing dead letters", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	subscribers, err := slurpee.DB.ListSubscribers(r.Context())
+	if err != nil {
+		log(r.Context()).Error("Error listing subscribers", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	hasNext := len(deadLetters) > deadLettersPerPage
+	if hasNext {
+		deadLetters = deadLetters[:deadLettersPerPage]
+	}
+
+	rows := make([]DeadLetterRow, len(deadLetters))
+	for i, dl := range deadLetters {
+		rows[i] = DeadLetterRow{
+			ID:             pgtypeUUIDToString(dl.ID),
+			EventID:        pgtypeUUIDToString(dl.EventID),
+			Subject:        dl.Subject,
+			SubscriberID:   pgtypeUUIDToString(dl.SubscriberID),
+			SubscriberName: dl.SubscriberName,
+			EndpointURL:    dl.EndpointUrl,
+			Attempts:       dl.Attempts,
+			LastError:      dl.LastError,
+			ExhaustedAt:    dl.ExhaustedAt.Time.Format("2006-01-02 15:04:05 MST"),
+		}
+	}
+
+	options := make([]SubscriberOption, len(subscribers))
+	for i, s := range subscribers {
+		options[i] = SubscriberOption{
+			ID:          pgtypeUUIDToString(s.ID),
+			Name:        s.Name,
+			EndpointURL: s.EndpointUrl,
+		}
+	}
+
+	if errorMsg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := DeadLettersListTemplate(rows, options, subscriberID, subject, page, hasNext, successMsg, errorMsg).Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering dead letters view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/sweater-ventures/slurpee/api"
 	"github.com/sweater-ventures/slurpee/app"
 	"github.com/sweater-ventures/slurpee/db"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func init() {
@@ -179,6 +181,13 @@ func eventDetailHandler(slurpee *app.Application, w http.ResponseWriter, r *http
 		return
 	}
 
+	deliveries, err := slurpee.DB.ListDeliveriesForEvent(r.Context(), pgID)
+	if err != nil {
+		log(r.Context()).Error("Error fetching deliveries", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	attempts, err := slurpee.DB.ListDeliveryAttemptsForEvent(r.Context(), pgID)
 	if err != nil {
 		log(r.Context()).Error("Error fetching delivery attempts", "err", err)
@@ -186,9 +195,24 @@ func eventDetailHandler(slurpee *app.Application, w http.ResponseWriter, r *http
 		return
 	}
 
-	detail, attemptRows := buildEventDetailView(event, attempts)
+	skipped, err := app.OutOfScopeSubscribers(r.Context(), slurpee, event)
+	if err != nil {
+		log(r.Context()).Error("Error resolving out-of-scope subscribers", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	detail, deliveryRows, attemptRows := buildEventDetailView(event, deliveries, attempts)
+	skippedRows := make([]SkippedSubscriberRow, len(skipped))
+	for i, s := range skipped {
+		skippedRows[i] = SkippedSubscriberRow{
+			ID:          pgtypeUUIDToString(s.ID),
+			Name:        s.Name,
+			EndpointURL: s.EndpointUrl,
+		}
+	}
 
-	if err := EventDetailTemplate(detail, attemptRows).Render(r.Context(), w); err != nil {
+	if err := EventDetailTemplate(detail, deliveryRows, attemptRows, skippedRows).Render(r.Context(), w); err != nil {
 		log(r.Context()).Error("Error rendering event detail view", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -214,15 +238,23 @@ func eventReplayAllHandler(slurpee *app.Application, w http.ResponseWriter, r *h
 		return
 	}
 
-	// Reset event status to pending and send to delivery channel
-	slurpee.DeliveryChan <- event
+	if err := app.ReplayEvent(r.Context(), slurpee, event); err != nil {
+		if errors.Is(err, app.ErrReplayInFlight) {
+			http.Error(w, "Event has deliveries in flight", http.StatusConflict)
+			return
+		}
+		log(r.Context()).Error("Error replaying event", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
 
-	// Re-fetch the event and delivery attempts for the updated view
+	// Re-fetch the event, deliveries and delivery attempts for the updated view
 	event, _ = slurpee.DB.GetEventByID(r.Context(), pgID)
+	deliveries, _ := slurpee.DB.ListDeliveriesForEvent(r.Context(), pgID)
 	attempts, _ := slurpee.DB.ListDeliveryAttemptsForEvent(r.Context(), pgID)
 
-	detail, attemptRows := buildEventDetailView(event, attempts)
-	if err := deliveryAttemptsSection(detail, attemptRows).Render(r.Context(), w); err != nil {
+	detail, deliveryRows, attemptRows := buildEventDetailView(event, deliveries, attempts)
+	if err := deliveryAttemptsSection(detail, deliveryRows, attemptRows).Render(r.Context(), w); err != nil {
 		log(r.Context()).Error("Error rendering delivery section", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -267,21 +299,37 @@ func eventReplaySubscriberHandler(slurpee *app.Application, w http.ResponseWrite
 		return
 	}
 
-	// Replay delivery to the single subscriber in a background goroutine
-	go app.ReplayToSubscriber(slurpee, event, subscriber)
+	// Submit the delivery to the dispatcher; it is retried like any other
+	if err := app.ReplayToSubscriber(r.Context(), slurpee, event, subscriber); err != nil {
+		switch {
+		case errors.Is(err, app.ErrReplayOutOfScope):
+			http.Error(w, "Subscriber is outside the publishing API secret's scope", http.StatusForbidden)
+		case errors.Is(err, app.ErrReplayNotSubscribed):
+			http.Error(w, "Subscriber has no subscription matching the event", http.StatusConflict)
+		case errors.Is(err, app.ErrReplayInFlight):
+			http.Error(w, "Event has deliveries in flight", http.StatusConflict)
+		case errors.Is(err, app.ErrDispatcherNotRunning):
+			http.Error(w, "Delivery dispatcher is not running", http.StatusServiceUnavailable)
+		default:
+			log(r.Context()).Error("Error replaying event to subscriber", "err", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
 
-	// Re-fetch the event and delivery attempts for the updated view
+	// Re-fetch the event, deliveries and delivery attempts for the updated view
 	event, _ = slurpee.DB.GetEventByID(r.Context(), pgID)
+	deliveries, _ := slurpee.DB.ListDeliveriesForEvent(r.Context(), pgID)
 	attempts, _ := slurpee.DB.ListDeliveryAttemptsForEvent(r.Context(), pgID)
 
-	detail, attemptRows := buildEventDetailView(event, attempts)
-	if err := deliveryAttemptsSection(detail, attemptRows).Render(r.Context(), w); err != nil {
+	detail, deliveryRows, attemptRows := buildEventDetailView(event, deliveries, attempts)
+	if err := deliveryAttemptsSection(detail, deliveryRows, attemptRows).Render(r.Context(), w); err != nil {
 		log(r.Context()).Error("Error rendering delivery section", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
-func buildEventDetailView(event db.Event, attempts []db.DeliveryAttempt) (EventDetail, []DeliveryAttemptRow) {
+func buildEventDetailView(event db.Event, deliveries []db.ListDeliveriesForEventRow, attempts []db.DeliveryAttempt) (EventDetail, []DeliveryRow, []DeliveryAttemptRow) {
 	var prettyData string
 	var rawData interface{}
 	if err := json.Unmarshal(event.Data, &rawData); err == nil {
@@ -308,6 +356,28 @@ func buildEventDetailView(event db.Event, attempts []db.DeliveryAttempt) (EventD
 		detail.StatusUpdatedAt = event.StatusUpdatedAt.Time.Format("2006-01-02 15:04:05 MST")
 	}
 
+	deliveryRows := make([]DeliveryRow, len(deliveries))
+	for i, d := range deliveries {
+		row := DeliveryRow{
+			SubscriberID:   pgtypeUUIDToString(d.SubscriberID),
+			SubscriberName: d.SubscriberName,
+			EndpointURL:    d.EndpointUrl,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastError:      d.LastError,
+		}
+		if d.LastAttemptAt.Valid {
+			row.LastAttemptAt = d.LastAttemptAt.Time.Format("2006-01-02 15:04:05 MST")
+		}
+		if d.NextAttemptAt.Valid {
+			row.NextAttemptAt = d.NextAttemptAt.Time.Format("2006-01-02 15:04:05 MST")
+		}
+		if d.LagMs.Valid {
+			row.Lag = formatMillis(float64(d.LagMs.Int64))
+		}
+		deliveryRows[i] = row
+	}
+
 	attemptRows := make([]DeliveryAttemptRow, len(attempts))
 	for i, a := range attempts {
 		row := DeliveryAttemptRow{
@@ -320,6 +390,9 @@ func buildEventDetailView(event db.Event, attempts []db.DeliveryAttempt) (EventD
 		if a.ResponseStatusCode.Valid {
 			row.ResponseStatusCode = fmt.Sprintf("%d", a.ResponseStatusCode.Int32)
 		}
+		if a.DurationMs.Valid {
+			row.Duration = formatMillis(float64(a.DurationMs.Int32))
+		}
 		if len(a.RequestHeaders) > 0 {
 			row.RequestHeaders = prettyJSON(a.RequestHeaders)
 		}
@@ -332,7 +405,7 @@ func buildEventDetailView(event db.Event, attempts []db.DeliveryAttempt) (EventD
 		attemptRows[i] = row
 	}
 
-	return detail, attemptRows
+	return detail, deliveryRows, attemptRows
 }
 
 func eventCreateFormHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
@@ -400,7 +473,9 @@ func eventCreateSubmitHandler(slurpee *app.Application, w http.ResponseWriter, r
 	now := time.Now().UTC()
 	eventID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 
-	event, err := slurpee.DB.InsertEvent(r.Context(), db.InsertEventParams{
+	ctx, span := app.StartCreateEventSpan(r.Context(), slurpee, app.IngestSpanParent(trace.SpanContext{}, traceID), subject)
+	defer span.End()
+	event, err := app.InsertEvent(ctx, slurpee, db.InsertEventParams{
 		ID:              eventID,
 		Subject:         subject,
 		Timestamp:       pgtype.Timestamptz{Time: now, Valid: true},
@@ -412,6 +487,7 @@ func eventCreateSubmitHandler(slurpee *app.Application, w http.ResponseWriter, r
 	})
 	if err != nil {
 		log(r.Context()).Error("Error creating event", "err", err)
+		span.SetStatus(codes.Error, "failed to insert event")
 		form.Errors["general"] = "Failed to create event. Please try again."
 		w.WriteHeader(http.StatusInternalServerError)
 		if renderErr := EventCreateTemplate(form).Render(r.Context(), w); renderErr != nil {
@@ -420,6 +496,7 @@ func eventCreateSubmitHandler(slurpee *app.Application, w http.ResponseWriter, r
 		return
 	}
 	api.LogEvent(r.Context(), slurpee, event)
+	slurpee.Metrics.EventIngested(event.Subject)
 	// Publish 'created' message to the event bus for SSE clients
 	props := app.ExtractLogProperties(r.Context(), slurpee, event.Subject, event.Data)
 	app.PublishCreatedEvent(slurpee, event, props)
@@ -613,3 +690,16 @@ func pgtypeUUIDToString(u pgtype.UUID) string {
 	}
 	return uuid.UUID(u.Bytes).String()
 }
+
+// formatMillis renders a duration in milliseconds in the largest unit that
+// keeps it readable.
+func formatMillis(ms float64) string {
+	switch {
+	case ms < 1000:
+		return fmt.Sprintf("%.0f ms", ms)
+	case ms < 60000:
+		return fmt.Sprintf("%.1f s", ms/1000)
+	default:
+		return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+	}
+}
@@ -48,6 +48,11 @@ func loggingCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *ht
 		renderLoggingWithError(slurpee, w, r, "Subject and properties are required")
 		return
 	}
+	patternSyntax, errMsg := parsePatternSyntax(r, subject)
+	if errMsg != "" {
+		renderLoggingWithError(slurpee, w, r, errMsg)
+		return
+	}
 
 	properties := parseProperties(propertiesStr)
 
@@ -56,6 +61,7 @@ func loggingCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *ht
 		ID:            configID,
 		Subject:       subject,
 		LogProperties: properties,
+		PatternSyntax: patternSyntax,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error creating log config", "err", err)
@@ -80,6 +86,11 @@ func loggingUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r *ht
 		renderLoggingWithError(slurpee, w, r, "Subject and properties are required")
 		return
 	}
+	patternSyntax, errMsg := parsePatternSyntax(r, subject)
+	if errMsg != "" {
+		renderLoggingWithError(slurpee, w, r, errMsg)
+		return
+	}
 
 	properties := parseProperties(propertiesStr)
 
@@ -89,6 +100,7 @@ func loggingUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r *ht
 		ID:            configID,
 		Subject:       subject,
 		LogProperties: properties,
+		PatternSyntax: patternSyntax,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error updating log config", "err", err)
@@ -123,6 +135,7 @@ func buildLogConfigRows(configs []db.LogConfig) []LogConfigRow {
 	for i, c := range configs {
 		rows[i] = LogConfigRow{
 			Subject:       c.Subject,
+			PatternSyntax: c.PatternSyntax,
 			LogProperties: strings.Join(c.LogProperties, ", "),
 		}
 	}
@@ -10,11 +10,14 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import (
 	"fmt"
+	"github.com/sweater-ventures/slurpee/app"
 	"github.com/sweater-ventures/slurpee/components"
+	"net/url"
 )
 
 type LogConfigRow struct {
 	Subject       string
+	PatternSyntax string
 	LogProperties string
 }
 
@@ -102,7 +105,7 @@ func loggingContent(configs []LogConfigRow, successMsg string, errorMsg string)
 			var templ_7745c5c3_Var4 string
 			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(successMsg)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 24, Col: 21}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 27, Col: 21}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
@@ -121,7 +124,7 @@ func loggingContent(configs []LogConfigRow, successMsg string, errorMsg string)
 			var templ_7745c5c3_Var5 string
 			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(errorMsg)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 29, Col: 19}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 32, Col: 19}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
 			if templ_7745c5c3_Err != nil {
@@ -139,7 +142,7 @@ func loggingContent(configs []LogConfigRow, successMsg string, errorMsg string)
 		var templ_7745c5c3_Var6 string
 		templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d", len(configs)))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 35, Col: 73}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 38, Col: 73}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 		if templ_7745c5c3_Err != nil {
@@ -163,61 +166,85 @@ func loggingContent(configs []LogConfigRow, successMsg string, errorMsg string)
 			var templ_7745c5c3_Var7 string
 			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(cfg.Subject)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 58, Col: 49}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 62, Col: 20}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</td><td class=\"font-mono text-sm\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, " ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = patternSyntaxBadge(cfg.PatternSyntax).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</td><td class=\"font-mono text-sm\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var8 string
 			templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(cfg.LogProperties)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 59, Col: 55}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 65, Col: 55}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "</td><td class=\"flex gap-2 justify-end\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</td><td class=\"flex gap-2 justify-end\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, showEditModal(cfg.Subject, cfg.LogProperties))
+			templ_7745c5c3_Err = templ.RenderScriptItems(ctx, templ_7745c5c3_Buffer, showEditModal(cfg.Subject, cfg.PatternSyntax, cfg.LogProperties))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<button class=\"btn btn-ghost btn-xs\" onclick=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<button class=\"btn btn-ghost btn-xs\" onclick=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var9 templ.ComponentScript = showEditModal(cfg.Subject, cfg.LogProperties)
+			var templ_7745c5c3_Var9 templ.ComponentScript = showEditModal(cfg.Subject, cfg.PatternSyntax, cfg.LogProperties)
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var9.Call)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "\">Edit</button> <button class=\"btn btn-ghost btn-xs text-error\" hx-delete=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "\">Edit</button> <button class=\"btn btn-ghost btn-xs text-error\" hx-delete=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var10 string
-			templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs("/logging/" + cfg.Subject)
+			templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs("/logging/" + url.PathEscape(cfg.Subject))
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 69, Col: 45}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/logging.templ`, Line: 75, Col: 61}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" hx-confirm=\"Are you sure you want to delete this logging configuration?\">Delete</button></td></tr>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" hx-confirm=\"Are you sure you want to delete this logging configuration?\">Delete</button></td></tr>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "</tbody></table></div><!-- Add Log Config Modal --><dialog id=\"add-logconfig-modal\" class=\"modal\"><div class=\"modal-box\"><h3 class=\"text-lg font-bold\">Add Logging Configuration</h3><form hx-post=\"/logging\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" class=\"mt-4\"><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Subject</span></label> <input type=\"text\" name=\"subject\" class=\"input input-bordered w-full font-mono\" placeholder=\"e.g., order.created\" required></div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Properties (comma-separated JSON paths)</span></label> <input type=\"text\" name=\"properties\" class=\"input input-bordered w-full font-mono\" placeholder=\"e.g., user_id, amount, status\" required></div><div class=\"modal-action\"><button type=\"button\" class=\"btn btn-ghost\" onclick=\"document.getElementById('add-logconfig-modal').close()\">Cancel</button> <button type=\"submit\" class=\"btn btn-primary\" onclick=\"document.getElementById('add-logconfig-modal').close()\">Add</button></div></form></div><form method=\"dialog\" class=\"modal-backdrop\"><button>close</button></form></dialog><!-- Edit Log Config Modal --><dialog id=\"edit-logconfig-modal\" class=\"modal\"><div class=\"modal-box\"><h3 class=\"text-lg font-bold\">Edit Logging Configuration</h3><form hx-put=\"/logging\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" class=\"mt-4\"><input type=\"hidden\" id=\"edit-subject\" name=\"subject\"><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Subject</span></label><p id=\"edit-subject-display\" class=\"font-mono text-sm mt-1\"></p></div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Properties (comma-separated JSON paths)</span></label> <input type=\"text\" id=\"edit-properties\" name=\"properties\" class=\"input input-bordered w-full font-mono\" required></div><div class=\"modal-action\"><button type=\"button\" class=\"btn btn-ghost\" onclick=\"document.getElementById('edit-logconfig-modal').close()\">Cancel</button> <button type=\"submit\" class=\"btn btn-primary\" onclick=\"document.getElementById('edit-logconfig-modal').close()\">Save</button></div></form></div><form method=\"dialog\" class=\"modal-backdrop\"><button>close</button></form></dialog>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</tbody></table></div><!-- Add Log Config Modal --><dialog id=\"add-logconfig-modal\" class=\"modal\"><div class=\"modal-box\"><h3 class=\"text-lg font-bold\">Add Logging Configuration</h3><form hx-post=\"/logging\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" class=\"mt-4\"><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Subject or Pattern</span></label> <input type=\"text\" name=\"subject\" class=\"input input-bordered w-full font-mono\" placeholder=\"e.g., order.created or order.*\" required> <label class=\"label\"><span class=\"label-text-alt\">A configuration for the exact subject wins; otherwise the longest matching pattern is used</span></label></div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Pattern Syntax</span></label> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = patternSyntaxSelect("", app.PatternSyntaxLike).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Properties (comma-separated JSON paths)</span></label> <input type=\"text\" name=\"properties\" class=\"input input-bordered w-full font-mono\" placeholder=\"e.g., user_id, amount, status\" required></div><div class=\"modal-action\"><button type=\"button\" class=\"btn btn-ghost\" onclick=\"document.getElementById('add-logconfig-modal').close()\">Cancel</button> <button type=\"submit\" class=\"btn btn-primary\" onclick=\"document.getElementById('add-logconfig-modal').close()\">Add</button></div></form></div><form method=\"dialog\" class=\"modal-backdrop\"><button>close</button></form></dialog><!-- Edit Log Config Modal --><dialog id=\"edit-logconfig-modal\" class=\"modal\"><div class=\"modal-box\"><h3 class=\"text-lg font-bold\">Edit Logging Configuration</h3><form hx-put=\"/logging\" hx-target=\"#logging-content\" hx-swap=\"innerHTML\" class=\"mt-4\"><input type=\"hidden\" id=\"edit-subject\" name=\"subject\"><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Subject</span></label><p id=\"edit-subject-display\" class=\"font-mono text-sm mt-1\"></p></div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Pattern Syntax</span></label> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = patternSyntaxSelect("edit-pattern-syntax", app.PatternSyntaxLike).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Properties (comma-separated JSON paths)</span></label> <input type=\"text\" id=\"edit-properties\" name=\"properties\" class=\"input input-bordered w-full font-mono\" required></div><div class=\"modal-action\"><button type=\"button\" class=\"btn btn-ghost\" onclick=\"document.getElementById('edit-logconfig-modal').close()\">Cancel</button> <button type=\"submit\" class=\"btn btn-primary\" onclick=\"document.getElementById('edit-logconfig-modal').close()\">Save</button></div></form></div><form method=\"dialog\" class=\"modal-backdrop\"><button>close</button></form></dialog>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -225,16 +252,17 @@ func loggingContent(configs []LogConfigRow, successMsg string, errorMsg string)
 	})
 }
 
-func showEditModal(subject string, properties string) templ.ComponentScript {
+func showEditModal(subject string, patternSyntax string, properties string) templ.ComponentScript {
 	return templ.ComponentScript{
-		Name: `__templ_showEditModal_59c6`,
-		Function: `function __templ_showEditModal_59c6(subject, properties){document.getElementById('edit-subject').value = subject;
+		Name: `__templ_showEditModal_e279`,
+		Function: `function __templ_showEditModal_e279(subject, patternSyntax, properties){document.getElementById('edit-subject').value = subject;
 	document.getElementById('edit-subject-display').textContent = subject;
+	document.getElementById('edit-pattern-syntax').value = patternSyntax || 'like';
 	document.getElementById('edit-properties').value = properties;
 	document.getElementById('edit-logconfig-modal').showModal();
 }`,
-		Call:       templ.SafeScript(`__templ_showEditModal_59c6`, subject, properties),
-		CallInline: templ.SafeScriptInline(`__templ_showEditModal_59c6`, subject, properties),
+		Call:       templ.SafeScript(`__templ_showEditModal_e279`, subject, patternSyntax, properties),
+		CallInline: templ.SafeScriptInline(`__templ_showEditModal_e279`, subject, patternSyntax, properties),
 	}
 }
 
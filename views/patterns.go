@@ -0,0 +1,73 @@
+package views
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sweater-ventures/slurpee/app"
+)
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("GET /patterns", routeHandler(slurpee, patternTesterHandler))
+		router.Handle("POST /patterns/test", routeHandler(slurpee, patternTestHandler))
+	})
+}
+
+// PatternTestResult is one subject checked by the pattern tester.
+type PatternTestResult struct {
+	Subject string
+	Matches bool
+}
+
+func patternTesterHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	if err := PatternTesterTemplate().Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering pattern tester view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func patternTestHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	pattern := strings.TrimSpace(r.FormValue("pattern"))
+	var results []PatternTestResult
+	syntax, errMsg := parsePatternSyntax(r, pattern)
+	if errMsg == "" {
+		for _, line := range strings.Split(r.FormValue("subjects"), "\n") {
+			subject := strings.TrimSpace(line)
+			if subject == "" {
+				continue
+			}
+			results = append(results, PatternTestResult{
+				Subject: subject,
+				Matches: app.MatchSubjectPattern(syntax, pattern, subject),
+			})
+		}
+	}
+
+	if err := patternTestResults(results, errMsg).Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering pattern test results", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// parsePatternSyntax reads the pattern_syntax form field, defaulting to LIKE
+// syntax, and validates pattern in it. The returned error message is empty if
+// the pattern is valid.
+func parsePatternSyntax(r *http.Request, pattern string) (string, string) {
+	syntax := r.FormValue("pattern_syntax")
+	if syntax == "" {
+		syntax = app.PatternSyntaxLike
+	}
+	if !app.ValidPatternSyntax(syntax) {
+		return syntax, "Pattern syntax must be LIKE or token"
+	}
+	if err := app.ValidateSubjectPattern(syntax, pattern); err != nil {
+		return syntax, "Invalid subject pattern: " + err.Error()
+	}
+	return syntax, ""
+}
@@ -0,0 +1,302 @@
+package views
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sweater-ventures/slurpee/app"
+	"github.com/sweater-ventures/slurpee/db"
+)
+
+func init() {
+	registerRoute(func(slurpee *app.Application, router *http.ServeMux) {
+		router.Handle("GET /replays", routeHandler(slurpee, replayJobsListHandler))
+		router.Handle("POST /replays", routeHandler(slurpee, replayJobCreateHandler))
+		router.Handle("GET /replays/{id}", routeHandler(slurpee, replayJobDetailHandler))
+		router.Handle("POST /replays/{id}/cancel", routeHandler(slurpee, replayJobCancelHandler))
+	})
+}
+
+const replayJobsPerPage = 50
+
+func replayJobsListHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	renderReplayJobsPage(slurpee, w, r, ReplayJobForm{}, "")
+}
+
+func replayJobCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	form := ReplayJobForm{
+		Subject:      strings.TrimSpace(r.FormValue("subject")),
+		Status:       r.FormValue("status"),
+		DateFrom:     r.FormValue("date_from"),
+		DateTo:       r.FormValue("date_to"),
+		Content:      strings.TrimSpace(r.FormValue("content")),
+		TraceID:      strings.TrimSpace(r.FormValue("trace_id")),
+		SubscriberID: r.FormValue("subscriber_id"),
+		Rate:         r.FormValue("rate_per_second"),
+	}
+
+	spec := app.ReplayJobSpec{
+		// Subject filter uses the same * wildcard as subscription patterns
+		SubjectFilter: strings.ReplaceAll(form.Subject, "*", "%"),
+		StatusFilter:  form.Status,
+	}
+	if form.DateFrom != "" {
+		t, err := time.Parse("2006-01-02", form.DateFrom)
+		if err != nil {
+			renderReplayJobsPage(slurpee, w, r, form, "From date must be a valid date")
+			return
+		}
+		spec.StartTimeFilter = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+	if form.DateTo != "" {
+		t, err := time.Parse("2006-01-02", form.DateTo)
+		if err != nil {
+			renderReplayJobsPage(slurpee, w, r, form, "To date must be a valid date")
+			return
+		}
+		// End of day
+		spec.EndTimeFilter = pgtype.Timestamptz{Time: t.Add(24*time.Hour - time.Second), Valid: true}
+	}
+	// Unlike the events search, a bad filter is an error rather than ignored:
+	// dropping it would replay more events than intended
+	if form.Content != "" {
+		var data map[string]any
+		if err := json.Unmarshal([]byte(form.Content), &data); err != nil {
+			renderReplayJobsPage(slurpee, w, r, form, "Content must be a JSON object")
+			return
+		}
+		spec.DataFilter = []byte(form.Content)
+	}
+	if form.TraceID != "" {
+		parsed, err := uuid.Parse(form.TraceID)
+		if err != nil {
+			renderReplayJobsPage(slurpee, w, r, form, "Trace ID must be a valid UUID")
+			return
+		}
+		spec.TraceIDFilter = parsed.String()
+	}
+	if form.SubscriberID != "" {
+		parsed, err := uuid.Parse(form.SubscriberID)
+		if err != nil {
+			renderReplayJobsPage(slurpee, w, r, form, "Invalid subscriber")
+			return
+		}
+		spec.SubscriberID = pgtype.UUID{Bytes: parsed, Valid: true}
+	}
+	if form.Rate != "" {
+		rate, err := strconv.Atoi(form.Rate)
+		if err != nil || rate < 1 || rate > app.MaxReplayRatePerSecond {
+			renderReplayJobsPage(slurpee, w, r, form, fmt.Sprintf("Rate must be between 1 and %d", app.MaxReplayRatePerSecond))
+			return
+		}
+		spec.RatePerSecond = int32(rate)
+	}
+	if err := app.ValidateReplayJobSpec(spec); err != nil {
+		renderReplayJobsPage(slurpee, w, r, form, err.Error())
+		return
+	}
+
+	job, err := app.StartReplayJob(r.Context(), slurpee, spec)
+	if err != nil {
+		switch {
+		case errors.Is(err, app.ErrDispatcherNotRunning):
+			renderReplayJobsPage(slurpee, w, r, form, "Delivery dispatcher is not running")
+		case errors.Is(err, pgx.ErrNoRows):
+			renderReplayJobsPage(slurpee, w, r, form, "Subscriber not found")
+		default:
+			log(r.Context()).Error("Error starting replay job", "err", err)
+			renderReplayJobsPage(slurpee, w, r, form, "Failed to start replay job")
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/replays/"+pgtypeUUIDToString(job.ID), http.StatusSeeOther)
+}
+
+func replayJobDetailHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	parsed, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid replay job ID", http.StatusBadRequest)
+		return
+	}
+
+	detail, err := buildReplayJobDetailView(slurpee, r, pgtype.UUID{Bytes: parsed, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Replay job not found", http.StatusNotFound)
+			return
+		}
+		log(r.Context()).Error("Error getting replay job", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Progress polling swaps in just the job details
+	if r.Header.Get("HX-Request") == "true" {
+		err = replayJobDetailContent(detail, "", "").Render(r.Context(), w)
+	} else {
+		err = ReplayJobDetailTemplate(detail).Render(r.Context(), w)
+	}
+	if err != nil {
+		log(r.Context()).Error("Error rendering replay job view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func replayJobCancelHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	parsed, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid replay job ID", http.StatusBadRequest)
+		return
+	}
+	jobID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	successMsg, errorMsg := "Replay job cancelled", ""
+	if err := app.CancelReplayJob(slurpee, jobID); err != nil {
+		successMsg, errorMsg = "", "Replay job is not running"
+	}
+
+	detail, err := buildReplayJobDetailView(slurpee, r, jobID)
+	if err != nil {
+		log(r.Context()).Error("Error getting replay job", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err := replayJobDetailContent(detail, successMsg, errorMsg).Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering replay job view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func renderReplayJobsPage(slurpee *app.Application, w http.ResponseWriter, r *http.Request, form ReplayJobForm, errorMsg string) {
+	jobs, err := slurpee.DB.ListReplayJobs(r.Context(), replayJobsPerPage)
+	if err != nil {
+		log(r.Context()).Error("Error listing replay jobs", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	subscribers, err := slurpee.DB.ListSubscribers(r.Context())
+	if err != nil {
+		log(r.Context()).Error("Error listing subscribers", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	names := make(map[pgtype.UUID]string, len(subscribers))
+	options := make([]SubscriberOption, len(subscribers))
+	for i, s := range subscribers {
+		names[s.ID] = s.Name
+		options[i] = SubscriberOption{
+			ID:          pgtypeUUIDToString(s.ID),
+			Name:        s.Name,
+			EndpointURL: s.EndpointUrl,
+		}
+	}
+
+	rows := make([]ReplayJobRow, len(jobs))
+	for i, job := range jobs {
+		rows[i] = ReplayJobRow{
+			ID:        pgtypeUUIDToString(job.ID),
+			Status:    job.Status,
+			Filters:   replayJobFilterSummary(job),
+			Target:    replayJobTarget(job, names),
+			Processed: job.Processed,
+			Total:     job.Total,
+			CreatedAt: job.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
+		}
+	}
+
+	if form.Rate == "" {
+		form.Rate = strconv.Itoa(app.DefaultReplayRatePerSecond)
+	}
+	if errorMsg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := ReplayJobsListTemplate(rows, options, form, errorMsg).Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering replay jobs view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func buildReplayJobDetailView(slurpee *app.Application, r *http.Request, id pgtype.UUID) (ReplayJobDetail, error) {
+	job, err := slurpee.DB.GetReplayJob(r.Context(), id)
+	if err != nil {
+		return ReplayJobDetail{}, err
+	}
+
+	names := map[pgtype.UUID]string{}
+	if job.SubscriberID.Valid {
+		if subscriber, err := slurpee.DB.GetSubscriberByID(r.Context(), job.SubscriberID); err == nil {
+			names[subscriber.ID] = subscriber.Name
+		}
+	}
+
+	detail := ReplayJobDetail{
+		ID:        pgtypeUUIDToString(job.ID),
+		Status:    job.Status,
+		Filters:   replayJobFilterSummary(job),
+		Target:    replayJobTarget(job, names),
+		Rate:      job.RatePerSecond,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Replayed:  job.Replayed,
+		Skipped:   job.Skipped,
+		Failed:    job.Failed,
+		LastError: job.LastError,
+		CreatedAt: job.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
+	}
+	if job.SubscriberID.Valid {
+		detail.SubscriberID = pgtypeUUIDToString(job.SubscriberID)
+	}
+	if job.FinishedAt.Valid {
+		detail.FinishedAt = job.FinishedAt.Time.Format("2006-01-02 15:04:05 MST")
+	}
+	return detail, nil
+}
+
+// replayJobFilterSummary describes the events a replay job selects, one
+// filter per entry.
+func replayJobFilterSummary(job db.ReplayJob) []string {
+	var filters []string
+	if job.SubjectFilter != "" {
+		filters = append(filters, "subject "+strings.ReplaceAll(job.SubjectFilter, "%", "*"))
+	}
+	if job.StatusFilter != "" {
+		filters = append(filters, "status "+job.StatusFilter)
+	}
+	if job.StartTimeFilter.Valid {
+		filters = append(filters, "from "+job.StartTimeFilter.Time.Format("2006-01-02 15:04:05 MST"))
+	}
+	filters = append(filters, "until "+job.EndTimeFilter.Time.Format("2006-01-02 15:04:05 MST"))
+	if len(job.DataFilter) > 0 {
+		filters = append(filters, "data "+string(job.DataFilter))
+	}
+	if job.TraceIDFilter != "" {
+		filters = append(filters, "trace "+job.TraceIDFilter)
+	}
+	return filters
+}
+
+func replayJobTarget(job db.ReplayJob, names map[pgtype.UUID]string) string {
+	if !job.SubscriberID.Valid {
+		return "All matching subscribers"
+	}
+	if name, ok := names[job.SubscriberID]; ok {
+		return name
+	}
+	return pgtypeUUIDToString(job.SubscriberID)
+}
@@ -50,6 +50,7 @@ func renderSecretsPage(slurpee *app.Application, w http.ResponseWriter, r *http.
 			ID:              pgtypeUUIDToString(s.ID),
 			Name:            s.Name,
 			SubjectPattern:  s.SubjectPattern,
+			PatternSyntax:   s.PatternSyntax,
 			SubscriberNames: s.SubscriberNames,
 			CreatedAt:       s.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
 		}
@@ -86,6 +87,11 @@ func secretCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *htt
 		renderSecretsPage(slurpee, w, r, "", "Name and subject pattern are required", "", "")
 		return
 	}
+	patternSyntax, errMsg := parsePatternSyntax(r, subjectPattern)
+	if errMsg != "" {
+		renderSecretsPage(slurpee, w, r, "", errMsg, "", "")
+		return
+	}
 
 	// Validate host:port constraint: all selected subscribers must share the same host:port
 	if len(subscriberIDs) > 1 {
@@ -137,6 +143,7 @@ func secretCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *htt
 		Name:           name,
 		SecretHash:     hash,
 		SubjectPattern: subjectPattern,
+		PatternSyntax:  patternSyntax,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error inserting API secret", "err", err)
@@ -222,6 +229,12 @@ func secretUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r *htt
 		renderSecretEditPage(slurpee, w, r, secret, "", "Name and subject pattern are required")
 		return
 	}
+	patternSyntax, errMsg := parsePatternSyntax(r, subjectPattern)
+	if errMsg != "" {
+		secret, _ := slurpee.DB.GetApiSecretByID(r.Context(), pgID)
+		renderSecretEditPage(slurpee, w, r, secret, "", errMsg)
+		return
+	}
 
 	// Validate host:port constraint
 	if len(subscriberIDs) > 1 {
@@ -253,11 +266,12 @@ func secretUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r *htt
 		}
 	}
 
-	// Update name and subject_pattern
+	// Update name, subject_pattern and pattern_syntax
 	_, err = slurpee.DB.UpdateApiSecret(r.Context(), db.UpdateApiSecretParams{
 		ID:             pgID,
 		Name:           name,
 		SubjectPattern: subjectPattern,
+		PatternSyntax:  patternSyntax,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error updating API secret", "err", err)
@@ -308,6 +322,7 @@ func renderSecretEditPage(slurpee *app.Application, w http.ResponseWriter, r *ht
 		ID:             pgtypeUUIDToString(secret.ID),
 		Name:           secret.Name,
 		SubjectPattern: secret.SubjectPattern,
+		PatternSyntax:  secret.PatternSyntax,
 	}
 
 	// Load all subscribers and mark which ones are associated
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -19,12 +22,20 @@ func init() {
 		router.Handle("GET /subscribers", routeHandler(slurpee, subscribersListHandler))
 		router.Handle("POST /subscribers", routeHandler(slurpee, subscriberCreateHandler))
 		router.Handle("PUT /subscribers/{id}", routeHandler(slurpee, subscriberUpdateHandler))
+		router.Handle("POST /subscribers/{id}/enable", routeHandler(slurpee, subscriberEnableHandler))
+		router.Handle("POST /subscribers/{id}/pause", routeHandler(slurpee, subscriberPauseHandler))
+		router.Handle("POST /subscribers/{id}/resume", routeHandler(slurpee, subscriberResumeHandler))
 		router.Handle("POST /subscribers/{id}/subscriptions", routeHandler(slurpee, subscriptionCreateHandler))
 		router.Handle("DELETE /subscribers/{id}/subscriptions/{subId}", routeHandler(slurpee, subscriptionDeleteHandler))
+		router.Handle("PUT /subscribers/{id}/subscriptions/{subId}/retry-policy", routeHandler(slurpee, subscriptionRetryPolicyHandler))
 		router.Handle("GET /subscribers/{id}", routeHandler(slurpee, subscriberDetailHandler))
 	})
 }
 
+// latencyWindow is how far back the subscribers page looks for delivery
+// latency percentiles.
+const latencyWindow = 24 * time.Hour
+
 func subscribersListHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
 	renderSubscribersPage(slurpee, w, r, "", "")
 }
@@ -45,6 +56,24 @@ func subscriberCreateHandler(slurpee *app.Application, w http.ResponseWriter, r
 		return
 	}
 
+	signingMode := r.FormValue("signing_mode")
+	if signingMode == "" {
+		signingMode = app.SigningModeSecret
+	}
+	if !app.ValidSigningMode(signingMode) {
+		renderSubscribersPage(slurpee, w, r, "", "Invalid signing mode")
+		return
+	}
+
+	payloadFormat := r.FormValue("payload_format")
+	if payloadFormat == "" {
+		payloadFormat = app.PayloadFormatData
+	}
+	if !app.ValidPayloadFormat(payloadFormat) {
+		renderSubscribersPage(slurpee, w, r, "", "Invalid payload format")
+		return
+	}
+
 	maxParallel := int32(1)
 	if maxParallelStr != "" {
 		val, err := strconv.ParseInt(maxParallelStr, 10, 32)
@@ -55,13 +84,62 @@ func subscriberCreateHandler(slurpee *app.Application, w http.ResponseWriter, r
 		maxParallel = int32(val)
 	}
 
+	batchSize, batchLingerMs, errMsg := parseBatchSettings(r.FormValue("batch_size"), r.FormValue("batch_linger_ms"))
+	if errMsg != "" {
+		renderSubscribersPage(slurpee, w, r, "", errMsg)
+		return
+	}
+
+	rateLimit, rateLimitBurst, errMsg := parseRateLimitSettings(r.FormValue("rate_limit_per_second"), r.FormValue("rate_limit_burst"))
+	if errMsg != "" {
+		renderSubscribersPage(slurpee, w, r, "", errMsg)
+		return
+	}
+
+	nonRetryable, err := app.ParseStatusList(r.FormValue("non_retryable_statuses"))
+	if err != nil {
+		renderSubscribersPage(slurpee, w, r, "", nonRetryableStatusesError)
+		return
+	}
+
+	clientSettings, errMsg := parseHTTPSettingsForm(slurpee, r, "")
+	if errMsg != "" {
+		renderSubscribersPage(slurpee, w, r, "", errMsg)
+		return
+	}
+
+	oauth, errMsg := parseOAuthForm(slurpee, r, "")
+	if errMsg != "" {
+		renderSubscribersPage(slurpee, w, r, "", errMsg)
+		return
+	}
+
 	newID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 	sub, err := slurpee.DB.UpsertSubscriber(r.Context(), db.UpsertSubscriberParams{
-		ID:          newID,
-		Name:        name,
-		EndpointUrl: endpointURL,
-		AuthSecret:  authSecret,
-		MaxParallel: maxParallel,
+		ID:                         newID,
+		Name:                       name,
+		EndpointUrl:                endpointURL,
+		AuthSecret:                 authSecret,
+		MaxParallel:                maxParallel,
+		SigningMode:                signingMode,
+		PayloadFormat:              payloadFormat,
+		BatchSize:                  batchSize,
+		BatchLingerMs:              batchLingerMs,
+		NonRetryableStatuses:       nonRetryable,
+		DisableOnGone:              r.FormValue("disable_on_gone") == "true",
+		HttpMethod:                 clientSettings.method,
+		TimeoutSeconds:             clientSettings.timeout,
+		ExtraHeaders:               clientSettings.extraHeaders,
+		CaBundle:                   clientSettings.caBundle,
+		ClientCert:                 clientSettings.clientCert,
+		ClientKeyEncrypted:         clientSettings.clientKeyEncrypted,
+		AuthMode:                   oauth.authMode,
+		OauthTokenUrl:              oauth.tokenURL,
+		OauthClientID:              oauth.clientID,
+		OauthClientSecretEncrypted: oauth.secretEncrypted,
+		OauthScopes:                oauth.scopes,
+		RateLimitPerSecond:         rateLimit,
+		RateLimitBurst:             rateLimitBurst,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error creating subscriber", "err", err)
@@ -74,6 +152,275 @@ func subscriberCreateHandler(slurpee *app.Application, w http.ResponseWriter, r
 	http.Redirect(w, r, "/subscribers/"+pgtypeUUIDToString(sub.ID), http.StatusSeeOther)
 }
 
+const nonRetryableStatusesError = "Non-retryable statuses must be comma-separated 4xx codes other than 408 and 429"
+
+// formatIntList renders a list of numbers, such as response codes or retry
+// delays, as comma-separated text for a form field.
+func formatIntList(values []int32) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// retryPolicySettings holds the retry policy fields of a subscription form.
+type retryPolicySettings struct {
+	policy   string
+	interval int32
+	schedule []int32
+	maxAge   pgtype.Int4
+}
+
+// parseRetryPolicyForm parses the retry policy fields of a subscription form.
+// Returns an error message if any field is invalid.
+func parseRetryPolicyForm(r *http.Request) (retryPolicySettings, string) {
+	settings := retryPolicySettings{policy: r.FormValue("retry_policy"), interval: 1}
+	if settings.policy == "" {
+		settings.policy = app.RetryPolicyExponential
+	}
+	if !app.ValidRetryPolicy(settings.policy) {
+		return settings, "Unknown retry policy"
+	}
+	if intervalStr := strings.TrimSpace(r.FormValue("retry_interval_seconds")); intervalStr != "" {
+		val, err := strconv.ParseInt(intervalStr, 10, 32)
+		if err != nil || !app.ValidRetryInterval(int32(val)) {
+			return settings, fmt.Sprintf("Retry interval must be between 1 and %d seconds", app.MaxRetryIntervalSeconds)
+		}
+		settings.interval = int32(val)
+	}
+	schedule, err := app.ParseRetrySchedule(r.FormValue("retry_schedule"))
+	if err != nil {
+		return settings, fmt.Sprintf("Retry schedule must be up to %d comma-separated delays between 1 and %d seconds", app.MaxRetryScheduleLength, app.MaxRetryIntervalSeconds)
+	}
+	if settings.policy == app.RetryPolicySchedule && len(schedule) == 0 {
+		return settings, "The custom schedule policy requires a retry schedule"
+	}
+	if settings.policy != app.RetryPolicySchedule && len(schedule) > 0 {
+		return settings, "A retry schedule requires the custom schedule policy"
+	}
+	settings.schedule = schedule
+	if maxAgeStr := strings.TrimSpace(r.FormValue("retry_max_age_seconds")); maxAgeStr != "" {
+		val, err := strconv.ParseInt(maxAgeStr, 10, 32)
+		if err != nil || val < 1 {
+			return settings, "Max retry age must be a positive number of seconds"
+		}
+		settings.maxAge = pgtype.Int4{Int32: int32(val), Valid: true}
+	}
+	return settings, ""
+}
+
+// formatRetryPolicy summarizes a subscription's retry policy for the
+// subscriptions table, e.g. "exponential-full-jitter from 2s, max age 3600s".
+func formatRetryPolicy(s db.Subscription) string {
+	var summary string
+	switch s.RetryPolicy {
+	case app.RetryPolicySchedule:
+		delays := make([]string, len(s.RetrySchedule))
+		for i, seconds := range s.RetrySchedule {
+			delays[i] = fmt.Sprintf("%ds", seconds)
+		}
+		summary = "schedule " + strings.Join(delays, ", ")
+	case app.RetryPolicyFixed:
+		summary = fmt.Sprintf("fixed %ds", s.RetryIntervalSeconds)
+	default:
+		summary = fmt.Sprintf("%s from %ds", s.RetryPolicy, s.RetryIntervalSeconds)
+	}
+	if s.RetryMaxAgeSeconds.Valid {
+		summary += fmt.Sprintf(", max age %ds", s.RetryMaxAgeSeconds.Int32)
+	}
+	return summary
+}
+
+// httpSettings holds the HTTP client fields of a subscriber form.
+type httpSettings struct {
+	method             string
+	timeout            int32
+	extraHeaders       []byte
+	caBundle           string
+	clientCert         string
+	clientKeyEncrypted string
+}
+
+// parseHTTPSettingsForm parses the HTTP client fields of a subscriber form.
+// A blank client key keeps existingKey as long as a client certificate is
+// still set. Returns an error message if any field is invalid.
+func parseHTTPSettingsForm(slurpee *app.Application, r *http.Request, existingKey string) (httpSettings, string) {
+	settings := httpSettings{
+		method:     r.FormValue("http_method"),
+		timeout:    app.DefaultDeliveryTimeoutSeconds,
+		caBundle:   strings.TrimSpace(r.FormValue("ca_bundle")),
+		clientCert: strings.TrimSpace(r.FormValue("client_cert")),
+	}
+	if settings.method == "" {
+		settings.method = http.MethodPost
+	}
+	if !app.ValidDeliveryMethod(settings.method) {
+		return settings, "HTTP method must be POST, PUT, or PATCH"
+	}
+	if timeoutStr := strings.TrimSpace(r.FormValue("timeout_seconds")); timeoutStr != "" {
+		val, err := strconv.ParseInt(timeoutStr, 10, 32)
+		if err != nil || !app.ValidDeliveryTimeout(int32(val)) {
+			return settings, fmt.Sprintf("Timeout must be between 1 and %d seconds", app.MaxDeliveryTimeoutSeconds)
+		}
+		settings.timeout = int32(val)
+	}
+
+	headers := map[string]string{}
+	for _, line := range strings.Split(r.FormValue("extra_headers"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return settings, "Extra headers must be one \"Name: value\" per line"
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	settings.extraHeaders, _ = json.Marshal(headers)
+	if _, err := app.ParseExtraHeaders(settings.extraHeaders); err != nil {
+		return settings, "Extra headers cannot set Content-Type, Host, or X-Slurpee-, X-Event-, and Ce- headers"
+	}
+
+	clientKey := strings.TrimSpace(r.FormValue("client_key"))
+	if clientKey == "" && settings.clientCert != "" && existingKey != "" {
+		// Validate the certificate against the key already stored
+		decrypted, err := app.DecryptSecret(slurpee.Config.EncryptionKey, existingKey)
+		if err != nil {
+			return settings, "The stored client key cannot be decrypted; enter it again"
+		}
+		if err := app.ValidateTLSSettings(settings.caBundle, settings.clientCert, decrypted); err != nil {
+			return settings, "Invalid TLS settings: " + err.Error()
+		}
+		settings.clientKeyEncrypted = existingKey
+		return settings, ""
+	}
+	if err := app.ValidateTLSSettings(settings.caBundle, settings.clientCert, clientKey); err != nil {
+		return settings, "Invalid TLS settings: " + err.Error()
+	}
+	if clientKey != "" {
+		encrypted, err := app.EncryptSecret(slurpee.Config.EncryptionKey, clientKey)
+		if err != nil {
+			return settings, "A client key can only be stored when ENCRYPTION_KEY is configured"
+		}
+		settings.clientKeyEncrypted = encrypted
+	}
+	return settings, ""
+}
+
+// oauthSettings holds the OAuth2 fields of a subscriber form.
+type oauthSettings struct {
+	authMode        string
+	tokenURL        string
+	clientID        string
+	secretEncrypted string
+	scopes          []string
+}
+
+// parseOAuthForm parses the auth mode and OAuth2 fields of a subscriber form.
+// A blank client secret keeps existingSecret. Returns an error message if any
+// field is invalid.
+func parseOAuthForm(slurpee *app.Application, r *http.Request, existingSecret string) (oauthSettings, string) {
+	settings := oauthSettings{authMode: r.FormValue("auth_mode"), scopes: []string{}}
+	if settings.authMode == "" {
+		settings.authMode = app.AuthModeNone
+	}
+	if !app.ValidAuthMode(settings.authMode) {
+		return settings, "Invalid auth mode"
+	}
+	if settings.authMode != app.AuthModeOAuth2 {
+		return settings, ""
+	}
+
+	settings.tokenURL = strings.TrimSpace(r.FormValue("oauth_token_url"))
+	settings.clientID = strings.TrimSpace(r.FormValue("oauth_client_id"))
+	settings.scopes = strings.Fields(r.FormValue("oauth_scopes"))
+	if !app.ValidTokenURL(settings.tokenURL) {
+		return settings, "OAuth2 token URL must be an http or https URL"
+	}
+	if settings.clientID == "" {
+		return settings, "OAuth2 client ID is required"
+	}
+	if strings.Contains(strings.ToLower(r.FormValue("extra_headers")), "authorization:") {
+		return settings, "Extra headers cannot set Authorization when using OAuth2"
+	}
+
+	clientSecret := r.FormValue("oauth_client_secret")
+	if clientSecret == "" {
+		if existingSecret == "" {
+			return settings, "OAuth2 client secret is required"
+		}
+		settings.secretEncrypted = existingSecret
+		return settings, ""
+	}
+	encrypted, err := app.EncryptSecret(slurpee.Config.EncryptionKey, clientSecret)
+	if err != nil {
+		return settings, "An OAuth2 client secret can only be stored when ENCRYPTION_KEY is configured"
+	}
+	settings.secretEncrypted = encrypted
+	return settings, ""
+}
+
+// formatExtraHeaders renders a subscriber's extra headers as "Name: value"
+// lines for the form, sorted by name.
+func formatExtraHeaders(raw []byte) string {
+	headers, _ := app.ParseExtraHeaders(raw)
+	lines := make([]string, 0, len(headers))
+	for name, value := range headers {
+		lines = append(lines, name+": "+value)
+	}
+	slices.Sort(lines)
+	return strings.Join(lines, "\n")
+}
+
+// parseBatchSettings parses the batch size and linger form fields. Empty
+// fields disable batching. Returns an error message if either is invalid.
+func parseBatchSettings(sizeStr, lingerStr string) (int32, int32, string) {
+	batchSize := int64(1)
+	if sizeStr != "" {
+		val, err := strconv.ParseInt(sizeStr, 10, 32)
+		if err != nil || val < 1 || val > app.MaxBatchSize {
+			return 0, 0, fmt.Sprintf("Batch size must be between 1 and %d", app.MaxBatchSize)
+		}
+		batchSize = val
+	}
+	var batchLingerMs int64
+	if lingerStr != "" {
+		val, err := strconv.ParseInt(lingerStr, 10, 32)
+		if err != nil || val < 0 || val > app.MaxBatchLingerMs {
+			return 0, 0, fmt.Sprintf("Batch linger must be between 0 and %d ms", app.MaxBatchLingerMs)
+		}
+		batchLingerMs = val
+	}
+	return int32(batchSize), int32(batchLingerMs), ""
+}
+
+// parseRateLimitSettings parses the rate limit and burst form fields. Empty
+// fields disable rate limiting. Returns an error message if either is invalid.
+func parseRateLimitSettings(rateStr, burstStr string) (float64, int32, string) {
+	var rate float64
+	if rateStr = strings.TrimSpace(rateStr); rateStr != "" {
+		val, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || !app.ValidRateLimit(val, 0) {
+			return 0, 0, fmt.Sprintf("Rate limit must be between 0 and %d per second", app.MaxRateLimitPerSecond)
+		}
+		rate = val
+	}
+	var burst int64
+	if burstStr = strings.TrimSpace(burstStr); burstStr != "" {
+		val, err := strconv.ParseInt(burstStr, 10, 32)
+		if err != nil || !app.ValidRateLimit(0, int32(val)) {
+			return 0, 0, fmt.Sprintf("Rate limit burst must be between 0 and %d", app.MaxRateLimitBurst)
+		}
+		burst = val
+	}
+	if burst > 0 && rate == 0 {
+		return 0, 0, "A rate limit burst requires a rate limit"
+	}
+	return rate, int32(burst), ""
+}
+
 func renderSubscribersPage(slurpee *app.Application, w http.ResponseWriter, r *http.Request, successMsg, errorMsg string) {
 	subscribers, err := slurpee.DB.ListSubscribersWithCounts(r.Context())
 	if err != nil {
@@ -82,6 +429,18 @@ func renderSubscribersPage(slurpee *app.Application, w http.ResponseWriter, r *h
 		return
 	}
 
+	since := pgtype.Timestamptz{Time: time.Now().Add(-latencyWindow), Valid: true}
+	stats, err := slurpee.DB.ListSubscriberLatencyStats(r.Context(), since)
+	if err != nil {
+		log(r.Context()).Error("Error getting subscriber latency stats", "err", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	latencies := make(map[pgtype.UUID]db.ListSubscriberLatencyStatsRow, len(stats))
+	for _, s := range stats {
+		latencies[s.SubscriberID] = s
+	}
+
 	rows := make([]SubscriberRow, len(subscribers))
 	for i, s := range subscribers {
 		rows[i] = SubscriberRow{
@@ -90,8 +449,22 @@ func renderSubscribersPage(slurpee *app.Application, w http.ResponseWriter, r *h
 			EndpointURL:       s.EndpointUrl,
 			MaxParallel:       s.MaxParallel,
 			SubscriptionCount: int(s.SubscriptionCount),
+			BreakerState:      string(app.SubscriberBreakerState(slurpee, s.ID)),
+			QueuedTasks:       app.SubscriberQueuedTasks(slurpee, s.ID),
+			Disabled:          s.DisabledAt.Valid,
+			Paused:            s.PausedAt.Valid,
 			CreatedAt:         s.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
 		}
+		if l, ok := latencies[s.ID]; ok {
+			if l.Attempts > 0 {
+				rows[i].Duration = fmt.Sprintf("%s / %s / %s",
+					formatMillis(l.DurationP50Ms), formatMillis(l.DurationP95Ms), formatMillis(l.DurationP99Ms))
+			}
+			if l.Delivered > 0 {
+				rows[i].Lag = fmt.Sprintf("%s / %s / %s",
+					formatMillis(l.LagP50Ms), formatMillis(l.LagP95Ms), formatMillis(l.LagP99Ms))
+			}
+		}
 	}
 
 	if errorMsg != "" {
@@ -151,17 +524,89 @@ func subscriberUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r
 		return
 	}
 
+	signingMode := r.FormValue("signing_mode")
+	if signingMode == "" {
+		signingMode = app.SigningModeSecret
+	}
+	if !app.ValidSigningMode(signingMode) {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Invalid signing mode")
+		return
+	}
+
+	payloadFormat := r.FormValue("payload_format")
+	if payloadFormat == "" {
+		payloadFormat = app.PayloadFormatData
+	}
+	if !app.ValidPayloadFormat(payloadFormat) {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Invalid payload format")
+		return
+	}
+
 	maxParallel, err := strconv.ParseInt(maxParallelStr, 10, 32)
 	if err != nil || maxParallel < 1 {
 		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Max parallel must be a positive integer")
 		return
 	}
 
+	batchSize, batchLingerMs, errMsg := parseBatchSettings(r.FormValue("batch_size"), r.FormValue("batch_linger_ms"))
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+
+	rateLimit, rateLimitBurst, errMsg := parseRateLimitSettings(r.FormValue("rate_limit_per_second"), r.FormValue("rate_limit_burst"))
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+
+	nonRetryable, err := app.ParseStatusList(r.FormValue("non_retryable_statuses"))
+	if err != nil {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, nonRetryableStatusesError)
+		return
+	}
+
+	existing, err := slurpee.DB.GetSubscriberByID(r.Context(), pgID)
+	if err != nil {
+		log(r.Context()).Error("Error fetching subscriber", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to update subscriber")
+		return
+	}
+	clientSettings, errMsg := parseHTTPSettingsForm(slurpee, r, existing.ClientKeyEncrypted)
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+	oauth, errMsg := parseOAuthForm(slurpee, r, existing.OauthClientSecretEncrypted)
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+
 	_, err = slurpee.DB.UpdateSubscriber(r.Context(), db.UpdateSubscriberParams{
-		ID:          pgID,
-		Name:        name,
-		AuthSecret:  authSecret,
-		MaxParallel: int32(maxParallel),
+		ID:                         pgID,
+		Name:                       name,
+		AuthSecret:                 authSecret,
+		MaxParallel:                int32(maxParallel),
+		SigningMode:                signingMode,
+		PayloadFormat:              payloadFormat,
+		BatchSize:                  batchSize,
+		BatchLingerMs:              batchLingerMs,
+		NonRetryableStatuses:       nonRetryable,
+		DisableOnGone:              r.FormValue("disable_on_gone") == "true",
+		HttpMethod:                 clientSettings.method,
+		TimeoutSeconds:             clientSettings.timeout,
+		ExtraHeaders:               clientSettings.extraHeaders,
+		CaBundle:                   clientSettings.caBundle,
+		ClientCert:                 clientSettings.clientCert,
+		ClientKeyEncrypted:         clientSettings.clientKeyEncrypted,
+		AuthMode:                   oauth.authMode,
+		OauthTokenUrl:              oauth.tokenURL,
+		OauthClientID:              oauth.clientID,
+		OauthClientSecretEncrypted: oauth.secretEncrypted,
+		OauthScopes:                oauth.scopes,
+		RateLimitPerSecond:         rateLimit,
+		RateLimitBurst:             rateLimitBurst,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error updating subscriber", "err", err)
@@ -182,6 +627,91 @@ func subscriberUpdateHandler(slurpee *app.Application, w http.ResponseWriter, r
 	}
 }
 
+func subscriberEnableHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+	pgID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	if err := slurpee.DB.EnableSubscriber(r.Context(), pgID); err != nil {
+		log(r.Context()).Error("Error enabling subscriber", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to enable subscriber")
+		return
+	}
+
+	slurpee.SubscriptionCache.Flush()
+
+	detail, subRows, err := buildSubscriberDetailView(slurpee, r, pgID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := subscriberDetailContent(detail, subRows, "Subscriber enabled", "").Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering subscriber detail view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func subscriberPauseHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+	pgID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	if err := app.PauseSubscriber(r.Context(), slurpee, pgID); err != nil {
+		log(r.Context()).Error("Error pausing subscriber", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to pause subscriber")
+		return
+	}
+
+	detail, subRows, err := buildSubscriberDetailView(slurpee, r, pgID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := subscriberDetailContent(detail, subRows, "Subscriber paused", "").Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering subscriber detail view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func subscriberResumeHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+	pgID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	released, err := app.ResumeSubscriber(r.Context(), slurpee, pgID)
+	if err != nil {
+		log(r.Context()).Error("Error resuming subscriber", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to resume subscriber")
+		return
+	}
+
+	detail, subRows, err := buildSubscriberDetailView(slurpee, r, pgID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	msg := fmt.Sprintf("Subscriber resumed; %d held deliveries released", released)
+	if err := subscriberDetailContent(detail, subRows, msg, "").Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering subscriber detail view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 func subscriptionCreateHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	parsed, err := uuid.Parse(idStr)
@@ -198,12 +728,24 @@ func subscriptionCreateHandler(slurpee *app.Application, w http.ResponseWriter,
 
 	subjectPattern := r.FormValue("subject_pattern")
 	filterStr := r.FormValue("filter")
+	transformStr := strings.TrimSpace(r.FormValue("transform"))
 	maxRetriesStr := r.FormValue("max_retries")
+	ordered := r.FormValue("ordered") == "true"
+	orderingKey := strings.TrimSpace(r.FormValue("ordering_key"))
 
 	if subjectPattern == "" {
 		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Subject pattern is required")
 		return
 	}
+	patternSyntax, errMsg := parsePatternSyntax(r, subjectPattern)
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+	if orderingKey != "" && !ordered {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Ordering key requires ordered delivery")
+		return
+	}
 
 	var filter []byte
 	if filterStr != "" {
@@ -211,9 +753,22 @@ func subscriptionCreateHandler(slurpee *app.Application, w http.ResponseWriter,
 			renderSubscriberDetailWithError(slurpee, w, r, pgID, "Filter must be valid JSON")
 			return
 		}
+		if _, err := app.ParseFilter([]byte(filterStr)); err != nil {
+			renderSubscriberDetailWithError(slurpee, w, r, pgID, "Invalid filter: "+err.Error())
+			return
+		}
 		filter = []byte(filterStr)
 	}
 
+	var transform []byte
+	if transformStr != "" {
+		if _, err := app.ParseTransform([]byte(transformStr)); err != nil {
+			renderSubscriberDetailWithError(slurpee, w, r, pgID, "Invalid transform: "+err.Error())
+			return
+		}
+		transform = []byte(transformStr)
+	}
+
 	var maxRetries pgtype.Int4
 	if maxRetriesStr != "" {
 		val, err := strconv.ParseInt(maxRetriesStr, 10, 32)
@@ -224,13 +779,27 @@ func subscriptionCreateHandler(slurpee *app.Application, w http.ResponseWriter,
 		maxRetries = pgtype.Int4{Int32: int32(val), Valid: true}
 	}
 
+	retry, errMsg := parseRetryPolicyForm(r)
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+
 	subID := pgtype.UUID{Bytes: uuid.Must(uuid.NewV7()), Valid: true}
 	_, err = slurpee.DB.CreateSubscription(r.Context(), db.CreateSubscriptionParams{
-		ID:             subID,
-		SubscriberID:   pgID,
-		SubjectPattern: subjectPattern,
-		Filter:         filter,
-		MaxRetries:     maxRetries,
+		ID:                   subID,
+		SubscriberID:         pgID,
+		SubjectPattern:       subjectPattern,
+		Filter:               filter,
+		MaxRetries:           maxRetries,
+		Ordered:              ordered,
+		OrderingKey:          orderingKey,
+		RetryPolicy:          retry.policy,
+		RetryIntervalSeconds: retry.interval,
+		RetrySchedule:        retry.schedule,
+		RetryMaxAgeSeconds:   retry.maxAge,
+		PatternSyntax:        patternSyntax,
+		Transform:            transform,
 	})
 	if err != nil {
 		log(r.Context()).Error("Error creating subscription", "err", err)
@@ -288,6 +857,79 @@ func subscriptionDeleteHandler(slurpee *app.Application, w http.ResponseWriter,
 	}
 }
 
+func subscriptionRetryPolicyHandler(slurpee *app.Application, w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	parsed, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+	pgID := pgtype.UUID{Bytes: parsed, Valid: true}
+
+	subIdStr := r.PathValue("subId")
+	subParsed, err := uuid.Parse(subIdStr)
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return
+	}
+	subPgID := pgtype.UUID{Bytes: subParsed, Valid: true}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	retry, errMsg := parseRetryPolicyForm(r)
+	if errMsg != "" {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, errMsg)
+		return
+	}
+
+	subscriptions, err := slurpee.DB.ListSubscriptionsForSubscriber(r.Context(), pgID)
+	if err != nil {
+		log(r.Context()).Error("Error listing subscriptions", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to update retry policy")
+		return
+	}
+	idx := slices.IndexFunc(subscriptions, func(s db.Subscription) bool { return s.ID == subPgID })
+	if idx < 0 {
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Subscription not found")
+		return
+	}
+	existing := subscriptions[idx]
+
+	_, err = slurpee.DB.UpdateSubscription(r.Context(), db.UpdateSubscriptionParams{
+		ID:                   existing.ID,
+		Filter:               existing.Filter,
+		MaxRetries:           existing.MaxRetries,
+		Ordered:              existing.Ordered,
+		OrderingKey:          existing.OrderingKey,
+		RetryPolicy:          retry.policy,
+		RetryIntervalSeconds: retry.interval,
+		RetrySchedule:        retry.schedule,
+		RetryMaxAgeSeconds:   retry.maxAge,
+		PatternSyntax:        existing.PatternSyntax,
+		Transform:            existing.Transform,
+	})
+	if err != nil {
+		log(r.Context()).Error("Error updating subscription retry policy", "err", err)
+		renderSubscriberDetailWithError(slurpee, w, r, pgID, "Failed to update retry policy")
+		return
+	}
+
+	slurpee.SubscriptionCache.Flush()
+
+	detail, subRows, err := buildSubscriberDetailView(slurpee, r, pgID)
+	if err != nil {
+		return
+	}
+
+	if err := subscriberDetailContent(detail, subRows, "Retry policy updated", "").Render(r.Context(), w); err != nil {
+		log(r.Context()).Error("Error rendering subscriber detail view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 func buildSubscriberDetailView(slurpee *app.Application, r *http.Request, pgID pgtype.UUID) (SubscriberDetail, []SubscriptionRow, error) {
 	subscriber, err := slurpee.DB.GetSubscriberByID(r.Context(), pgID)
 	if err != nil {
@@ -305,13 +947,45 @@ func buildSubscriberDetailView(slurpee *app.Application, r *http.Request, pgID p
 	}
 
 	detail := SubscriberDetail{
-		ID:          pgtypeUUIDToString(subscriber.ID),
-		Name:        subscriber.Name,
-		EndpointURL: subscriber.EndpointUrl,
-		AuthSecret:  subscriber.AuthSecret,
-		MaxParallel: subscriber.MaxParallel,
-		CreatedAt:   subscriber.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
-		UpdatedAt:   subscriber.UpdatedAt.Time.Format("2006-01-02 15:04:05 MST"),
+		ID:             pgtypeUUIDToString(subscriber.ID),
+		Name:           subscriber.Name,
+		EndpointURL:    subscriber.EndpointUrl,
+		AuthSecret:     subscriber.AuthSecret,
+		MaxParallel:    subscriber.MaxParallel,
+		SigningMode:    subscriber.SigningMode,
+		PayloadFormat:  subscriber.PayloadFormat,
+		BatchSize:      subscriber.BatchSize,
+		BatchLingerMs:  subscriber.BatchLingerMs,
+		RateLimit:      strconv.FormatFloat(subscriber.RateLimitPerSecond, 'f', -1, 64),
+		RateLimitBurst: subscriber.RateLimitBurst,
+		QueuedTasks:    app.SubscriberQueuedTasks(slurpee, subscriber.ID),
+		NonRetryable:   formatIntList(subscriber.NonRetryableStatuses),
+		DisableOnGone:  subscriber.DisableOnGone,
+		HTTPMethod:     subscriber.HttpMethod,
+		TimeoutSeconds: subscriber.TimeoutSeconds,
+		ExtraHeaders:   formatExtraHeaders(subscriber.ExtraHeaders),
+		CABundle:       subscriber.CaBundle,
+		ClientCert:     subscriber.ClientCert,
+		HasClientKey:   subscriber.ClientKeyEncrypted != "",
+		AuthMode:       subscriber.AuthMode,
+		OAuthTokenURL:  subscriber.OauthTokenUrl,
+		OAuthClientID:  subscriber.OauthClientID,
+		OAuthScopes:    strings.Join(subscriber.OauthScopes, " "),
+		HasOAuthSecret: subscriber.OauthClientSecretEncrypted != "",
+		Disabled:       subscriber.DisabledAt.Valid,
+		DisabledAt:     subscriber.DisabledAt.Time.Format("2006-01-02 15:04:05 MST"),
+		DisabledReason: subscriber.DisabledReason,
+		Paused:         subscriber.PausedAt.Valid,
+		PausedAt:       subscriber.PausedAt.Time.Format("2006-01-02 15:04:05 MST"),
+		CreatedAt:      subscriber.CreatedAt.Time.Format("2006-01-02 15:04:05 MST"),
+		UpdatedAt:      subscriber.UpdatedAt.Time.Format("2006-01-02 15:04:05 MST"),
+	}
+	if detail.Paused {
+		held, err := slurpee.DB.CountHeldDeliveryRetries(r.Context(), pgID)
+		if err != nil {
+			log(r.Context()).Error("Error counting held deliveries", "err", err)
+		}
+		detail.HeldDeliveries = held
 	}
 
 	subRows := make([]SubscriptionRow, len(subscriptions))
@@ -319,10 +993,23 @@ func buildSubscriberDetailView(slurpee *app.Application, r *http.Request, pgID p
 		row := SubscriptionRow{
 			ID:             pgtypeUUIDToString(s.ID),
 			SubjectPattern: s.SubjectPattern,
+			PatternSyntax:  s.PatternSyntax,
+			Ordered:        s.Ordered,
+			OrderingKey:    s.OrderingKey,
+			RetrySummary:   formatRetryPolicy(s),
+			RetryPolicy:    s.RetryPolicy,
+			RetryInterval:  fmt.Sprintf("%d", s.RetryIntervalSeconds),
+			RetrySchedule:  formatIntList(s.RetrySchedule),
+		}
+		if s.RetryMaxAgeSeconds.Valid {
+			row.RetryMaxAge = fmt.Sprintf("%d", s.RetryMaxAgeSeconds.Int32)
 		}
 		if len(s.Filter) > 0 && string(s.Filter) != "null" {
 			row.Filter = prettyJSON(s.Filter)
 		}
+		if len(s.Transform) > 0 && string(s.Transform) != "null" {
+			row.Transform = prettyJSON(s.Transform)
+		}
 		if s.MaxRetries.Valid {
 			row.MaxRetries = fmt.Sprintf("%d", s.MaxRetries.Int32)
 		}
@@ -345,4 +1032,3 @@ func renderSubscriberDetailWithError(slurpee *app.Application, w http.ResponseWr
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
-
@@ -0,0 +1,11 @@
+package webhook
+
+// BatchResponse is the optional JSON body a subscriber returns with a 2xx
+// response to a batched delivery to report events it could not process.
+// Events listed in Failed are retried individually; every other event in the
+// batch is treated as delivered. An empty or non-JSON body accepts the whole
+// batch.
+type BatchResponse struct {
+	// Failed holds the IDs of the events in the batch that were not processed.
+	Failed []string `json:"failed"`
+}
@@ -0,0 +1,2 @@
+// Package webhook provides helpers for subscribers to verify signed Slurpee deliveries.
+package webhook
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderSignature carries the HMAC-SHA256 signature of a delivery.
+	HeaderSignature = "X-Slurpee-Signature"
+	// HeaderTimestamp carries the Unix time (seconds) at which the delivery was signed.
+	HeaderTimestamp = "X-Slurpee-Timestamp"
+	// HeaderEventID carries the ID of the delivered event.
+	HeaderEventID = "X-Event-ID"
+	// HeaderBatchID carries the ID of a batched delivery, signed in place of the event ID.
+	HeaderBatchID = "X-Slurpee-Batch-ID"
+	// HeaderBatchSize carries the number of events in a batched delivery.
+	HeaderBatchSize = "X-Slurpee-Batch-Size"
+
+	// DefaultTolerance is the maximum age of a signed delivery accepted by VerifyRequest.
+	DefaultTolerance = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+// Delivery kinds. The kind is signed along with the delivery's ID, so the
+// signature of a single event cannot be passed off as that of a batch with the
+// same ID, or the reverse.
+const (
+	KindEvent = "event"
+	KindBatch = "batch"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing signature header")
+	ErrInvalidTimestamp = errors.New("invalid timestamp header")
+	ErrTimestampExpired = errors.New("timestamp outside tolerance")
+	ErrInvalidSignature = errors.New("signature mismatch")
+)
+
+// Sign computes the signature header value for a delivery of the given kind:
+// the event ID for KindEvent, the batch ID for KindBatch. The HMAC-SHA256 is
+// taken over "<kind>.<timestamp>.<id>.<body>" using the subscriber's auth
+// secret.
+func Sign(secret string, timestamp int64, kind, id string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s.%d.%s.", kind, timestamp, id)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the given delivery parts.
+func Verify(secret, signature string, timestamp int64, kind, id string, body []byte) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	expected := Sign(secret, timestamp, kind, id, body)
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// VerifyRequest validates the signature headers of an incoming delivery and
+// returns its body. The request body is consumed and replaced so handlers can
+// read it again. A tolerance of zero uses DefaultTolerance. Requests with a
+// batch ID header are verified as batches, others as single events.
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+
+	signature := r.Header.Get(HeaderSignature)
+	if signature == "" {
+		return nil, ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidTimestamp
+	}
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return nil, ErrTimestampExpired
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	kind, id := KindEvent, r.Header.Get(HeaderEventID)
+	if batchID := r.Header.Get(HeaderBatchID); batchID != "" {
+		kind, id = KindBatch, batchID
+	}
+	if !Verify(secret, signature, timestamp, kind, id, body) {
+		return nil, ErrInvalidSignature
+	}
+	return body, nil
+}
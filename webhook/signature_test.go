@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedRequest(t *testing.T, secret string, timestamp int64, eventID string, body []byte) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header.Set(HeaderEventID, eventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, KindEvent, eventID, body))
+	return req
+}
+
+func TestSign_Deterministic(t *testing.T) {
+	a := Sign("secret", 1700000000, KindEvent, "evt-1", []byte(`{"a":1}`))
+	b := Sign("secret", 1700000000, KindEvent, "evt-1", []byte(`{"a":1}`))
+	assert.Equal(t, a, b)
+	assert.Contains(t, a, "sha256=")
+	assert.NotEqual(t, a, Sign("other", 1700000000, KindEvent, "evt-1", []byte(`{"a":1}`)))
+	assert.NotEqual(t, a, Sign("secret", 1700000001, KindEvent, "evt-1", []byte(`{"a":1}`)))
+	assert.NotEqual(t, a, Sign("secret", 1700000000, KindEvent, "evt-2", []byte(`{"a":1}`)))
+	assert.NotEqual(t, a, Sign("secret", 1700000000, KindBatch, "evt-1", []byte(`{"a":1}`)))
+}
+
+func TestVerifyRequest_Valid(t *testing.T) {
+	body := []byte(`{"order_id":"123"}`)
+	req := newSignedRequest(t, "secret", time.Now().Unix(), "evt-1", body)
+
+	got, err := VerifyRequest(req, "secret", 0)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	// Body is still readable by the handler
+	again, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, again)
+}
+
+func TestVerifyRequest_WrongSecret(t *testing.T) {
+	req := newSignedRequest(t, "secret", time.Now().Unix(), "evt-1", []byte(`{}`))
+	_, err := VerifyRequest(req, "wrong", 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyRequest_TamperedBody(t *testing.T) {
+	req := newSignedRequest(t, "secret", time.Now().Unix(), "evt-1", []byte(`{"amount":1}`))
+	req.Body = io.NopCloser(bytes.NewReader([]byte(`{"amount":1000}`)))
+	_, err := VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyRequest_ExpiredTimestamp(t *testing.T) {
+	req := newSignedRequest(t, "secret", time.Now().Add(-10*time.Minute).Unix(), "evt-1", []byte(`{}`))
+	_, err := VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrTimestampExpired)
+}
+
+func TestVerifyRequest_MissingHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader([]byte(`{}`)))
+	_, err := VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrMissingSignature)
+
+	req.Header.Set(HeaderSignature, "sha256=abc")
+	_, err = VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrInvalidTimestamp)
+}
+
+func TestVerifyRequest_BatchSignedWithBatchID(t *testing.T) {
+	body := []byte(`[{"id":"evt-1"},{"id":"evt-2"}]`)
+	timestamp := time.Now().Unix()
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header.Set(HeaderBatchID, "batch-1")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", timestamp, KindBatch, "batch-1", body))
+
+	got, err := VerifyRequest(req, "secret", 0)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	req = httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header.Set(HeaderBatchID, "batch-2")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", timestamp, KindBatch, "batch-1", body))
+	_, err = VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifyRequest_KindIsSigned(t *testing.T) {
+	body := []byte(`[{"id":"evt-1"}]`)
+	timestamp := time.Now().Unix()
+
+	// An event signature does not verify a batch with the same ID
+	req := newSignedRequest(t, "secret", timestamp, "id-1", body)
+	req.Header.Del(HeaderEventID)
+	req.Header.Set(HeaderBatchID, "id-1")
+	_, err := VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// nor does a batch signature verify an event
+	req = httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header.Set(HeaderEventID, "id-1")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign("secret", timestamp, KindBatch, "id-1", body))
+	_, err = VerifyRequest(req, "secret", 0)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}